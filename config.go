@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	stdnet "net"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/destructiqn/kogtevran/config"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/destructiqn/kogtevran/minecraft/protocol"
+	"github.com/destructiqn/kogtevran/proxy"
+	"github.com/destructiqn/kogtevran/upstream"
+)
+
+// settings are the loaded configuration along with what is built from it, they are replaced as a whole on reload
+type settings struct {
+	*config.Config
+	compressor *pk.Compressor
+	// dialers pick how players logging in are connected to their route
+	dialers upstream.DialerStrategy
+}
+
+var currentSettings atomic.Value
+
+// router picks the upstream of sessions by hostname, its routes are replaced on reload
+var router = upstream.NewRouter()
+
+// egress keeps the outbound proxies of sessions, assignments outlive reloads
+var egress = upstream.NewEgressPool()
+
+func getSettings() *settings {
+	return currentSettings.Load().(*settings)
+}
+
+// loadSettings loads and validates the configuration, nothing is applied until it is valid as a whole
+func loadSettings(path string) (*settings, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = proxy.ValidateModuleOptions(cfg.Modules); err != nil {
+		return nil, fmt.Errorf("invalid config: modules: %w", err)
+	}
+
+	for i, route := range cfg.Routes {
+		if err = proxy.ValidateModuleOptions(route.Modules); err != nil {
+			return nil, fmt.Errorf("invalid config: routes[%d].modules: %w", i, err)
+		}
+	}
+
+	compressor, err := pk.NewCompressor(cfg.Connection.CompressionLevel)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: connection.compressionLevel: %w", err)
+	}
+
+	return &settings{Config: cfg, compressor: compressor}, nil
+}
+
+func applySettings(s *settings) error {
+	policy, err := proxy.ParseOverflowPolicy(s.Connection.QueuePolicy)
+	if err != nil {
+		return err
+	}
+
+	dialTimeout := time.Duration(s.Connection.DialTimeout)
+	defaultRoute, err := newRoute(config.RouteConfig{Name: config.DefaultRoute, Upstream: s.Upstream}, s.Modules, dialTimeout)
+	if err != nil {
+		return err
+	}
+
+	routes := []*upstream.Route{defaultRoute}
+	for _, routeConfig := range s.Routes {
+		route, err := newRoute(routeConfig, s.Modules, dialTimeout)
+		if err != nil {
+			return err
+		}
+
+		routes = append(routes, route)
+	}
+
+	dialers, err := newDialerStrategy(s.Egress, dialTimeout)
+	if err != nil {
+		return err
+	}
+
+	router.Update(routes)
+	s.dialers = dialers
+	proxy.SetSettings(proxy.Settings{
+		QueueCapacity:       s.Connection.QueueCapacity,
+		QueueOverflowPolicy: policy,
+		KeepAliveInterval:   time.Duration(s.Auxiliary.KeepAliveInterval),
+		ModuleOptions:       s.Modules,
+	})
+	protocol.SetLimits(map[protocol.ConnectionState]*pk.Limits{
+		protocol.ConnStateHandshake: packetLimits(s.Limits.Handshake),
+		protocol.ConnStateStatus:    packetLimits(s.Limits.Status),
+		protocol.ConnStateLogin:     packetLimits(s.Limits.Login),
+		protocol.ConnStatePlay:      packetLimits(s.Limits.Play),
+	})
+
+	currentSettings.Store(s)
+	return nil
+}
+
+// packetLimits returns the decoding limits of a state from its section
+func packetLimits(limits config.StateLimits) *pk.Limits {
+	return &pk.Limits{
+		MaxPacketLength: limits.MaxPacketLength,
+		MaxStringLength: limits.MaxStringLength,
+		MaxArrayLength:  limits.MaxArrayLength,
+		MaxNBTDepth:     limits.MaxNBTDepth,
+		MaxNBTSize:      limits.MaxNBTSize,
+	}
+}
+
+// newRoute builds the route from its section, the module options of the route override modules.
+// Connecting to a node fails after the dial timeout, the next node is tried then
+func newRoute(routeConfig config.RouteConfig, modules map[string]map[string]string, dialTimeout time.Duration) (*upstream.Route, error) {
+	u := routeConfig.Upstream
+	strategy, err := upstream.ParseStrategy(u.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]upstream.Target, 0, len(u.Addresses))
+	for _, address := range u.Addresses {
+		targets = append(targets, upstream.Target{Address: u.NodeAddress(address), Weight: u.Weight(address)})
+	}
+
+	dial := upstream.Dialer((&stdnet.Dialer{Timeout: dialTimeout}).Dial)
+	if u.ProxyAddress != "" {
+		proxy, err := upstream.NewEgress(routeConfig.Name, u.ProxyAddress, u.ProxyProtocol, dialTimeout)
+		if err != nil {
+			return nil, err
+		}
+
+		dial = proxy.Dial
+	}
+
+	profile := make(map[string]map[string]string, len(modules))
+	for _, options := range []map[string]map[string]string{modules, routeConfig.Modules} {
+		for identifier, values := range options {
+			if profile[identifier] == nil {
+				profile[identifier] = make(map[string]string, len(values))
+			}
+
+			for option, value := range values {
+				profile[identifier][option] = value
+			}
+		}
+	}
+
+	return &upstream.Route{
+		Name:      routeConfig.Name,
+		Hostnames: routeConfig.Hostnames,
+		Targets:   targets,
+		Strategy:  strategy,
+		Interval:  time.Duration(u.HealthCheckInterval),
+		Timeout:   time.Duration(u.HealthCheckTimeout),
+		Dial:      dial,
+		Modules:   profile,
+	}, nil
+}
+
+// newDialerStrategy connects players through the egress pool when it has proxies, directly otherwise
+func newDialerStrategy(egressConfig config.EgressConfig, dialTimeout time.Duration) (upstream.DialerStrategy, error) {
+	key, err := upstream.ParseSessionKey(egressConfig.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	egresses := make([]*upstream.Egress, 0, len(egressConfig.Proxies))
+	for _, proxy := range egressConfig.Proxies {
+		e, err := upstream.NewEgress(proxy.Name, proxy.Address, proxy.Protocol, dialTimeout)
+		if err != nil {
+			return nil, err
+		}
+
+		egresses = append(egresses, e)
+	}
+
+	egress.Update(egresses, key, egressConfig.Assignments, time.Duration(egressConfig.Cooldown))
+	if len(egresses) == 0 {
+		return upstream.DirectStrategy{}, nil
+	}
+
+	return egress, nil
+}
+
+// reloadOnSignal reloads the configuration on SIGHUP, the settings apply to sessions started afterwards.
+// The listeners are not restarted, changes to them are reported and ignored
+func reloadOnSignal(path string) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	for range signals {
+		s, err := loadSettings(path)
+		if err != nil {
+			log.Println("keeping the current config, unable to reload:", err)
+			continue
+		}
+
+		if listen := getSettings().Listen; !reflect.DeepEqual(s.Listen, listen) {
+			log.Println("listener settings cannot be changed without a restart, they are ignored")
+			s.Listen = listen
+		}
+
+		if err = applySettings(s); err != nil {
+			log.Println("keeping the current config, unable to apply:", err)
+			continue
+		}
+
+		log.Println("reloaded config")
+	}
+}
@@ -0,0 +1,518 @@
+// Package config loads the settings of the proxy. They are read from a JSON file selected with the --config flag
+// or KV_CONFIG, see config.example.json in the root of the repository. Fields missing from the file keep their
+// defaults, unknown fields are an error.
+//
+// Environment variables override the file:
+//
+//	KV_LISTEN_ADDR, KV_AUXILIARY_ADDR, KV_METRICS_ADDR     listen.minecraft, listen.auxiliary, listen.metrics
+//	KV_CERT_PATH, KV_CERT_KEY_PATH                         listen.certPath, listen.keyPath
+//	KV_UPSTREAM_ADDRS                                      upstream.addresses, separated by commas
+//	KV_UPSTREAM_PORT                                       upstream.port
+//	KV_UPSTREAM_STRATEGY                                   upstream.strategy
+//	KV_UPSTREAM_CHECK_INTERVAL, KV_UPSTREAM_CHECK_TIMEOUT  upstream.healthCheckInterval, upstream.healthCheckTimeout
+//	KV_PROXY_ADDR, KV_PROXY_PROTOCOL                       upstream.proxyAddress, upstream.proxyProtocol
+//	KV_COMPRESSION_THRESHOLD, KV_COMPRESSION_LEVEL         connection.compressionThreshold, connection.compressionLevel
+//	KV_QUEUE_CAPACITY, KV_QUEUE_POLICY                     connection.queueCapacity, connection.queuePolicy
+//	KV_DIAL_TIMEOUT                                        connection.dialTimeout
+//	KV_HANDSHAKE_TIMEOUT, KV_LOGIN_TIMEOUT                 connection.handshakeTimeout, connection.loginTimeout
+//	KV_KEEPALIVE_INTERVAL                                  auxiliary.keepAliveInterval
+//	KV_EGRESS_KEY, KV_EGRESS_COOLDOWN                      egress.key, egress.cooldown
+//
+// Players are sent to upstream unless one of the routes has the hostname they connected with. Routes are
+// configured in the file only, the upstream fields they leave out are taken from the upstream section.
+// So are the egress proxies, when there are any players logging in connect through them instead of the proxy
+// of their route.
+//
+// The limits section bounds what either side of a connection can make the proxy decode in each state, players
+// whose connections send more are disconnected.
+//
+// The proxy reloads the file on SIGHUP. Everything but the listen section applies to sessions started afterwards.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	Listen     ListenConfig     `json:"listen"`
+	Upstream   UpstreamConfig   `json:"upstream"`
+	Connection ConnectionConfig `json:"connection"`
+	Auxiliary  AuxiliaryConfig  `json:"auxiliary"`
+	// Routes send players connecting with their hostnames to other servers
+	Routes []RouteConfig `json:"routes"`
+	Egress EgressConfig  `json:"egress"`
+	// Modules override the defaults of module options, keyed by module identifier and option name.
+	// Values are parsed like the /set command does, e.g. {"KillAura": {"interval": "50ms"}}
+	Modules map[string]map[string]string `json:"modules"`
+	Limits  LimitsConfig                 `json:"limits"`
+}
+
+// ListenConfig cannot be changed by a reload, the listeners are started once
+type ListenConfig struct {
+	Minecraft string `json:"minecraft" env:"KV_LISTEN_ADDR"`
+	Auxiliary string `json:"auxiliary" env:"KV_AUXILIARY_ADDR"`
+	Metrics   string `json:"metrics" env:"KV_METRICS_ADDR"`
+	// The auxiliary listener serves TLS when both are set
+	CertPath string `json:"certPath" env:"KV_CERT_PATH"`
+	KeyPath  string `json:"keyPath" env:"KV_CERT_KEY_PATH"`
+}
+
+type UpstreamConfig struct {
+	// Addresses are the nodes of the server, either hosts connected to on the port or host:port addresses
+	Addresses []string `json:"addresses" env:"KV_UPSTREAM_ADDRS"`
+	Port      int      `json:"port" env:"KV_UPSTREAM_PORT"`
+	// Weights of the addresses, those missing weigh 1. Nodes weighing 0 are not picked for new sessions
+	Weights map[string]int `json:"weights"`
+	// Strategy is weighted, picking nodes at random by weight, or leastConnections
+	Strategy string `json:"strategy" env:"KV_UPSTREAM_STRATEGY"`
+	// Nodes are pinged on the interval, those not answering within the timeout are considered down
+	HealthCheckInterval Duration `json:"healthCheckInterval" env:"KV_UPSTREAM_CHECK_INTERVAL"`
+	HealthCheckTimeout  Duration `json:"healthCheckTimeout" env:"KV_UPSTREAM_CHECK_TIMEOUT"`
+	// Connections to the server go through the proxy when its address is set, the protocol is socks4, socks4a,
+	// socks5 or http
+	ProxyAddress  string `json:"proxyAddress" env:"KV_PROXY_ADDR"`
+	ProxyProtocol string `json:"proxyProtocol" env:"KV_PROXY_PROTOCOL"`
+}
+
+// DefaultRoute is the name of the route made of the upstream section, routes cannot be named like it
+const DefaultRoute = "default"
+
+type RouteConfig struct {
+	Name string `json:"name"`
+	// Hostnames are those typed in the client, e.g. survival.proxy.local. A leading "*." matches any subdomain
+	Hostnames []string `json:"hostnames"`
+	// Upstream fields left out are taken from the upstream section, addresses and weights are not
+	Upstream UpstreamConfig `json:"upstream"`
+	// Modules override the module options of the modules section for players on the route
+	Modules map[string]map[string]string `json:"modules"`
+}
+
+// EgressConfig is the pool of outbound proxies players are connected through, sessions are assigned one
+// by username or license when they log in
+type EgressConfig struct {
+	Proxies []EgressProxyConfig `json:"proxies"`
+	// Key is what sessions keep their proxy by, username or license. Sessions without a license use the username
+	Key string `json:"key" env:"KV_EGRESS_KEY"`
+	// Assignments limit usernames or license IDs to the named proxies, the others are spread over all of them
+	Assignments map[string][]string `json:"assignments"`
+	// A proxy which fails to connect is skipped for the cooldown, its sessions rotate to the next one
+	Cooldown Duration `json:"cooldown" env:"KV_EGRESS_COOLDOWN"`
+}
+
+type EgressProxyConfig struct {
+	Name string `json:"name"`
+	// Address is host:port, the protocol is socks4, socks4a, socks5 or http
+	Address  string `json:"address"`
+	Protocol string `json:"protocol"`
+}
+
+type ConnectionConfig struct {
+	// CompressionThreshold is the size packets to the client are compressed from, -1 disables compression
+	CompressionThreshold int `json:"compressionThreshold" env:"KV_COMPRESSION_THRESHOLD"`
+	// CompressionLevel is the zlib level from -2 (Huffman only) to 9 (best compression)
+	CompressionLevel int `json:"compressionLevel" env:"KV_COMPRESSION_LEVEL"`
+	// QueueCapacity is the number of packets waiting to be written to either side of a tunnel,
+	// QueuePolicy is what happens when it is exceeded: drop the packet or disconnect
+	QueueCapacity int    `json:"queueCapacity" env:"KV_QUEUE_CAPACITY"`
+	QueuePolicy   string `json:"queuePolicy" env:"KV_QUEUE_POLICY"`
+	// DialTimeout bounds connecting to a node, through the proxy if there is one. The client has HandshakeTimeout
+	// to send its handshake and LoginTimeout from then on to get into the game
+	DialTimeout      Duration `json:"dialTimeout" env:"KV_DIAL_TIMEOUT"`
+	HandshakeTimeout Duration `json:"handshakeTimeout" env:"KV_HANDSHAKE_TIMEOUT"`
+	LoginTimeout     Duration `json:"loginTimeout" env:"KV_LOGIN_TIMEOUT"`
+}
+
+type AuxiliaryConfig struct {
+	// KeepAliveInterval is how often the auxiliary client is pinged, it is dropped after missing two pings
+	KeepAliveInterval Duration `json:"keepAliveInterval" env:"KV_KEEPALIVE_INTERVAL"`
+}
+
+// LimitsConfig has the decoding limits of each connection state, they apply to connections entering the state
+type LimitsConfig struct {
+	Handshake StateLimits `json:"handshake"`
+	Status    StateLimits `json:"status"`
+	Login     StateLimits `json:"login"`
+	Play      StateLimits `json:"play"`
+}
+
+// StateLimits bound the packets of a state, a limit of zero forbids the respective data
+type StateLimits struct {
+	// MaxPacketLength bounds frames and the uncompressed length of compressed packets
+	MaxPacketLength int `json:"maxPacketLength"`
+	// MaxStringLength is in bytes, not in characters
+	MaxStringLength int `json:"maxStringLength"`
+	MaxArrayLength  int `json:"maxArrayLength"`
+	// MaxNBTDepth bounds nesting of NBT lists and compounds, and of ByteMaps
+	MaxNBTDepth int `json:"maxNBTDepth"`
+	MaxNBTSize  int `json:"maxNBTSize"`
+}
+
+// maxPacketLength is the largest length the VarInt prefix of a packet can hold
+const maxPacketLength = 2097151
+
+// validate reports the problems of the limits of the state, field is where they are in the file
+func (l *StateLimits) validate(field string, check func(ok bool, format string, args ...interface{})) {
+	check(l.MaxPacketLength > 0 && l.MaxPacketLength <= maxPacketLength,
+		"%s.maxPacketLength %d is not between 1 and %d", field, l.MaxPacketLength, maxPacketLength)
+	check(l.MaxStringLength >= 0, "%s.maxStringLength must not be negative", field)
+	check(l.MaxArrayLength >= 0, "%s.maxArrayLength must not be negative", field)
+	check(l.MaxNBTDepth >= 0, "%s.maxNBTDepth must not be negative", field)
+	check(l.MaxNBTSize >= 0, "%s.maxNBTSize must not be negative", field)
+}
+
+// Duration is a time.Duration written as a string in the file, e.g. "20s"
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("duration must be a string like \"20s\": %w", err)
+	}
+
+	duration, err := time.ParseDuration(raw)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(duration)
+	return nil
+}
+
+// Default returns the settings the proxy has been running with before the configuration file
+func Default() *Config {
+	return &Config{
+		Listen: ListenConfig{
+			Minecraft: "0.0.0.0:25565",
+			Auxiliary: "0.0.0.0:8080",
+			Metrics:   "0.0.0.0:9090",
+		},
+		Upstream: UpstreamConfig{
+			Addresses:           []string{"5.39.71.168", "51.178.178.68", "5.39.71.183", "178.33.226.137"},
+			Port:                25565,
+			Strategy:            "weighted",
+			HealthCheckInterval: Duration(15 * time.Second),
+			HealthCheckTimeout:  Duration(5 * time.Second),
+		},
+		Connection: ConnectionConfig{
+			CompressionThreshold: 1024,
+			CompressionLevel:     -1,
+			QueueCapacity:        1024,
+			QueuePolicy:          "drop",
+			DialTimeout:          Duration(5 * time.Second),
+			HandshakeTimeout:     Duration(5 * time.Second),
+			LoginTimeout:         Duration(30 * time.Second),
+		},
+		Auxiliary: AuxiliaryConfig{
+			KeepAliveInterval: Duration(20 * time.Second),
+		},
+		Egress: EgressConfig{
+			Key:      "username",
+			Cooldown: Duration(time.Minute),
+		},
+		Limits: LimitsConfig{
+			Handshake: StateLimits{MaxPacketLength: 1 << 12, MaxStringLength: 1 << 11},
+			Status:    StateLimits{MaxPacketLength: 1 << 18, MaxStringLength: 32767 * 4},
+			Login:     StateLimits{MaxPacketLength: 1 << 18, MaxStringLength: 32767 * 4},
+			Play: StateLimits{
+				MaxPacketLength: maxPacketLength,
+				MaxStringLength: 32767 * 4,
+				MaxArrayLength:  1 << 16,
+				MaxNBTDepth:     512,
+				MaxNBTSize:      maxPacketLength,
+			},
+		},
+	}
+}
+
+// Load reads the file over the defaults and applies environment overrides, the result is validated.
+// Without a path only the defaults and the environment are used
+func Load(path string) (*Config, error) {
+	config := Default()
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading config: %w", err)
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err = decoder.Decode(config); err != nil {
+			return nil, fmt.Errorf("parsing config %s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(reflect.ValueOf(config).Elem(), os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	config.inheritUpstream()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// inheritUpstream fills the upstream fields the routes leave out from the upstream section
+func (c *Config) inheritUpstream() {
+	for i := range c.Routes {
+		upstream := &c.Routes[i].Upstream
+		if upstream.Port == 0 {
+			upstream.Port = c.Upstream.Port
+		}
+
+		if upstream.Strategy == "" {
+			upstream.Strategy = c.Upstream.Strategy
+		}
+
+		if upstream.HealthCheckInterval == 0 {
+			upstream.HealthCheckInterval = c.Upstream.HealthCheckInterval
+		}
+
+		if upstream.HealthCheckTimeout == 0 {
+			upstream.HealthCheckTimeout = c.Upstream.HealthCheckTimeout
+		}
+
+		if upstream.ProxyAddress == "" {
+			upstream.ProxyAddress, upstream.ProxyProtocol = c.Upstream.ProxyAddress, c.Upstream.ProxyProtocol
+		}
+	}
+}
+
+// applyEnv sets the fields with an env tag from the variables which are set, nested structures are walked
+func applyEnv(value reflect.Value, lookup func(string) (string, bool)) error {
+	for i := 0; i < value.NumField(); i++ {
+		field, structField := value.Field(i), value.Type().Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := applyEnv(field, lookup); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, ok := structField.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+
+		raw, ok := lookup(name)
+		if !ok {
+			continue
+		}
+
+		if err := setField(field, raw); err != nil {
+			return fmt.Errorf("parsing %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func setField(field reflect.Value, raw string) error {
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(raw)
+	case int:
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(value))
+	case Duration:
+		value, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(value))
+	case []string:
+		values := strings.Split(raw, ",")
+		for i := range values {
+			values[i] = strings.TrimSpace(values[i])
+		}
+		field.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+
+	return nil
+}
+
+// Weight returns the weight of the address, 1 unless it is set
+func (u *UpstreamConfig) Weight(address string) int {
+	if weight, ok := u.Weights[address]; ok {
+		return weight
+	}
+
+	return 1
+}
+
+// NodeAddress returns the host:port address of an entry of Addresses
+func (u *UpstreamConfig) NodeAddress(address string) string {
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return address
+	}
+
+	return net.JoinHostPort(address, strconv.Itoa(u.Port))
+}
+
+// validate reports the problems of the upstream, field is where it is in the file
+func (u *UpstreamConfig) validate(field string, check func(ok bool, format string, args ...interface{})) {
+	check(len(u.Addresses) > 0, "%s.addresses must not be empty", field)
+	var weight int
+	for i, address := range u.Addresses {
+		check(address != "", "%s.addresses must not contain empty addresses", field)
+		check(!contains(u.Addresses[:i], address), "%s.addresses has %s more than once", field, address)
+		weight += u.Weight(address)
+	}
+	for address, w := range u.Weights {
+		check(w >= 0, "%s.weights of %s is negative", field, address)
+		check(contains(u.Addresses, address), "%s.weights has %s, which is not in %s.addresses", field, address, field)
+	}
+	check(weight > 0, "%s.weights leave no node to connect to", field)
+	check(u.Port > 0 && u.Port <= 65535, "%s.port %d is out of range", field, u.Port)
+	check(u.Strategy == "weighted" || u.Strategy == "leastConnections",
+		"%s.strategy %q is not weighted or leastConnections", field, u.Strategy)
+	check(u.HealthCheckInterval > 0, "%s.healthCheckInterval must be positive", field)
+	check(u.HealthCheckTimeout > 0, "%s.healthCheckTimeout must be positive", field)
+	if u.ProxyAddress != "" {
+		check(validProxyProtocol(u.ProxyProtocol), "%s.proxyProtocol %q is not socks4, socks4a, socks5 or http", field, u.ProxyProtocol)
+	}
+}
+
+func validProxyProtocol(protocol string) bool {
+	switch protocol {
+	case "socks4", "socks4a", "socks5", "http":
+		return true
+	}
+
+	return false
+}
+
+// validHostname accepts lowercase hostnames without a port, optionally starting with the "*." wildcard
+func validHostname(hostname string) bool {
+	hostname = strings.TrimPrefix(hostname, "*.")
+	if hostname == "" || hostname != strings.ToLower(hostname) {
+		return false
+	}
+
+	for _, label := range strings.Split(hostname, ".") {
+		if label == "" || strings.ContainsAny(label, "*:/ ") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidationError lists every problem of a configuration, so that they can be fixed at once
+type ValidationError []string
+
+func (e ValidationError) Error() string {
+	return "invalid config: " + strings.Join(e, "; ")
+}
+
+func (c *Config) Validate() error {
+	var problems ValidationError
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	listeners := []struct{ name, address string }{
+		{"listen.minecraft", c.Listen.Minecraft},
+		{"listen.auxiliary", c.Listen.Auxiliary},
+		{"listen.metrics", c.Listen.Metrics},
+	}
+	for _, listener := range listeners {
+		_, _, err := net.SplitHostPort(listener.address)
+		check(err == nil, "%s %q is not a host:port address", listener.name, listener.address)
+	}
+	check((c.Listen.CertPath == "") == (c.Listen.KeyPath == ""), "listen.certPath and listen.keyPath must be set together")
+
+	c.Upstream.validate("upstream", check)
+
+	hostnames := make(map[string]string)
+	for i, route := range c.Routes {
+		field := fmt.Sprintf("routes[%d]", i)
+		check(route.Name != "" && route.Name != DefaultRoute, "%s.name must be set and must not be %q", field, DefaultRoute)
+		for _, other := range c.Routes[:i] {
+			check(route.Name == "" || other.Name != route.Name, "%s.name %q is used by another route", field, route.Name)
+		}
+
+		check(len(route.Hostnames) > 0, "%s.hostnames must not be empty", field)
+		for _, hostname := range route.Hostnames {
+			check(validHostname(hostname), "%s.hostnames has %q, which is not a lowercase hostname without a port", field, hostname)
+			if other, ok := hostnames[hostname]; ok {
+				check(false, "%s.hostnames has %s, which is routed to %s already", field, hostname, other)
+			}
+			hostnames[hostname] = route.Name
+		}
+
+		route.Upstream.validate(field+".upstream", check)
+	}
+
+	check(c.Connection.CompressionThreshold >= -1, "connection.compressionThreshold %d is below -1", c.Connection.CompressionThreshold)
+	check(c.Connection.CompressionLevel >= -2 && c.Connection.CompressionLevel <= 9,
+		"connection.compressionLevel %d is not between -2 and 9", c.Connection.CompressionLevel)
+	check(c.Connection.QueueCapacity > 0, "connection.queueCapacity must be positive")
+	check(c.Connection.QueuePolicy == "drop" || c.Connection.QueuePolicy == "disconnect",
+		"connection.queuePolicy %q is not drop or disconnect", c.Connection.QueuePolicy)
+	check(c.Connection.DialTimeout > 0, "connection.dialTimeout must be positive")
+	check(c.Connection.HandshakeTimeout > 0, "connection.handshakeTimeout must be positive")
+	check(c.Connection.LoginTimeout > 0, "connection.loginTimeout must be positive")
+
+	check(c.Auxiliary.KeepAliveInterval > 0, "auxiliary.keepAliveInterval must be positive")
+
+	var proxies []string
+	for i, proxy := range c.Egress.Proxies {
+		check(proxy.Name != "", "egress.proxies[%d].name must be set", i)
+		check(proxy.Name == "" || !contains(proxies, proxy.Name), "egress.proxies[%d].name %q is used by another proxy", i, proxy.Name)
+		_, _, err := net.SplitHostPort(proxy.Address)
+		check(err == nil, "egress.proxies[%d].address %q is not a host:port address", i, proxy.Address)
+		check(validProxyProtocol(proxy.Protocol), "egress.proxies[%d].protocol %q is not socks4, socks4a, socks5 or http", i, proxy.Protocol)
+		proxies = append(proxies, proxy.Name)
+	}
+	check(c.Egress.Key == "username" || c.Egress.Key == "license", "egress.key %q is not username or license", c.Egress.Key)
+	for key, names := range c.Egress.Assignments {
+		check(len(names) > 0, "egress.assignments of %s must not be empty", key)
+		for _, name := range names {
+			check(contains(proxies, name), "egress.assignments of %s has %s, which is not in egress.proxies", key, name)
+		}
+	}
+	check(c.Egress.Cooldown > 0, "egress.cooldown must be positive")
+
+	c.Limits.Handshake.validate("limits.handshake", check)
+	c.Limits.Status.validate("limits.status", check)
+	c.Limits.Login.validate("limits.login", check)
+	c.Limits.Play.validate("limits.play", check)
+
+	if len(problems) > 0 {
+		return problems
+	}
+
+	return nil
+}
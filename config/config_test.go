@@ -0,0 +1,245 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := ioutil.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestDefault(t *testing.T) {
+	assert.NoError(t, Default().Validate())
+
+	config, err := Load("")
+	assert.NoError(t, err)
+	assert.Equal(t, Default(), config)
+}
+
+func TestLoad_Example(t *testing.T) {
+	config, err := Load(filepath.Join("..", "config.example.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, Default().Upstream.Addresses, config.Upstream.Addresses)
+	assert.Equal(t, "35ms", config.Modules["KillAura"]["interval"])
+	assert.Len(t, config.Routes, 2)
+	assert.Equal(t, Default().Limits, config.Limits)
+}
+
+func TestLoad_Routes(t *testing.T) {
+	path := writeConfig(t, `{
+		"upstream": {"addresses": ["a.example.com"], "port": 25570, "proxyAddress": "127.0.0.1:1080", "proxyProtocol": "socks5"},
+		"routes": [
+			{"name": "mini", "hostnames": ["mini.proxy.local"], "upstream": {"addresses": ["mini.example.com"], "strategy": "leastConnections"}},
+			{"name": "survival", "hostnames": ["*.survival.proxy.local"], "upstream": {"addresses": ["survival.example.com"], "port": 25565, "proxyAddress": "127.0.0.1:1081", "proxyProtocol": "socks4"}}
+		]
+	}`)
+
+	config, err := Load(path)
+	assert.NoError(t, err)
+
+	// Fields left out of a route are taken from the upstream section
+	mini := config.Routes[0].Upstream
+	assert.Equal(t, 25570, mini.Port)
+	assert.Equal(t, "leastConnections", mini.Strategy)
+	assert.Equal(t, Default().Upstream.HealthCheckInterval, mini.HealthCheckInterval)
+	assert.Equal(t, "127.0.0.1:1080", mini.ProxyAddress)
+
+	survival := config.Routes[1].Upstream
+	assert.Equal(t, 25565, survival.Port)
+	assert.Equal(t, "weighted", survival.Strategy)
+	assert.Equal(t, "socks4", survival.ProxyProtocol)
+	assert.Equal(t, "survival.example.com:25565", survival.NodeAddress("survival.example.com"))
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `{"upstream": {"addresses": ["mc.example.com"]}, "auxiliary": {"keepAliveInterval": "5s"}}`)
+
+	config, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mc.example.com"}, config.Upstream.Addresses)
+	assert.Equal(t, Duration(5*time.Second), config.Auxiliary.KeepAliveInterval)
+	// Fields missing from the file keep their defaults
+	assert.Equal(t, 25565, config.Upstream.Port)
+
+	_, err = Load(writeConfig(t, `{"upstream": {"adresses": []}}`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "adresses")
+
+	_, err = Load(writeConfig(t, `{"auxiliary": {"keepAliveInterval": 20}}`))
+	assert.Error(t, err)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.json"))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"KV_LISTEN_ADDR":        "127.0.0.1:25566",
+		"KV_UPSTREAM_ADDRS":     "a.example.com, b.example.com",
+		"KV_UPSTREAM_PORT":      "25570",
+		"KV_KEEPALIVE_INTERVAL": "1m",
+		"KV_LOGIN_TIMEOUT":      "10s",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	config := Default()
+	assert.NoError(t, applyEnv(reflect.ValueOf(config).Elem(), lookup))
+	assert.Equal(t, "127.0.0.1:25566", config.Listen.Minecraft)
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, config.Upstream.Addresses)
+	assert.Equal(t, 25570, config.Upstream.Port)
+	assert.Equal(t, Duration(time.Minute), config.Auxiliary.KeepAliveInterval)
+	assert.Equal(t, Duration(10*time.Second), config.Connection.LoginTimeout)
+
+	env["KV_UPSTREAM_PORT"] = "port"
+	err := applyEnv(reflect.ValueOf(config).Elem(), lookup)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "KV_UPSTREAM_PORT")
+}
+
+func TestValidate(t *testing.T) {
+	config := Default()
+	config.Listen.Metrics = "9090"
+	config.Listen.CertPath = "cert.pem"
+	config.Upstream.Port = 0
+	config.Upstream.ProxyAddress, config.Upstream.ProxyProtocol = "127.0.0.1:1080", "https"
+	config.Connection.CompressionLevel = 10
+	config.Connection.QueuePolicy = "block"
+	config.Connection.LoginTimeout = 0
+
+	err := config.Validate()
+	problems, ok := err.(ValidationError)
+	assert.True(t, ok)
+	assert.Len(t, problems, 7)
+	for _, field := range []string{"listen.metrics", "listen.keyPath", "upstream.port", "upstream.proxyProtocol", "connection.compressionLevel", "connection.queuePolicy", "connection.loginTimeout"} {
+		assert.Contains(t, err.Error(), field)
+	}
+	assert.True(t, strings.HasPrefix(err.Error(), "invalid config: "))
+}
+
+func TestValidate_Upstream(t *testing.T) {
+	config := Default()
+	config.Upstream.Addresses = []string{"a.example.com", "b.example.com:25570", "a.example.com"}
+	config.Upstream.Weights = map[string]int{"c.example.com": 1, "b.example.com:25570": -1}
+	config.Upstream.Strategy = "roundRobin"
+
+	err := config.Validate()
+	assert.Error(t, err)
+	for _, problem := range []string{"more than once", "c.example.com", "negative", "upstream.strategy"} {
+		assert.Contains(t, err.Error(), problem)
+	}
+
+	config = Default()
+	config.Upstream.Addresses = []string{"a.example.com", "b.example.com:25570"}
+	config.Upstream.Weights = map[string]int{"a.example.com": 0, "b.example.com:25570": 0}
+	assert.Error(t, config.Validate())
+
+	config.Upstream.Weights["a.example.com"] = 3
+	assert.NoError(t, config.Validate())
+	assert.Equal(t, "a.example.com:25565", config.Upstream.NodeAddress("a.example.com"))
+	assert.Equal(t, "b.example.com:25570", config.Upstream.NodeAddress("b.example.com:25570"))
+	assert.Equal(t, 3, config.Upstream.Weight("a.example.com"))
+	assert.Equal(t, 1, Default().Upstream.Weight("a.example.com"))
+}
+
+func TestValidate_Routes(t *testing.T) {
+	route := func(name string, hostnames ...string) RouteConfig {
+		return RouteConfig{Name: name, Hostnames: hostnames, Upstream: Default().Upstream}
+	}
+
+	config := Default()
+	config.Routes = []RouteConfig{
+		route("mini", "mini.proxy.local", "*.mini.proxy.local"),
+		route("survival", "survival.proxy.local"),
+	}
+	assert.NoError(t, config.Validate())
+
+	config.Routes = []RouteConfig{
+		route(DefaultRoute, "Mini.proxy.local", "*.mini.proxy.local"),
+		route("survival", "survival.proxy.local:25565", "mini.*.local"),
+		route("survival"),
+		route("skyblock", "*.mini.proxy.local"),
+	}
+	config.Routes[3].Upstream.Addresses = nil
+
+	err := config.Validate()
+	problems, ok := err.(ValidationError)
+	assert.True(t, ok)
+	for _, problem := range []string{
+		"routes[0].name", `"Mini.proxy.local"`, `"survival.proxy.local:25565"`, `"mini.*.local"`,
+		"routes[2].name \"survival\" is used", "routes[2].hostnames must not be empty",
+		"*.mini.proxy.local, which is routed to default", "routes[3].upstream.addresses",
+	} {
+		assert.Contains(t, err.Error(), problem)
+	}
+	assert.Len(t, problems, 9)
+}
+
+func TestValidate_Egress(t *testing.T) {
+	config := Default()
+	config.Egress.Proxies = []EgressProxyConfig{
+		{Name: "eu", Address: "10.0.0.1:1080", Protocol: "socks5"},
+		{Name: "us", Address: "10.0.0.2:3128", Protocol: "http"},
+	}
+	config.Egress.Key = "license"
+	config.Egress.Assignments = map[string][]string{"Steve": {"us"}}
+	assert.NoError(t, config.Validate())
+
+	config.Egress.Proxies = append(config.Egress.Proxies, EgressProxyConfig{Name: "eu", Address: "10.0.0.3", Protocol: "socks6"})
+	config.Egress.Key = "ip"
+	config.Egress.Assignments["Alex"] = []string{"asia"}
+	config.Egress.Cooldown = 0
+
+	err := config.Validate()
+	problems, ok := err.(ValidationError)
+	assert.True(t, ok)
+	for _, problem := range []string{
+		`egress.proxies[2].name "eu"`, `egress.proxies[2].address "10.0.0.3"`, `egress.proxies[2].protocol "socks6"`,
+		`egress.key "ip"`, "egress.assignments of Alex has asia", "egress.cooldown",
+	} {
+		assert.Contains(t, err.Error(), problem)
+	}
+	assert.Len(t, problems, 6)
+}
+
+func TestValidate_Limits(t *testing.T) {
+	// Limits left out of the file keep their defaults, those set to zero forbid the data
+	path := writeConfig(t, `{"limits": {"play": {"maxNBTDepth": 64, "maxNBTSize": 0}}}`)
+	config, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 64, config.Limits.Play.MaxNBTDepth)
+	assert.Equal(t, 0, config.Limits.Play.MaxNBTSize)
+	assert.Equal(t, Default().Limits.Play.MaxArrayLength, config.Limits.Play.MaxArrayLength)
+	assert.Equal(t, Default().Limits.Login, config.Limits.Login)
+
+	config = Default()
+	config.Limits.Handshake.MaxPacketLength = 0
+	config.Limits.Play.MaxPacketLength = 1 << 22
+	config.Limits.Login.MaxArrayLength = -1
+
+	err = config.Validate()
+	problems, ok := err.(ValidationError)
+	assert.True(t, ok)
+	for _, problem := range []string{
+		"limits.handshake.maxPacketLength 0", "limits.play.maxPacketLength 4194304", "limits.login.maxArrayLength",
+	} {
+		assert.Contains(t, err.Error(), problem)
+	}
+	assert.Len(t, problems, 3)
+}
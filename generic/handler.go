@@ -1,11 +1,31 @@
 package generic
 
-import pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+import (
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/destructiqn/kogtevran/minecraft/protocol"
+)
 
+type PacketHandler func(packet protocol.Packet, tunnel Tunnel) (result *HandlerResult, err error)
+
+// HandlerPriority defines the order in which handlers of the same packet are called, lower goes first.
+// Core proxy handlers are registered with PriorityNormal, those tracking only what modules pass with PriorityLate
+type HandlerPriority int
+
+const (
+	PriorityEarly HandlerPriority = iota - 1
+	PriorityNormal
+	PriorityLate
+)
+
+// HandlerResult tells the pipeline what to do with the packet after the handler.
+// Handlers modify decoded packet in place, so later handlers see the changes, and the packet
+// is marshalled again only once after the whole pipeline, if any of handlers reported a modification
 type HandlerResult struct {
 	ShouldPass bool
 	IsModified bool
-	Packet     pk.Packet
+
+	// Packet is the resulting packet, set by the pipeline if it was modified
+	Packet pk.Packet
 }
 
 func PassPacket() *HandlerResult {
@@ -14,14 +34,14 @@ func PassPacket() *HandlerResult {
 	}
 }
 
-func ModifyPacket(packet pk.Packet) *HandlerResult {
+func ModifyPacket() *HandlerResult {
 	return &HandlerResult{
 		ShouldPass: true,
 		IsModified: true,
-		Packet:     packet,
 	}
 }
 
+// RejectPacket drops the packet, remaining handlers are not called
 func RejectPacket() *HandlerResult {
 	return &HandlerResult{}
 }
@@ -0,0 +1,61 @@
+package generic
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrClickTimeout = errors.New("click was not confirmed in time")
+	ErrInvalidSlot  = errors.New("slot is out of range")
+	ErrEmptySlot    = errors.New("slot is empty")
+	ErrSlotOccupied = errors.New("slot is occupied")
+	ErrNotStackable = errors.New("items can not be stacked")
+	ErrItemNotFound = errors.New("item was not found")
+)
+
+// ClickResult is resolved when the server confirms or rejects the click
+type ClickResult struct {
+	ActionNumber int16
+	accepted     bool
+	done         chan struct{}
+	once         sync.Once
+}
+
+func NewClickResult(actionNumber int16) *ClickResult {
+	return &ClickResult{ActionNumber: actionNumber, done: make(chan struct{})}
+}
+
+// Resolve is called by the inventory handler, only the first call takes effect
+func (r *ClickResult) Resolve(accepted bool) {
+	r.once.Do(func() {
+		r.accepted = accepted
+		close(r.done)
+	})
+}
+
+func (r *ClickResult) Done() <-chan struct{} {
+	return r.done
+}
+
+// IsAccepted is false until the result is resolved
+func (r *ClickResult) IsAccepted() bool {
+	select {
+	case <-r.done:
+		return r.accepted
+	default:
+		return false
+	}
+}
+
+// Wait blocks until the result is resolved. Confirmations are handled by the clientbound pipe,
+// so it must not be called from clientbound packet handlers
+func (r *ClickResult) Wait(timeout time.Duration) (accepted bool, err error) {
+	select {
+	case <-r.done:
+		return r.accepted, nil
+	case <-time.After(timeout):
+		return false, ErrClickTimeout
+	}
+}
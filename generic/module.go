@@ -1,6 +1,11 @@
 package generic
 
-import "time"
+import (
+	"context"
+	"time"
+
+	"github.com/destructiqn/kogtevran/minecraft/protocol"
+)
 
 type Module interface {
 	Register(conn Tunnel)
@@ -13,12 +18,21 @@ type Module interface {
 	Close()
 }
 
+// VersionedModule is a module which needs packets that are encoded differently on some versions,
+// it is not registered for sessions of those
+type VersionedModule interface {
+	Module
+	SupportsVersion(version *protocol.Version) bool
+}
+
 type TickingModule interface {
 	Module
 	Tick() error
 	GetInterval() time.Duration
+	// StopTicker stops the ticker of the module, it can be called any number of times
 	StopTicker()
-	GetInterruptChannel() chan bool
+	// GetContext returns the context the ticker runs until, it is derived from the context of the tunnel
+	GetContext() context.Context
 }
 
 type ModuleHandler interface {
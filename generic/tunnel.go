@@ -1,27 +1,46 @@
 package generic
 
 import (
+	"context"
+
 	"github.com/Tnze/go-mc/chat"
 	"github.com/destructiqn/kogtevran/minecraft"
+	"github.com/destructiqn/kogtevran/minecraft/biomes"
+	"github.com/destructiqn/kogtevran/minecraft/blocks"
 	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
 	"github.com/destructiqn/kogtevran/minecraft/protocol"
+	"github.com/google/uuid"
 )
 
 type Tunnel interface {
 	SetState(state protocol.ConnectionState)
+	SetVersion(version *protocol.Version)
+	GetVersion() *protocol.Version
 	WriteClient(packet pk.Packet) error
 	WriteServer(packet pk.Packet) error
+	Flush() error
 	GetInventoryHandler() InventoryHandler
 	GetTexteriaHandler() TexteriaHandler
 	GetPlayerHandler() PlayerHandler
 	GetEntityHandler() EntityHandler
+	GetWorldHandler() WorldHandler
 	GetModuleHandler() ModuleHandler
 	GetChatHandler() ChatHandler
+	GetHandlerRegistry() HandlerRegistry
 	Disconnect(message chat.Message)
 	GetRemoteAddr() string
+	// GetContext returns the context of the session, it is cancelled once the tunnel is closed
+	GetContext() context.Context
 	Close()
 }
 
+// HandlerRegistry keeps packet handlers subscribed to a (state, direction, packet ID) triple.
+// Packet is a prototype of the structure the raw packet is decoded into, a fresh copy is used for every packet
+type HandlerRegistry interface {
+	RegisterHandler(owner interface{}, state protocol.ConnectionState, direction int, id int32, packet protocol.Packet, priority HandlerPriority, handler PacketHandler)
+	UnregisterHandlers(owner interface{})
+}
+
 type PlayerHandler interface {
 	IsFlying() bool
 	IsOnGround() bool
@@ -29,29 +48,60 @@ type PlayerHandler interface {
 	GetLocation() *minecraft.Location
 	GetEntityID() int32
 	GetHealth() float64
+	GetFood() int
+	GetSaturation() float64
+	GetExperience() minecraft.Experience
+	GetGameMode() minecraft.GameMode
+	IsHardcore() bool
+	GetAbilities() minecraft.Abilities
+	GetEffects() []minecraft.PotionEffect
+	GetEffect(effectID int) (minecraft.PotionEffect, bool)
+	GetAttribute(key string) (minecraft.Attribute, bool)
+	GetAttributes() map[string]minecraft.Attribute
+	GetSpawnPosition() pk.Position
+	GetDimension() int
 	GetPlayerName() string
 	Attack(target int) error
 	ChangeSlot(slot int) error
 	GetCurrentSlot() int
 }
 
+// EntityHandler returns snapshots of entities, they are safe to keep and use from any goroutine
 type EntityHandler interface {
 	InitPlayer(entityID int, player *minecraft.Player)
 	InitMob(entityID int, mob *minecraft.Mob)
+	InitObject(entityID int, object *minecraft.Object)
 	EntityRelativeMove(entityID int, dx, dy, dz float64)
 	EntityTeleport(entityID int, x, y, z, yaw, pitch float64)
+	SetVelocity(entityID int, velocity minecraft.Velocity)
+	SetHeadYaw(entityID int, yaw float64)
+	SetEquipment(entityID int, slot minecraft.EquipmentSlot, item pk.Slot)
+	UpdateMetadata(entityID int, metadata pk.EntityMetadata)
+	AttachEntity(entityID, vehicleID int, leash bool)
 	ResetEntities()
 	DestroyEntities(entityIDs []int)
 	GetEntity(entityID int) (minecraft.Entity, bool)
 	GetEntities() map[int]minecraft.Entity
-	Lock()
-	Unlock()
+	GetPlayerListEntry(id uuid.UUID) (minecraft.PlayerListEntry, bool)
+	FindEntities(filter minecraft.EntityFilter) []minecraft.Entity
+	GetEntitiesWithin(location *minecraft.Location, radius float64, filter minecraft.EntityFilter) []minecraft.Entity
+	GetNearestEntity(location *minecraft.Location, filter minecraft.EntityFilter) (minecraft.Entity, bool)
+	GetPlayerByName(name string) (*minecraft.Player, bool)
+}
+
+type WorldHandler interface {
+	GetDimension() int
+	IsLoaded(position pk.Position) bool
+	GetBlock(position pk.Position) blocks.Block
+	GetBiome(position pk.Position) (biomes.Biome, bool)
 }
 
 type ChatHandler interface {
 	SendMessage(message chat.Message, position protocol.ChatPosition) error
 }
 
+// Window clicks are confirmed by the server asynchronously, a rejected click rolls back the local changes made by it.
+// Lock and Unlock keep sequences of clicks from interleaving, contents are always safe to access
 type Window interface {
 	GetType() string
 	GetSize() int
@@ -59,8 +109,17 @@ type Window interface {
 	GetContents() map[int]pk.Slot
 	GetItem(slot int) pk.Slot
 	PutItem(slot int, item pk.Slot)
-	Click(slot int, mode, button byte) error
-	Move(from, to int) error
+	GetID() int
+	GetLayout() minecraft.WindowLayout
+	FindItems(filter minecraft.ItemFilter, region minecraft.WindowRegion) []int
+	FindItem(filter minecraft.ItemFilter, region minecraft.WindowRegion) (slot int, ok bool)
+	Click(slot int, mode, button byte) (*ClickResult, error)
+	Move(from, to int) (*ClickResult, error)
+	ShiftClick(slot int) (*ClickResult, error)
+	SwapHotbar(slot, index int) (*ClickResult, error)
+	Drop(slot int, stack bool) (*ClickResult, error)
+	Split(from, to int) (*ClickResult, error)
+	Merge(from, to int) (*ClickResult, error)
 	Lock()
 	Unlock()
 }
@@ -68,6 +127,8 @@ type Window interface {
 type InventoryHandler interface {
 	GetWindows() []Window
 	GetWindow(id int) (Window, bool)
+	GetOpenWindow() Window
+	SelectItem(filter minecraft.ItemFilter, fallback int) (index int, err error)
 	OpenWindow(id int, window Window)
 	CloseWindow(id int)
 	Reset()
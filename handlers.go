@@ -9,32 +9,29 @@ import (
 	"github.com/Tnze/go-mc/chat"
 	"github.com/destructiqn/kogtevran/generic"
 	"github.com/destructiqn/kogtevran/metrics"
+	mcnet "github.com/destructiqn/kogtevran/minecraft/net"
 	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
 	"github.com/destructiqn/kogtevran/minecraft/protocol"
 	"github.com/destructiqn/kogtevran/proxy"
 
-	"github.com/destructiqn/kogtevran/modules/antiknockback"
-	"github.com/destructiqn/kogtevran/modules/autosoup"
-	"github.com/destructiqn/kogtevran/modules/fastbreak"
-	"github.com/destructiqn/kogtevran/modules/flight"
-	"github.com/destructiqn/kogtevran/modules/longjump"
-	"github.com/destructiqn/kogtevran/modules/nobadeffects"
-	"github.com/destructiqn/kogtevran/modules/nofall"
-	"github.com/destructiqn/kogtevran/modules/nuker"
-	"github.com/destructiqn/kogtevran/modules/speedhack"
-	"github.com/destructiqn/kogtevran/modules/unlimitedcps"
-
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-type RawPacketHandler func(packet *protocol.WrappedPacket, tunnel *proxy.MinecraftTunnel) (result *generic.HandlerResult, err error)
-type PacketHandler func(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error)
-type ProtocolStateHandler map[int32]RawPacketHandler
+type CoreHandler struct {
+	Packet   protocol.Packet
+	Handlers []generic.PacketHandler
+	// Priority is PriorityNormal unless the handlers track only what modules let through
+	Priority generic.HandlerPriority
+}
+
+type ProtocolStateHandler map[int32]CoreHandler
 type ProtocolStateHandlerPool map[protocol.ConnectionState]ProtocolStateHandler
 type PluginMessageHandler func(data []byte, tunnel generic.Tunnel) (next bool, err error)
 
-const CompressionThreshold = 1024
+// PluginMessageModifier returns the data to replace the message with, or nil to leave it as it is
+type PluginMessageModifier func(data []byte, tunnel generic.Tunnel) (result []byte, next bool, err error)
 
+// Core handlers of the proxy itself, modules subscribe to packets via generic.HandlerRegistry
 var (
 	ServerboundHandlers = ProtocolStateHandlerPool{
 		protocol.ConnStateHandshake: ProtocolStateHandler{
@@ -51,19 +48,16 @@ var (
 				proxy.HandleChatMessage,
 			),
 			protocol.ServerboundPlayer: WrapPacketHandlers(&protocol.Player{},
-				proxy.HandlePlayer, nofall.HandlePlayer,
+				proxy.HandlePlayer,
 			),
 			protocol.ServerboundPlayerPosition: WrapPacketHandlers(&protocol.PlayerPosition{},
-				longjump.HandlePlayerPosition, proxy.HandlePlayerPosition, nofall.HandlePlayerPosition,
+				proxy.HandlePlayerPosition,
 			),
 			protocol.ServerboundPlayerLook: WrapPacketHandlers(&protocol.PlayerLook{},
-				proxy.HandlePlayerLook, nofall.HandlePlayerLook,
+				proxy.HandlePlayerLook,
 			),
 			protocol.ServerboundPlayerPositionAndLook: WrapPacketHandlers(&protocol.ServerPlayerPositionAndLook{},
-				longjump.HandleServerPlayerPositionAndLook, proxy.HandleServerPlayerPositionAndLook, nofall.HandleServerPlayerPositionAndLook,
-			),
-			protocol.ServerboundPlayerDigging: WrapPacketHandlers(&protocol.PlayerDigging{},
-				fastbreak.HandlePlayerDigging,
+				proxy.HandleServerPlayerPositionAndLook,
 			),
 			protocol.ServerboundHeldItemChange: WrapPacketHandlers(&protocol.ServerHeldItemChange{},
 				proxy.HandleHeldItemChange,
@@ -71,6 +65,12 @@ var (
 			protocol.ServerboundCloseWindow: WrapPacketHandlers(&protocol.ServerCloseWindow{},
 				proxy.HandleCloseWindow,
 			),
+			protocol.ServerboundClickWindow: WrapPacketHandlers(&protocol.ClickWindow{},
+				proxy.HandleClickWindow,
+			),
+			protocol.ServerboundConfirmTransaction: WrapPacketHandlers(&protocol.ServerConfirmTransaction{},
+				proxy.HandleServerConfirmTransaction,
+			),
 			protocol.ServerboundPlayerAbilities: WrapPacketHandlers(&protocol.ServerPlayerAbilities{},
 				proxy.HandleServerPlayerAbilities,
 			),
@@ -89,10 +89,22 @@ var (
 
 		protocol.ConnStatePlay: ProtocolStateHandler{
 			protocol.ClientboundJoinGame: WrapPacketHandlers(&protocol.JoinGame{},
-				proxy.HandleJoinGame, unlimitedcps.HandleJoinGame,
+				proxy.HandleJoinGame,
+			),
+			protocol.ClientboundEntityEquipment: WrapPacketHandlers(&protocol.EntityEquipment{},
+				proxy.HandleEntityEquipment,
+			),
+			protocol.ClientboundPluginMessage: WrapPacketHandlers(&protocol.PluginMessage{},
+				ModifyPluginMessage("Texteria", proxy.HandleClientboundTexteriaPacket),
+			),
+			protocol.ClientboundSpawnPosition: WrapPacketHandlers(&protocol.SpawnPosition{},
+				proxy.HandleSpawnPosition,
 			),
 			protocol.ClientboundUpdateHealth: WrapPacketHandlers(&protocol.UpdateHealth{},
-				proxy.HandleUpdateHealth, autosoup.HandleUpdateHealth,
+				proxy.HandleUpdateHealth,
+			),
+			protocol.ClientboundRespawn: WrapPacketHandlers(&protocol.Respawn{},
+				proxy.HandleRespawn,
 			),
 			protocol.ClientboundPlayerPositionAndLook: WrapPacketHandlers(&protocol.PlayerPositionAndLook{},
 				proxy.HandlePlayerPositionAndLook,
@@ -100,11 +112,14 @@ var (
 			protocol.ClientboundSpawnPlayer: WrapPacketHandlers(&protocol.SpawnPlayer{},
 				proxy.HandleSpawnPlayer,
 			),
+			protocol.ClientboundSpawnObject: WrapPacketHandlers(&protocol.SpawnObject{},
+				proxy.HandleSpawnObject,
+			),
 			protocol.ClientboundSpawnMob: WrapPacketHandlers(&protocol.SpawnMob{},
 				proxy.HandleSpawnMob,
 			),
 			protocol.ClientboundEntityVelocity: WrapPacketHandlers(&protocol.EntityVelocity{},
-				antiknockback.HandleEntityVelocity,
+				proxy.HandleEntityVelocity,
 			),
 			protocol.ClientboundDestroyEntities: WrapPacketHandlers(&protocol.DestroyEntities{},
 				proxy.HandleDestroyEntities,
@@ -118,29 +133,65 @@ var (
 			protocol.ClientboundEntityTeleport: WrapPacketHandlers(&protocol.EntityTeleport{},
 				proxy.HandleEntityTeleport,
 			),
-			protocol.ClientboundEntityEffect: WrapPacketHandlers(&protocol.EntityEffect{},
-				nobadeffects.HandleEntityEffect,
+			protocol.ClientboundEntityHeadLook: WrapPacketHandlers(&protocol.EntityHeadLook{},
+				proxy.HandleEntityHeadLook,
+			),
+			protocol.ClientboundAttachEntity: WrapPacketHandlers(&protocol.AttachEntity{},
+				proxy.HandleAttachEntity,
+			),
+			protocol.ClientboundEntityMetadata: WrapPacketHandlers(&protocol.EntityMetadata{},
+				proxy.HandleEntityMetadata,
+			),
+			protocol.ClientboundEntityEffect: WrapLatePacketHandlers(&protocol.EntityEffect{},
+				proxy.HandleEntityEffect,
+			),
+			protocol.ClientboundRemoveEntityEffect: WrapPacketHandlers(&protocol.RemoveEntityEffect{},
+				proxy.HandleRemoveEntityEffect,
+			),
+			protocol.ClientboundSetExperience: WrapPacketHandlers(&protocol.SetExperience{},
+				proxy.HandleSetExperience,
 			),
 			protocol.ClientboundEntityProperties: WrapPacketHandlers(&protocol.EntityProperties{},
-				speedhack.HandleEntityProperties,
+				proxy.HandleEntityProperties,
+			),
+			protocol.ClientboundChunkData: WrapPacketHandlers(&protocol.ChunkData{},
+				proxy.HandleChunkData,
+			),
+			protocol.ClientboundMultiBlockChange: WrapPacketHandlers(&protocol.MultiBlockChange{},
+				proxy.HandleMultiBlockChange,
 			),
 			protocol.ClientboundBlockChange: WrapPacketHandlers(&protocol.BlockChange{},
-				nuker.HandleBlockChange,
+				proxy.HandleBlockChange,
+			),
+			protocol.ClientboundMapChunkBulk: WrapPacketHandlers(&protocol.MapChunkBulk{},
+				proxy.HandleMapChunkBulk,
+			),
+			protocol.ClientboundExplosion: WrapPacketHandlers(&protocol.Explosion{},
+				proxy.HandleExplosion,
+			),
+			protocol.ClientboundChangeGameState: WrapPacketHandlers(&protocol.ChangeGameState{},
+				proxy.HandleChangeGameState,
 			),
 			protocol.ClientboundOpenWindow: WrapPacketHandlers(&protocol.OpenWindow{},
-				proxy.HandleOpenWindow, /*cheststealer.HandleOpenWindow,*/
+				proxy.HandleOpenWindow,
 			),
 			protocol.ClientboundCloseWindow: WrapPacketHandlers(&protocol.CloseWindow{},
 				proxy.HandleCloseWindow,
 			),
 			protocol.ClientboundSetSlot: WrapPacketHandlers(&protocol.SetSlot{},
-				proxy.HandleSetSlot, /*cheststealer.HandleSetSlot,*/
+				proxy.HandleSetSlot,
 			),
 			protocol.ClientboundWindowItems: WrapPacketHandlers(&protocol.WindowItems{},
-				proxy.HandleWindowItems, /*cheststealer.HandleWindowItems,*/
+				proxy.HandleWindowItems,
+			),
+			protocol.ClientboundConfirmTransaction: WrapPacketHandlers(&protocol.ConfirmTransaction{},
+				proxy.HandleConfirmTransaction,
+			),
+			protocol.ClientboundPlayerListItem: WrapPacketHandlers(&protocol.PlayerListItem{},
+				proxy.HandlePlayerListItem,
 			),
 			protocol.ClientboundPlayerAbilities: WrapPacketHandlers(&protocol.PlayerAbilities{},
-				flight.HandlePlayerAbilities,
+				proxy.HandlePlayerAbilities,
 			),
 			protocol.ClientboundDisconnect: WrapPacketHandlers(&protocol.Disconnect{},
 				HandleDisconnect,
@@ -149,24 +200,34 @@ var (
 	}
 )
 
-func WrapPacketHandlers(packet protocol.Packet, handlers ...PacketHandler) RawPacketHandler {
-	return func(rawPacket *protocol.WrappedPacket, tunnel *proxy.MinecraftTunnel) (result *generic.HandlerResult, err error) {
-		err = packet.Read(rawPacket.Packet)
-		if err != nil {
-			return
-		}
+func WrapPacketHandlers(packet protocol.Packet, handlers ...generic.PacketHandler) CoreHandler {
+	return CoreHandler{Packet: packet, Handlers: handlers}
+}
+
+// WrapLatePacketHandlers registers the handlers after those of modules, packets dropped by modules never reach them
+func WrapLatePacketHandlers(packet protocol.Packet, handlers ...generic.PacketHandler) CoreHandler {
+	return CoreHandler{Packet: packet, Handlers: handlers, Priority: generic.PriorityLate}
+}
+
+// RegisterCoreHandlers subscribes core proxy handlers before any module gets registered,
+// so modules with the same priority always see the state already updated by them
+func RegisterCoreHandlers(tunnel *proxy.MinecraftTunnel) {
+	registerHandlerPool(tunnel, ServerboundHandlers, protocol.ConnC2S)
+	registerHandlerPool(tunnel, ClientboundHandlers, protocol.ConnS2C)
+}
 
-		for _, handler := range handlers {
-			result, err = handler(packet, tunnel)
-			if err != nil {
-				return
+func registerHandlerPool(tunnel *proxy.MinecraftTunnel, pool ProtocolStateHandlerPool, direction int) {
+	registry := tunnel.GetHandlerRegistry()
+	for state, stateHandler := range pool {
+		for id, coreHandler := range stateHandler {
+			for _, handler := range coreHandler.Handlers {
+				registry.RegisterHandler(nil, state, direction, id, coreHandler.Packet, coreHandler.Priority, handler)
 			}
 		}
-
-		return
 	}
 }
 
+// HandleHandshake switches the state, the version has been checked and set before the pipes were started
 func HandleHandshake(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	handshake := packet.(*protocol.Handshake)
 	metrics.HandshakeCount.With(prometheus.Labels{"state": fmt.Sprintf("%d", handshake.NextState)}).Inc()
@@ -191,14 +252,14 @@ func HandleHandshake(packet protocol.Packet, tunnel generic.Tunnel) (result *gen
 	handshake.ServerAddress = pk.String(fmt.Sprintf("%s ", host))
 	handshake.ServerPort = pk.UnsignedShort(port)
 
-	return generic.ModifyPacket(handshake.Marshal()), nil
+	return generic.ModifyPacket(), nil
 }
 
 func HandleLoginStart(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	loginStart := packet.(*protocol.LoginStart)
 	minecraftTunnel := tunnel.(*proxy.MinecraftTunnel)
 	log.Println(loginStart.Name, "is connecting from", minecraftTunnel.Client.Socket.RemoteAddr())
-	minecraftTunnel.PlayerHandler.PlayerName = string(loginStart.Name)
+	minecraftTunnel.PlayerHandler.SetPlayerName(string(loginStart.Name))
 
 	host, _, err := net.SplitHostPort(minecraftTunnel.Client.Socket.RemoteAddr().String())
 	if err != nil {
@@ -245,10 +306,16 @@ func HandleEncryptionRequest(packet protocol.Packet, tunnel generic.Tunnel) (res
 		return
 	}
 
-	if minecraftTunnel.TunnelPair == nil || minecraftTunnel.TunnelPair.Auxiliary == nil {
+	// The pipe is blocked until the client responds, so the request is sent right away
+	err = tunnel.Flush()
+	if err != nil {
 		return
 	}
 
+	if minecraftTunnel.TunnelPair == nil || minecraftTunnel.TunnelPair.Auxiliary == nil {
+		return generic.RejectPacket(), nil
+	}
+
 	err = minecraftTunnel.TunnelPair.Auxiliary.SendMessage(proxy.EncryptionDataRequest, proxy.AuxiliaryEncryptionRequest{
 		PublicKey: encryptionRequest.PublicKey,
 		ServerID:  string(encryptionRequest.ServerID),
@@ -257,16 +324,36 @@ func HandleEncryptionRequest(packet protocol.Packet, tunnel generic.Tunnel) (res
 		return
 	}
 
-	key := <-minecraftTunnel.EnableEncryptionS2C
+	var key []byte
+	select {
+	case key = <-minecraftTunnel.EnableEncryptionS2C:
+	case <-tunnel.GetContext().Done():
+		return nil, proxy.ErrTunnelClosed
+	}
+
 	s2ce, s2cd := newSymmetricEncryption(key)
-	minecraftTunnel.Server.SetCipher(s2ce, s2cd)
-	return
+	// The cipher is set by the writer of the server, the pipe reading from it is waiting here
+	err = minecraftTunnel.Exec(protocol.ConnC2S, func(conn *mcnet.Conn) {
+		conn.SetCipher(s2ce, s2cd)
+	})
+	if err != nil {
+		return
+	}
+
+	// Encryption request has already been written to the client
+	return generic.RejectPacket(), nil
 }
 
 func HandleEncryptionResponse(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	minecraftTunnel := tunnel.(*proxy.MinecraftTunnel)
 	encryptionResponse := packet.(*protocol.EncryptionResponse)
-	sharedSecret := <-minecraftTunnel.EnableEncryptionC2S
+
+	var sharedSecret []byte
+	select {
+	case sharedSecret = <-minecraftTunnel.EnableEncryptionC2S:
+	case <-tunnel.GetContext().Done():
+		return nil, proxy.ErrTunnelClosed
+	}
 
 	err = tunnel.WriteServer(encryptionResponse.Marshal())
 	if err != nil {
@@ -274,22 +361,46 @@ func HandleEncryptionResponse(packet protocol.Packet, tunnel generic.Tunnel) (re
 	}
 
 	c2se, c2sd := newSymmetricEncryption(sharedSecret)
-	minecraftTunnel.Client.SetCipher(c2se, c2sd)
-	minecraftTunnel.EnableEncryptionS2C <- sharedSecret
+	err = minecraftTunnel.Exec(protocol.ConnS2C, func(conn *mcnet.Conn) {
+		conn.SetCipher(c2se, c2sd)
+	})
+	if err != nil {
+		return
+	}
+
+	select {
+	case minecraftTunnel.EnableEncryptionS2C <- sharedSecret:
+	case <-tunnel.GetContext().Done():
+		return nil, proxy.ErrTunnelClosed
+	}
+
+	threshold := getSettings().Connection.CompressionThreshold
+	err = tunnel.WriteClient((&protocol.SetCompression{Threshold: pk.VarInt(threshold)}).Marshal())
+	if err != nil {
+		return
+	}
 
-	err = tunnel.WriteClient((&protocol.SetCompression{Threshold: CompressionThreshold}).Marshal())
+	err = minecraftTunnel.Exec(protocol.ConnS2C, func(conn *mcnet.Conn) {
+		conn.SetThreshold(threshold)
+	})
 	if err != nil {
 		return
 	}
 
-	minecraftTunnel.Client.SetThreshold(CompressionThreshold)
-	return
+	// Encryption response has already been written to the server
+	return generic.RejectPacket(), nil
 }
 
 func HandleSetCompression(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	minecraftTunnel := tunnel.(*proxy.MinecraftTunnel)
 	setCompression := packet.(*protocol.SetCompression)
-	minecraftTunnel.Server.SetThreshold(int(setCompression.Threshold))
+	err = minecraftTunnel.Exec(protocol.ConnC2S, func(conn *mcnet.Conn) {
+		conn.SetThreshold(int(setCompression.Threshold))
+	})
+	if err != nil {
+		return
+	}
+
 	return generic.RejectPacket(), nil
 }
 
@@ -310,7 +421,7 @@ func HandleDisconnect(packet protocol.Packet, tunnel generic.Tunnel) (result *ge
 	return generic.RejectPacket(), nil
 }
 
-func HandlePluginMessage(targetChannel string, handler PluginMessageHandler) PacketHandler {
+func HandlePluginMessage(targetChannel string, handler PluginMessageHandler) generic.PacketHandler {
 	return func(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 		var (
 			data    []byte
@@ -339,3 +450,25 @@ func HandlePluginMessage(targetChannel string, handler PluginMessageHandler) Pac
 		return generic.PassPacket(), nil
 	}
 }
+
+func ModifyPluginMessage(targetChannel string, modifier PluginMessageModifier) generic.PacketHandler {
+	return func(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+		pluginMessage := packet.(*protocol.PluginMessage)
+		if string(pluginMessage.Channel) != targetChannel {
+			return generic.PassPacket(), nil
+		}
+
+		data, next, err := modifier(pluginMessage.Data, tunnel)
+		switch {
+		case err != nil:
+			return nil, err
+		case !next:
+			return generic.RejectPacket(), nil
+		case data != nil:
+			pluginMessage.Data = data
+			return generic.ModifyPacket(), nil
+		}
+
+		return generic.PassPacket(), nil
+	}
+}
@@ -10,6 +10,10 @@ func (d *DevelopmentLicense) IsRelated(_ generic.Tunnel) bool {
 	return true
 }
 
+func (d *DevelopmentLicense) GetID() string {
+	return "development"
+}
+
 func (d *DevelopmentLicense) GetFeatures() uint64 {
 	return 0xFFFFFFFFFFFFFFFF
 }
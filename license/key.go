@@ -42,6 +42,11 @@ func (k *KogtevranClaims) HasFeature(feature Feature) bool {
 	return k.Features&uint64(feature) > 0
 }
 
+// GetID returns the ID the token has been issued with
+func (k *KogtevranClaims) GetID() string {
+	return k.Id
+}
+
 func (k *KogtevranClaims) GetFeatures() uint64 {
 	return k.Features
 }
@@ -3,6 +3,8 @@ package license
 import "github.com/destructiqn/kogtevran/generic"
 
 type License interface {
+	// GetID tells licenses apart, e.g. to assign outbound proxies by license
+	GetID() string
 	GetFeatures() uint64
 	HasFeature(feature Feature) bool
 	IsRelated(tunnel generic.Tunnel) bool
@@ -3,36 +3,48 @@ package main
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"errors"
+	"flag"
 	"fmt"
-	"h12.io/socks"
 	"io"
 	"log"
-	"math/rand"
 	stdnet "net"
 	"net/http"
 	"os"
 	"runtime/debug"
 	"time"
 
+	"github.com/Tnze/go-mc/chat"
 	"github.com/destructiqn/kogtevran/metrics"
 	"github.com/destructiqn/kogtevran/minecraft/net"
 	"github.com/destructiqn/kogtevran/minecraft/net/CFB8"
 	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
 	"github.com/destructiqn/kogtevran/minecraft/protocol"
 	"github.com/destructiqn/kogtevran/proxy"
+	"github.com/destructiqn/kogtevran/upstream"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-var ServerPort = 25565
+func main() {
+	configPath := flag.String("config", os.Getenv("KV_CONFIG"), "path to the configuration file, see config.example.json")
+	flag.Parse()
 
-func GetRemoteAddr() string {
-	return fmt.Sprintf("%s:%d", GetConnectAddress(), ServerPort)
-}
+	s, err := loadSettings(*configPath)
+	if err != nil {
+		log.Fatalln(err)
+	}
 
-func main() {
+	if err = applySettings(s); err != nil {
+		log.Fatalln(err)
+	}
+
+	go reloadOnSignal(*configPath)
+
+	listen := s.Listen
 	metrics.RegisterMetrics()
 	go func() {
-		err := http.ListenAndServe("0.0.0.0:9090", promhttp.Handler())
+		err := http.ListenAndServe(listen.Metrics, promhttp.Handler())
 		if err != nil {
 			log.Fatalln("prometheus listener error:", err)
 		}
@@ -40,11 +52,10 @@ func main() {
 
 	go func() {
 		var err error
-		if certPath, ok := os.LookupEnv("KV_CERT_PATH"); ok {
-			keyPath := os.Getenv("KV_CERT_KEY_PATH")
-			err = http.ListenAndServeTLS("0.0.0.0:8080", certPath, keyPath, http.HandlerFunc(proxy.WebsocketHandler))
+		if listen.CertPath != "" {
+			err = http.ListenAndServeTLS(listen.Auxiliary, listen.CertPath, listen.KeyPath, http.HandlerFunc(proxy.WebsocketHandler))
 		} else {
-			err = http.ListenAndServe("0.0.0.0:8080", http.HandlerFunc(proxy.WebsocketHandler))
+			err = http.ListenAndServe(listen.Auxiliary, http.HandlerFunc(proxy.WebsocketHandler))
 		}
 
 		if err != nil {
@@ -52,7 +63,7 @@ func main() {
 		}
 	}()
 
-	proxyServer, err := net.ListenMC("0.0.0.0:25565")
+	proxyServer, err := net.ListenMC(listen.Minecraft)
 	if err != nil {
 		log.Fatalln("error starting proxy listener")
 	}
@@ -65,28 +76,146 @@ func main() {
 			continue
 		}
 
-		dial := stdnet.Dial
-		if proxyAddr, ok := os.LookupEnv("KV_PROXY_ADDR"); ok {
-			proto := os.Getenv("KV_PROXY_PROTOCOL")
-			dial = socks.Dial(fmt.Sprintf("%s://%s?timeout=5s", proto, proxyAddr))
+		// Nothing in the loop waits for a client or an upstream, a slow one does not hold up the others
+		go handleConnection(client)
+	}
+}
+
+// handleConnection reads the handshake of the client to pick its route, the upstream is dialed afterwards.
+// The client has the handshake timeout to send it and the login timeout from then on to get into the game
+func handleConnection(client *net.Conn) {
+	s := getSettings()
+	client.SetLimits(protocol.GetLimits(protocol.ConnStateHandshake))
+
+	var handshakePacket pk.Packet
+	var handshake protocol.Handshake
+	_ = client.Socket.SetReadDeadline(time.Now().Add(time.Duration(s.Connection.HandshakeTimeout)))
+	if err := client.ReadPacket(&handshakePacket); err != nil {
+		log.Println("error reading handshake from", client.Socket.RemoteAddr(), err)
+		_ = client.Close()
+		return
+	}
+
+	if err := handshake.Read(handshakePacket); err != nil {
+		log.Println("invalid handshake from", client.Socket.RemoteAddr(), err)
+		_ = client.Close()
+		return
+	}
+	_ = client.Socket.SetReadDeadline(time.Time{})
+
+	// Logins with a version the proxy cannot map are turned away before anything is dialed for them
+	version, supported := protocol.GetVersion(int32(handshake.ProtocolVersion))
+	if !supported && handshake.NextState == pk.VarInt(protocol.ConnStateLogin) {
+		log.Println("rejected login with unsupported protocol version", handshake.ProtocolVersion, "from", client.Socket.RemoteAddr())
+		rejectLogin(client, &handshake, chat.Text(fmt.Sprintf("unsupported protocol version %d, supported versions are %s",
+			handshake.ProtocolVersion, protocol.SupportedVersions())))
+		return
+	}
+
+	loginDeadline := time.Now().Add(time.Duration(s.Connection.LoginTimeout))
+	route, err := router.Route(string(handshake.ServerAddress))
+	if err != nil {
+		log.Println("error routing", handshake.ServerAddress, "from", client.Socket.RemoteAddr(), err)
+		rejectLogin(client, &handshake, chat.Text("unknown server address"))
+		return
+	}
+
+	pending := []pk.Packet{handshakePacket}
+	dial := route.Dial
+	if handshake.NextState == pk.VarInt(protocol.ConnStateLogin) {
+		// The dialer is picked by who is logging in, the login start is read before connecting
+		var loginStartPacket pk.Packet
+		var loginStart protocol.LoginStart
+		client.SetLimits(protocol.GetLimits(protocol.ConnStateLogin))
+		_ = client.Socket.SetReadDeadline(loginDeadline)
+		if err = client.ReadPacket(&loginStartPacket); err != nil {
+			log.Println("error reading login start from", client.Socket.RemoteAddr(), err)
+			rejectLogin(client, &handshake, chat.Text("login timed out"))
+			return
+		}
+
+		if loginStartPacket.ID != protocol.ServerboundLoginStart || loginStart.Read(loginStartPacket) != nil {
+			log.Println("invalid login start from", client.Socket.RemoteAddr())
+			rejectLogin(client, &handshake, chat.Text("invalid login"))
+			return
 		}
+		_ = client.Socket.SetReadDeadline(time.Time{})
 
-		targetAddr := GetRemoteAddr()
-		server, err := net.DialMC(targetAddr, dial)
-		if err != nil {
-			log.Println("error connecting to vimeworld:", err)
-			continue
+		pending = append(pending, loginStartPacket)
+		dial = s.dialers.Dialer(sessionOf(client, string(loginStart.Name)), route.Dial)
+	}
+
+	server, node, err := route.ConnectWith(dial)
+	if err != nil {
+		log.Println("error connecting to", route.Name, "upstream:", err)
+		rejectLogin(client, &handshake, chat.Text("unable to connect to the server, try again later"))
+		return
+	}
+
+	client.SetCompressor(s.compressor)
+	server.SetCompressor(s.compressor)
+	conn := proxy.WrapConn(server, client)
+	conn.TargetAddress = node.Address
+	if supported {
+		conn.SetVersion(version)
+	}
+	conn.ModuleProfile = route.Modules
+	RegisterCoreHandlers(conn)
+
+	loginTimer := time.AfterFunc(time.Until(loginDeadline), func() {
+		switch conn.GetState() {
+		case protocol.ConnStatePlay:
+		case protocol.ConnStateLogin:
+			log.Println("login from", client.Socket.RemoteAddr(), "timed out")
+			conn.Disconnect(chat.Text("login timed out"))
+		default:
+			conn.Close()
 		}
+	})
+
+	go func() {
+		<-conn.GetContext().Done()
+		loginTimer.Stop()
+		node.Release()
+	}()
+
+	go pipe(conn, protocol.ConnS2C)
+	go pipe(conn, protocol.ConnC2S, pending...)
+}
 
-		conn := proxy.WrapConn(server, &client)
-		conn.TargetAddress = targetAddr
+// sessionOf identifies the player logging in, the license is the one of the auxiliary connection it is linked with
+func sessionOf(client *net.Conn, username string) upstream.Session {
+	session := upstream.Session{Username: username}
+	host, _, err := stdnet.SplitHostPort(client.Socket.RemoteAddr().String())
+	if err != nil {
+		return session
+	}
 
-		go pipe(conn, protocol.ConnS2C)
-		go pipe(conn, protocol.ConnC2S)
+	pair, ok := proxy.CurrentTunnelPool.GetPair(proxy.TunnelPairID{Username: username, RemoteAddr: host})
+	if ok && pair.License != nil {
+		session.License = pair.License.GetID()
 	}
+
+	return session
 }
 
-func pipe(conn *proxy.MinecraftTunnel, typ int) {
+// rejectLogin closes the connection of a client which has not got a tunnel, the reason is sent when it is logging in.
+// Clients asking for the status cannot be sent one
+func rejectLogin(client *net.Conn, handshake *protocol.Handshake, reason chat.Message) {
+	defer client.Close()
+	if handshake.NextState != pk.VarInt(protocol.ConnStateLogin) {
+		return
+	}
+
+	metrics.Disconnects.With(prometheus.Labels{"reason": reason.String()}).Inc()
+	_ = client.Socket.SetWriteDeadline(time.Now().Add(time.Duration(getSettings().Connection.HandshakeTimeout)))
+	if err := client.WritePacket(pk.Marshal(protocol.ClientboundLoginDisconnect, reason)); err == nil {
+		_ = client.Flush()
+	}
+}
+
+// pipe forwards the packets of one side of the tunnel, pending are packets read before it has been set up
+func pipe(conn *proxy.MinecraftTunnel, typ int, pending ...pk.Packet) {
 	defer func() {
 		conn.Close()
 		err := recover()
@@ -108,9 +237,12 @@ func pipe(conn *proxy.MinecraftTunnel, typ int) {
 	var err error
 	for {
 		var packet pk.Packet
-		err = src.ReadPacket(&packet)
-		if err != nil {
-			if conn.Closed {
+		if len(pending) > 0 {
+			packet, pending = pending[0], pending[1:]
+		} else if err = src.ReadPacket(&packet); err != nil {
+			if conn.IsClosed() {
+				// A failed write closes the tunnel, it is the error worth reporting
+				err = conn.Err()
 				break
 			}
 
@@ -118,47 +250,56 @@ func pipe(conn *proxy.MinecraftTunnel, typ int) {
 				return
 			}
 
+			if errors.Is(err, pk.ErrLimitExceeded) {
+				disconnectOverLimit(conn, srcName, direction, err)
+				return
+			}
+
 			log.Println(direction, "error reading packet", err)
 			break
 		}
 
-		wrappedPacket := protocol.WrapPacket(packet, typ)
-		stateHandlerPool := ClientboundHandlers
-		if typ == protocol.ConnC2S {
-			stateHandlerPool = ServerboundHandlers
+		// Packets are handled and written as of the state they were read in
+		state, version := conn.GetState(), conn.GetVersion()
+		id, ok := version.LogicalID(state, typ, packet.ID)
+		if !ok {
+			// The version encodes the packet differently, it is passed without being decoded
+			err = conn.WriteRaw(packet, typ)
+			if err != nil {
+				log.Println(direction, "error writing packet", packet.ID, "to", dstName)
+				break
+			}
+
+			packets++
+			continue
 		}
 
-		next := true
-		if stateHandler, ok := stateHandlerPool[conn.State]; ok {
-			if handler, ok := stateHandler[packet.ID]; ok {
-				result, err := handler(wrappedPacket, conn)
-				if err != nil {
-					log.Println(direction, "error handling packet", protocol.FormatPacket(packet.ID, typ), err)
-					next = true
-					continue
-				}
-
-				if result == nil {
-					continue
-				}
-
-				if result.IsModified {
-					packet = result.Packet
-				}
-
-				if next {
-					next = result.ShouldPass
-				}
+		packet.ID = id
+		wrappedPacket := protocol.WrapPacket(packet, typ)
+
+		result, handlingErr := conn.HandlerRegistry.Handle(packet, typ)
+		if handlingErr != nil {
+			if errors.Is(handlingErr, pk.ErrLimitExceeded) {
+				disconnectOverLimit(conn, srcName, direction, handlingErr)
+				return
 			}
+
+			log.Println(direction, "error handling packet", protocol.FormatPacket(packet.ID, typ), handlingErr)
+			continue
 		}
 
-		if next {
-			write := conn.WriteClient
-			if typ == protocol.ConnC2S {
-				write = conn.WriteServer
-			}
+		if result == nil {
+			continue
+		}
 
-			err = write(packet)
+		if result.IsModified {
+			packet = result.Packet
+		}
+
+		if result.ShouldPass {
+			keepAlive := protocol.IsKeepAlive(state, packet.ID, typ)
+			packet.ID, _ = version.ID(state, typ, packet.ID)
+			err = conn.Forward(packet, typ, keepAlive)
 			if err != nil {
 				log.Println(direction, "error writing packet", wrappedPacket.Name, "to", dstName)
 				break
@@ -173,17 +314,11 @@ func pipe(conn *proxy.MinecraftTunnel, typ int) {
 	}
 }
 
-func GetConnectAddress() string {
-	addresses := []string{
-		"5.39.71.168",
-		"51.178.178.68",
-		"5.39.71.183",
-		"178.33.226.137",
-	}
-
-	rand.Seed(time.Now().UnixNano())
-	i := rand.Intn(len(addresses))
-	return addresses[i]
+// disconnectOverLimit kicks the client when either side sends a packet over the decoding limits,
+// the stream cannot be read any further
+func disconnectOverLimit(conn *proxy.MinecraftTunnel, srcName, direction string, err error) {
+	log.Println(direction, "packet exceeds decoding limits:", err)
+	conn.Disconnect(chat.Text(fmt.Sprintf("%s sent a packet exceeding decoding limits", srcName)))
 }
 
 func newSymmetricEncryption(key []byte) (eStream, dStream cipher.Stream) {
@@ -30,6 +30,62 @@ var (
 		Name:      "modules",
 		Help:      "Amount of currently enabled module instances",
 	}, []string{"identifier"})
+
+	WriteQueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kogtevran",
+		Subsystem: "server",
+		Name:      "write_queue_depth",
+		Help:      "Amount of packets waiting to be written, by the side they are written to",
+	}, []string{"direction"})
+
+	WriteQueueOverflows = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kogtevran",
+		Subsystem: "server",
+		Name:      "write_queue_overflows",
+		Help:      "Amount of packets written to a full queue",
+	}, []string{"direction", "policy"})
+
+	UpstreamHealthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kogtevran",
+		Subsystem: "server",
+		Name:      "upstream_healthy",
+		Help:      "Whether the upstream node is considered to be up",
+	}, []string{"pool", "address"})
+
+	UpstreamConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kogtevran",
+		Subsystem: "server",
+		Name:      "upstream_connections",
+		Help:      "Amount of sessions connected through the upstream node",
+	}, []string{"pool", "address"})
+
+	UpstreamLatency = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kogtevran",
+		Subsystem: "server",
+		Name:      "upstream_latency_seconds",
+		Help:      "Round trip of the last status ping of the upstream node",
+	}, []string{"pool", "address"})
+
+	UpstreamHealthChecks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kogtevran",
+		Subsystem: "server",
+		Name:      "upstream_health_checks",
+		Help:      "Amount of status pings of upstream nodes",
+	}, []string{"pool", "address", "result"})
+
+	UpstreamDialFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kogtevran",
+		Subsystem: "server",
+		Name:      "upstream_dial_failures",
+		Help:      "Amount of failed connections to upstream nodes",
+	}, []string{"pool", "address"})
+
+	EgressDialFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kogtevran",
+		Subsystem: "server",
+		Name:      "egress_dial_failures",
+		Help:      "Amount of failed connections through outbound proxies",
+	}, []string{"egress"})
 )
 
 func RegisterMetrics() {
@@ -37,4 +93,12 @@ func RegisterMetrics() {
 	prometheus.MustRegister(HandshakeCount)
 	prometheus.MustRegister(Disconnects)
 	prometheus.MustRegister(UsedModules)
+	prometheus.MustRegister(WriteQueueDepth)
+	prometheus.MustRegister(WriteQueueOverflows)
+	prometheus.MustRegister(UpstreamHealthy)
+	prometheus.MustRegister(UpstreamConnections)
+	prometheus.MustRegister(UpstreamLatency)
+	prometheus.MustRegister(UpstreamHealthChecks)
+	prometheus.MustRegister(UpstreamDialFailures)
+	prometheus.MustRegister(EgressDialFailures)
 }
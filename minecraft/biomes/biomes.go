@@ -0,0 +1,575 @@
+// Code generated by gen_data.go DO NOT EDIT.
+// Package biomes stores information about biomes in Minecraft.
+package biomes
+
+// ID describes the numeric ID of a biome.
+type ID int
+
+// Biome describes information about a type of biome.
+type Biome struct {
+	ID          ID
+	DisplayName string
+	Name        string
+	Temperature float64
+	Rainfall    float64
+}
+
+var (
+	Ocean = Biome{
+		ID:          0,
+		DisplayName: "Ocean",
+		Name:        "ocean",
+		Temperature: 0.5,
+		Rainfall:    0.5,
+	}
+	Plains = Biome{
+		ID:          1,
+		DisplayName: "Plains",
+		Name:        "plains",
+		Temperature: 0.8,
+		Rainfall:    0.4,
+	}
+	Desert = Biome{
+		ID:          2,
+		DisplayName: "Desert",
+		Name:        "desert",
+		Temperature: 2,
+		Rainfall:    0,
+	}
+	ExtremeHills = Biome{
+		ID:          3,
+		DisplayName: "Extreme Hills",
+		Name:        "extreme_hills",
+		Temperature: 0.2,
+		Rainfall:    0.3,
+	}
+	Forest = Biome{
+		ID:          4,
+		DisplayName: "Forest",
+		Name:        "forest",
+		Temperature: 0.7,
+		Rainfall:    0.8,
+	}
+	Taiga = Biome{
+		ID:          5,
+		DisplayName: "Taiga",
+		Name:        "taiga",
+		Temperature: 0.25,
+		Rainfall:    0.8,
+	}
+	Swampland = Biome{
+		ID:          6,
+		DisplayName: "Swampland",
+		Name:        "swampland",
+		Temperature: 0.8,
+		Rainfall:    0.9,
+	}
+	River = Biome{
+		ID:          7,
+		DisplayName: "River",
+		Name:        "river",
+		Temperature: 0.5,
+		Rainfall:    0.5,
+	}
+	Hell = Biome{
+		ID:          8,
+		DisplayName: "Hell",
+		Name:        "hell",
+		Temperature: 2,
+		Rainfall:    0,
+	}
+	Sky = Biome{
+		ID:          9,
+		DisplayName: "The End",
+		Name:        "sky",
+		Temperature: 0.5,
+		Rainfall:    0.5,
+	}
+	FrozenOcean = Biome{
+		ID:          10,
+		DisplayName: "FrozenOcean",
+		Name:        "frozen_ocean",
+		Temperature: 0,
+		Rainfall:    0.5,
+	}
+	FrozenRiver = Biome{
+		ID:          11,
+		DisplayName: "FrozenRiver",
+		Name:        "frozen_river",
+		Temperature: 0,
+		Rainfall:    0.5,
+	}
+	IcePlains = Biome{
+		ID:          12,
+		DisplayName: "Ice Plains",
+		Name:        "ice_plains",
+		Temperature: 0,
+		Rainfall:    0.5,
+	}
+	IceMountains = Biome{
+		ID:          13,
+		DisplayName: "Ice Mountains",
+		Name:        "ice_mountains",
+		Temperature: 0,
+		Rainfall:    0.5,
+	}
+	MushroomIsland = Biome{
+		ID:          14,
+		DisplayName: "MushroomIsland",
+		Name:        "mushroom_island",
+		Temperature: 0.9,
+		Rainfall:    1,
+	}
+	MushroomIslandShore = Biome{
+		ID:          15,
+		DisplayName: "MushroomIslandShore",
+		Name:        "mushroom_island_shore",
+		Temperature: 0.9,
+		Rainfall:    1,
+	}
+	Beach = Biome{
+		ID:          16,
+		DisplayName: "Beach",
+		Name:        "beach",
+		Temperature: 0.8,
+		Rainfall:    0.4,
+	}
+	DesertHills = Biome{
+		ID:          17,
+		DisplayName: "DesertHills",
+		Name:        "desert_hills",
+		Temperature: 2,
+		Rainfall:    0,
+	}
+	ForestHills = Biome{
+		ID:          18,
+		DisplayName: "ForestHills",
+		Name:        "forest_hills",
+		Temperature: 0.7,
+		Rainfall:    0.8,
+	}
+	TaigaHills = Biome{
+		ID:          19,
+		DisplayName: "TaigaHills",
+		Name:        "taiga_hills",
+		Temperature: 0.25,
+		Rainfall:    0.8,
+	}
+	ExtremeHillsEdge = Biome{
+		ID:          20,
+		DisplayName: "Extreme Hills Edge",
+		Name:        "extreme_hills_edge",
+		Temperature: 0.2,
+		Rainfall:    0.3,
+	}
+	Jungle = Biome{
+		ID:          21,
+		DisplayName: "Jungle",
+		Name:        "jungle",
+		Temperature: 0.95,
+		Rainfall:    0.9,
+	}
+	JungleHills = Biome{
+		ID:          22,
+		DisplayName: "JungleHills",
+		Name:        "jungle_hills",
+		Temperature: 0.95,
+		Rainfall:    0.9,
+	}
+	JungleEdge = Biome{
+		ID:          23,
+		DisplayName: "JungleEdge",
+		Name:        "jungle_edge",
+		Temperature: 0.95,
+		Rainfall:    0.8,
+	}
+	DeepOcean = Biome{
+		ID:          24,
+		DisplayName: "Deep Ocean",
+		Name:        "deep_ocean",
+		Temperature: 0.5,
+		Rainfall:    0.5,
+	}
+	StoneBeach = Biome{
+		ID:          25,
+		DisplayName: "Stone Beach",
+		Name:        "stone_beach",
+		Temperature: 0.2,
+		Rainfall:    0.3,
+	}
+	ColdBeach = Biome{
+		ID:          26,
+		DisplayName: "Cold Beach",
+		Name:        "cold_beach",
+		Temperature: 0.05,
+		Rainfall:    0.3,
+	}
+	BirchForest = Biome{
+		ID:          27,
+		DisplayName: "Birch Forest",
+		Name:        "birch_forest",
+		Temperature: 0.6,
+		Rainfall:    0.6,
+	}
+	BirchForestHills = Biome{
+		ID:          28,
+		DisplayName: "Birch Forest Hills",
+		Name:        "birch_forest_hills",
+		Temperature: 0.6,
+		Rainfall:    0.6,
+	}
+	RoofedForest = Biome{
+		ID:          29,
+		DisplayName: "Roofed Forest",
+		Name:        "roofed_forest",
+		Temperature: 0.7,
+		Rainfall:    0.8,
+	}
+	ColdTaiga = Biome{
+		ID:          30,
+		DisplayName: "Cold Taiga",
+		Name:        "cold_taiga",
+		Temperature: -0.5,
+		Rainfall:    0.4,
+	}
+	ColdTaigaHills = Biome{
+		ID:          31,
+		DisplayName: "Cold Taiga Hills",
+		Name:        "cold_taiga_hills",
+		Temperature: -0.5,
+		Rainfall:    0.4,
+	}
+	MegaTaiga = Biome{
+		ID:          32,
+		DisplayName: "Mega Taiga",
+		Name:        "mega_taiga",
+		Temperature: 0.3,
+		Rainfall:    0.8,
+	}
+	MegaTaigaHills = Biome{
+		ID:          33,
+		DisplayName: "Mega Taiga Hills",
+		Name:        "mega_taiga_hills",
+		Temperature: 0.3,
+		Rainfall:    0.8,
+	}
+	ExtremeHillsPlus = Biome{
+		ID:          34,
+		DisplayName: "Extreme Hills+",
+		Name:        "extreme_hills_plus",
+		Temperature: 0.2,
+		Rainfall:    0.3,
+	}
+	Savanna = Biome{
+		ID:          35,
+		DisplayName: "Savanna",
+		Name:        "savanna",
+		Temperature: 1.2,
+		Rainfall:    0,
+	}
+	SavannaPlateau = Biome{
+		ID:          36,
+		DisplayName: "Savanna Plateau",
+		Name:        "savanna_plateau",
+		Temperature: 1,
+		Rainfall:    0,
+	}
+	Mesa = Biome{
+		ID:          37,
+		DisplayName: "Mesa",
+		Name:        "mesa",
+		Temperature: 2,
+		Rainfall:    0,
+	}
+	MesaPlateauF = Biome{
+		ID:          38,
+		DisplayName: "Mesa Plateau F",
+		Name:        "mesa_plateau_f",
+		Temperature: 2,
+		Rainfall:    0,
+	}
+	MesaPlateau = Biome{
+		ID:          39,
+		DisplayName: "Mesa Plateau",
+		Name:        "mesa_plateau",
+		Temperature: 2,
+		Rainfall:    0,
+	}
+	SunflowerPlains = Biome{
+		ID:          129,
+		DisplayName: "Sunflower Plains",
+		Name:        "sunflower_plains",
+		Temperature: 0.8,
+		Rainfall:    0.4,
+	}
+	DesertM = Biome{
+		ID:          130,
+		DisplayName: "Desert M",
+		Name:        "desert_m",
+		Temperature: 2,
+		Rainfall:    0,
+	}
+	ExtremeHillsM = Biome{
+		ID:          131,
+		DisplayName: "Extreme Hills M",
+		Name:        "extreme_hills_m",
+		Temperature: 0.2,
+		Rainfall:    0.3,
+	}
+	FlowerForest = Biome{
+		ID:          132,
+		DisplayName: "Flower Forest",
+		Name:        "flower_forest",
+		Temperature: 0.7,
+		Rainfall:    0.8,
+	}
+	TaigaM = Biome{
+		ID:          133,
+		DisplayName: "Taiga M",
+		Name:        "taiga_m",
+		Temperature: 0.25,
+		Rainfall:    0.8,
+	}
+	SwamplandM = Biome{
+		ID:          134,
+		DisplayName: "Swampland M",
+		Name:        "swampland_m",
+		Temperature: 0.8,
+		Rainfall:    0.9,
+	}
+	IcePlainsSpikes = Biome{
+		ID:          140,
+		DisplayName: "Ice Plains Spikes",
+		Name:        "ice_plains_spikes",
+		Temperature: 0,
+		Rainfall:    0.5,
+	}
+	JungleM = Biome{
+		ID:          149,
+		DisplayName: "Jungle M",
+		Name:        "jungle_m",
+		Temperature: 0.95,
+		Rainfall:    0.9,
+	}
+	JungleEdgeM = Biome{
+		ID:          151,
+		DisplayName: "JungleEdge M",
+		Name:        "jungle_edge_m",
+		Temperature: 0.95,
+		Rainfall:    0.8,
+	}
+	BirchForestM = Biome{
+		ID:          155,
+		DisplayName: "Birch Forest M",
+		Name:        "birch_forest_m",
+		Temperature: 0.6,
+		Rainfall:    0.6,
+	}
+	BirchForestHillsM = Biome{
+		ID:          156,
+		DisplayName: "Birch Forest Hills M",
+		Name:        "birch_forest_hills_m",
+		Temperature: 0.6,
+		Rainfall:    0.6,
+	}
+	RoofedForestM = Biome{
+		ID:          157,
+		DisplayName: "Roofed Forest M",
+		Name:        "roofed_forest_m",
+		Temperature: 0.7,
+		Rainfall:    0.8,
+	}
+	ColdTaigaM = Biome{
+		ID:          158,
+		DisplayName: "Cold Taiga M",
+		Name:        "cold_taiga_m",
+		Temperature: -0.5,
+		Rainfall:    0.4,
+	}
+	MegaSpruceTaiga = Biome{
+		ID:          160,
+		DisplayName: "Mega Spruce Taiga",
+		Name:        "mega_spruce_taiga",
+		Temperature: 0.25,
+		Rainfall:    0.8,
+	}
+	RedwoodTaigaHillsM = Biome{
+		ID:          161,
+		DisplayName: "Redwood Taiga Hills M",
+		Name:        "redwood_taiga_hills_m",
+		Temperature: 0.25,
+		Rainfall:    0.8,
+	}
+	ExtremeHillsPlusM = Biome{
+		ID:          162,
+		DisplayName: "Extreme Hills+ M",
+		Name:        "extreme_hills_plus_m",
+		Temperature: 0.2,
+		Rainfall:    0.3,
+	}
+	SavannaM = Biome{
+		ID:          163,
+		DisplayName: "Savanna M",
+		Name:        "savanna_m",
+		Temperature: 1.1,
+		Rainfall:    0,
+	}
+	SavannaPlateauM = Biome{
+		ID:          164,
+		DisplayName: "Savanna Plateau M",
+		Name:        "savanna_plateau_m",
+		Temperature: 1,
+		Rainfall:    0,
+	}
+	MesaBryce = Biome{
+		ID:          165,
+		DisplayName: "Mesa (Bryce)",
+		Name:        "mesa_bryce",
+		Temperature: 2,
+		Rainfall:    0,
+	}
+	MesaPlateauFM = Biome{
+		ID:          166,
+		DisplayName: "Mesa Plateau F M",
+		Name:        "mesa_plateau_f_m",
+		Temperature: 2,
+		Rainfall:    0,
+	}
+	MesaPlateauM = Biome{
+		ID:          167,
+		DisplayName: "Mesa Plateau M",
+		Name:        "mesa_plateau_m",
+		Temperature: 2,
+		Rainfall:    0,
+	}
+)
+
+// ByID is an index of minecraft biomes by their ID.
+var ByID = map[ID]*Biome{
+	0:   &Ocean,
+	1:   &Plains,
+	2:   &Desert,
+	3:   &ExtremeHills,
+	4:   &Forest,
+	5:   &Taiga,
+	6:   &Swampland,
+	7:   &River,
+	8:   &Hell,
+	9:   &Sky,
+	10:  &FrozenOcean,
+	11:  &FrozenRiver,
+	12:  &IcePlains,
+	13:  &IceMountains,
+	14:  &MushroomIsland,
+	15:  &MushroomIslandShore,
+	16:  &Beach,
+	17:  &DesertHills,
+	18:  &ForestHills,
+	19:  &TaigaHills,
+	20:  &ExtremeHillsEdge,
+	21:  &Jungle,
+	22:  &JungleHills,
+	23:  &JungleEdge,
+	24:  &DeepOcean,
+	25:  &StoneBeach,
+	26:  &ColdBeach,
+	27:  &BirchForest,
+	28:  &BirchForestHills,
+	29:  &RoofedForest,
+	30:  &ColdTaiga,
+	31:  &ColdTaigaHills,
+	32:  &MegaTaiga,
+	33:  &MegaTaigaHills,
+	34:  &ExtremeHillsPlus,
+	35:  &Savanna,
+	36:  &SavannaPlateau,
+	37:  &Mesa,
+	38:  &MesaPlateauF,
+	39:  &MesaPlateau,
+	129: &SunflowerPlains,
+	130: &DesertM,
+	131: &ExtremeHillsM,
+	132: &FlowerForest,
+	133: &TaigaM,
+	134: &SwamplandM,
+	140: &IcePlainsSpikes,
+	149: &JungleM,
+	151: &JungleEdgeM,
+	155: &BirchForestM,
+	156: &BirchForestHillsM,
+	157: &RoofedForestM,
+	158: &ColdTaigaM,
+	160: &MegaSpruceTaiga,
+	161: &RedwoodTaigaHillsM,
+	162: &ExtremeHillsPlusM,
+	163: &SavannaM,
+	164: &SavannaPlateauM,
+	165: &MesaBryce,
+	166: &MesaPlateauFM,
+	167: &MesaPlateauM,
+}
+
+// ByName is an index of minecraft biomes by their name.
+var ByName = map[string]*Biome{
+	"ocean":                 &Ocean,
+	"plains":                &Plains,
+	"desert":                &Desert,
+	"extreme_hills":         &ExtremeHills,
+	"forest":                &Forest,
+	"taiga":                 &Taiga,
+	"swampland":             &Swampland,
+	"river":                 &River,
+	"hell":                  &Hell,
+	"sky":                   &Sky,
+	"frozen_ocean":          &FrozenOcean,
+	"frozen_river":          &FrozenRiver,
+	"ice_plains":            &IcePlains,
+	"ice_mountains":         &IceMountains,
+	"mushroom_island":       &MushroomIsland,
+	"mushroom_island_shore": &MushroomIslandShore,
+	"beach":                 &Beach,
+	"desert_hills":          &DesertHills,
+	"forest_hills":          &ForestHills,
+	"taiga_hills":           &TaigaHills,
+	"extreme_hills_edge":    &ExtremeHillsEdge,
+	"jungle":                &Jungle,
+	"jungle_hills":          &JungleHills,
+	"jungle_edge":           &JungleEdge,
+	"deep_ocean":            &DeepOcean,
+	"stone_beach":           &StoneBeach,
+	"cold_beach":            &ColdBeach,
+	"birch_forest":          &BirchForest,
+	"birch_forest_hills":    &BirchForestHills,
+	"roofed_forest":         &RoofedForest,
+	"cold_taiga":            &ColdTaiga,
+	"cold_taiga_hills":      &ColdTaigaHills,
+	"mega_taiga":            &MegaTaiga,
+	"mega_taiga_hills":      &MegaTaigaHills,
+	"extreme_hills_plus":    &ExtremeHillsPlus,
+	"savanna":               &Savanna,
+	"savanna_plateau":       &SavannaPlateau,
+	"mesa":                  &Mesa,
+	"mesa_plateau_f":        &MesaPlateauF,
+	"mesa_plateau":          &MesaPlateau,
+	"sunflower_plains":      &SunflowerPlains,
+	"desert_m":              &DesertM,
+	"extreme_hills_m":       &ExtremeHillsM,
+	"flower_forest":         &FlowerForest,
+	"taiga_m":               &TaigaM,
+	"swampland_m":           &SwamplandM,
+	"ice_plains_spikes":     &IcePlainsSpikes,
+	"jungle_m":              &JungleM,
+	"jungle_edge_m":         &JungleEdgeM,
+	"birch_forest_m":        &BirchForestM,
+	"birch_forest_hills_m":  &BirchForestHillsM,
+	"roofed_forest_m":       &RoofedForestM,
+	"cold_taiga_m":          &ColdTaigaM,
+	"mega_spruce_taiga":     &MegaSpruceTaiga,
+	"redwood_taiga_hills_m": &RedwoodTaigaHillsM,
+	"extreme_hills_plus_m":  &ExtremeHillsPlusM,
+	"savanna_m":             &SavannaM,
+	"savanna_plateau_m":     &SavannaPlateauM,
+	"mesa_bryce":            &MesaBryce,
+	"mesa_plateau_f_m":      &MesaPlateauFM,
+	"mesa_plateau_m":        &MesaPlateauM,
+}
@@ -0,0 +1,131 @@
+package minecraft
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/destructiqn/kogtevran/minecraft/biomes"
+)
+
+const (
+	ChunkSectionCount = 16
+
+	sectionBlocks       = 16 * 16 * 16
+	sectionBlockDataLen = sectionBlocks * 2
+	sectionLightLen     = sectionBlocks / 2
+	biomesLen           = 16 * 16
+)
+
+var ErrChunkDataTooShort = errors.New("chunk data is too short")
+
+type ChunkPosition struct {
+	X, Z int
+}
+
+// ChunkSection stores 16x16x16 block states, each state is block ID << 4 | metadata
+type ChunkSection struct {
+	Blocks [sectionBlocks]uint16
+}
+
+type Chunk struct {
+	Sections [ChunkSectionCount]*ChunkSection
+	// Biomes are indexed by z << 4 | x, they are only sent with ground-up continuous data
+	Biomes [biomesLen]byte
+}
+
+func sectionIndex(x, y, z int) int {
+	return (y&15)<<8 | z<<4 | x
+}
+
+// GetBlockState returns the block state at the coordinates relative to the chunk
+func (c *Chunk) GetBlockState(x, y, z int) uint16 {
+	if y < 0 || y >= ChunkSectionCount*16 {
+		return 0
+	}
+
+	section := c.Sections[y>>4]
+	if section == nil {
+		return 0
+	}
+
+	return section.Blocks[sectionIndex(x, y, z)]
+}
+
+// GetBiome returns the biome of the column at the coordinates relative to the chunk
+func (c *Chunk) GetBiome(x, z int) biomes.ID {
+	return biomes.ID(c.Biomes[z<<4|x])
+}
+
+func (c *Chunk) SetBlockState(x, y, z int, state uint16) {
+	if y < 0 || y >= ChunkSectionCount*16 {
+		return
+	}
+
+	section := c.Sections[y>>4]
+	if section == nil {
+		if state == 0 {
+			return
+		}
+
+		section = &ChunkSection{}
+		c.Sections[y>>4] = section
+	}
+
+	section.Blocks[sectionIndex(x, y, z)] = state
+}
+
+// ChunkDataLen returns the length of data of a single chunk column in 1.8 format
+func ChunkDataLen(bitMask uint16, skyLight, groundUp bool) int {
+	sections := 0
+	for i := 0; i < ChunkSectionCount; i++ {
+		if bitMask&(1<<i) != 0 {
+			sections++
+		}
+	}
+
+	length := sections * (sectionBlockDataLen + sectionLightLen)
+	if skyLight {
+		length += sections * sectionLightLen
+	}
+
+	if groundUp {
+		length += biomesLen
+	}
+
+	return length
+}
+
+// ReadSections replaces the sections present in the bit mask with the ones from data. Ground-up continuous data
+// replaces the whole column, so the sections not present in the bit mask become empty.
+// Block states of all sections go first in 1.8 format, light is skipped and biomes are at the end
+func (c *Chunk) ReadSections(data []byte, bitMask uint16, skyLight, groundUp bool) (n int, err error) {
+	n = ChunkDataLen(bitMask, skyLight, groundUp)
+	if len(data) < n {
+		return 0, ErrChunkDataTooShort
+	}
+
+	offset := 0
+	for i := 0; i < ChunkSectionCount; i++ {
+		if bitMask&(1<<i) == 0 {
+			if groundUp {
+				c.Sections[i] = nil
+			}
+
+			continue
+		}
+
+		section := &ChunkSection{}
+		for j := range section.Blocks {
+			section.Blocks[j] = binary.LittleEndian.Uint16(data[offset+j*2:])
+		}
+
+		c.Sections[i] = section
+		offset += sectionBlockDataLen
+	}
+
+	if groundUp {
+		copy(c.Biomes[:], data[n-biomesLen:n])
+	}
+
+	return n, nil
+}
@@ -0,0 +1,210 @@
+// Code generated by gen_data.go DO NOT EDIT.
+// Package effects stores information about effects in Minecraft.
+package effects
+
+// ID describes the numeric ID of an effect.
+type ID int
+
+// Effect describes information about a type of effect.
+type Effect struct {
+	ID          ID
+	DisplayName string
+	Name        string
+	// Type is either "good" or "bad"
+	Type string
+}
+
+var (
+	Slowness = Effect{
+		ID:          2,
+		DisplayName: "Slowness",
+		Name:        "Slowness",
+		Type:        "bad",
+	}
+	MiningFatigue = Effect{
+		ID:          4,
+		DisplayName: "Mining Fatigue",
+		Name:        "MiningFatigue",
+		Type:        "bad",
+	}
+	InstantDamage = Effect{
+		ID:          7,
+		DisplayName: "Instant Damage",
+		Name:        "InstantDamage",
+		Type:        "bad",
+	}
+	Nausea = Effect{
+		ID:          9,
+		DisplayName: "Nausea",
+		Name:        "Nausea",
+		Type:        "bad",
+	}
+	Blindness = Effect{
+		ID:          15,
+		DisplayName: "Blindness",
+		Name:        "Blindness",
+		Type:        "bad",
+	}
+	Hunger = Effect{
+		ID:          17,
+		DisplayName: "Hunger",
+		Name:        "Hunger",
+		Type:        "bad",
+	}
+	Weakness = Effect{
+		ID:          18,
+		DisplayName: "Weakness",
+		Name:        "Weakness",
+		Type:        "bad",
+	}
+	Poison = Effect{
+		ID:          19,
+		DisplayName: "Poison",
+		Name:        "Poison",
+		Type:        "bad",
+	}
+	Wither = Effect{
+		ID:          20,
+		DisplayName: "Wither",
+		Name:        "Wither",
+		Type:        "bad",
+	}
+	Speed = Effect{
+		ID:          1,
+		DisplayName: "Speed",
+		Name:        "Speed",
+		Type:        "good",
+	}
+	Haste = Effect{
+		ID:          3,
+		DisplayName: "Haste",
+		Name:        "Haste",
+		Type:        "good",
+	}
+	Strength = Effect{
+		ID:          5,
+		DisplayName: "Strength",
+		Name:        "Strength",
+		Type:        "good",
+	}
+	InstantHealth = Effect{
+		ID:          6,
+		DisplayName: "Instant Health",
+		Name:        "InstantHealth",
+		Type:        "good",
+	}
+	JumpBoost = Effect{
+		ID:          8,
+		DisplayName: "Jump Boost",
+		Name:        "JumpBoost",
+		Type:        "good",
+	}
+	Regeneration = Effect{
+		ID:          10,
+		DisplayName: "Regeneration",
+		Name:        "Regeneration",
+		Type:        "good",
+	}
+	Resistance = Effect{
+		ID:          11,
+		DisplayName: "Resistance",
+		Name:        "Resistance",
+		Type:        "good",
+	}
+	FireResistance = Effect{
+		ID:          12,
+		DisplayName: "Fire Resistance",
+		Name:        "FireResistance",
+		Type:        "good",
+	}
+	WaterBreathing = Effect{
+		ID:          13,
+		DisplayName: "Water Breathing",
+		Name:        "WaterBreathing",
+		Type:        "good",
+	}
+	Invisibility = Effect{
+		ID:          14,
+		DisplayName: "Invisibility",
+		Name:        "Invisibility",
+		Type:        "good",
+	}
+	NightVision = Effect{
+		ID:          16,
+		DisplayName: "Night Vision",
+		Name:        "NightVision",
+		Type:        "good",
+	}
+	HealthBoost = Effect{
+		ID:          21,
+		DisplayName: "Health Boost",
+		Name:        "HealthBoost",
+		Type:        "good",
+	}
+	Absorption = Effect{
+		ID:          22,
+		DisplayName: "Absorption",
+		Name:        "Absorption",
+		Type:        "good",
+	}
+	Saturation = Effect{
+		ID:          23,
+		DisplayName: "Saturation",
+		Name:        "Saturation",
+		Type:        "good",
+	}
+)
+
+// ByID is an index of minecraft effects by their ID.
+var ByID = map[ID]*Effect{
+	2:  &Slowness,
+	4:  &MiningFatigue,
+	7:  &InstantDamage,
+	9:  &Nausea,
+	15: &Blindness,
+	17: &Hunger,
+	18: &Weakness,
+	19: &Poison,
+	20: &Wither,
+	1:  &Speed,
+	3:  &Haste,
+	5:  &Strength,
+	6:  &InstantHealth,
+	8:  &JumpBoost,
+	10: &Regeneration,
+	11: &Resistance,
+	12: &FireResistance,
+	13: &WaterBreathing,
+	14: &Invisibility,
+	16: &NightVision,
+	21: &HealthBoost,
+	22: &Absorption,
+	23: &Saturation,
+}
+
+// ByName is an index of minecraft effects by their name.
+var ByName = map[string]*Effect{
+	"Slowness":       &Slowness,
+	"MiningFatigue":  &MiningFatigue,
+	"InstantDamage":  &InstantDamage,
+	"Nausea":         &Nausea,
+	"Blindness":      &Blindness,
+	"Hunger":         &Hunger,
+	"Weakness":       &Weakness,
+	"Poison":         &Poison,
+	"Wither":         &Wither,
+	"Speed":          &Speed,
+	"Haste":          &Haste,
+	"Strength":       &Strength,
+	"InstantHealth":  &InstantHealth,
+	"JumpBoost":      &JumpBoost,
+	"Regeneration":   &Regeneration,
+	"Resistance":     &Resistance,
+	"FireResistance": &FireResistance,
+	"WaterBreathing": &WaterBreathing,
+	"Invisibility":   &Invisibility,
+	"NightVision":    &NightVision,
+	"HealthBoost":    &HealthBoost,
+	"Absorption":     &Absorption,
+	"Saturation":     &Saturation,
+}
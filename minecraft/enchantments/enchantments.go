@@ -0,0 +1,225 @@
+// Code generated by gen_data.go DO NOT EDIT.
+// Package enchantments stores information about enchantments in Minecraft.
+package enchantments
+
+// ID describes the numeric ID of an enchantment.
+type ID int
+
+// Enchantment describes information about a type of enchantment.
+type Enchantment struct {
+	ID          ID
+	DisplayName string
+	Name        string
+	MaxLevel    int
+}
+
+var (
+	Protection = Enchantment{
+		ID:          0,
+		DisplayName: "Protection",
+		Name:        "protection",
+		MaxLevel:    4,
+	}
+	FireProtection = Enchantment{
+		ID:          1,
+		DisplayName: "Fire Protection",
+		Name:        "fire_protection",
+		MaxLevel:    4,
+	}
+	FeatherFalling = Enchantment{
+		ID:          2,
+		DisplayName: "Feather Falling",
+		Name:        "feather_falling",
+		MaxLevel:    4,
+	}
+	BlastProtection = Enchantment{
+		ID:          3,
+		DisplayName: "Blast Protection",
+		Name:        "blast_protection",
+		MaxLevel:    4,
+	}
+	ProjectileProtection = Enchantment{
+		ID:          4,
+		DisplayName: "Projectile Protection",
+		Name:        "projectile_protection",
+		MaxLevel:    4,
+	}
+	Respiration = Enchantment{
+		ID:          5,
+		DisplayName: "Respiration",
+		Name:        "respiration",
+		MaxLevel:    3,
+	}
+	AquaAffinity = Enchantment{
+		ID:          6,
+		DisplayName: "Aqua Affinity",
+		Name:        "aqua_affinity",
+		MaxLevel:    1,
+	}
+	Thorns = Enchantment{
+		ID:          7,
+		DisplayName: "Thorns",
+		Name:        "thorns",
+		MaxLevel:    3,
+	}
+	DepthStrider = Enchantment{
+		ID:          8,
+		DisplayName: "Depth Strider",
+		Name:        "depth_strider",
+		MaxLevel:    3,
+	}
+	Sharpness = Enchantment{
+		ID:          16,
+		DisplayName: "Sharpness",
+		Name:        "sharpness",
+		MaxLevel:    5,
+	}
+	Smite = Enchantment{
+		ID:          17,
+		DisplayName: "Smite",
+		Name:        "smite",
+		MaxLevel:    5,
+	}
+	BaneOfArthropods = Enchantment{
+		ID:          18,
+		DisplayName: "Bane of Arthropods",
+		Name:        "bane_of_arthropods",
+		MaxLevel:    5,
+	}
+	Knockback = Enchantment{
+		ID:          19,
+		DisplayName: "Knockback",
+		Name:        "knockback",
+		MaxLevel:    2,
+	}
+	FireAspect = Enchantment{
+		ID:          20,
+		DisplayName: "Fire Aspect",
+		Name:        "fire_aspect",
+		MaxLevel:    2,
+	}
+	Looting = Enchantment{
+		ID:          21,
+		DisplayName: "Looting",
+		Name:        "looting",
+		MaxLevel:    3,
+	}
+	Efficiency = Enchantment{
+		ID:          32,
+		DisplayName: "Efficiency",
+		Name:        "efficiency",
+		MaxLevel:    5,
+	}
+	SilkTouch = Enchantment{
+		ID:          33,
+		DisplayName: "Silk Touch",
+		Name:        "silk_touch",
+		MaxLevel:    1,
+	}
+	Unbreaking = Enchantment{
+		ID:          34,
+		DisplayName: "Unbreaking",
+		Name:        "unbreaking",
+		MaxLevel:    3,
+	}
+	Fortune = Enchantment{
+		ID:          35,
+		DisplayName: "Fortune",
+		Name:        "fortune",
+		MaxLevel:    3,
+	}
+	Power = Enchantment{
+		ID:          48,
+		DisplayName: "Power",
+		Name:        "power",
+		MaxLevel:    5,
+	}
+	Punch = Enchantment{
+		ID:          49,
+		DisplayName: "Punch",
+		Name:        "punch",
+		MaxLevel:    2,
+	}
+	Flame = Enchantment{
+		ID:          50,
+		DisplayName: "Flame",
+		Name:        "flame",
+		MaxLevel:    1,
+	}
+	Infinity = Enchantment{
+		ID:          51,
+		DisplayName: "Infinity",
+		Name:        "infinity",
+		MaxLevel:    1,
+	}
+	LuckOfTheSea = Enchantment{
+		ID:          61,
+		DisplayName: "Luck of the Sea",
+		Name:        "luck_of_the_sea",
+		MaxLevel:    3,
+	}
+	Lure = Enchantment{
+		ID:          62,
+		DisplayName: "Lure",
+		Name:        "lure",
+		MaxLevel:    3,
+	}
+)
+
+// ByID is an index of minecraft enchantments by their ID.
+var ByID = map[ID]*Enchantment{
+	0:  &Protection,
+	1:  &FireProtection,
+	2:  &FeatherFalling,
+	3:  &BlastProtection,
+	4:  &ProjectileProtection,
+	5:  &Respiration,
+	6:  &AquaAffinity,
+	7:  &Thorns,
+	8:  &DepthStrider,
+	16: &Sharpness,
+	17: &Smite,
+	18: &BaneOfArthropods,
+	19: &Knockback,
+	20: &FireAspect,
+	21: &Looting,
+	32: &Efficiency,
+	33: &SilkTouch,
+	34: &Unbreaking,
+	35: &Fortune,
+	48: &Power,
+	49: &Punch,
+	50: &Flame,
+	51: &Infinity,
+	61: &LuckOfTheSea,
+	62: &Lure,
+}
+
+// ByName is an index of minecraft enchantments by their name.
+var ByName = map[string]*Enchantment{
+	"protection":            &Protection,
+	"fire_protection":       &FireProtection,
+	"feather_falling":       &FeatherFalling,
+	"blast_protection":      &BlastProtection,
+	"projectile_protection": &ProjectileProtection,
+	"respiration":           &Respiration,
+	"aqua_affinity":         &AquaAffinity,
+	"thorns":                &Thorns,
+	"depth_strider":         &DepthStrider,
+	"sharpness":             &Sharpness,
+	"smite":                 &Smite,
+	"bane_of_arthropods":    &BaneOfArthropods,
+	"knockback":             &Knockback,
+	"fire_aspect":           &FireAspect,
+	"looting":               &Looting,
+	"efficiency":            &Efficiency,
+	"silk_touch":            &SilkTouch,
+	"unbreaking":            &Unbreaking,
+	"fortune":               &Fortune,
+	"power":                 &Power,
+	"punch":                 &Punch,
+	"flame":                 &Flame,
+	"infinity":              &Infinity,
+	"luck_of_the_sea":       &LuckOfTheSea,
+	"lure":                  &Lure,
+}
@@ -0,0 +1,562 @@
+// Code generated by gen_data.go DO NOT EDIT.
+// Package entities stores information about entities in Minecraft.
+package entities
+
+// ID describes the numeric ID of an entity, mobs and objects have separate IDs.
+type ID int
+
+// Entity describes information about a type of entity.
+type Entity struct {
+	ID          ID
+	DisplayName string
+	Name        string
+	// Type is either "mob" or "object"
+	Type   string
+	Width  float64
+	Height float64
+}
+
+var (
+	Creeper = Entity{
+		ID:          50,
+		DisplayName: "Creeper",
+		Name:        "Creeper",
+		Type:        "mob",
+		Width:       0.6,
+		Height:      1.8,
+	}
+	Skeleton = Entity{
+		ID:          51,
+		DisplayName: "Skeleton",
+		Name:        "Skeleton",
+		Type:        "mob",
+		Width:       0.6,
+		Height:      1.95,
+	}
+	Spider = Entity{
+		ID:          52,
+		DisplayName: "Spider",
+		Name:        "Spider",
+		Type:        "mob",
+		Width:       1.4,
+		Height:      0.9,
+	}
+	Giant = Entity{
+		ID:          53,
+		DisplayName: "Giant",
+		Name:        "Giant",
+		Type:        "mob",
+		Width:       3.6,
+		Height:      10.8,
+	}
+	Zombie = Entity{
+		ID:          54,
+		DisplayName: "Zombie",
+		Name:        "Zombie",
+		Type:        "mob",
+		Width:       0.6,
+		Height:      1.95,
+	}
+	Slime = Entity{
+		ID:          55,
+		DisplayName: "Slime",
+		Name:        "Slime",
+		Type:        "mob",
+		Width:       0.51,
+		Height:      0.51,
+	}
+	Ghast = Entity{
+		ID:          56,
+		DisplayName: "Ghast",
+		Name:        "Ghast",
+		Type:        "mob",
+		Width:       4,
+		Height:      4,
+	}
+	PigZombie = Entity{
+		ID:          57,
+		DisplayName: "Zombie Pigman",
+		Name:        "PigZombie",
+		Type:        "mob",
+		Width:       0.6,
+		Height:      1.95,
+	}
+	Enderman = Entity{
+		ID:          58,
+		DisplayName: "Enderman",
+		Name:        "Enderman",
+		Type:        "mob",
+		Width:       0.6,
+		Height:      2.9,
+	}
+	CaveSpider = Entity{
+		ID:          59,
+		DisplayName: "Cave Spider",
+		Name:        "CaveSpider",
+		Type:        "mob",
+		Width:       0.7,
+		Height:      0.5,
+	}
+	Silverfish = Entity{
+		ID:          60,
+		DisplayName: "Silverfish",
+		Name:        "Silverfish",
+		Type:        "mob",
+		Width:       0.4,
+		Height:      0.3,
+	}
+	Blaze = Entity{
+		ID:          61,
+		DisplayName: "Blaze",
+		Name:        "Blaze",
+		Type:        "mob",
+		Width:       0.6,
+		Height:      1.8,
+	}
+	LavaSlime = Entity{
+		ID:          62,
+		DisplayName: "Magma Cube",
+		Name:        "LavaSlime",
+		Type:        "mob",
+		Width:       0.51,
+		Height:      0.51,
+	}
+	EnderDragon = Entity{
+		ID:          63,
+		DisplayName: "Ender Dragon",
+		Name:        "EnderDragon",
+		Type:        "mob",
+		Width:       16,
+		Height:      8,
+	}
+	WitherBoss = Entity{
+		ID:          64,
+		DisplayName: "Wither",
+		Name:        "WitherBoss",
+		Type:        "mob",
+		Width:       0.9,
+		Height:      3.5,
+	}
+	Bat = Entity{
+		ID:          65,
+		DisplayName: "Bat",
+		Name:        "Bat",
+		Type:        "mob",
+		Width:       0.5,
+		Height:      0.9,
+	}
+	Witch = Entity{
+		ID:          66,
+		DisplayName: "Witch",
+		Name:        "Witch",
+		Type:        "mob",
+		Width:       0.6,
+		Height:      1.95,
+	}
+	Endermite = Entity{
+		ID:          67,
+		DisplayName: "Endermite",
+		Name:        "Endermite",
+		Type:        "mob",
+		Width:       0.4,
+		Height:      0.3,
+	}
+	Guardian = Entity{
+		ID:          68,
+		DisplayName: "Guardian",
+		Name:        "Guardian",
+		Type:        "mob",
+		Width:       0.85,
+		Height:      0.85,
+	}
+	Pig = Entity{
+		ID:          90,
+		DisplayName: "Pig",
+		Name:        "Pig",
+		Type:        "mob",
+		Width:       0.9,
+		Height:      0.9,
+	}
+	Sheep = Entity{
+		ID:          91,
+		DisplayName: "Sheep",
+		Name:        "Sheep",
+		Type:        "mob",
+		Width:       0.9,
+		Height:      1.3,
+	}
+	Cow = Entity{
+		ID:          92,
+		DisplayName: "Cow",
+		Name:        "Cow",
+		Type:        "mob",
+		Width:       0.9,
+		Height:      1.3,
+	}
+	Chicken = Entity{
+		ID:          93,
+		DisplayName: "Chicken",
+		Name:        "Chicken",
+		Type:        "mob",
+		Width:       0.4,
+		Height:      0.7,
+	}
+	Squid = Entity{
+		ID:          94,
+		DisplayName: "Squid",
+		Name:        "Squid",
+		Type:        "mob",
+		Width:       0.95,
+		Height:      0.95,
+	}
+	Wolf = Entity{
+		ID:          95,
+		DisplayName: "Wolf",
+		Name:        "Wolf",
+		Type:        "mob",
+		Width:       0.6,
+		Height:      0.8,
+	}
+	MushroomCow = Entity{
+		ID:          96,
+		DisplayName: "Mooshroom",
+		Name:        "MushroomCow",
+		Type:        "mob",
+		Width:       0.9,
+		Height:      1.3,
+	}
+	SnowMan = Entity{
+		ID:          97,
+		DisplayName: "Snow Golem",
+		Name:        "SnowMan",
+		Type:        "mob",
+		Width:       0.7,
+		Height:      1.9,
+	}
+	Ozelot = Entity{
+		ID:          98,
+		DisplayName: "Ocelot",
+		Name:        "Ozelot",
+		Type:        "mob",
+		Width:       0.6,
+		Height:      0.7,
+	}
+	VillagerGolem = Entity{
+		ID:          99,
+		DisplayName: "Iron Golem",
+		Name:        "VillagerGolem",
+		Type:        "mob",
+		Width:       1.4,
+		Height:      2.9,
+	}
+	EntityHorse = Entity{
+		ID:          100,
+		DisplayName: "Horse",
+		Name:        "EntityHorse",
+		Type:        "mob",
+		Width:       1.4,
+		Height:      1.6,
+	}
+	Rabbit = Entity{
+		ID:          101,
+		DisplayName: "Rabbit",
+		Name:        "Rabbit",
+		Type:        "mob",
+		Width:       0.6,
+		Height:      0.7,
+	}
+	Villager = Entity{
+		ID:          120,
+		DisplayName: "Villager",
+		Name:        "Villager",
+		Type:        "mob",
+		Width:       0.6,
+		Height:      1.95,
+	}
+	Boat = Entity{
+		ID:          1,
+		DisplayName: "Boat",
+		Name:        "Boat",
+		Type:        "object",
+		Width:       1.5,
+		Height:      0.6,
+	}
+	Item = Entity{
+		ID:          2,
+		DisplayName: "Dropped Item",
+		Name:        "Item",
+		Type:        "object",
+		Width:       0.25,
+		Height:      0.25,
+	}
+	MinecartRideable = Entity{
+		ID:          10,
+		DisplayName: "Minecart",
+		Name:        "MinecartRideable",
+		Type:        "object",
+		Width:       0.98,
+		Height:      0.7,
+	}
+	PrimedTnt = Entity{
+		ID:          50,
+		DisplayName: "Primed TNT",
+		Name:        "PrimedTnt",
+		Type:        "object",
+		Width:       0.98,
+		Height:      0.98,
+	}
+	EnderCrystal = Entity{
+		ID:          51,
+		DisplayName: "Ender Crystal",
+		Name:        "EnderCrystal",
+		Type:        "object",
+		Width:       2,
+		Height:      2,
+	}
+	Arrow = Entity{
+		ID:          60,
+		DisplayName: "Arrow",
+		Name:        "Arrow",
+		Type:        "object",
+		Width:       0.5,
+		Height:      0.5,
+	}
+	Snowball = Entity{
+		ID:          61,
+		DisplayName: "Snowball",
+		Name:        "Snowball",
+		Type:        "object",
+		Width:       0.25,
+		Height:      0.25,
+	}
+	Egg = Entity{
+		ID:          62,
+		DisplayName: "Egg",
+		Name:        "Egg",
+		Type:        "object",
+		Width:       0.25,
+		Height:      0.25,
+	}
+	Fireball = Entity{
+		ID:          63,
+		DisplayName: "Fireball",
+		Name:        "Fireball",
+		Type:        "object",
+		Width:       1,
+		Height:      1,
+	}
+	SmallFireball = Entity{
+		ID:          64,
+		DisplayName: "Fire Charge",
+		Name:        "SmallFireball",
+		Type:        "object",
+		Width:       0.3125,
+		Height:      0.3125,
+	}
+	ThrownEnderpearl = Entity{
+		ID:          65,
+		DisplayName: "Thrown Ender Pearl",
+		Name:        "ThrownEnderpearl",
+		Type:        "object",
+		Width:       0.25,
+		Height:      0.25,
+	}
+	WitherSkull = Entity{
+		ID:          66,
+		DisplayName: "Wither Skull",
+		Name:        "WitherSkull",
+		Type:        "object",
+		Width:       0.3125,
+		Height:      0.3125,
+	}
+	FallingSand = Entity{
+		ID:          70,
+		DisplayName: "Falling Block",
+		Name:        "FallingSand",
+		Type:        "object",
+		Width:       0.98,
+		Height:      0.98,
+	}
+	ItemFrame = Entity{
+		ID:          71,
+		DisplayName: "Item Frame",
+		Name:        "ItemFrame",
+		Type:        "object",
+		Width:       0.5,
+		Height:      0.5,
+	}
+	EyeOfEnderSignal = Entity{
+		ID:          72,
+		DisplayName: "Eye of Ender",
+		Name:        "EyeOfEnderSignal",
+		Type:        "object",
+		Width:       0.25,
+		Height:      0.25,
+	}
+	ThrownPotion = Entity{
+		ID:          73,
+		DisplayName: "Thrown Potion",
+		Name:        "ThrownPotion",
+		Type:        "object",
+		Width:       0.25,
+		Height:      0.25,
+	}
+	ThrownExpBottle = Entity{
+		ID:          75,
+		DisplayName: "Thrown Exp Bottle",
+		Name:        "ThrownExpBottle",
+		Type:        "object",
+		Width:       0.25,
+		Height:      0.25,
+	}
+	FireworksRocketEntity = Entity{
+		ID:          76,
+		DisplayName: "Firework Rocket",
+		Name:        "FireworksRocketEntity",
+		Type:        "object",
+		Width:       0.25,
+		Height:      0.25,
+	}
+	LeashKnot = Entity{
+		ID:          77,
+		DisplayName: "Leash Knot",
+		Name:        "LeashKnot",
+		Type:        "object",
+		Width:       0.5,
+		Height:      0.5,
+	}
+	ArmorStand = Entity{
+		ID:          78,
+		DisplayName: "Armor Stand",
+		Name:        "ArmorStand",
+		Type:        "object",
+		Width:       0.5,
+		Height:      2,
+	}
+	FishingHook = Entity{
+		ID:          90,
+		DisplayName: "Fishing Hook",
+		Name:        "FishingHook",
+		Type:        "object",
+		Width:       0.25,
+		Height:      0.25,
+	}
+)
+
+// ByID is an index of minecraft mobs by their ID.
+var ByID = map[ID]*Entity{
+	50:  &Creeper,
+	51:  &Skeleton,
+	52:  &Spider,
+	53:  &Giant,
+	54:  &Zombie,
+	55:  &Slime,
+	56:  &Ghast,
+	57:  &PigZombie,
+	58:  &Enderman,
+	59:  &CaveSpider,
+	60:  &Silverfish,
+	61:  &Blaze,
+	62:  &LavaSlime,
+	63:  &EnderDragon,
+	64:  &WitherBoss,
+	65:  &Bat,
+	66:  &Witch,
+	67:  &Endermite,
+	68:  &Guardian,
+	90:  &Pig,
+	91:  &Sheep,
+	92:  &Cow,
+	93:  &Chicken,
+	94:  &Squid,
+	95:  &Wolf,
+	96:  &MushroomCow,
+	97:  &SnowMan,
+	98:  &Ozelot,
+	99:  &VillagerGolem,
+	100: &EntityHorse,
+	101: &Rabbit,
+	120: &Villager,
+}
+
+// ObjectsByID is an index of minecraft objects by their ID.
+var ObjectsByID = map[ID]*Entity{
+	1:  &Boat,
+	2:  &Item,
+	10: &MinecartRideable,
+	50: &PrimedTnt,
+	51: &EnderCrystal,
+	60: &Arrow,
+	61: &Snowball,
+	62: &Egg,
+	63: &Fireball,
+	64: &SmallFireball,
+	65: &ThrownEnderpearl,
+	66: &WitherSkull,
+	70: &FallingSand,
+	71: &ItemFrame,
+	72: &EyeOfEnderSignal,
+	73: &ThrownPotion,
+	75: &ThrownExpBottle,
+	76: &FireworksRocketEntity,
+	77: &LeashKnot,
+	78: &ArmorStand,
+	90: &FishingHook,
+}
+
+// ByName is an index of minecraft entities by their name.
+var ByName = map[string]*Entity{
+	"Creeper":               &Creeper,
+	"Skeleton":              &Skeleton,
+	"Spider":                &Spider,
+	"Giant":                 &Giant,
+	"Zombie":                &Zombie,
+	"Slime":                 &Slime,
+	"Ghast":                 &Ghast,
+	"PigZombie":             &PigZombie,
+	"Enderman":              &Enderman,
+	"CaveSpider":            &CaveSpider,
+	"Silverfish":            &Silverfish,
+	"Blaze":                 &Blaze,
+	"LavaSlime":             &LavaSlime,
+	"EnderDragon":           &EnderDragon,
+	"WitherBoss":            &WitherBoss,
+	"Bat":                   &Bat,
+	"Witch":                 &Witch,
+	"Endermite":             &Endermite,
+	"Guardian":              &Guardian,
+	"Pig":                   &Pig,
+	"Sheep":                 &Sheep,
+	"Cow":                   &Cow,
+	"Chicken":               &Chicken,
+	"Squid":                 &Squid,
+	"Wolf":                  &Wolf,
+	"MushroomCow":           &MushroomCow,
+	"SnowMan":               &SnowMan,
+	"Ozelot":                &Ozelot,
+	"VillagerGolem":         &VillagerGolem,
+	"EntityHorse":           &EntityHorse,
+	"Rabbit":                &Rabbit,
+	"Villager":              &Villager,
+	"Boat":                  &Boat,
+	"Item":                  &Item,
+	"MinecartRideable":      &MinecartRideable,
+	"PrimedTnt":             &PrimedTnt,
+	"EnderCrystal":          &EnderCrystal,
+	"Arrow":                 &Arrow,
+	"Snowball":              &Snowball,
+	"Egg":                   &Egg,
+	"Fireball":              &Fireball,
+	"SmallFireball":         &SmallFireball,
+	"ThrownEnderpearl":      &ThrownEnderpearl,
+	"WitherSkull":           &WitherSkull,
+	"FallingSand":           &FallingSand,
+	"ItemFrame":             &ItemFrame,
+	"EyeOfEnderSignal":      &EyeOfEnderSignal,
+	"ThrownPotion":          &ThrownPotion,
+	"ThrownExpBottle":       &ThrownExpBottle,
+	"FireworksRocketEntity": &FireworksRocketEntity,
+	"LeashKnot":             &LeashKnot,
+	"ArmorStand":            &ArmorStand,
+	"FishingHook":           &FishingHook,
+}
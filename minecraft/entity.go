@@ -1,60 +1,295 @@
 package minecraft
 
-type MobType int
+import (
+	"github.com/Tnze/go-mc/chat"
+	"github.com/destructiqn/kogtevran/minecraft/entities"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/google/uuid"
+)
+
+// Metadata indexes shared by all entities, and by living ones starting from MetadataHealth
+const (
+	MetadataFlags      = 0
+	MetadataAir        = 1
+	MetadataCustomName = 2
+	MetadataHealth     = 6
+)
+
+type EntityFlags byte
+
+const (
+	EntityOnFire EntityFlags = 1 << iota
+	EntityCrouched
+	_
+	EntitySprinting
+	EntityEating
+	EntityInvisible
+)
+
+func (f EntityFlags) Has(flag EntityFlags) bool {
+	return f&flag != 0
+}
+
+type EquipmentSlot int
 
 const (
-	Creeper      MobType = 50
-	Skeleton     MobType = 51
-	Spider       MobType = 52
-	GiantZombie  MobType = 53
-	Zombie       MobType = 54
-	Slime        MobType = 55
-	Ghast        MobType = 56
-	ZombiePigman MobType = 57
-	Enderman     MobType = 58
-	CaveSpider   MobType = 59
-	Silverfish   MobType = 60
-	Blaze        MobType = 61
-	MagmaCube    MobType = 62
-	EnderDragon  MobType = 63
-	Wither       MobType = 64
-	Bat          MobType = 65
-	Witch        MobType = 66
-	Endermite    MobType = 67
-	Guardian     MobType = 68
-	Shulker      MobType = 69
-	Pig          MobType = 90
-	Sheep        MobType = 91
-	Cow          MobType = 92
-	Chicken      MobType = 93
-	Squid        MobType = 94
-	Wolf         MobType = 95
-	Mooshroom    MobType = 96
-	Snowman      MobType = 97
-	Ocelot       MobType = 98
-	IronGolem    MobType = 99
-	Horse        MobType = 100
-	Rabbit       MobType = 101
-	Villager     MobType = 120
+	EquipmentHeld EquipmentSlot = iota
+	EquipmentBoots
+	EquipmentLeggings
+	EquipmentChestplate
+	EquipmentHelmet
+	EquipmentSlotCount
 )
 
+type Equipment [EquipmentSlotCount]pk.Slot
+
+// Velocity is measured in blocks per tick
+type Velocity struct {
+	X, Y, Z float64
+}
+
+// NoEntity is the vehicle or leash holder ID of an entity that is not attached to anything
+const NoEntity = -1
+
+// Entity returned by handlers is a snapshot, changing it does not affect the tracked entity
 type Entity interface {
+	Copy() Entity
+	GetEntityID() int
 	GetLocation() *Location
+	GetVelocity() *Velocity
+	GetHeadYaw() float64
+	SetHeadYaw(yaw float64)
+	GetMetadata() pk.EntityMetadata
+	GetFlags() EntityFlags
+	GetHealth() (health float64, ok bool)
+	GetCustomName() string
+	GetEquipment() *Equipment
+	GetVehicleID() int
+	GetLeashHolderID() int
+	Attach(entityID int, leash bool)
 }
 
 type DefaultEntity struct {
-	Location *Location
+	EntityID      int
+	Location      *Location
+	Velocity      Velocity
+	HeadYaw       float64
+	Metadata      pk.EntityMetadata
+	Equipment     Equipment
+	VehicleID     int
+	LeashHolderID int
+}
+
+func NewDefaultEntity(entityID int, location *Location, metadata pk.EntityMetadata) DefaultEntity {
+	if metadata == nil {
+		metadata = make(pk.EntityMetadata)
+	}
+
+	entity := DefaultEntity{
+		EntityID:      entityID,
+		Location:      location,
+		HeadYaw:       location.Yaw,
+		Metadata:      metadata,
+		VehicleID:     NoEntity,
+		LeashHolderID: NoEntity,
+	}
+
+	for i := range entity.Equipment {
+		entity.Equipment[i] = pk.Slot{BlockID: -1}
+	}
+
+	return entity
+}
+
+// copy returns a deep copy of the entity state, metadata values are never changed in place, so they are shared
+func (e *DefaultEntity) copy() DefaultEntity {
+	entity := *e
+	if e.Location != nil {
+		location := *e.Location
+		entity.Location = &location
+	}
+
+	entity.Metadata = make(pk.EntityMetadata, len(e.Metadata))
+	for index, value := range e.Metadata {
+		entity.Metadata[index] = value
+	}
+
+	return entity
+}
+
+func (e *DefaultEntity) GetEntityID() int {
+	return e.EntityID
 }
 
 func (e *DefaultEntity) GetLocation() *Location {
 	return e.Location
 }
 
+func (e *DefaultEntity) GetVelocity() *Velocity {
+	return &e.Velocity
+}
+
+func (e *DefaultEntity) GetHeadYaw() float64 {
+	return e.HeadYaw
+}
+
+func (e *DefaultEntity) SetHeadYaw(yaw float64) {
+	e.HeadYaw = yaw
+}
+
+func (e *DefaultEntity) GetMetadata() pk.EntityMetadata {
+	return e.Metadata
+}
+
+func (e *DefaultEntity) GetFlags() EntityFlags {
+	flags, _ := e.Metadata[MetadataFlags].(pk.MetadataByte)
+	return EntityFlags(flags)
+}
+
+// GetHealth is only known for living entities, and only when the server shares it
+func (e *DefaultEntity) GetHealth() (health float64, ok bool) {
+	value, ok := e.Metadata[MetadataHealth].(pk.MetadataFloat)
+	return float64(value), ok
+}
+
+func (e *DefaultEntity) GetCustomName() string {
+	name, _ := e.Metadata[MetadataCustomName].(pk.MetadataString)
+	return string(name)
+}
+
+func (e *DefaultEntity) GetEquipment() *Equipment {
+	return &e.Equipment
+}
+
+func (e *DefaultEntity) GetVehicleID() int {
+	return e.VehicleID
+}
+
+func (e *DefaultEntity) GetLeashHolderID() int {
+	return e.LeashHolderID
+}
+
+// Attach mounts the entity on a vehicle or leashes it, NoEntity detaches it
+func (e *DefaultEntity) Attach(entityID int, leash bool) {
+	if leash {
+		e.LeashHolderID = entityID
+	} else {
+		e.VehicleID = entityID
+	}
+}
+
 type Mob struct {
 	DefaultEntity
-	Type MobType
+	Type entities.ID
+}
+
+// GetType returns the generated data of the mob type, mobs added after 1.8 are unknown
+func (m *Mob) GetType() (*entities.Entity, bool) {
+	entity, ok := entities.ByID[m.Type]
+	return entity, ok
+}
+
+func (m *Mob) Copy() Entity {
+	mob := *m
+	mob.DefaultEntity = m.DefaultEntity.copy()
+	return &mob
+}
+
+type Object struct {
+	DefaultEntity
+	Type entities.ID
+	Data int
+}
+
+func (o *Object) GetType() (*entities.Entity, bool) {
+	entity, ok := entities.ObjectsByID[o.Type]
+	return entity, ok
+}
+
+func (o *Object) Copy() Entity {
+	object := *o
+	object.DefaultEntity = o.DefaultEntity.copy()
+	return &object
 }
 
 type Player struct {
 	DefaultEntity
+	UUID        uuid.UUID
+	Name        string
+	DisplayName *chat.Message
+}
+
+// Copy shares the display name, it is replaced rather than changed on tab list updates
+func (p *Player) Copy() Entity {
+	player := *p
+	player.DefaultEntity = p.DefaultEntity.copy()
+	return &player
+}
+
+// GetName returns the name from the tab list, or the custom name if the player is not listed there
+func (p *Player) GetName() string {
+	if p.Name != "" {
+		return p.Name
+	}
+
+	return p.GetCustomName()
+}
+
+type PlayerListEntry struct {
+	UUID        uuid.UUID
+	Name        string
+	GameMode    int
+	Ping        int
+	DisplayName *chat.Message
+}
+
+type EntityFilter func(entity Entity) bool
+
+func IsPlayer(entity Entity) bool {
+	_, ok := entity.(*Player)
+	return ok
+}
+
+func IsMob(entity Entity) bool {
+	_, ok := entity.(*Mob)
+	return ok
+}
+
+func IsObject(entity Entity) bool {
+	_, ok := entity.(*Object)
+	return ok
+}
+
+func IsMobOfType(types ...entities.Entity) EntityFilter {
+	return func(entity Entity) bool {
+		mob, ok := entity.(*Mob)
+		if !ok {
+			return false
+		}
+
+		for _, mobType := range types {
+			if mobType.Type == "mob" && mob.Type == mobType.ID {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+func IsObjectOfType(types ...entities.Entity) EntityFilter {
+	return func(entity Entity) bool {
+		object, ok := entity.(*Object)
+		if !ok {
+			return false
+		}
+
+		for _, objectType := range types {
+			if objectType.Type == "object" && object.Type == objectType.ID {
+				return true
+			}
+		}
+
+		return false
+	}
 }
@@ -0,0 +1,344 @@
+//go:build generate
+// +build generate
+
+// gen_data.go generates information about blocks, items, entities, effects, enchantments and biomes
+// from a local checkout of PrismarineJS/minecraft-data.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/template"
+
+	"github.com/iancoleman/strcase"
+)
+
+const (
+	//language=gohtml
+	header = `// Code generated by gen_data.go DO NOT EDIT.
+// Package {{ .Package }} stores information about {{ .Description }} in Minecraft.
+package {{ .Package }}
+`
+
+	//language=gohtml
+	blocksTmpl = `// Code generated by gen_data.go DO NOT EDIT.
+// Package item stores information about blocks in Minecraft.
+package blocks
+// ID describes the numeric ID of an item.
+type ID uint32
+// Block describes information about a type of item.
+type Block struct {
+	ID          ID
+	DisplayName string
+	Name        string
+	StackSize   uint
+	Hardness    float64
+	Diggable    bool
+	Transparent bool
+}
+var (
+	{{- range .Values }}
+	{{ .CamelName }} = Block{
+		ID: {{ .ID }},
+		DisplayName: "{{ .DisplayName }}",
+		Name: "{{ .Name }}",
+		StackSize: {{ .StackSize }},
+		Hardness: {{ .Hardness }},
+		Diggable: {{ .Diggable }},
+		Transparent: {{ .Transparent }},
+	}{{ end }}
+)
+// ByID is an index of minecraft blocks by their ID.
+var ByID = map[ID]*Block{ {{ range .Values }}
+	{{ .ID }}: &{{ .CamelName }},{{ end }}
+}`
+
+	//language=gohtml
+	itemsTmpl = header + `
+// ID describes the numeric ID of an item.
+type ID int16
+// Item describes information about a type of item.
+type Item struct {
+	ID            ID
+	DisplayName   string
+	Name          string
+	StackSize     int
+	// MaxDurability is zero for items that do not wear out
+	MaxDurability int
+}
+var (
+	{{- range .Values }}
+	{{ .CamelName }} = Item{
+		ID: {{ .ID }},
+		DisplayName: "{{ .DisplayName }}",
+		Name: "{{ .Name }}",
+		StackSize: {{ .StackSize }},
+		MaxDurability: {{ .MaxDurability }},
+	}{{ end }}
+)
+` + indexes
+
+	//language=gohtml
+	entitiesTmpl = header + `
+// ID describes the numeric ID of an entity, mobs and objects have separate IDs.
+type ID int
+// Entity describes information about a type of entity.
+type Entity struct {
+	ID          ID
+	DisplayName string
+	Name        string
+	// Type is either "mob" or "object"
+	Type        string
+	Width       float64
+	Height      float64
+}
+var (
+	{{- range .Values }}
+	{{ .CamelName }} = Entity{
+		ID: {{ .ID }},
+		DisplayName: "{{ .DisplayName }}",
+		Name: "{{ .Name }}",
+		Type: "{{ .Type }}",
+		Width: {{ .Width }},
+		Height: {{ .Height }},
+	}{{ end }}
+)
+// ByID is an index of minecraft mobs by their ID.
+var ByID = map[ID]*Entity{ {{ range .Values }}{{ if eq .Type "mob" }}
+	{{ .ID }}: &{{ .CamelName }},{{ end }}{{ end }}
+}
+// ObjectsByID is an index of minecraft objects by their ID.
+var ObjectsByID = map[ID]*Entity{ {{ range .Values }}{{ if eq .Type "object" }}
+	{{ .ID }}: &{{ .CamelName }},{{ end }}{{ end }}
+}
+// ByName is an index of minecraft entities by their name.
+var ByName = map[string]*Entity{ {{ range .Values }}
+	"{{ .Name }}": &{{ .CamelName }},{{ end }}
+}`
+
+	//language=gohtml
+	effectsTmpl = header + `
+// ID describes the numeric ID of an effect.
+type ID int
+// Effect describes information about a type of effect.
+type Effect struct {
+	ID          ID
+	DisplayName string
+	Name        string
+	// Type is either "good" or "bad"
+	Type        string
+}
+var (
+	{{- range .Values }}
+	{{ .CamelName }} = Effect{
+		ID: {{ .ID }},
+		DisplayName: "{{ .DisplayName }}",
+		Name: "{{ .Name }}",
+		Type: "{{ .Type }}",
+	}{{ end }}
+)
+` + indexes
+
+	//language=gohtml
+	enchantmentsTmpl = header + `
+// ID describes the numeric ID of an enchantment.
+type ID int
+// Enchantment describes information about a type of enchantment.
+type Enchantment struct {
+	ID          ID
+	DisplayName string
+	Name        string
+	MaxLevel    int
+}
+var (
+	{{- range .Values }}
+	{{ .CamelName }} = Enchantment{
+		ID: {{ .ID }},
+		DisplayName: "{{ .DisplayName }}",
+		Name: "{{ .Name }}",
+		MaxLevel: {{ .MaxLevel }},
+	}{{ end }}
+)
+` + indexes
+
+	//language=gohtml
+	biomesTmpl = header + `
+// ID describes the numeric ID of a biome.
+type ID int
+// Biome describes information about a type of biome.
+type Biome struct {
+	ID          ID
+	DisplayName string
+	Name        string
+	Temperature float64
+	Rainfall    float64
+}
+var (
+	{{- range .Values }}
+	{{ .CamelName }} = Biome{
+		ID: {{ .ID }},
+		DisplayName: "{{ .DisplayName }}",
+		Name: "{{ .Name }}",
+		Temperature: {{ .Temperature }},
+		Rainfall: {{ .Rainfall }},
+	}{{ end }}
+)
+` + indexes
+
+	//language=gohtml
+	indexes = `// ByID is an index of minecraft {{ .Description }} by their ID.
+var ByID = map[ID]*{{ .Type }}{ {{ range .Values }}
+	{{ .ID }}: &{{ .CamelName }},{{ end }}
+}
+// ByName is an index of minecraft {{ .Description }} by their name.
+var ByName = map[string]*{{ .Type }}{ {{ range .Values }}
+	"{{ .Name }}": &{{ .CamelName }},{{ end }}
+}`
+)
+
+// Info holds the fields of all of the datasets, each template uses its own ones
+type Info struct {
+	ID          int    `json:"id"`
+	CamelName   string `json:"-"`
+	DisplayName string `json:"displayName"`
+	Name        string `json:"name"`
+
+	StackSize     uint    `json:"stackSize"`
+	Hardness      float64 `json:"hardness"`
+	Diggable      bool    `json:"diggable"`
+	Transparent   bool    `json:"transparent"`
+	MaxDurability int     `json:"maxDurability"`
+	Type          string  `json:"type"`
+	Width         float64 `json:"width"`
+	Height        float64 `json:"height"`
+	MaxLevel      int     `json:"maxLevel"`
+	Temperature   float64 `json:"temperature"`
+	Rainfall      float64 `json:"rainfall"`
+}
+
+type dataset struct {
+	Package     string
+	Description string
+	Type        string
+	Values      []*Info
+
+	template string
+}
+
+var datasets = []*dataset{
+	{Package: "blocks", Description: "blocks", Type: "Block", template: blocksTmpl},
+	{Package: "items", Description: "items", Type: "Item", template: itemsTmpl},
+	{Package: "entities", Description: "entities", Type: "Entity", template: entitiesTmpl},
+	{Package: "effects", Description: "effects", Type: "Effect", template: effectsTmpl},
+	{Package: "enchantments", Description: "enchantments", Type: "Enchantment", template: enchantmentsTmpl},
+	{Package: "biomes", Description: "biomes", Type: "Biome", template: biomesTmpl},
+}
+
+// dataDir returns the directory with dataPaths.json, the path is either the checkout or its data directory
+func dataDir(path string) (string, error) {
+	for _, dir := range []string{filepath.Join(path, "data"), path} {
+		if _, err := os.Stat(filepath.Join(dir, "dataPaths.json")); err == nil {
+			return dir, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s is not a minecraft-data checkout", path)
+}
+
+func readInfo(dir, version, name string) ([]*Info, error) {
+	f, err := os.Open(filepath.Join(dir, "dataPaths.json"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var paths struct {
+		PC map[string]map[string]string `json:"pc"`
+	}
+
+	if err := json.NewDecoder(f).Decode(&paths); err != nil {
+		return nil, err
+	}
+
+	path, ok := paths.PC[version][name]
+	if !ok {
+		return nil, fmt.Errorf("no %s for version %s", name, version)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, path, name+".json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var values []*Info
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	sort.SliceStable(values, func(i, j int) bool {
+		if values[i].Type != values[j].Type {
+			return values[i].Type < values[j].Type
+		}
+
+		return values[i].ID < values[j].ID
+	})
+
+	for _, value := range values {
+		value.CamelName = strcase.ToCamel(value.Name)
+		if value.DisplayName == "" {
+			value.DisplayName = value.CamelName
+		}
+	}
+
+	return values, nil
+}
+
+func generate(d *dataset) error {
+	var buf bytes.Buffer
+	if err := template.Must(template.New(d.Package).Parse(d.template)).Execute(&buf, d); err != nil {
+		return err
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(d.Package, 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(d.Package, d.Package+".go"), source, 0644)
+}
+
+//go:generate go run $GOFILE -data $MINECRAFT_DATA
+func main() {
+	path := flag.String("data", os.Getenv("MINECRAFT_DATA"), "path to the minecraft-data checkout")
+	version := flag.String("version", "1.8", "minecraft version to generate information for")
+	flag.Parse()
+
+	dir, err := dataDir(*path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, d := range datasets {
+		fmt.Printf("generating %s/%s.go\n", d.Package, d.Package)
+		d.Values, err = readInfo(dir, *version, d.Package)
+		if err == nil {
+			err = generate(d)
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
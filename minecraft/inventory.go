@@ -0,0 +1,112 @@
+package minecraft
+
+import (
+	"bytes"
+
+	"github.com/destructiqn/kogtevran/minecraft/blocks"
+	"github.com/destructiqn/kogtevran/minecraft/items"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+)
+
+const (
+	HotbarSize        = 9
+	MainInventorySize = 27
+
+	// PlayerInventorySize includes the crafting result, crafting grid and armor slots
+	PlayerInventorySize = 9 + MainInventorySize + HotbarSize
+)
+
+type WindowRegion int
+
+const (
+	RegionContainer WindowRegion = 1 << iota
+	RegionMain
+	RegionHotbar
+
+	RegionInventory = RegionMain | RegionHotbar
+	RegionAll       = RegionContainer | RegionInventory
+)
+
+// WindowLayout maps window slots to regions, every window has the slots of its container
+// followed by the main inventory and the hotbar of the player
+type WindowLayout struct {
+	ContainerSize int
+}
+
+// PlayerInventoryLayout is the layout of the window 0, crafting and armor slots make its container
+var PlayerInventoryLayout = WindowLayout{ContainerSize: PlayerInventorySize - MainInventorySize - HotbarSize}
+
+func (l WindowLayout) Size() int {
+	return l.ContainerSize + MainInventorySize + HotbarSize
+}
+
+// Region returns the region of the slot, or zero if the slot is outside the window
+func (l WindowLayout) Region(slot int) WindowRegion {
+	switch {
+	case slot < 0 || slot >= l.Size():
+		return 0
+	case slot < l.ContainerSize:
+		return RegionContainer
+	case slot < l.ContainerSize+MainInventorySize:
+		return RegionMain
+	default:
+		return RegionHotbar
+	}
+}
+
+// Slots returns slots of the regions in ascending order
+func (l WindowLayout) Slots(region WindowRegion) []int {
+	slots := make([]int, 0)
+	for slot := 0; slot < l.Size(); slot++ {
+		if l.Region(slot)&region != 0 {
+			slots = append(slots, slot)
+		}
+	}
+
+	return slots
+}
+
+// HotbarSlot returns the window slot of the hotbar index from 0 to 8
+func (l WindowLayout) HotbarSlot(index int) int {
+	return l.ContainerSize + MainInventorySize + index
+}
+
+func (l WindowLayout) HotbarIndex(slot int) (index int, ok bool) {
+	if l.Region(slot) != RegionHotbar {
+		return 0, false
+	}
+
+	return slot - l.HotbarSlot(0), true
+}
+
+type ItemFilter func(item pk.Slot) bool
+
+func IsItem(id int16) ItemFilter {
+	return func(item pk.Slot) bool {
+		return item.BlockID == id
+	}
+}
+
+func IsItemWithDamage(id, damage int16) ItemFilter {
+	return func(item pk.Slot) bool {
+		return item.BlockID == id && item.ItemDamage == damage
+	}
+}
+
+// CanStack reports whether the items are of the same kind and may share a slot
+func CanStack(a, b pk.Slot) bool {
+	return !a.IsEmpty() && a.BlockID == b.BlockID && a.ItemDamage == b.ItemDamage &&
+		a.ItemData.Type == b.ItemData.Type && bytes.Equal(a.ItemData.Data, b.ItemData.Data)
+}
+
+func MaxStackSize(id int16) int {
+	if item, ok := items.ByID[items.ID(id)]; ok {
+		return item.StackSize
+	}
+
+	if block, ok := blocks.ByID[blocks.ID(id)]; ok && block.StackSize > 0 {
+		return int(block.StackSize)
+	}
+
+	return 64
+}
@@ -0,0 +1,304 @@
+package minecraft
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/Tnze/go-mc/nbt"
+	"github.com/destructiqn/kogtevran/minecraft/enchantments"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/destructiqn/kogtevran/minecraft/tag"
+)
+
+var ErrNoItem = errors.New("slot is empty")
+
+type Enchantment struct {
+	ID    enchantments.ID
+	Level int
+}
+
+// ItemEffect is a custom effect of a potion, its duration is in ticks
+type ItemEffect struct {
+	EffectID      int
+	Amplifier     int
+	Duration      int
+	Ambient       bool
+	ShowParticles bool
+}
+
+// itemTags are the values decoded from the tags, the ones changed since decoding are written back
+type itemTags struct {
+	Enchantments       []Enchantment
+	StoredEnchantments []Enchantment
+	Name               string
+	Lore               []string
+	Unbreakable        bool
+	// SkullOwner is written back as a name, the profile it is decoded from is replaced
+	SkullOwner string
+	Effects    []ItemEffect
+}
+
+// Item is a view of the slot with its tags decoded. Tags it does not know about are kept as they are,
+// so an item that has not been changed is encoded into the same bytes it was decoded from
+type Item struct {
+	ID     int16
+	Count  int8
+	Damage int16
+
+	itemTags
+
+	// Tag is the whole tag of the item, it is updated with the decoded values on encoding
+	Tag      tag.Compound
+	hasTag   bool
+	original itemTags
+}
+
+func NewItem(slot pk.Slot) (*Item, error) {
+	if slot.IsEmpty() {
+		return nil, ErrNoItem
+	}
+
+	item := &Item{ID: slot.BlockID, Count: slot.ItemCount, Damage: slot.ItemDamage}
+	if slot.ItemData.Type != tag.TagCompound {
+		return item, nil
+	}
+
+	compound, err := tag.Decode(slot.ItemData.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Tag, item.hasTag = compound, true
+	item.itemTags = decodeItemTags(compound)
+	item.original = decodeItemTags(compound)
+	return item, nil
+}
+
+// Slot encodes the item back, only the values changed since decoding are written to the tag
+func (i *Item) Slot() (pk.Slot, error) {
+	slot := pk.Slot{BlockID: i.ID, ItemCount: i.Count, ItemDamage: i.Damage}
+	i.encodeItemTags()
+	if !i.hasTag && len(i.Tag) == 0 {
+		slot.ItemData = nbt.RawMessage{Type: tag.TagEnd}
+		return slot, nil
+	}
+
+	data, err := tag.Encode(i.Tag)
+	if err != nil {
+		return pk.Slot{}, err
+	}
+
+	slot.ItemData = nbt.RawMessage{Type: tag.TagCompound, Data: data}
+	return slot, nil
+}
+
+func (i *Item) IsEnchanted() bool {
+	return len(i.Enchantments) > 0
+}
+
+// GetEnchantment returns the level of the enchantment, or zero if the item does not have it
+func (i *Item) GetEnchantment(id enchantments.ID) int {
+	for _, enchantment := range i.Enchantments {
+		if enchantment.ID == id {
+			return enchantment.Level
+		}
+	}
+
+	return 0
+}
+
+func toInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int8:
+		return int(v), true
+	case int16:
+		return int(v), true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	}
+
+	return 0, false
+}
+
+func decodeEnchantments(compound tag.Compound, name string) []Enchantment {
+	list, ok := compound.GetList(name)
+	if !ok {
+		return nil
+	}
+
+	decoded := make([]Enchantment, 0, len(list.Values))
+	for _, value := range list.Values {
+		entry, _ := value.(tag.Compound)
+		id, _ := entry.Get("id")
+		level, _ := entry.Get("lvl")
+
+		enchantment := Enchantment{}
+		enchantmentID, _ := toInt(id)
+		enchantment.ID = enchantments.ID(enchantmentID)
+		enchantment.Level, _ = toInt(level)
+		decoded = append(decoded, enchantment)
+	}
+
+	return decoded
+}
+
+func decodeItemTags(compound tag.Compound) itemTags {
+	tags := itemTags{
+		Enchantments:       decodeEnchantments(compound, "ench"),
+		StoredEnchantments: decodeEnchantments(compound, "StoredEnchantments"),
+	}
+
+	if display, ok := compound.GetCompound("display"); ok {
+		tags.Name, _ = display.GetString("Name")
+		if lore, ok := display.GetList("Lore"); ok {
+			tags.Lore = make([]string, 0, len(lore.Values))
+			for _, line := range lore.Values {
+				s, _ := line.(string)
+				tags.Lore = append(tags.Lore, s)
+			}
+		}
+	}
+
+	unbreakable, _ := compound.Get("Unbreakable")
+	if value, _ := toInt(unbreakable); value != 0 {
+		tags.Unbreakable = true
+	}
+
+	// Owner is either a name or a game profile
+	tags.SkullOwner, _ = compound.GetString("SkullOwner")
+	if owner, ok := compound.GetCompound("SkullOwner"); ok {
+		tags.SkullOwner, _ = owner.GetString("Name")
+	}
+
+	if effects, ok := compound.GetList("CustomPotionEffects"); ok {
+		tags.Effects = make([]ItemEffect, 0, len(effects.Values))
+		for _, value := range effects.Values {
+			entry, _ := value.(tag.Compound)
+			id, _ := entry.Get("Id")
+			amplifier, _ := entry.Get("Amplifier")
+			duration, _ := entry.Get("Duration")
+			ambient, _ := entry.Get("Ambient")
+
+			effect := ItemEffect{ShowParticles: true}
+			effect.EffectID, _ = toInt(id)
+			effect.Amplifier, _ = toInt(amplifier)
+			effect.Duration, _ = toInt(duration)
+			if value, _ := toInt(ambient); value != 0 {
+				effect.Ambient = true
+			}
+
+			if showParticles, ok := entry.Get("ShowParticles"); ok {
+				value, _ := toInt(showParticles)
+				effect.ShowParticles = value != 0
+			}
+
+			tags.Effects = append(tags.Effects, effect)
+		}
+	}
+
+	return tags
+}
+
+func boolTag(value bool) int8 {
+	if value {
+		return 1
+	}
+
+	return 0
+}
+
+func encodeEnchantments(values []Enchantment) tag.List {
+	list := tag.List{Type: tag.TagCompound, Values: make([]interface{}, 0, len(values))}
+	for _, enchantment := range values {
+		list.Values = append(list.Values, tag.Compound{
+			{Name: "id", Value: int16(enchantment.ID)},
+			{Name: "lvl", Value: int16(enchantment.Level)},
+		})
+	}
+
+	return list
+}
+
+// setOrDelete updates the tag of the changed value, empty values remove the tag
+func setOrDelete(compound *tag.Compound, name string, empty bool, value func() interface{}) {
+	if empty {
+		compound.Delete(name)
+	} else {
+		compound.Set(name, value())
+	}
+}
+
+func (i *Item) encodeItemTags() {
+	changed := func(a, b interface{}) bool {
+		return !reflect.DeepEqual(a, b)
+	}
+
+	if changed(i.Enchantments, i.original.Enchantments) {
+		setOrDelete(&i.Tag, "ench", len(i.Enchantments) == 0, func() interface{} {
+			return encodeEnchantments(i.Enchantments)
+		})
+	}
+
+	if changed(i.StoredEnchantments, i.original.StoredEnchantments) {
+		setOrDelete(&i.Tag, "StoredEnchantments", len(i.StoredEnchantments) == 0, func() interface{} {
+			return encodeEnchantments(i.StoredEnchantments)
+		})
+	}
+
+	if changed(i.Name, i.original.Name) || changed(i.Lore, i.original.Lore) {
+		display, _ := i.Tag.GetCompound("display")
+		if changed(i.Name, i.original.Name) {
+			setOrDelete(&display, "Name", i.Name == "", func() interface{} {
+				return i.Name
+			})
+		}
+
+		if changed(i.Lore, i.original.Lore) {
+			setOrDelete(&display, "Lore", len(i.Lore) == 0, func() interface{} {
+				lore := tag.List{Type: tag.TagString, Values: make([]interface{}, 0, len(i.Lore))}
+				for _, line := range i.Lore {
+					lore.Values = append(lore.Values, line)
+				}
+
+				return lore
+			})
+		}
+
+		setOrDelete(&i.Tag, "display", len(display) == 0, func() interface{} {
+			return display
+		})
+	}
+
+	if changed(i.Unbreakable, i.original.Unbreakable) {
+		setOrDelete(&i.Tag, "Unbreakable", !i.Unbreakable, func() interface{} {
+			return int8(1)
+		})
+	}
+
+	if changed(i.SkullOwner, i.original.SkullOwner) {
+		setOrDelete(&i.Tag, "SkullOwner", i.SkullOwner == "", func() interface{} {
+			return i.SkullOwner
+		})
+	}
+
+	if changed(i.Effects, i.original.Effects) {
+		setOrDelete(&i.Tag, "CustomPotionEffects", len(i.Effects) == 0, func() interface{} {
+			effects := tag.List{Type: tag.TagCompound, Values: make([]interface{}, 0, len(i.Effects))}
+			for _, effect := range i.Effects {
+				effects.Values = append(effects.Values, tag.Compound{
+					{Name: "Id", Value: int8(effect.EffectID)},
+					{Name: "Amplifier", Value: int8(effect.Amplifier)},
+					{Name: "Duration", Value: int32(effect.Duration)},
+					{Name: "Ambient", Value: boolTag(effect.Ambient)},
+					{Name: "ShowParticles", Value: boolTag(effect.ShowParticles)},
+				})
+			}
+
+			return effects
+		})
+	}
+
+	i.original = decodeItemTags(i.Tag)
+}
@@ -0,0 +1,128 @@
+package minecraft
+
+import (
+	"testing"
+
+	"github.com/Tnze/go-mc/nbt"
+	"github.com/destructiqn/kogtevran/minecraft/enchantments"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/destructiqn/kogtevran/minecraft/tag"
+	"github.com/stretchr/testify/assert"
+)
+
+// Slots captured from a 1.8 server, the header is followed by the unnamed root compound
+var (
+	capturedSword = []byte("\x01\x14\x01\x00\x00" + "\x0a\x00\x00" +
+		"\x09\x00\x04ench\x0a\x00\x00\x00\x01" +
+		"\x02\x00\x02id\x00\x10" + "\x02\x00\x03lvl\x00\x05" + "\x00" +
+		"\x0a\x00\x07display" +
+		"\x08\x00\x04Name\x00\x0c\xc2\xa7cExcalibur" +
+		"\x09\x00\x04Lore\x08\x00\x00\x00\x01\x00\x05Sharp" + "\x00" +
+		"\x01\x00\x0bUnbreakable\x01" +
+		"\x03\x00\x09HideFlags\x00\x00\x00\x01" +
+		"\x00")
+
+	capturedPotion = []byte("\x01\x75\x01\x20\x01" + "\x0a\x00\x00" +
+		"\x09\x00\x13CustomPotionEffects\x0a\x00\x00\x00\x01" +
+		"\x01\x00\x02Id\x01" + "\x01\x00\x09Amplifier\x01" + "\x03\x00\x08Duration\x00\x00\x02\x58" + "\x00" +
+		"\x00")
+
+	capturedSkull = []byte("\x01\x8d\x01\x00\x03" + "\x0a\x00\x00" +
+		"\x0a\x00\x0aSkullOwner" +
+		"\x08\x00\x02Id\x00\x24069a79f4-44e9-4726-a5be-fca90e38aaf5" +
+		"\x08\x00\x04Name\x00\x05Notch" + "\x00" +
+		"\x00")
+)
+
+// capturedSlot splits the captured bytes the same way Slot.ReadFrom does
+func capturedSlot(captured []byte) pk.Slot {
+	return pk.Slot{
+		BlockID:    int16(captured[0])<<8 | int16(captured[1]),
+		ItemCount:  int8(captured[2]),
+		ItemDamage: int16(captured[3])<<8 | int16(captured[4]),
+		ItemData:   nbt.RawMessage{Type: captured[5], Data: captured[8:]},
+	}
+}
+
+func TestItem_RoundTrip(t *testing.T) {
+	for _, captured := range [][]byte{capturedSword, capturedPotion, capturedSkull} {
+		slot := capturedSlot(captured)
+		item, err := NewItem(slot)
+		assert.NoError(t, err)
+
+		encoded, err := item.Slot()
+		assert.NoError(t, err)
+		assert.Equal(t, slot, encoded)
+	}
+
+	// Items without tags stay without them
+	slot := pk.Slot{BlockID: 278, ItemCount: 1}
+	item, err := NewItem(slot)
+	assert.NoError(t, err)
+	encoded, err := item.Slot()
+	assert.NoError(t, err)
+	assert.Equal(t, slot, encoded)
+
+	_, err = NewItem(pk.Slot{BlockID: -1})
+	assert.Equal(t, ErrNoItem, err)
+}
+
+func TestItem_Decode(t *testing.T) {
+	sword, err := NewItem(capturedSlot(capturedSword))
+	assert.NoError(t, err)
+	assert.Equal(t, int16(276), sword.ID)
+	assert.True(t, sword.IsEnchanted())
+	assert.Equal(t, []Enchantment{{ID: enchantments.Sharpness.ID, Level: 5}}, sword.Enchantments)
+	assert.Equal(t, 5, sword.GetEnchantment(enchantments.Sharpness.ID))
+	assert.Equal(t, 0, sword.GetEnchantment(enchantments.Smite.ID))
+	assert.Equal(t, "§cExcalibur", sword.Name)
+	assert.Equal(t, []string{"Sharp"}, sword.Lore)
+	assert.True(t, sword.Unbreakable)
+
+	potion, err := NewItem(capturedSlot(capturedPotion))
+	assert.NoError(t, err)
+	assert.Equal(t, int16(8193), potion.Damage)
+	assert.Equal(t, []ItemEffect{{EffectID: 1, Amplifier: 1, Duration: 600, ShowParticles: true}}, potion.Effects)
+
+	skull, err := NewItem(capturedSlot(capturedSkull))
+	assert.NoError(t, err)
+	assert.Equal(t, "Notch", skull.SkullOwner)
+}
+
+func TestItem_Encode(t *testing.T) {
+	sword, _ := NewItem(capturedSlot(capturedSword))
+	sword.Name = "Sword"
+	sword.Lore = nil
+	sword.Unbreakable = false
+	sword.Enchantments = append(sword.Enchantments, Enchantment{ID: enchantments.Unbreaking.ID, Level: 3})
+
+	slot, err := sword.Slot()
+	assert.NoError(t, err)
+
+	decoded, err := NewItem(slot)
+	assert.NoError(t, err)
+	assert.Equal(t, "Sword", decoded.Name)
+	assert.Nil(t, decoded.Lore)
+	assert.False(t, decoded.Unbreakable)
+	assert.Equal(t, 3, decoded.GetEnchantment(enchantments.Unbreaking.ID))
+
+	// Unknown tags stay in place
+	names := make([]string, 0)
+	for _, entry := range decoded.Tag {
+		names = append(names, entry.Name)
+	}
+	assert.Equal(t, []string{"ench", "display", "HideFlags"}, names)
+	flags, _ := decoded.Tag.Get("HideFlags")
+	assert.Equal(t, int32(1), flags)
+
+	// Tags are created for items that had none
+	item, _ := NewItem(pk.Slot{BlockID: 373, ItemCount: 1})
+	item.Effects = []ItemEffect{{EffectID: 5, Duration: 20, Ambient: true}}
+	slot, err = item.Slot()
+	assert.NoError(t, err)
+	assert.Equal(t, tag.TagCompound, slot.ItemData.Type)
+
+	decoded, err = NewItem(slot)
+	assert.NoError(t, err)
+	assert.Equal(t, item.Effects, decoded.Effects)
+}
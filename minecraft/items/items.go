@@ -0,0 +1,1710 @@
+// Code generated by gen_data.go DO NOT EDIT.
+// Package items stores information about items in Minecraft.
+package items
+
+// ID describes the numeric ID of an item.
+type ID int16
+
+// Item describes information about a type of item.
+type Item struct {
+	ID          ID
+	DisplayName string
+	Name        string
+	StackSize   int
+	// MaxDurability is zero for items that do not wear out
+	MaxDurability int
+}
+
+var (
+	IronShovel = Item{
+		ID:            256,
+		DisplayName:   "Iron Shovel",
+		Name:          "iron_shovel",
+		StackSize:     1,
+		MaxDurability: 250,
+	}
+	IronPickaxe = Item{
+		ID:            257,
+		DisplayName:   "Iron Pickaxe",
+		Name:          "iron_pickaxe",
+		StackSize:     1,
+		MaxDurability: 250,
+	}
+	IronAxe = Item{
+		ID:            258,
+		DisplayName:   "Iron Axe",
+		Name:          "iron_axe",
+		StackSize:     1,
+		MaxDurability: 250,
+	}
+	FlintAndSteel = Item{
+		ID:            259,
+		DisplayName:   "Flint and Steel",
+		Name:          "flint_and_steel",
+		StackSize:     1,
+		MaxDurability: 64,
+	}
+	Apple = Item{
+		ID:            260,
+		DisplayName:   "Apple",
+		Name:          "apple",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Bow = Item{
+		ID:            261,
+		DisplayName:   "Bow",
+		Name:          "bow",
+		StackSize:     1,
+		MaxDurability: 384,
+	}
+	Arrow = Item{
+		ID:            262,
+		DisplayName:   "Arrow",
+		Name:          "arrow",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Coal = Item{
+		ID:            263,
+		DisplayName:   "Coal",
+		Name:          "coal",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Diamond = Item{
+		ID:            264,
+		DisplayName:   "Diamond",
+		Name:          "diamond",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	IronIngot = Item{
+		ID:            265,
+		DisplayName:   "Iron Ingot",
+		Name:          "iron_ingot",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	GoldIngot = Item{
+		ID:            266,
+		DisplayName:   "Gold Ingot",
+		Name:          "gold_ingot",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	IronSword = Item{
+		ID:            267,
+		DisplayName:   "Iron Sword",
+		Name:          "iron_sword",
+		StackSize:     1,
+		MaxDurability: 250,
+	}
+	WoodenSword = Item{
+		ID:            268,
+		DisplayName:   "Wooden Sword",
+		Name:          "wooden_sword",
+		StackSize:     1,
+		MaxDurability: 59,
+	}
+	WoodenShovel = Item{
+		ID:            269,
+		DisplayName:   "Wooden Shovel",
+		Name:          "wooden_shovel",
+		StackSize:     1,
+		MaxDurability: 59,
+	}
+	WoodenPickaxe = Item{
+		ID:            270,
+		DisplayName:   "Wooden Pickaxe",
+		Name:          "wooden_pickaxe",
+		StackSize:     1,
+		MaxDurability: 59,
+	}
+	WoodenAxe = Item{
+		ID:            271,
+		DisplayName:   "Wooden Axe",
+		Name:          "wooden_axe",
+		StackSize:     1,
+		MaxDurability: 59,
+	}
+	StoneSword = Item{
+		ID:            272,
+		DisplayName:   "Stone Sword",
+		Name:          "stone_sword",
+		StackSize:     1,
+		MaxDurability: 131,
+	}
+	StoneShovel = Item{
+		ID:            273,
+		DisplayName:   "Stone Shovel",
+		Name:          "stone_shovel",
+		StackSize:     1,
+		MaxDurability: 131,
+	}
+	StonePickaxe = Item{
+		ID:            274,
+		DisplayName:   "Stone Pickaxe",
+		Name:          "stone_pickaxe",
+		StackSize:     1,
+		MaxDurability: 131,
+	}
+	StoneAxe = Item{
+		ID:            275,
+		DisplayName:   "Stone Axe",
+		Name:          "stone_axe",
+		StackSize:     1,
+		MaxDurability: 131,
+	}
+	DiamondSword = Item{
+		ID:            276,
+		DisplayName:   "Diamond Sword",
+		Name:          "diamond_sword",
+		StackSize:     1,
+		MaxDurability: 1561,
+	}
+	DiamondShovel = Item{
+		ID:            277,
+		DisplayName:   "Diamond Shovel",
+		Name:          "diamond_shovel",
+		StackSize:     1,
+		MaxDurability: 1561,
+	}
+	DiamondPickaxe = Item{
+		ID:            278,
+		DisplayName:   "Diamond Pickaxe",
+		Name:          "diamond_pickaxe",
+		StackSize:     1,
+		MaxDurability: 1561,
+	}
+	DiamondAxe = Item{
+		ID:            279,
+		DisplayName:   "Diamond Axe",
+		Name:          "diamond_axe",
+		StackSize:     1,
+		MaxDurability: 1561,
+	}
+	Stick = Item{
+		ID:            280,
+		DisplayName:   "Stick",
+		Name:          "stick",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Bowl = Item{
+		ID:            281,
+		DisplayName:   "Bowl",
+		Name:          "bowl",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	MushroomStew = Item{
+		ID:            282,
+		DisplayName:   "Mushroom Stew",
+		Name:          "mushroom_stew",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	GoldenSword = Item{
+		ID:            283,
+		DisplayName:   "Golden Sword",
+		Name:          "golden_sword",
+		StackSize:     1,
+		MaxDurability: 32,
+	}
+	GoldenShovel = Item{
+		ID:            284,
+		DisplayName:   "Golden Shovel",
+		Name:          "golden_shovel",
+		StackSize:     1,
+		MaxDurability: 32,
+	}
+	GoldenPickaxe = Item{
+		ID:            285,
+		DisplayName:   "Golden Pickaxe",
+		Name:          "golden_pickaxe",
+		StackSize:     1,
+		MaxDurability: 32,
+	}
+	GoldenAxe = Item{
+		ID:            286,
+		DisplayName:   "Golden Axe",
+		Name:          "golden_axe",
+		StackSize:     1,
+		MaxDurability: 32,
+	}
+	String = Item{
+		ID:            287,
+		DisplayName:   "String",
+		Name:          "string",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Feather = Item{
+		ID:            288,
+		DisplayName:   "Feather",
+		Name:          "feather",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Gunpowder = Item{
+		ID:            289,
+		DisplayName:   "Gunpowder",
+		Name:          "gunpowder",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	WoodenHoe = Item{
+		ID:            290,
+		DisplayName:   "Wooden Hoe",
+		Name:          "wooden_hoe",
+		StackSize:     1,
+		MaxDurability: 59,
+	}
+	StoneHoe = Item{
+		ID:            291,
+		DisplayName:   "Stone Hoe",
+		Name:          "stone_hoe",
+		StackSize:     1,
+		MaxDurability: 131,
+	}
+	IronHoe = Item{
+		ID:            292,
+		DisplayName:   "Iron Hoe",
+		Name:          "iron_hoe",
+		StackSize:     1,
+		MaxDurability: 250,
+	}
+	DiamondHoe = Item{
+		ID:            293,
+		DisplayName:   "Diamond Hoe",
+		Name:          "diamond_hoe",
+		StackSize:     1,
+		MaxDurability: 1561,
+	}
+	GoldenHoe = Item{
+		ID:            294,
+		DisplayName:   "Golden Hoe",
+		Name:          "golden_hoe",
+		StackSize:     1,
+		MaxDurability: 32,
+	}
+	WheatSeeds = Item{
+		ID:            295,
+		DisplayName:   "Seeds",
+		Name:          "wheat_seeds",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Wheat = Item{
+		ID:            296,
+		DisplayName:   "Wheat",
+		Name:          "wheat",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Bread = Item{
+		ID:            297,
+		DisplayName:   "Bread",
+		Name:          "bread",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	LeatherHelmet = Item{
+		ID:            298,
+		DisplayName:   "Leather Cap",
+		Name:          "leather_helmet",
+		StackSize:     1,
+		MaxDurability: 55,
+	}
+	LeatherChestplate = Item{
+		ID:            299,
+		DisplayName:   "Leather Tunic",
+		Name:          "leather_chestplate",
+		StackSize:     1,
+		MaxDurability: 80,
+	}
+	LeatherLeggings = Item{
+		ID:            300,
+		DisplayName:   "Leather Pants",
+		Name:          "leather_leggings",
+		StackSize:     1,
+		MaxDurability: 75,
+	}
+	LeatherBoots = Item{
+		ID:            301,
+		DisplayName:   "Leather Boots",
+		Name:          "leather_boots",
+		StackSize:     1,
+		MaxDurability: 65,
+	}
+	ChainmailHelmet = Item{
+		ID:            302,
+		DisplayName:   "Chain Helmet",
+		Name:          "chainmail_helmet",
+		StackSize:     1,
+		MaxDurability: 165,
+	}
+	ChainmailChestplate = Item{
+		ID:            303,
+		DisplayName:   "Chain Chestplate",
+		Name:          "chainmail_chestplate",
+		StackSize:     1,
+		MaxDurability: 240,
+	}
+	ChainmailLeggings = Item{
+		ID:            304,
+		DisplayName:   "Chain Leggings",
+		Name:          "chainmail_leggings",
+		StackSize:     1,
+		MaxDurability: 225,
+	}
+	ChainmailBoots = Item{
+		ID:            305,
+		DisplayName:   "Chain Boots",
+		Name:          "chainmail_boots",
+		StackSize:     1,
+		MaxDurability: 195,
+	}
+	IronHelmet = Item{
+		ID:            306,
+		DisplayName:   "Iron Helmet",
+		Name:          "iron_helmet",
+		StackSize:     1,
+		MaxDurability: 165,
+	}
+	IronChestplate = Item{
+		ID:            307,
+		DisplayName:   "Iron Chestplate",
+		Name:          "iron_chestplate",
+		StackSize:     1,
+		MaxDurability: 240,
+	}
+	IronLeggings = Item{
+		ID:            308,
+		DisplayName:   "Iron Leggings",
+		Name:          "iron_leggings",
+		StackSize:     1,
+		MaxDurability: 225,
+	}
+	IronBoots = Item{
+		ID:            309,
+		DisplayName:   "Iron Boots",
+		Name:          "iron_boots",
+		StackSize:     1,
+		MaxDurability: 195,
+	}
+	DiamondHelmet = Item{
+		ID:            310,
+		DisplayName:   "Diamond Helmet",
+		Name:          "diamond_helmet",
+		StackSize:     1,
+		MaxDurability: 363,
+	}
+	DiamondChestplate = Item{
+		ID:            311,
+		DisplayName:   "Diamond Chestplate",
+		Name:          "diamond_chestplate",
+		StackSize:     1,
+		MaxDurability: 528,
+	}
+	DiamondLeggings = Item{
+		ID:            312,
+		DisplayName:   "Diamond Leggings",
+		Name:          "diamond_leggings",
+		StackSize:     1,
+		MaxDurability: 495,
+	}
+	DiamondBoots = Item{
+		ID:            313,
+		DisplayName:   "Diamond Boots",
+		Name:          "diamond_boots",
+		StackSize:     1,
+		MaxDurability: 429,
+	}
+	GoldenHelmet = Item{
+		ID:            314,
+		DisplayName:   "Golden Helmet",
+		Name:          "golden_helmet",
+		StackSize:     1,
+		MaxDurability: 77,
+	}
+	GoldenChestplate = Item{
+		ID:            315,
+		DisplayName:   "Golden Chestplate",
+		Name:          "golden_chestplate",
+		StackSize:     1,
+		MaxDurability: 112,
+	}
+	GoldenLeggings = Item{
+		ID:            316,
+		DisplayName:   "Golden Leggings",
+		Name:          "golden_leggings",
+		StackSize:     1,
+		MaxDurability: 105,
+	}
+	GoldenBoots = Item{
+		ID:            317,
+		DisplayName:   "Golden Boots",
+		Name:          "golden_boots",
+		StackSize:     1,
+		MaxDurability: 91,
+	}
+	Flint = Item{
+		ID:            318,
+		DisplayName:   "Flint",
+		Name:          "flint",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Porkchop = Item{
+		ID:            319,
+		DisplayName:   "Raw Porkchop",
+		Name:          "porkchop",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	CookedPorkchop = Item{
+		ID:            320,
+		DisplayName:   "Cooked Porkchop",
+		Name:          "cooked_porkchop",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Painting = Item{
+		ID:            321,
+		DisplayName:   "Painting",
+		Name:          "painting",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	GoldenApple = Item{
+		ID:            322,
+		DisplayName:   "Golden Apple",
+		Name:          "golden_apple",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Sign = Item{
+		ID:            323,
+		DisplayName:   "Sign",
+		Name:          "sign",
+		StackSize:     16,
+		MaxDurability: 0,
+	}
+	WoodenDoor = Item{
+		ID:            324,
+		DisplayName:   "Oak Door",
+		Name:          "wooden_door",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Bucket = Item{
+		ID:            325,
+		DisplayName:   "Bucket",
+		Name:          "bucket",
+		StackSize:     16,
+		MaxDurability: 0,
+	}
+	WaterBucket = Item{
+		ID:            326,
+		DisplayName:   "Water Bucket",
+		Name:          "water_bucket",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	LavaBucket = Item{
+		ID:            327,
+		DisplayName:   "Lava Bucket",
+		Name:          "lava_bucket",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	Minecart = Item{
+		ID:            328,
+		DisplayName:   "Minecart",
+		Name:          "minecart",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	Saddle = Item{
+		ID:            329,
+		DisplayName:   "Saddle",
+		Name:          "saddle",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	IronDoor = Item{
+		ID:            330,
+		DisplayName:   "Iron Door",
+		Name:          "iron_door",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Redstone = Item{
+		ID:            331,
+		DisplayName:   "Redstone",
+		Name:          "redstone",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Snowball = Item{
+		ID:            332,
+		DisplayName:   "Snowball",
+		Name:          "snowball",
+		StackSize:     16,
+		MaxDurability: 0,
+	}
+	Boat = Item{
+		ID:            333,
+		DisplayName:   "Boat",
+		Name:          "boat",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	Leather = Item{
+		ID:            334,
+		DisplayName:   "Leather",
+		Name:          "leather",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	MilkBucket = Item{
+		ID:            335,
+		DisplayName:   "Milk",
+		Name:          "milk_bucket",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	Brick = Item{
+		ID:            336,
+		DisplayName:   "Brick",
+		Name:          "brick",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	ClayBall = Item{
+		ID:            337,
+		DisplayName:   "Clay",
+		Name:          "clay_ball",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Reeds = Item{
+		ID:            338,
+		DisplayName:   "Sugar Canes",
+		Name:          "reeds",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Paper = Item{
+		ID:            339,
+		DisplayName:   "Paper",
+		Name:          "paper",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Book = Item{
+		ID:            340,
+		DisplayName:   "Book",
+		Name:          "book",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	SlimeBall = Item{
+		ID:            341,
+		DisplayName:   "Slimeball",
+		Name:          "slime_ball",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	ChestMinecart = Item{
+		ID:            342,
+		DisplayName:   "Minecart with Chest",
+		Name:          "chest_minecart",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	FurnaceMinecart = Item{
+		ID:            343,
+		DisplayName:   "Minecart with Furnace",
+		Name:          "furnace_minecart",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	Egg = Item{
+		ID:            344,
+		DisplayName:   "Egg",
+		Name:          "egg",
+		StackSize:     16,
+		MaxDurability: 0,
+	}
+	Compass = Item{
+		ID:            345,
+		DisplayName:   "Compass",
+		Name:          "compass",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	FishingRod = Item{
+		ID:            346,
+		DisplayName:   "Fishing Rod",
+		Name:          "fishing_rod",
+		StackSize:     1,
+		MaxDurability: 64,
+	}
+	Clock = Item{
+		ID:            347,
+		DisplayName:   "Clock",
+		Name:          "clock",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	GlowstoneDust = Item{
+		ID:            348,
+		DisplayName:   "Glowstone Dust",
+		Name:          "glowstone_dust",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Fish = Item{
+		ID:            349,
+		DisplayName:   "Raw Fish",
+		Name:          "fish",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	CookedFish = Item{
+		ID:            350,
+		DisplayName:   "Cooked Fish",
+		Name:          "cooked_fish",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Dye = Item{
+		ID:            351,
+		DisplayName:   "Dye",
+		Name:          "dye",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Bone = Item{
+		ID:            352,
+		DisplayName:   "Bone",
+		Name:          "bone",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Sugar = Item{
+		ID:            353,
+		DisplayName:   "Sugar",
+		Name:          "sugar",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Cake = Item{
+		ID:            354,
+		DisplayName:   "Cake",
+		Name:          "cake",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	Bed = Item{
+		ID:            355,
+		DisplayName:   "Bed",
+		Name:          "bed",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	Repeater = Item{
+		ID:            356,
+		DisplayName:   "Redstone Repeater",
+		Name:          "repeater",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Cookie = Item{
+		ID:            357,
+		DisplayName:   "Cookie",
+		Name:          "cookie",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	FilledMap = Item{
+		ID:            358,
+		DisplayName:   "Map",
+		Name:          "filled_map",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Shears = Item{
+		ID:            359,
+		DisplayName:   "Shears",
+		Name:          "shears",
+		StackSize:     1,
+		MaxDurability: 238,
+	}
+	Melon = Item{
+		ID:            360,
+		DisplayName:   "Melon",
+		Name:          "melon",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	PumpkinSeeds = Item{
+		ID:            361,
+		DisplayName:   "Pumpkin Seeds",
+		Name:          "pumpkin_seeds",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	MelonSeeds = Item{
+		ID:            362,
+		DisplayName:   "Melon Seeds",
+		Name:          "melon_seeds",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Beef = Item{
+		ID:            363,
+		DisplayName:   "Raw Beef",
+		Name:          "beef",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	CookedBeef = Item{
+		ID:            364,
+		DisplayName:   "Steak",
+		Name:          "cooked_beef",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Chicken = Item{
+		ID:            365,
+		DisplayName:   "Raw Chicken",
+		Name:          "chicken",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	CookedChicken = Item{
+		ID:            366,
+		DisplayName:   "Cooked Chicken",
+		Name:          "cooked_chicken",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	RottenFlesh = Item{
+		ID:            367,
+		DisplayName:   "Rotten Flesh",
+		Name:          "rotten_flesh",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	EnderPearl = Item{
+		ID:            368,
+		DisplayName:   "Ender Pearl",
+		Name:          "ender_pearl",
+		StackSize:     16,
+		MaxDurability: 0,
+	}
+	BlazeRod = Item{
+		ID:            369,
+		DisplayName:   "Blaze Rod",
+		Name:          "blaze_rod",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	GhastTear = Item{
+		ID:            370,
+		DisplayName:   "Ghast Tear",
+		Name:          "ghast_tear",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	GoldNugget = Item{
+		ID:            371,
+		DisplayName:   "Gold Nugget",
+		Name:          "gold_nugget",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	NetherWart = Item{
+		ID:            372,
+		DisplayName:   "Nether Wart",
+		Name:          "nether_wart",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Potion = Item{
+		ID:            373,
+		DisplayName:   "Potion",
+		Name:          "potion",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	GlassBottle = Item{
+		ID:            374,
+		DisplayName:   "Glass Bottle",
+		Name:          "glass_bottle",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	SpiderEye = Item{
+		ID:            375,
+		DisplayName:   "Spider Eye",
+		Name:          "spider_eye",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	FermentedSpiderEye = Item{
+		ID:            376,
+		DisplayName:   "Fermented Spider Eye",
+		Name:          "fermented_spider_eye",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	BlazePowder = Item{
+		ID:            377,
+		DisplayName:   "Blaze Powder",
+		Name:          "blaze_powder",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	MagmaCream = Item{
+		ID:            378,
+		DisplayName:   "Magma Cream",
+		Name:          "magma_cream",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	BrewingStand = Item{
+		ID:            379,
+		DisplayName:   "Brewing Stand",
+		Name:          "brewing_stand",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Cauldron = Item{
+		ID:            380,
+		DisplayName:   "Cauldron",
+		Name:          "cauldron",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	EnderEye = Item{
+		ID:            381,
+		DisplayName:   "Eye of Ender",
+		Name:          "ender_eye",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	SpeckledMelon = Item{
+		ID:            382,
+		DisplayName:   "Glistering Melon",
+		Name:          "speckled_melon",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	SpawnEgg = Item{
+		ID:            383,
+		DisplayName:   "Spawn Egg",
+		Name:          "spawn_egg",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	ExperienceBottle = Item{
+		ID:            384,
+		DisplayName:   "Bottle o' Enchanting",
+		Name:          "experience_bottle",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	FireCharge = Item{
+		ID:            385,
+		DisplayName:   "Fire Charge",
+		Name:          "fire_charge",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	WritableBook = Item{
+		ID:            386,
+		DisplayName:   "Book and Quill",
+		Name:          "writable_book",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	WrittenBook = Item{
+		ID:            387,
+		DisplayName:   "Written Book",
+		Name:          "written_book",
+		StackSize:     16,
+		MaxDurability: 0,
+	}
+	Emerald = Item{
+		ID:            388,
+		DisplayName:   "Emerald",
+		Name:          "emerald",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	ItemFrame = Item{
+		ID:            389,
+		DisplayName:   "Item Frame",
+		Name:          "item_frame",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	FlowerPot = Item{
+		ID:            390,
+		DisplayName:   "Flower Pot",
+		Name:          "flower_pot",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Carrot = Item{
+		ID:            391,
+		DisplayName:   "Carrot",
+		Name:          "carrot",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Potato = Item{
+		ID:            392,
+		DisplayName:   "Potato",
+		Name:          "potato",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	BakedPotato = Item{
+		ID:            393,
+		DisplayName:   "Baked Potato",
+		Name:          "baked_potato",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	PoisonousPotato = Item{
+		ID:            394,
+		DisplayName:   "Poisonous Potato",
+		Name:          "poisonous_potato",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Map = Item{
+		ID:            395,
+		DisplayName:   "Empty Map",
+		Name:          "map",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	GoldenCarrot = Item{
+		ID:            396,
+		DisplayName:   "Golden Carrot",
+		Name:          "golden_carrot",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Skull = Item{
+		ID:            397,
+		DisplayName:   "Skull",
+		Name:          "skull",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	CarrotOnAStick = Item{
+		ID:            398,
+		DisplayName:   "Carrot on a Stick",
+		Name:          "carrot_on_a_stick",
+		StackSize:     1,
+		MaxDurability: 25,
+	}
+	NetherStar = Item{
+		ID:            399,
+		DisplayName:   "Nether Star",
+		Name:          "nether_star",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	PumpkinPie = Item{
+		ID:            400,
+		DisplayName:   "Pumpkin Pie",
+		Name:          "pumpkin_pie",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Fireworks = Item{
+		ID:            401,
+		DisplayName:   "Firework Rocket",
+		Name:          "fireworks",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	FireworkCharge = Item{
+		ID:            402,
+		DisplayName:   "Firework Star",
+		Name:          "firework_charge",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	EnchantedBook = Item{
+		ID:            403,
+		DisplayName:   "Enchanted Book",
+		Name:          "enchanted_book",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	Comparator = Item{
+		ID:            404,
+		DisplayName:   "Redstone Comparator",
+		Name:          "comparator",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Netherbrick = Item{
+		ID:            405,
+		DisplayName:   "Nether Brick",
+		Name:          "netherbrick",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Quartz = Item{
+		ID:            406,
+		DisplayName:   "Nether Quartz",
+		Name:          "quartz",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	TntMinecart = Item{
+		ID:            407,
+		DisplayName:   "Minecart with TNT",
+		Name:          "tnt_minecart",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	HopperMinecart = Item{
+		ID:            408,
+		DisplayName:   "Minecart with Hopper",
+		Name:          "hopper_minecart",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	PrismarineShard = Item{
+		ID:            409,
+		DisplayName:   "Prismarine Shard",
+		Name:          "prismarine_shard",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	PrismarineCrystals = Item{
+		ID:            410,
+		DisplayName:   "Prismarine Crystals",
+		Name:          "prismarine_crystals",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Rabbit = Item{
+		ID:            411,
+		DisplayName:   "Raw Rabbit",
+		Name:          "rabbit",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	CookedRabbit = Item{
+		ID:            412,
+		DisplayName:   "Cooked Rabbit",
+		Name:          "cooked_rabbit",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	RabbitStew = Item{
+		ID:            413,
+		DisplayName:   "Rabbit Stew",
+		Name:          "rabbit_stew",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	RabbitFoot = Item{
+		ID:            414,
+		DisplayName:   "Rabbit's Foot",
+		Name:          "rabbit_foot",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	RabbitHide = Item{
+		ID:            415,
+		DisplayName:   "Rabbit Hide",
+		Name:          "rabbit_hide",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	ArmorStand = Item{
+		ID:            416,
+		DisplayName:   "Armor Stand",
+		Name:          "armor_stand",
+		StackSize:     16,
+		MaxDurability: 0,
+	}
+	IronHorseArmor = Item{
+		ID:            417,
+		DisplayName:   "Iron Horse Armor",
+		Name:          "iron_horse_armor",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	GoldenHorseArmor = Item{
+		ID:            418,
+		DisplayName:   "Gold Horse Armor",
+		Name:          "golden_horse_armor",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	DiamondHorseArmor = Item{
+		ID:            419,
+		DisplayName:   "Diamond Horse Armor",
+		Name:          "diamond_horse_armor",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	Lead = Item{
+		ID:            420,
+		DisplayName:   "Lead",
+		Name:          "lead",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	NameTag = Item{
+		ID:            421,
+		DisplayName:   "Name Tag",
+		Name:          "name_tag",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	CommandBlockMinecart = Item{
+		ID:            422,
+		DisplayName:   "Minecart with Command Block",
+		Name:          "command_block_minecart",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	Mutton = Item{
+		ID:            423,
+		DisplayName:   "Raw Mutton",
+		Name:          "mutton",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	CookedMutton = Item{
+		ID:            424,
+		DisplayName:   "Cooked Mutton",
+		Name:          "cooked_mutton",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Banner = Item{
+		ID:            425,
+		DisplayName:   "Banner",
+		Name:          "banner",
+		StackSize:     16,
+		MaxDurability: 0,
+	}
+	SpruceDoor = Item{
+		ID:            427,
+		DisplayName:   "Spruce Door",
+		Name:          "spruce_door",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	BirchDoor = Item{
+		ID:            428,
+		DisplayName:   "Birch Door",
+		Name:          "birch_door",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	JungleDoor = Item{
+		ID:            429,
+		DisplayName:   "Jungle Door",
+		Name:          "jungle_door",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	AcaciaDoor = Item{
+		ID:            430,
+		DisplayName:   "Acacia Door",
+		Name:          "acacia_door",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	DarkOakDoor = Item{
+		ID:            431,
+		DisplayName:   "Dark Oak Door",
+		Name:          "dark_oak_door",
+		StackSize:     64,
+		MaxDurability: 0,
+	}
+	Record13 = Item{
+		ID:            2256,
+		DisplayName:   "13 Disc",
+		Name:          "record_13",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	RecordCat = Item{
+		ID:            2257,
+		DisplayName:   "Cat Disc",
+		Name:          "record_cat",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	RecordBlocks = Item{
+		ID:            2258,
+		DisplayName:   "Blocks Disc",
+		Name:          "record_blocks",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	RecordChirp = Item{
+		ID:            2259,
+		DisplayName:   "Chirp Disc",
+		Name:          "record_chirp",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	RecordFar = Item{
+		ID:            2260,
+		DisplayName:   "Far Disc",
+		Name:          "record_far",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	RecordMall = Item{
+		ID:            2261,
+		DisplayName:   "Mall Disc",
+		Name:          "record_mall",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	RecordMellohi = Item{
+		ID:            2262,
+		DisplayName:   "Mellohi Disc",
+		Name:          "record_mellohi",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	RecordStal = Item{
+		ID:            2263,
+		DisplayName:   "Stal Disc",
+		Name:          "record_stal",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	RecordStrad = Item{
+		ID:            2264,
+		DisplayName:   "Strad Disc",
+		Name:          "record_strad",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	RecordWard = Item{
+		ID:            2265,
+		DisplayName:   "Ward Disc",
+		Name:          "record_ward",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	Record11 = Item{
+		ID:            2266,
+		DisplayName:   "11 Disc",
+		Name:          "record_11",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+	RecordWait = Item{
+		ID:            2267,
+		DisplayName:   "Wait Disc",
+		Name:          "record_wait",
+		StackSize:     1,
+		MaxDurability: 0,
+	}
+)
+
+// ByID is an index of minecraft items by their ID.
+var ByID = map[ID]*Item{
+	256:  &IronShovel,
+	257:  &IronPickaxe,
+	258:  &IronAxe,
+	259:  &FlintAndSteel,
+	260:  &Apple,
+	261:  &Bow,
+	262:  &Arrow,
+	263:  &Coal,
+	264:  &Diamond,
+	265:  &IronIngot,
+	266:  &GoldIngot,
+	267:  &IronSword,
+	268:  &WoodenSword,
+	269:  &WoodenShovel,
+	270:  &WoodenPickaxe,
+	271:  &WoodenAxe,
+	272:  &StoneSword,
+	273:  &StoneShovel,
+	274:  &StonePickaxe,
+	275:  &StoneAxe,
+	276:  &DiamondSword,
+	277:  &DiamondShovel,
+	278:  &DiamondPickaxe,
+	279:  &DiamondAxe,
+	280:  &Stick,
+	281:  &Bowl,
+	282:  &MushroomStew,
+	283:  &GoldenSword,
+	284:  &GoldenShovel,
+	285:  &GoldenPickaxe,
+	286:  &GoldenAxe,
+	287:  &String,
+	288:  &Feather,
+	289:  &Gunpowder,
+	290:  &WoodenHoe,
+	291:  &StoneHoe,
+	292:  &IronHoe,
+	293:  &DiamondHoe,
+	294:  &GoldenHoe,
+	295:  &WheatSeeds,
+	296:  &Wheat,
+	297:  &Bread,
+	298:  &LeatherHelmet,
+	299:  &LeatherChestplate,
+	300:  &LeatherLeggings,
+	301:  &LeatherBoots,
+	302:  &ChainmailHelmet,
+	303:  &ChainmailChestplate,
+	304:  &ChainmailLeggings,
+	305:  &ChainmailBoots,
+	306:  &IronHelmet,
+	307:  &IronChestplate,
+	308:  &IronLeggings,
+	309:  &IronBoots,
+	310:  &DiamondHelmet,
+	311:  &DiamondChestplate,
+	312:  &DiamondLeggings,
+	313:  &DiamondBoots,
+	314:  &GoldenHelmet,
+	315:  &GoldenChestplate,
+	316:  &GoldenLeggings,
+	317:  &GoldenBoots,
+	318:  &Flint,
+	319:  &Porkchop,
+	320:  &CookedPorkchop,
+	321:  &Painting,
+	322:  &GoldenApple,
+	323:  &Sign,
+	324:  &WoodenDoor,
+	325:  &Bucket,
+	326:  &WaterBucket,
+	327:  &LavaBucket,
+	328:  &Minecart,
+	329:  &Saddle,
+	330:  &IronDoor,
+	331:  &Redstone,
+	332:  &Snowball,
+	333:  &Boat,
+	334:  &Leather,
+	335:  &MilkBucket,
+	336:  &Brick,
+	337:  &ClayBall,
+	338:  &Reeds,
+	339:  &Paper,
+	340:  &Book,
+	341:  &SlimeBall,
+	342:  &ChestMinecart,
+	343:  &FurnaceMinecart,
+	344:  &Egg,
+	345:  &Compass,
+	346:  &FishingRod,
+	347:  &Clock,
+	348:  &GlowstoneDust,
+	349:  &Fish,
+	350:  &CookedFish,
+	351:  &Dye,
+	352:  &Bone,
+	353:  &Sugar,
+	354:  &Cake,
+	355:  &Bed,
+	356:  &Repeater,
+	357:  &Cookie,
+	358:  &FilledMap,
+	359:  &Shears,
+	360:  &Melon,
+	361:  &PumpkinSeeds,
+	362:  &MelonSeeds,
+	363:  &Beef,
+	364:  &CookedBeef,
+	365:  &Chicken,
+	366:  &CookedChicken,
+	367:  &RottenFlesh,
+	368:  &EnderPearl,
+	369:  &BlazeRod,
+	370:  &GhastTear,
+	371:  &GoldNugget,
+	372:  &NetherWart,
+	373:  &Potion,
+	374:  &GlassBottle,
+	375:  &SpiderEye,
+	376:  &FermentedSpiderEye,
+	377:  &BlazePowder,
+	378:  &MagmaCream,
+	379:  &BrewingStand,
+	380:  &Cauldron,
+	381:  &EnderEye,
+	382:  &SpeckledMelon,
+	383:  &SpawnEgg,
+	384:  &ExperienceBottle,
+	385:  &FireCharge,
+	386:  &WritableBook,
+	387:  &WrittenBook,
+	388:  &Emerald,
+	389:  &ItemFrame,
+	390:  &FlowerPot,
+	391:  &Carrot,
+	392:  &Potato,
+	393:  &BakedPotato,
+	394:  &PoisonousPotato,
+	395:  &Map,
+	396:  &GoldenCarrot,
+	397:  &Skull,
+	398:  &CarrotOnAStick,
+	399:  &NetherStar,
+	400:  &PumpkinPie,
+	401:  &Fireworks,
+	402:  &FireworkCharge,
+	403:  &EnchantedBook,
+	404:  &Comparator,
+	405:  &Netherbrick,
+	406:  &Quartz,
+	407:  &TntMinecart,
+	408:  &HopperMinecart,
+	409:  &PrismarineShard,
+	410:  &PrismarineCrystals,
+	411:  &Rabbit,
+	412:  &CookedRabbit,
+	413:  &RabbitStew,
+	414:  &RabbitFoot,
+	415:  &RabbitHide,
+	416:  &ArmorStand,
+	417:  &IronHorseArmor,
+	418:  &GoldenHorseArmor,
+	419:  &DiamondHorseArmor,
+	420:  &Lead,
+	421:  &NameTag,
+	422:  &CommandBlockMinecart,
+	423:  &Mutton,
+	424:  &CookedMutton,
+	425:  &Banner,
+	427:  &SpruceDoor,
+	428:  &BirchDoor,
+	429:  &JungleDoor,
+	430:  &AcaciaDoor,
+	431:  &DarkOakDoor,
+	2256: &Record13,
+	2257: &RecordCat,
+	2258: &RecordBlocks,
+	2259: &RecordChirp,
+	2260: &RecordFar,
+	2261: &RecordMall,
+	2262: &RecordMellohi,
+	2263: &RecordStal,
+	2264: &RecordStrad,
+	2265: &RecordWard,
+	2266: &Record11,
+	2267: &RecordWait,
+}
+
+// ByName is an index of minecraft items by their name.
+var ByName = map[string]*Item{
+	"iron_shovel":            &IronShovel,
+	"iron_pickaxe":           &IronPickaxe,
+	"iron_axe":               &IronAxe,
+	"flint_and_steel":        &FlintAndSteel,
+	"apple":                  &Apple,
+	"bow":                    &Bow,
+	"arrow":                  &Arrow,
+	"coal":                   &Coal,
+	"diamond":                &Diamond,
+	"iron_ingot":             &IronIngot,
+	"gold_ingot":             &GoldIngot,
+	"iron_sword":             &IronSword,
+	"wooden_sword":           &WoodenSword,
+	"wooden_shovel":          &WoodenShovel,
+	"wooden_pickaxe":         &WoodenPickaxe,
+	"wooden_axe":             &WoodenAxe,
+	"stone_sword":            &StoneSword,
+	"stone_shovel":           &StoneShovel,
+	"stone_pickaxe":          &StonePickaxe,
+	"stone_axe":              &StoneAxe,
+	"diamond_sword":          &DiamondSword,
+	"diamond_shovel":         &DiamondShovel,
+	"diamond_pickaxe":        &DiamondPickaxe,
+	"diamond_axe":            &DiamondAxe,
+	"stick":                  &Stick,
+	"bowl":                   &Bowl,
+	"mushroom_stew":          &MushroomStew,
+	"golden_sword":           &GoldenSword,
+	"golden_shovel":          &GoldenShovel,
+	"golden_pickaxe":         &GoldenPickaxe,
+	"golden_axe":             &GoldenAxe,
+	"string":                 &String,
+	"feather":                &Feather,
+	"gunpowder":              &Gunpowder,
+	"wooden_hoe":             &WoodenHoe,
+	"stone_hoe":              &StoneHoe,
+	"iron_hoe":               &IronHoe,
+	"diamond_hoe":            &DiamondHoe,
+	"golden_hoe":             &GoldenHoe,
+	"wheat_seeds":            &WheatSeeds,
+	"wheat":                  &Wheat,
+	"bread":                  &Bread,
+	"leather_helmet":         &LeatherHelmet,
+	"leather_chestplate":     &LeatherChestplate,
+	"leather_leggings":       &LeatherLeggings,
+	"leather_boots":          &LeatherBoots,
+	"chainmail_helmet":       &ChainmailHelmet,
+	"chainmail_chestplate":   &ChainmailChestplate,
+	"chainmail_leggings":     &ChainmailLeggings,
+	"chainmail_boots":        &ChainmailBoots,
+	"iron_helmet":            &IronHelmet,
+	"iron_chestplate":        &IronChestplate,
+	"iron_leggings":          &IronLeggings,
+	"iron_boots":             &IronBoots,
+	"diamond_helmet":         &DiamondHelmet,
+	"diamond_chestplate":     &DiamondChestplate,
+	"diamond_leggings":       &DiamondLeggings,
+	"diamond_boots":          &DiamondBoots,
+	"golden_helmet":          &GoldenHelmet,
+	"golden_chestplate":      &GoldenChestplate,
+	"golden_leggings":        &GoldenLeggings,
+	"golden_boots":           &GoldenBoots,
+	"flint":                  &Flint,
+	"porkchop":               &Porkchop,
+	"cooked_porkchop":        &CookedPorkchop,
+	"painting":               &Painting,
+	"golden_apple":           &GoldenApple,
+	"sign":                   &Sign,
+	"wooden_door":            &WoodenDoor,
+	"bucket":                 &Bucket,
+	"water_bucket":           &WaterBucket,
+	"lava_bucket":            &LavaBucket,
+	"minecart":               &Minecart,
+	"saddle":                 &Saddle,
+	"iron_door":              &IronDoor,
+	"redstone":               &Redstone,
+	"snowball":               &Snowball,
+	"boat":                   &Boat,
+	"leather":                &Leather,
+	"milk_bucket":            &MilkBucket,
+	"brick":                  &Brick,
+	"clay_ball":              &ClayBall,
+	"reeds":                  &Reeds,
+	"paper":                  &Paper,
+	"book":                   &Book,
+	"slime_ball":             &SlimeBall,
+	"chest_minecart":         &ChestMinecart,
+	"furnace_minecart":       &FurnaceMinecart,
+	"egg":                    &Egg,
+	"compass":                &Compass,
+	"fishing_rod":            &FishingRod,
+	"clock":                  &Clock,
+	"glowstone_dust":         &GlowstoneDust,
+	"fish":                   &Fish,
+	"cooked_fish":            &CookedFish,
+	"dye":                    &Dye,
+	"bone":                   &Bone,
+	"sugar":                  &Sugar,
+	"cake":                   &Cake,
+	"bed":                    &Bed,
+	"repeater":               &Repeater,
+	"cookie":                 &Cookie,
+	"filled_map":             &FilledMap,
+	"shears":                 &Shears,
+	"melon":                  &Melon,
+	"pumpkin_seeds":          &PumpkinSeeds,
+	"melon_seeds":            &MelonSeeds,
+	"beef":                   &Beef,
+	"cooked_beef":            &CookedBeef,
+	"chicken":                &Chicken,
+	"cooked_chicken":         &CookedChicken,
+	"rotten_flesh":           &RottenFlesh,
+	"ender_pearl":            &EnderPearl,
+	"blaze_rod":              &BlazeRod,
+	"ghast_tear":             &GhastTear,
+	"gold_nugget":            &GoldNugget,
+	"nether_wart":            &NetherWart,
+	"potion":                 &Potion,
+	"glass_bottle":           &GlassBottle,
+	"spider_eye":             &SpiderEye,
+	"fermented_spider_eye":   &FermentedSpiderEye,
+	"blaze_powder":           &BlazePowder,
+	"magma_cream":            &MagmaCream,
+	"brewing_stand":          &BrewingStand,
+	"cauldron":               &Cauldron,
+	"ender_eye":              &EnderEye,
+	"speckled_melon":         &SpeckledMelon,
+	"spawn_egg":              &SpawnEgg,
+	"experience_bottle":      &ExperienceBottle,
+	"fire_charge":            &FireCharge,
+	"writable_book":          &WritableBook,
+	"written_book":           &WrittenBook,
+	"emerald":                &Emerald,
+	"item_frame":             &ItemFrame,
+	"flower_pot":             &FlowerPot,
+	"carrot":                 &Carrot,
+	"potato":                 &Potato,
+	"baked_potato":           &BakedPotato,
+	"poisonous_potato":       &PoisonousPotato,
+	"map":                    &Map,
+	"golden_carrot":          &GoldenCarrot,
+	"skull":                  &Skull,
+	"carrot_on_a_stick":      &CarrotOnAStick,
+	"nether_star":            &NetherStar,
+	"pumpkin_pie":            &PumpkinPie,
+	"fireworks":              &Fireworks,
+	"firework_charge":        &FireworkCharge,
+	"enchanted_book":         &EnchantedBook,
+	"comparator":             &Comparator,
+	"netherbrick":            &Netherbrick,
+	"quartz":                 &Quartz,
+	"tnt_minecart":           &TntMinecart,
+	"hopper_minecart":        &HopperMinecart,
+	"prismarine_shard":       &PrismarineShard,
+	"prismarine_crystals":    &PrismarineCrystals,
+	"rabbit":                 &Rabbit,
+	"cooked_rabbit":          &CookedRabbit,
+	"rabbit_stew":            &RabbitStew,
+	"rabbit_foot":            &RabbitFoot,
+	"rabbit_hide":            &RabbitHide,
+	"armor_stand":            &ArmorStand,
+	"iron_horse_armor":       &IronHorseArmor,
+	"golden_horse_armor":     &GoldenHorseArmor,
+	"diamond_horse_armor":    &DiamondHorseArmor,
+	"lead":                   &Lead,
+	"name_tag":               &NameTag,
+	"command_block_minecart": &CommandBlockMinecart,
+	"mutton":                 &Mutton,
+	"cooked_mutton":          &CookedMutton,
+	"banner":                 &Banner,
+	"spruce_door":            &SpruceDoor,
+	"birch_door":             &BirchDoor,
+	"jungle_door":            &JungleDoor,
+	"acacia_door":            &AcaciaDoor,
+	"dark_oak_door":          &DarkOakDoor,
+	"record_13":              &Record13,
+	"record_cat":             &RecordCat,
+	"record_blocks":          &RecordBlocks,
+	"record_chirp":           &RecordChirp,
+	"record_far":             &RecordFar,
+	"record_mall":            &RecordMall,
+	"record_mellohi":         &RecordMellohi,
+	"record_stal":            &RecordStal,
+	"record_strad":           &RecordStrad,
+	"record_ward":            &RecordWard,
+	"record_11":              &Record11,
+	"record_wait":            &RecordWait,
+}
@@ -1,54 +1,76 @@
-//From https://play.golang.org/p/LTbId4b6M2
-
+// Package CFB8 implements the 8-bit cipher feedback mode Minecraft encrypts connections with.
 package CFB8
 
 import "crypto/cipher"
 
+// ringBlocks is the size of the ring in blocks, the register is moved back to its start once in that many bytes
+const ringBlocks = 64
+
+// CFB8 encrypts a byte per block encryption. The shift register is a window sliding over a ring:
+// every byte of ciphertext is appended after the window instead of shifting the register by a byte
 type CFB8 struct {
 	c         cipher.Block
 	blockSize int
-	iv, tmp   []byte
+	ring      []byte
+	offset    int
+	out       []byte
 	de        bool
 }
 
-func NewCFB8Decrypt(c cipher.Block, iv []byte) *CFB8 {
-	cp := make([]byte, len(iv))
-	copy(cp, iv)
+func newCFB8(c cipher.Block, iv []byte, decrypt bool) *CFB8 {
+	blockSize := c.BlockSize()
+	if len(iv) != blockSize {
+		panic("CFB8: IV length must equal block size")
+	}
+
+	ring := make([]byte, blockSize*ringBlocks)
+	copy(ring, iv)
 	return &CFB8{
 		c:         c,
-		blockSize: c.BlockSize(),
-		iv:        cp,
-		tmp:       make([]byte, c.BlockSize()),
-		de:        true,
+		blockSize: blockSize,
+		ring:      ring,
+		out:       make([]byte, blockSize),
+		de:        decrypt,
 	}
 }
 
+func NewCFB8Decrypt(c cipher.Block, iv []byte) *CFB8 {
+	return newCFB8(c, iv, true)
+}
+
 func NewCFB8Encrypt(c cipher.Block, iv []byte) *CFB8 {
-	cp := make([]byte, len(iv))
-	copy(cp, iv)
-	return &CFB8{
-		c:         c,
-		blockSize: c.BlockSize(),
-		iv:        cp,
-		tmp:       make([]byte, c.BlockSize()),
-		de:        false,
-	}
+	return newCFB8(c, iv, false)
 }
 
+// XORKeyStream implements cipher.Stream, dst and src may overlap entirely
 func (cf *CFB8) XORKeyStream(dst, src []byte) {
-	for i := 0; i < len(src); i++ {
-		val := src[i]
-		copy(cf.tmp, cf.iv)
-		cf.c.Encrypt(cf.iv, cf.iv)
-		val = val ^ cf.iv[0]
+	if len(dst) < len(src) {
+		panic("CFB8: output smaller than input")
+	}
 
-		copy(cf.iv, cf.tmp[1:])
+	block, blockSize, ring, out, offset := cf.c, cf.blockSize, cf.ring, cf.out, cf.offset
+	end := len(ring) - blockSize
+	for i, val := range src {
+		block.Encrypt(out, ring[offset:offset+blockSize])
+		result := val ^ out[0]
+
+		// The register is fed with the ciphertext, which is the input when decrypting
+		feedback := result
 		if cf.de {
-			cf.iv[15] = src[i]
+			feedback = val
+		}
+
+		if offset == end {
+			copy(ring, ring[offset+1:])
+			offset = 0
+			ring[blockSize-1] = feedback
 		} else {
-			cf.iv[15] = val
+			ring[offset+blockSize] = feedback
+			offset++
 		}
 
-		dst[i] = val
+		dst[i] = result
 	}
+
+	cf.offset = offset
 }
@@ -0,0 +1,167 @@
+package CFB8
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// legacyCFB8 is the implementation CFB8 replaced, it shifts the register by a byte for every byte
+type legacyCFB8 struct {
+	c       cipher.Block
+	iv, tmp []byte
+	de      bool
+}
+
+func newLegacyCFB8(c cipher.Block, iv []byte, decrypt bool) *legacyCFB8 {
+	cp := make([]byte, len(iv))
+	copy(cp, iv)
+	return &legacyCFB8{c: c, iv: cp, tmp: make([]byte, c.BlockSize()), de: decrypt}
+}
+
+func (cf *legacyCFB8) XORKeyStream(dst, src []byte) {
+	for i := 0; i < len(src); i++ {
+		val := src[i]
+		copy(cf.tmp, cf.iv)
+		cf.c.Encrypt(cf.iv, cf.iv)
+		val = val ^ cf.iv[0]
+
+		copy(cf.iv, cf.tmp[1:])
+		if cf.de {
+			cf.iv[15] = src[i]
+		} else {
+			cf.iv[15] = val
+		}
+
+		dst[i] = val
+	}
+}
+
+func decodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+// Vectors of NIST SP 800-38A, F.3.7 to F.3.12
+var vectors = []struct {
+	key, iv, plaintext, ciphertext string
+}{
+	{
+		key:        "2b7e151628aed2a6abf7158809cf4f3c",
+		iv:         "000102030405060708090a0b0c0d0e0f",
+		plaintext:  "6bc1bee22e409f96e93d7e117393172aae2d",
+		ciphertext: "3b79424c9c0dd436bace9e0ed4586a4f32b9",
+	},
+	{
+		key:        "8e73b0f7da0e6452c810f32b809079e562f8ead2522c6b7b",
+		iv:         "000102030405060708090a0b0c0d0e0f",
+		plaintext:  "6bc1bee22e409f96e93d7e117393172aae2d",
+		ciphertext: "cda2521ef0a905ca44cd057cbf0d47a0678a",
+	},
+	{
+		key:        "603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4",
+		iv:         "000102030405060708090a0b0c0d0e0f",
+		plaintext:  "6bc1bee22e409f96e93d7e117393172aae2d",
+		ciphertext: "dc1f1a8520a64db55fcc8ac554844e889700",
+	},
+}
+
+func TestCFB8_Vectors(t *testing.T) {
+	for _, vector := range vectors {
+		block, err := aes.NewCipher(decodeHex(vector.key))
+		assert.NoError(t, err)
+
+		plaintext, ciphertext := decodeHex(vector.plaintext), decodeHex(vector.ciphertext)
+		encrypted := make([]byte, len(plaintext))
+		NewCFB8Encrypt(block, decodeHex(vector.iv)).XORKeyStream(encrypted, plaintext)
+		assert.Equal(t, ciphertext, encrypted, vector.key)
+
+		// Decryption is done in place, as cipher.StreamReader does
+		decrypted := append([]byte{}, ciphertext...)
+		NewCFB8Decrypt(block, decodeHex(vector.iv)).XORKeyStream(decrypted, decrypted)
+		assert.Equal(t, plaintext, decrypted, vector.key)
+	}
+}
+
+// TestCFB8_Legacy checks that streams split into arbitrary chunks match the former implementation,
+// well past the point the register wraps around the ring
+func TestCFB8_Legacy(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	key := make([]byte, 16)
+	random.Read(key)
+	block, _ := aes.NewCipher(key)
+
+	plaintext := make([]byte, 10*ringBlocks*aes.BlockSize+7)
+	random.Read(plaintext)
+
+	expected := make([]byte, len(plaintext))
+	newLegacyCFB8(block, key, false).XORKeyStream(expected, plaintext)
+
+	encrypter, decrypter := NewCFB8Encrypt(block, key), NewCFB8Decrypt(block, key)
+	encrypted, decrypted := make([]byte, len(plaintext)), make([]byte, len(plaintext))
+	for offset := 0; offset < len(plaintext); {
+		end := offset + random.Intn(3*aes.BlockSize)
+		if end > len(plaintext) {
+			end = len(plaintext)
+		}
+
+		encrypter.XORKeyStream(encrypted[offset:end], plaintext[offset:end])
+		decrypter.XORKeyStream(decrypted[offset:end], encrypted[offset:end])
+		offset = end
+	}
+
+	assert.True(t, bytes.Equal(expected, encrypted))
+	assert.True(t, bytes.Equal(plaintext, decrypted))
+}
+
+func TestCFB8_Stream(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	block, _ := aes.NewCipher(key)
+
+	var buf bytes.Buffer
+	writer := cipher.StreamWriter{S: NewCFB8Encrypt(block, key), W: &buf}
+	_, err := writer.Write([]byte("kogtevran"))
+	assert.NoError(t, err)
+
+	reader := cipher.StreamReader{S: NewCFB8Decrypt(block, key), R: &buf}
+	decrypted := make([]byte, 9)
+	_, err = reader.Read(decrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "kogtevran", string(decrypted))
+
+	assert.Panics(t, func() { NewCFB8Encrypt(block, key[:8]) })
+	assert.Panics(t, func() { NewCFB8Encrypt(block, key).XORKeyStream(make([]byte, 1), make([]byte, 2)) })
+}
+
+func BenchmarkCFB8(b *testing.B) {
+	key := []byte("0123456789abcdef")
+	block, _ := aes.NewCipher(key)
+
+	for _, benchmark := range []struct {
+		name   string
+		stream cipher.Stream
+	}{
+		{"legacy", newLegacyCFB8(block, key, false)},
+		{"ring", NewCFB8Encrypt(block, key)},
+	} {
+		for _, size := range []int{64, 16384} {
+			b.Run(benchmark.name+"/"+map[int]string{64: "packet", 16384: "chunk"}[size], func(b *testing.B) {
+				buf := make([]byte, size)
+				b.SetBytes(int64(size))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					benchmark.stream.XORKeyStream(buf, buf)
+				}
+			})
+		}
+	}
+}
@@ -2,16 +2,21 @@
 package net
 
 import (
+	"bufio"
 	"bytes"
 	"crypto/cipher"
 	"io"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
 )
 
+// BufferSize is the size of the read and write buffers of a Conn
+const BufferSize = 32 * 1024
+
 // A Listener is a minecraft Listener
 type Listener struct{ net.Listener }
 
@@ -25,19 +30,9 @@ func ListenMC(addr string) (*Listener, error) {
 }
 
 //Accept a minecraft Conn
-func (l Listener) Accept() (Conn, error) {
+func (l Listener) Accept() (*Conn, error) {
 	conn, err := l.Listener.Accept()
-	return Conn{
-		Socket:    conn,
-		Reader:    conn,
-		Writer:    conn,
-		threshold: -1,
-		bufPool: &sync.Pool{
-			New: func() interface{} {
-				return new(bytes.Buffer)
-			},
-		},
-	}, err
+	return WrapConn(conn), err
 }
 
 //Conn is a minecraft Connection
@@ -46,50 +41,42 @@ type Conn struct {
 	io.Reader
 	io.Writer
 
-	threshold int
-	bufPool   *sync.Pool
+	// The buffers are right above the socket, so that the cipher works on the bytes that are sent
+	// and nothing read ahead is lost when encryption is enabled
+	readBuffer  *bufio.Reader
+	writeBuffer *bufio.Writer
+
+	threshold  int
+	compressor *pk.Compressor
+	bufPool    *sync.Pool
+	// limits holds the *pk.Limits read packets are checked against, it is replaced as the state changes
+	limits atomic.Value
 }
 
 // DialMC create a Minecraft connection
 func DialMC(addr string, dial func(string, string) (net.Conn, error)) (*Conn, error) {
 	conn, err := dial("tcp", addr)
-	return &Conn{
-		Socket:    conn,
-		Reader:    conn,
-		Writer:    conn,
-		threshold: -1,
-		bufPool: &sync.Pool{
-			New: func() interface{} {
-				return new(bytes.Buffer)
-			},
-		},
-	}, err
+	return WrapConn(conn), err
 }
 
 // DialMCTimeout acts like DialMC but takes a timeout.
 func DialMCTimeout(addr string, timeout time.Duration) (*Conn, error) {
 	conn, err := net.DialTimeout("tcp", addr, timeout)
-	return &Conn{
-		Socket:    conn,
-		Reader:    conn,
-		Writer:    conn,
-		threshold: -1,
-		bufPool: &sync.Pool{
-			New: func() interface{} {
-				return new(bytes.Buffer)
-			},
-		},
-	}, err
+	return WrapConn(conn), err
 }
 
 // WrapConn warp an net.Conn to MC-Conn
 // Helps you modify the connection process (eg. using DialContext).
 func WrapConn(conn net.Conn) *Conn {
+	readBuffer, writeBuffer := bufio.NewReaderSize(conn, BufferSize), bufio.NewWriterSize(conn, BufferSize)
 	return &Conn{
-		Socket:    conn,
-		Reader:    conn,
-		Writer:    conn,
-		threshold: -1,
+		Socket:      conn,
+		Reader:      readBuffer,
+		Writer:      writeBuffer,
+		readBuffer:  readBuffer,
+		writeBuffer: writeBuffer,
+		threshold:   -1,
+		compressor:  pk.DefaultCompressor,
 		bufPool: &sync.Pool{
 			New: func() interface{} {
 				return new(bytes.Buffer)
@@ -103,12 +90,25 @@ func (c *Conn) Close() error { return c.Socket.Close() }
 
 // ReadPacket read a Packet from Conn.
 func (c *Conn) ReadPacket(p *pk.Packet) error {
-	return p.UnPack(c.Reader, c.threshold, c.bufPool)
+	p.Limits, _ = c.limits.Load().(*pk.Limits)
+	return p.UnPackWith(c.Reader, c.threshold, c.bufPool, c.compressor)
+}
+
+// Buffered returns the number of bytes that have been received but not read yet,
+// when it is zero the next ReadPacket is going to wait for the socket.
+func (c *Conn) Buffered() int {
+	return c.readBuffer.Buffered()
 }
 
 // WritePacket write a Packet to Conn.
+// The packet is buffered, it is sent once the buffer is full or Flush is called.
 func (c *Conn) WritePacket(p pk.Packet) error {
-	return p.Pack(c.Writer, c.threshold, c.bufPool)
+	return p.PackWith(c.Writer, c.threshold, c.bufPool, c.compressor)
+}
+
+// Flush sends the buffered packets.
+func (c *Conn) Flush() error {
+	return c.writeBuffer.Flush()
 }
 
 // SetCipher load the decode/encode stream to this Conn
@@ -116,14 +116,26 @@ func (c *Conn) SetCipher(ecoStream, decoStream cipher.Stream) {
 	//加密连接
 	c.Reader = cipher.StreamReader{ //Set receiver for AES
 		S: decoStream,
-		R: c.Socket,
+		R: c.readBuffer,
 	}
 	c.Writer = cipher.StreamWriter{
 		S: ecoStream,
-		W: c.Socket,
+		W: c.writeBuffer,
 	}
 }
 
+// SetCompressor set the compressor packets of Conn are compressed and decompressed with,
+// pk.DefaultCompressor is used until it is set. Connections can share a compressor.
+func (c *Conn) SetCompressor(compressor *pk.Compressor) {
+	c.compressor = compressor
+}
+
+// SetLimits set the limits packets read from Conn are unpacked and scanned with,
+// pk.DefaultLimits are used until they are set.
+func (c *Conn) SetLimits(limits *pk.Limits) {
+	c.limits.Store(limits)
+}
+
 // SetThreshold set threshold to Conn.
 // The data packet with length equal or longer then threshold
 // will be compressed when sending.
@@ -0,0 +1,225 @@
+package net
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/destructiqn/kogtevran/minecraft/net/CFB8"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingConn counts the calls that would be syscalls on a socket, reads are served from the stream
+type countingConn struct {
+	net.Conn
+	stream        io.Reader
+	reads, writes int
+	written       bytes.Buffer
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	c.reads++
+	return c.stream.Read(b)
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	c.writes++
+	return c.written.Write(b)
+}
+
+// unbuffered returns the connection as it was before the buffers, every field is written to the socket
+func unbuffered(conn net.Conn) *Conn {
+	c := WrapConn(conn)
+	c.Reader, c.Writer = conn, conn
+	return c
+}
+
+func newStreams(key []byte) (encrypt, decrypt cipher.Stream) {
+	block, _ := aes.NewCipher(key)
+	return CFB8.NewCFB8Encrypt(block, key), CFB8.NewCFB8Decrypt(block, key)
+}
+
+// movement is the packet sent the most, a relative entity move
+var movement = pk.Marshal(0x15, pk.VarInt(1), pk.Byte(1), pk.Byte(2), pk.Byte(3), pk.Boolean(true))
+
+func TestConn_Flush(t *testing.T) {
+	socket := &countingConn{}
+	conn := WrapConn(socket)
+
+	for i := 0; i < 16; i++ {
+		assert.NoError(t, conn.WritePacket(movement))
+	}
+	assert.Zero(t, socket.writes)
+
+	assert.NoError(t, conn.Flush())
+	assert.Equal(t, 1, socket.writes)
+
+	decoded := WrapConn(&countingConn{stream: &socket.written})
+	for i := 0; i < 16; i++ {
+		var packet pk.Packet
+		assert.NoError(t, decoded.ReadPacket(&packet))
+		assert.Equal(t, movement.Data, packet.Data)
+		assert.Equal(t, i < 15, decoded.Buffered() > 0)
+	}
+}
+
+func TestConn_SetCipher(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	encrypt, decrypt := newStreams(key)
+
+	// The packet before the cipher is plain, the ones after it are encrypted, all of them are read at once
+	var stream bytes.Buffer
+	sender := WrapConn(&countingConn{})
+	_ = movement.Pack(&stream, -1, sender.bufPool)
+	writer := cipher.StreamWriter{S: encrypt, W: &stream}
+	for i := 0; i < 2; i++ {
+		_ = movement.Pack(writer, -1, sender.bufPool)
+	}
+
+	socket := &countingConn{stream: &stream}
+	conn := WrapConn(socket)
+
+	var packet pk.Packet
+	assert.NoError(t, conn.ReadPacket(&packet))
+	assert.True(t, conn.Buffered() > 0)
+
+	conn.SetCipher(nil, decrypt)
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, conn.ReadPacket(&packet))
+		assert.Equal(t, movement, packet)
+	}
+	assert.Equal(t, 1, socket.reads)
+
+	// Packets buffered before the cipher is set are sent as they are
+	encrypt, decrypt = newStreams(key)
+	socket = &countingConn{}
+	conn = WrapConn(socket)
+	assert.NoError(t, conn.WritePacket(movement))
+	conn.SetCipher(encrypt, nil)
+	assert.NoError(t, conn.WritePacket(movement))
+	assert.NoError(t, conn.Flush())
+
+	received := WrapConn(&countingConn{stream: &socket.written})
+	assert.NoError(t, received.ReadPacket(&packet))
+	received.SetCipher(nil, decrypt)
+	assert.NoError(t, received.ReadPacket(&packet))
+	assert.Equal(t, movement, packet)
+}
+
+// BenchmarkConn_WritePacket writes batches of packets as the pipe does, flushing after each of them
+func BenchmarkConn_WritePacket(b *testing.B) {
+	for name, wrap := range map[string]func(net.Conn) *Conn{"unbuffered": unbuffered, "buffered": WrapConn} {
+		b.Run(name, func(b *testing.B) {
+			socket := &countingConn{}
+			conn := wrap(socket)
+			conn.SetThreshold(256)
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = conn.WritePacket(movement)
+				if i%16 == 15 {
+					_ = conn.Flush()
+				}
+
+				socket.written.Reset()
+			}
+
+			b.ReportMetric(float64(socket.writes)/float64(b.N), "syscalls/packet")
+		})
+	}
+}
+
+func BenchmarkConn_ReadPacket(b *testing.B) {
+	for name, wrap := range map[string]func(net.Conn) *Conn{"unbuffered": unbuffered, "buffered": WrapConn} {
+		b.Run(name, func(b *testing.B) {
+			var stream bytes.Buffer
+			sender := WrapConn(&countingConn{})
+			for i := 0; i < b.N; i++ {
+				_ = movement.Pack(&stream, 256, sender.bufPool)
+			}
+
+			socket := &countingConn{stream: &stream}
+			conn := wrap(socket)
+			conn.SetThreshold(256)
+
+			b.ReportAllocs()
+			var packet pk.Packet
+			for i := 0; i < b.N; i++ {
+				_ = conn.ReadPacket(&packet)
+			}
+
+			b.ReportMetric(float64(socket.reads)/float64(b.N), "syscalls/packet")
+		})
+	}
+}
+
+// BenchmarkConn_Latency measures the time a batch of encrypted packets takes to reach the peer over loopback
+func BenchmarkConn_Latency(b *testing.B) {
+	const batch = 16
+
+	for name, wrap := range map[string]func(net.Conn) *Conn{"unbuffered": unbuffered, "buffered": WrapConn} {
+		b.Run(name, func(b *testing.B) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				b.Skip("loopback is not available:", err)
+			}
+			defer listener.Close()
+
+			key := []byte("0123456789abcdef")
+			received := make(chan struct{})
+			go func() {
+				socket, err := listener.Accept()
+				if err != nil {
+					return
+				}
+
+				conn := WrapConn(socket)
+				_, decrypt := newStreams(key)
+				conn.SetCipher(nil, decrypt)
+
+				var packet pk.Packet
+				for {
+					for i := 0; i < batch; i++ {
+						if conn.ReadPacket(&packet) != nil {
+							return
+						}
+					}
+
+					received <- struct{}{}
+				}
+			}()
+
+			socket, err := net.Dial("tcp", listener.Addr().String())
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer socket.Close()
+
+			conn := wrap(socket)
+			encrypt, _ := newStreams(key)
+			if name == "unbuffered" {
+				conn.Writer = cipher.StreamWriter{S: encrypt, W: socket}
+			} else {
+				conn.SetCipher(encrypt, nil)
+			}
+
+			b.ResetTimer()
+			start := time.Now()
+			for i := 0; i < b.N; i++ {
+				for j := 0; j < batch; j++ {
+					_ = conn.WritePacket(movement)
+				}
+
+				_ = conn.Flush()
+				<-received
+			}
+
+			b.ReportMetric(float64(time.Since(start).Nanoseconds())/float64(b.N*batch), "ns/packet")
+		})
+	}
+}
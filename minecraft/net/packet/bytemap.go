@@ -2,32 +2,65 @@ package packet
 
 import (
 	"bytes"
-	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
+	"sort"
 
 	"github.com/google/uuid"
 )
 
-func ReadMap(src []byte) (map[string]interface{}, error) {
+// ErrInvalidLength is returned when a length prefix is negative or longer than the data left
+var ErrInvalidLength = errors.New("invalid length")
+
+// readLength reads the length of an array, every element takes at least one byte
+func readLength(reader *bytes.Reader, limits *Limits) (int, error) {
+	var l VarInt
+	_, err := l.ReadFrom(reader)
+	if err != nil {
+		return 0, err
+	}
+
+	if l < 0 || int(l) > reader.Len() {
+		return 0, ErrInvalidLength
+	}
+
+	if err := checkLimit("byte map array length", int(l), limits.MaxArrayLength); err != nil {
+		return 0, err
+	}
+
+	return int(l), nil
+}
+
+// ReadMap decodes a ByteMap. Values are decoded into types that are encoded back the same way:
+// Int, Long, Byte, String, Short, Float, Double and Boolean are decoded into int32, int64, byte, string, int16,
+// float32, float64 and bool, variable-length integers into VarInt, VarLong and SignedVarInt,
+// nested ByteMaps into map[string]interface{}, and arrays into slices of the respective types.
+// The limits are those of the packet carrying the ByteMap
+func ReadMap(src []byte, limits *Limits) (map[string]interface{}, error) {
+	return readMap(src, limits, 1)
+}
+
+// readMap decodes a ByteMap nested at the depth
+func readMap(src []byte, limits *Limits, depth int) (map[string]interface{}, error) {
+	if err := checkLimit("byte map depth", depth, limits.MaxNBTDepth); err != nil {
+		return nil, err
+	}
+
 	m := make(map[string]interface{})
 	reader := bytes.NewReader(src)
-	for {
+	for reader.Len() > 0 {
 		var key String
 		_, err := key.ReadFrom(reader)
 		if err != nil {
-			if err == io.EOF || err == io.ErrUnexpectedEOF {
-				return m, nil
-			}
-
-			return nil, fmt.Errorf("reading key: %s", err)
+			return nil, fmt.Errorf("reading key: %w", err)
 		}
 
 		var vType Byte
 		_, err = vType.ReadFrom(reader)
 		if err != nil {
-			return nil, fmt.Errorf("reading type: %s", err)
+			return nil, fmt.Errorf("reading type: %w", err)
 		}
 
 		k := string(key)
@@ -36,7 +69,7 @@ func ReadMap(src []byte) (map[string]interface{}, error) {
 			var v Int
 			_, err := v.ReadFrom(reader)
 			if err != nil {
-				return nil, fmt.Errorf("reading int (1): %s", err)
+				return nil, fmt.Errorf("reading int (1): %w", err)
 			}
 
 			m[k] = int32(v)
@@ -44,7 +77,7 @@ func ReadMap(src []byte) (map[string]interface{}, error) {
 			var v Byte
 			_, err := v.ReadFrom(reader)
 			if err != nil {
-				return nil, fmt.Errorf("reading byte (2): %s", err)
+				return nil, fmt.Errorf("reading byte (2): %w", err)
 			}
 
 			m[k] = byte(v)
@@ -52,7 +85,7 @@ func ReadMap(src []byte) (map[string]interface{}, error) {
 			var v Long
 			_, err := v.ReadFrom(reader)
 			if err != nil {
-				return nil, fmt.Errorf("reading long (3): %s", err)
+				return nil, fmt.Errorf("reading long (3): %w", err)
 			}
 
 			m[k] = int64(v)
@@ -60,7 +93,7 @@ func ReadMap(src []byte) (map[string]interface{}, error) {
 			var v String
 			_, err := v.ReadFrom(reader)
 			if err != nil {
-				return nil, fmt.Errorf("reading string (4): %s", err)
+				return nil, fmt.Errorf("reading string (4): %w", err)
 			}
 
 			m[k] = string(v)
@@ -68,7 +101,7 @@ func ReadMap(src []byte) (map[string]interface{}, error) {
 			var v Short
 			_, err := v.ReadFrom(reader)
 			if err != nil {
-				return nil, fmt.Errorf("reading short (5): %s", err)
+				return nil, fmt.Errorf("reading short (5): %w", err)
 			}
 
 			m[k] = int16(v)
@@ -76,7 +109,7 @@ func ReadMap(src []byte) (map[string]interface{}, error) {
 			var v Float
 			_, err := v.ReadFrom(reader)
 			if err != nil {
-				return nil, fmt.Errorf("reading float (6): %s", err)
+				return nil, fmt.Errorf("reading float (6): %w", err)
 			}
 
 			m[k] = float32(v)
@@ -84,7 +117,7 @@ func ReadMap(src []byte) (map[string]interface{}, error) {
 			var v Double
 			_, err := v.ReadFrom(reader)
 			if err != nil {
-				return nil, fmt.Errorf("reading double (7): %s", err)
+				return nil, fmt.Errorf("reading double (7): %w", err)
 			}
 
 			m[k] = float64(v)
@@ -92,7 +125,7 @@ func ReadMap(src []byte) (map[string]interface{}, error) {
 			var v Boolean
 			_, err := v.ReadFrom(reader)
 			if err != nil {
-				return nil, fmt.Errorf("reading boolean (8): %s", err)
+				return nil, fmt.Errorf("reading boolean (8): %w", err)
 			}
 
 			m[k] = bool(v)
@@ -100,12 +133,12 @@ func ReadMap(src []byte) (map[string]interface{}, error) {
 			var v ByteArray
 			_, err := v.ReadFrom(reader)
 			if err != nil {
-				return nil, fmt.Errorf("reading byte array (9): %s", err)
+				return nil, fmt.Errorf("reading byte array (9): %w", err)
 			}
 
-			byteMap, err := ReadMap(v)
+			byteMap, err := readMap(v, limits, depth+1)
 			if err != nil {
-				return nil, fmt.Errorf("reading byte map (9): %s", err)
+				return nil, fmt.Errorf("reading byte map (9): %w", err)
 			}
 
 			m[k] = byteMap
@@ -113,23 +146,22 @@ func ReadMap(src []byte) (map[string]interface{}, error) {
 			var v ByteArray
 			_, err := v.ReadFrom(reader)
 			if err != nil {
-				return nil, fmt.Errorf("reading byte array (10): %s", err)
+				return nil, fmt.Errorf("reading byte array (10): %w", err)
 			}
 
 			m[k] = []byte(v)
 		case 11:
-			var l VarInt
-			_, err := l.ReadFrom(reader)
+			l, err := readLength(reader, limits)
 			if err != nil {
-				return nil, fmt.Errorf("reading string array length (11): %s", err)
+				return nil, fmt.Errorf("reading string array length (11): %w", err)
 			}
 
 			v := make([]string, l)
-			for i := 0; i < int(l); i++ {
+			for i := 0; i < l; i++ {
 				var s String
 				_, err := s.ReadFrom(reader)
 				if err != nil {
-					return nil, fmt.Errorf("reading string array contents (11): %s", err)
+					return nil, fmt.Errorf("reading string array contents (11): %w", err)
 				}
 
 				v[i] = string(s)
@@ -137,23 +169,22 @@ func ReadMap(src []byte) (map[string]interface{}, error) {
 
 			m[k] = v
 		case 12:
-			var l VarInt
-			_, err := l.ReadFrom(reader)
+			l, err := readLength(reader, limits)
 			if err != nil {
-				return nil, fmt.Errorf("reading byte map array length (12): %s", err)
+				return nil, fmt.Errorf("reading byte map array length (12): %w", err)
 			}
 
 			v := make([]map[string]interface{}, l)
-			for i := 0; i < int(l); i++ {
+			for i := 0; i < l; i++ {
 				var iv ByteArray
 				_, err := iv.ReadFrom(reader)
 				if err != nil {
-					return nil, fmt.Errorf("reading byte map corresponding byte array (12): %s", err)
+					return nil, fmt.Errorf("reading byte map corresponding byte array (12): %w", err)
 				}
 
-				byteMap, err := ReadMap(iv)
+				byteMap, err := readMap(iv, limits, depth+1)
 				if err != nil {
-					return nil, fmt.Errorf("reading byte map in byte array (12): %s", err)
+					return nil, fmt.Errorf("reading byte map in byte array (12): %w", err)
 				}
 
 				v[i] = byteMap
@@ -164,42 +195,38 @@ func ReadMap(src []byte) (map[string]interface{}, error) {
 			var v VarInt
 			_, err := v.ReadFrom(reader)
 			if err != nil {
-				return nil, fmt.Errorf("reading var int (13): %s", err)
+				return nil, fmt.Errorf("reading var int (13): %w", err)
 			}
 
-			m[k] = int32(v)
+			m[k] = v
 		case 14:
-			var v Long
+			var v VarLong
 			_, err := v.ReadFrom(reader)
 			if err != nil {
-				return nil, fmt.Errorf("reading long (14): %s", err)
+				return nil, fmt.Errorf("reading var long (14): %w", err)
 			}
 
-			m[k] = int64(v)
+			m[k] = v
 		case 15:
 			var v UUID
 			_, err := v.ReadFrom(reader)
 			if err != nil {
-				return nil, fmt.Errorf("reading uuid (15): %s", err)
+				return nil, fmt.Errorf("reading uuid (15): %w", err)
 			}
 
 			m[k] = uuid.UUID(v)
 		case 16:
-			var l VarInt
-			_, err := l.ReadFrom(reader)
+			l, err := readLength(reader, limits)
 			if err != nil {
-				return nil, fmt.Errorf("reading var int array length (16): %s", err)
+				return nil, fmt.Errorf("reading var int array length (16): %w", err)
 			}
 
-			v := make([]int, l)
-			for i := 0; i < int(l); i++ {
-				var iv VarInt
-				_, err := iv.ReadFrom(reader)
+			v := make([]VarInt, l)
+			for i := 0; i < l; i++ {
+				_, err := v[i].ReadFrom(reader)
 				if err != nil {
-					return nil, fmt.Errorf("reading var int array contents (16): %s", err)
+					return nil, fmt.Errorf("reading var int array contents (16): %w", err)
 				}
-
-				v[i] = int(iv)
 			}
 
 			m[k] = v
@@ -207,61 +234,54 @@ func ReadMap(src []byte) (map[string]interface{}, error) {
 			var v SignedVarInt
 			_, err := v.ReadFrom(reader)
 			if err != nil {
-				return nil, fmt.Errorf("reading signed var int (17): %s", err)
+				return nil, fmt.Errorf("reading signed var int (17): %w", err)
 			}
 
-			m[k] = int32(v)
+			m[k] = v
 		case 18:
-			var l VarInt
-			_, err := l.ReadFrom(reader)
+			l, err := readLength(reader, limits)
 			if err != nil {
-				return nil, fmt.Errorf("reading signed var int array (18): %s", err)
+				return nil, fmt.Errorf("reading signed var int array (18): %w", err)
 			}
 
-			v := make([]int, l)
-			for i := 0; i < int(l); i++ {
-				var iv SignedVarInt
-				_, err := iv.ReadFrom(reader)
+			v := make([]SignedVarInt, l)
+			for i := 0; i < l; i++ {
+				_, err := v[i].ReadFrom(reader)
 				if err != nil {
-					return nil, fmt.Errorf("reading signed var int array contents (18): %s", err)
+					return nil, fmt.Errorf("reading signed var int array contents (18): %w", err)
 				}
-
-				v[i] = int(iv)
 			}
 
 			m[k] = v
 		case 19:
-			var l VarInt
-			_, err := l.ReadFrom(reader)
+			l, err := readLength(reader, limits)
 			if err != nil {
-				return nil, fmt.Errorf("reading int array length (19): %s", err)
+				return nil, fmt.Errorf("reading int array length (19): %w", err)
 			}
 
-			v := make([]int, l)
-			for i := 0; i < int(l); i++ {
+			v := make([]int32, l)
+			for i := 0; i < l; i++ {
 				var iv Int
 				_, err := iv.ReadFrom(reader)
 				if err != nil {
-					return nil, fmt.Errorf("reading int array contents (19): %s", err)
+					return nil, fmt.Errorf("reading int array contents (19): %w", err)
 				}
 
-				v[i] = int(iv)
+				v[i] = int32(iv)
 			}
 
 			m[k] = v
 		case 20:
-			var l VarInt
-			_, err := l.ReadFrom(reader)
+			l, err := readLength(reader, limits)
 			if err != nil {
-				return nil, fmt.Errorf("reading table first level contents length (20): %s", err)
+				return nil, fmt.Errorf("reading table first level contents length (20): %w", err)
 			}
 
 			v := make([][]string, l)
-			for i := 0; i < int(l); i++ {
-				var il VarInt
-				_, err := il.ReadFrom(reader)
+			for i := 0; i < l; i++ {
+				il, err := readLength(reader, limits)
 				if err != nil {
-					return nil, fmt.Errorf("reading table second level contents length (20): %s", err)
+					return nil, fmt.Errorf("reading table second level contents length (20): %w", err)
 				}
 
 				v[i] = make([]string, il)
@@ -270,7 +290,7 @@ func ReadMap(src []byte) (map[string]interface{}, error) {
 					var iv String
 					_, err := iv.ReadFrom(reader)
 					if err != nil {
-						return nil, fmt.Errorf("reading table contents (20): %s", err)
+						return nil, fmt.Errorf("reading table contents (20): %w", err)
 					}
 
 					v[i][j] = string(iv)
@@ -279,35 +299,46 @@ func ReadMap(src []byte) (map[string]interface{}, error) {
 
 			m[k] = v
 		case 21:
-			var l VarInt
-			_, err := l.ReadFrom(reader)
+			l, err := readLength(reader, limits)
 			if err != nil {
-				return nil, fmt.Errorf("reading long array length (21): %s", err)
+				return nil, fmt.Errorf("reading long array length (21): %w", err)
 			}
 
 			v := make([]int64, l)
-			for i := 0; i < int(l); i++ {
+			for i := 0; i < l; i++ {
 				var iv Long
 				_, err := iv.ReadFrom(reader)
 				if err != nil {
-					return nil, fmt.Errorf("reading long array contents (21): %s", err)
+					return nil, fmt.Errorf("reading long array contents (21): %w", err)
 				}
 
 				v[i] = int64(iv)
 			}
 
 			m[k] = v
+		default:
+			return nil, fmt.Errorf("unknown type of %s: %d", k, vType)
 		}
 	}
+
+	return m, nil
 }
 
+// EncodeMap encodes a map or a struct with mapstructure tags as a ByteMap. Map keys are sorted, so the encoding
+// does not depend on the map order, and empty strings of structs are left out. The order of keys is not kept
+// by ReadMap, a decoded ByteMap is encoded back into the same values, but not necessarily into the same bytes
 func EncodeMap(d interface{}) ([]byte, error) {
 	buffer := &bytes.Buffer{}
 	t, v := reflect.TypeOf(d), reflect.ValueOf(d)
 
 	switch t.Kind() {
 	case reflect.Map:
-		for _, mk := range v.MapKeys() {
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+
+		for _, mk := range keys {
 			mv := v.MapIndex(mk)
 			_, err := String(mk.String()).WriteTo(buffer)
 			if err != nil {
@@ -316,14 +347,14 @@ func EncodeMap(d interface{}) ([]byte, error) {
 
 			err = writeField(mv.Interface(), buffer)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("%s: %w", mk.String(), err)
 			}
 		}
 	case reflect.Struct:
 		for i := 0; i < t.NumField(); i++ {
 			ft, fv := t.Field(i), v.Field(i)
 			value, ok := ft.Tag.Lookup("mapstructure")
-			if !ok {
+			if !ok || fv.Kind() == reflect.String && fv.String() == "" {
 				continue
 			}
 
@@ -334,7 +365,7 @@ func EncodeMap(d interface{}) ([]byte, error) {
 
 			err = writeField(fv.Interface(), buffer)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("%s: %w", value, err)
 			}
 		}
 	case reflect.Ptr:
@@ -346,346 +377,143 @@ func EncodeMap(d interface{}) ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
-func writeField(f interface{}, writer io.Writer) error {
-	v := reflect.ValueOf(f)
-	switch v.Kind() {
-	case reflect.Int:
-		j := v.Int()
-		if j >= 0 && j < 2097152 {
-			_, err := Byte(13).WriteTo(writer)
-			if err != nil {
-				return err
-			}
-
-			_, err = VarInt(j).WriteTo(writer)
-			if err != nil {
-				return err
-			}
-		} else if j < 0 && j > -1048576 {
-			_, err := Byte(17).WriteTo(writer)
-			if err != nil {
-				return err
-			}
-
-			_, err = SignedVarInt(j).WriteTo(writer)
-			if err != nil {
-				return err
-			}
-		} else {
-			_, err := Byte(1).WriteTo(writer)
-			if err != nil {
-				return err
-			}
+// writeFields writes the type of the value followed by the fields encoding it
+func writeFields(writer io.Writer, vType byte, fields ...FieldEncoder) error {
+	_, err := Byte(vType).WriteTo(writer)
+	if err != nil {
+		return err
+	}
 
-			_, err = Int(j).WriteTo(writer)
-			if err != nil {
-				return err
-			}
-		}
-	case reflect.Float32: // Float
-		_, err := Byte(6).WriteTo(writer)
+	for _, field := range fields {
+		_, err := field.WriteTo(writer)
 		if err != nil {
 			return err
 		}
+	}
 
-		_, err = Float(v.Float()).WriteTo(writer)
-		if err != nil {
-			return err
-		}
-	case reflect.Uint8: // Byte
-		_, err := Byte(2).WriteTo(writer)
-		if err != nil {
-			return err
-		}
+	return nil
+}
 
-		_, err = Byte(v.Uint()).WriteTo(writer)
-		if err != nil {
-			return err
-		}
-	case reflect.Int16: // Short
-		_, err := Byte(5).WriteTo(writer)
-		if err != nil {
-			return err
-		}
+// writeArray writes the type of the array, its length and the elements
+func writeArray(writer io.Writer, vType byte, length int, element func(i int) FieldEncoder) error {
+	fields := make([]FieldEncoder, 0, length+1)
+	fields = append(fields, VarInt(length))
+	for i := 0; i < length; i++ {
+		fields = append(fields, element(i))
+	}
 
-		_, err = Short(v.Int()).WriteTo(writer)
-		if err != nil {
-			return err
-		}
-	case reflect.Int64: // Long
-		i := v.Int()
-		if i >= 0 && i < 2097152 {
-			_, err := Byte(14).WriteTo(writer)
-			if err != nil {
-				return err
-			}
+	return writeFields(writer, vType, fields...)
+}
 
-			_, err = VarInt(i).WriteTo(writer)
-			if err != nil {
-				return err
-			}
-		} else {
-			_, err := Byte(3).WriteTo(writer)
-			if err != nil {
-				return err
+func writeField(f interface{}, writer io.Writer) error {
+	// Types decoded by ReadMap are encoded the same way they were decoded
+	switch v := f.(type) {
+	case int32:
+		return writeFields(writer, 1, Int(v))
+	case int64:
+		return writeFields(writer, 3, Long(v))
+	case VarInt:
+		return writeFields(writer, 13, v)
+	case VarLong:
+		return writeFields(writer, 14, v)
+	case SignedVarInt:
+		return writeFields(writer, 17, v)
+	case uuid.UUID:
+		return writeFields(writer, 15, UUID(v))
+	case []VarInt:
+		return writeArray(writer, 16, len(v), func(i int) FieldEncoder { return v[i] })
+	case []SignedVarInt:
+		return writeArray(writer, 18, len(v), func(i int) FieldEncoder { return v[i] })
+	case []int32:
+		return writeArray(writer, 19, len(v), func(i int) FieldEncoder { return Int(v[i]) })
+	case []int:
+		return writeInts(writer, v)
+	case []byte:
+		return writeFields(writer, 10, ByteArray(v))
+	case []string:
+		return writeArray(writer, 11, len(v), func(i int) FieldEncoder { return String(v[i]) })
+	case [][]string:
+		fields := []FieldEncoder{VarInt(len(v))}
+		for _, row := range v {
+			fields = append(fields, VarInt(len(row)))
+			for _, s := range row {
+				fields = append(fields, String(s))
 			}
+		}
 
-			_, err = Long(i).WriteTo(writer)
+		return writeFields(writer, 20, fields...)
+	case []int64:
+		return writeArray(writer, 21, len(v), func(i int) FieldEncoder { return Long(v[i]) })
+	case []map[string]interface{}:
+		fields := make([]FieldEncoder, 0, len(v))
+		for _, byteMap := range v {
+			encoded, err := EncodeMap(byteMap)
 			if err != nil {
 				return err
 			}
-		}
-	case reflect.String:
-		if v.String() == "" {
-			return nil
-		}
 
-		_, err := Byte(4).WriteTo(writer)
-		if err != nil {
-			return err
+			fields = append(fields, ByteArray(encoded))
 		}
 
-		_, err = String(v.String()).WriteTo(writer)
-		if err != nil {
-			return err
-		}
-	case reflect.Float64: // Double
-		_, err := Byte(7).WriteTo(writer)
-		if err != nil {
-			return err
-		}
+		return writeArray(writer, 12, len(v), func(i int) FieldEncoder { return fields[i] })
+	}
 
-		_, err = Double(v.Float()).WriteTo(writer)
-		if err != nil {
-			return err
-		}
-	case reflect.Bool:
-		_, err := Byte(8).WriteTo(writer)
-		if err != nil {
-			return err
+	v := reflect.ValueOf(f)
+	switch v.Kind() {
+	case reflect.Int:
+		// Plain integers take the shortest encoding
+		j := v.Int()
+		if j >= 0 && j < 2097152 {
+			return writeFields(writer, 13, VarInt(j))
+		} else if j < 0 && j > -1048576 {
+			return writeFields(writer, 17, SignedVarInt(j))
 		}
 
-		_, err = Boolean(v.Bool()).WriteTo(writer)
-		if err != nil {
-			return err
-		}
+		return writeFields(writer, 1, Int(j))
+	case reflect.Float32: // Float
+		return writeFields(writer, 6, Float(v.Float()))
+	case reflect.Uint8: // Byte
+		return writeFields(writer, 2, Byte(v.Uint()))
+	case reflect.Int16: // Short
+		return writeFields(writer, 5, Short(v.Int()))
+	case reflect.String:
+		return writeFields(writer, 4, String(v.String()))
+	case reflect.Float64: // Double
+		return writeFields(writer, 7, Double(v.Float()))
+	case reflect.Bool:
+		return writeFields(writer, 8, Boolean(v.Bool()))
 	case reflect.Struct, reflect.Map: // ByteMap
-		_, err := Byte(9).WriteTo(writer)
-		if err != nil {
-			return err
-		}
-
 		byteMap, err := EncodeMap(f)
 		if err != nil {
 			return err
 		}
 
-		_, err = ByteArray(byteMap).WriteTo(writer)
-		if err != nil {
-			return err
-		}
-	case reflect.Array, reflect.Slice: // Arrays of different types
-		switch f.(type) {
-		case []int:
-			a := f.([]int)
-			if len(a) == 0 {
-				_, err := Byte(16).WriteTo(writer)
-				if err != nil {
-					return err
-				}
-
-				_, err = Byte(0).WriteTo(writer)
-				if err != nil {
-					return err
-				}
-			} else {
-				flag := true
-				flag1 := false
-
-				for k := 0; k < 4 && k < len(a); k++ {
-					if a[k] < 0 || a[k] > 2097152 {
-						flag = false
-					}
-
-					if a[k] < 0 && a[k] > -1048576 {
-						flag1 = true
-					}
-				}
-
-				if flag {
-					_, err := Byte(16).WriteTo(writer)
-					if err != nil {
-						return err
-					}
-
-					_, err = VarInt(len(a)).WriteTo(writer)
-					if err != nil {
-						return err
-					}
-
-					for _, m := range a {
-						_, err := VarInt(m).WriteTo(writer)
-						if err != nil {
-							return err
-						}
-					}
-				} else if flag1 {
-					_, err := Byte(18).WriteTo(writer)
-					if err != nil {
-						return err
-					}
-
-					_, err = VarInt(len(a)).WriteTo(writer)
-					if err != nil {
-						return err
-					}
-
-					for _, m := range a {
-						_, err := SignedVarInt(m).WriteTo(writer)
-						if err != nil {
-							return err
-						}
-					}
-				} else {
-					_, err := Byte(19).WriteTo(writer)
-					if err != nil {
-						return err
-					}
-
-					_, err = VarInt(len(a)).WriteTo(writer)
-					if err != nil {
-						return err
-					}
-
-					for _, m := range a {
-						_, err := Int(m).WriteTo(writer)
-						if err != nil {
-							return err
-						}
-					}
-				}
-			}
-		case []byte:
-			_, err := Byte(10).WriteTo(writer)
-			if err != nil {
-				return err
-			}
-
-			_, err = ByteArray(f.([]byte)).WriteTo(writer)
-			if err != nil {
-				return err
-			}
-		case []string:
-			a := f.([]string)
-
-			_, err := Byte(11).WriteTo(writer)
-			if err != nil {
-				return err
-			}
-
-			_, err = VarInt(len(a)).WriteTo(writer)
-			if err != nil {
-				return err
-			}
-
-			for _, s := range a {
-				_, err := String(s).WriteTo(writer)
-				if err != nil {
-					return err
-				}
-			}
-		case [][]string:
-			a := f.([][]string)
-
-			_, err := Byte(20).WriteTo(writer)
-			if err != nil {
-				return err
-			}
-
-			_, err = VarInt(len(a)).WriteTo(writer)
-			if err != nil {
-				return err
-			}
-
-			for _, i := range a {
-				_, err = VarInt(len(i)).WriteTo(writer)
-				if err != nil {
-					return err
-				}
-
-				for _, j := range i {
-					_, err = String(j).WriteTo(writer)
-					if err != nil {
-						return err
-					}
-				}
-			}
-		case []int64:
-			a := f.([]int64)
-
-			_, err := Byte(21).WriteTo(writer)
-			if err != nil {
-				return err
-			}
-
-			_, err = VarInt(len(a)).WriteTo(writer)
-			if err != nil {
-				return err
-			}
-
-			for _, i := range a {
-				_, err := Long(i).WriteTo(writer)
-				if err != nil {
-					return err
-				}
-			}
-		case []map[string]interface{}:
-			a := f.([]map[string]interface{})
-
-			_, err := Byte(12).WriteTo(writer)
-			if err != nil {
-				return err
-			}
+		return writeFields(writer, 9, ByteArray(byteMap))
+	}
 
-			_, err = VarInt(len(a)).WriteTo(writer)
-			if err != nil {
-				return err
-			}
+	return fmt.Errorf("unsupported type: %T", f)
+}
 
-			for _, byteMap := range a {
-				eByteMap, err := EncodeMap(byteMap)
-				if err != nil {
-					return err
-				}
+// writeInts picks the shortest array encoding judging by the first elements
+func writeInts(writer io.Writer, a []int) error {
+	flag := true
+	flag1 := false
 
-				_, err = ByteArray(eByteMap).WriteTo(writer)
-				if err != nil {
-					return err
-				}
-			}
+	for k := 0; k < 4 && k < len(a); k++ {
+		if a[k] < 0 || a[k] > 2097152 {
+			flag = false
 		}
-	default: // All others
-		switch f.(type) {
-		case uuid.UUID:
-			_, err := Byte(15).WriteTo(writer)
-			if err != nil {
-				return err
-			}
-
-			i := f.(uuid.UUID)
-			_, err = Long(binary.BigEndian.Uint64(i[:8])).WriteTo(writer)
-			if err != nil {
-				return err
-			}
 
-			_, err = Long(binary.BigEndian.Uint64(i[8:])).WriteTo(writer)
-			if err != nil {
-				return err
-			}
-		default:
-			return fmt.Errorf("unsupported type: %s", v.Type())
+		if a[k] < 0 && a[k] > -1048576 {
+			flag1 = true
 		}
 	}
 
-	return nil
+	if flag {
+		return writeArray(writer, 16, len(a), func(i int) FieldEncoder { return VarInt(a[i]) })
+	} else if flag1 {
+		return writeArray(writer, 18, len(a), func(i int) FieldEncoder { return SignedVarInt(a[i]) })
+	}
+
+	return writeArray(writer, 19, len(a), func(i int) FieldEncoder { return Int(a[i]) })
 }
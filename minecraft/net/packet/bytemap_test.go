@@ -0,0 +1,214 @@
+package packet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// Texteria messages in testdata are hex dumps laid out the way servers send them, with the keys unsorted
+var goldenMessages = []struct {
+	name    string
+	decoded []map[string]interface{}
+}{
+	{
+		name: "add",
+		decoded: []map[string]interface{}{
+			{
+				"%":      "add",
+				"click":  map[string]interface{}{"act": "SCRIPT"},
+				"color":  int32(-0x80000000),
+				"dur":    VarLong(300000),
+				"height": VarInt(46),
+				"id":     "hp",
+				"scale":  float32(2.5),
+				"text":   []string{"§9K"},
+				"vis":    []map[string]interface{}{{"show": false, "type": "f3"}},
+				"x":      SignedVarInt(-7),
+				"z":      int16(3),
+			},
+			{
+				"%":   "remove",
+				"ids": []string{"kv.mh", "hp"},
+			},
+		},
+	},
+	{
+		name: "arrays",
+		decoded: []map[string]interface{}{
+			{
+				"bytes":    []byte{1, 2, 3},
+				"double":   0.5,
+				"flag":     byte(0xFF),
+				"ints":     []int32{-1, 70000},
+				"long":     int64(-1),
+				"longs":    []int64{1},
+				"svarints": []SignedVarInt{-1, 1},
+				"table":    [][]string{{"a"}, {}},
+				"uuid":     uuid.UUID{0x06, 0x9a, 0x79, 0xf4, 0x44, 0xe9, 0x47, 0x26, 0xa5, 0xbe, 0xfc, 0xa9, 0x0e, 0x38, 0xaa, 0xf5},
+				"varints":  []VarInt{0, 300},
+			},
+		},
+	},
+}
+
+// readGoldenActions reads the actions of a Texteria message dumped in testdata, lines starting with # are comments
+func readGoldenActions(tb testing.TB, name string) [][]byte {
+	dump, err := os.ReadFile(filepath.Join("testdata", "texteria", name+".hex"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	var digits strings.Builder
+	for _, line := range strings.Split(string(dump), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			digits.WriteString(strings.TrimSpace(line))
+		}
+	}
+
+	message, err := hex.DecodeString(digits.String())
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	reader := bytes.NewReader(message)
+	var amount VarInt
+	if _, err = amount.ReadFrom(reader); err != nil {
+		tb.Fatal(err)
+	}
+
+	actions := make([][]byte, amount)
+	for i := range actions {
+		var action ByteArray
+		if _, err = action.ReadFrom(reader); err != nil {
+			tb.Fatal(err)
+		}
+
+		actions[i] = action
+	}
+
+	return actions
+}
+
+func TestReadMap_Golden(t *testing.T) {
+	for _, golden := range goldenMessages {
+		actions := readGoldenActions(t, golden.name)
+		assert.Len(t, actions, len(golden.decoded), golden.name)
+
+		for i, action := range actions {
+			decoded, err := ReadMap(action, DefaultLimits)
+			assert.NoError(t, err, golden.name)
+			assert.Equal(t, golden.decoded[i], decoded, golden.name)
+
+			// Keys are written sorted, so servers' actions are encoded back into the same values in another order
+			encoded, err := EncodeMap(decoded)
+			assert.NoError(t, err, golden.name)
+			assert.Len(t, encoded, len(action), golden.name)
+
+			again, err := ReadMap(encoded, DefaultLimits)
+			assert.NoError(t, err, golden.name)
+			assert.Equal(t, decoded, again, golden.name)
+		}
+	}
+}
+
+func TestEncodeMap(t *testing.T) {
+	// Plain integers take the shortest encoding, which is decoded into the respective type
+	encoded, err := EncodeMap(map[string]interface{}{
+		"small":    7,
+		"negative": -7,
+		"big":      1 << 30,
+		"ints":     []int{-1, 5},
+		"empty":    "",
+		"nested":   map[string]interface{}{"list": []int{}},
+	})
+	assert.NoError(t, err)
+
+	decoded, err := ReadMap(encoded, DefaultLimits)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"small":    VarInt(7),
+		"negative": SignedVarInt(-7),
+		"big":      int32(1 << 30),
+		"ints":     []SignedVarInt{-1, 5},
+		"empty":    "",
+		"nested":   map[string]interface{}{"list": []VarInt{}},
+	}, decoded)
+
+	// Empty strings of structs are left out
+	encoded, err = EncodeMap(&struct {
+		Action string `mapstructure:"%"`
+		ID     string `mapstructure:"id"`
+		Hidden int
+	}{Action: "reset"})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x01, '%', 4, 0x05, 'r', 'e', 's', 'e', 't'}, encoded)
+
+	_, err = EncodeMap(map[string]interface{}{"channel": make(chan int)})
+	assert.Error(t, err)
+}
+
+func TestReadMap_Malformed(t *testing.T) {
+	for _, payload := range [][]byte{
+		{0x01, 'a', 0x7F},                             // unknown type
+		{0x01, 'a', 1, 0x00, 0x00},                    // truncated int
+		{0x01, 'a', 11, 0xFF, 0xFF, 0xFF, 0xFF, 0x07}, // array longer than the payload
+		{0x01, 'a', 9, 0x02, 0x01, 'b'},               // truncated nested map
+		{0x05, 'a'},                                   // truncated key
+	} {
+		_, err := ReadMap(payload, DefaultLimits)
+		assert.Error(t, err, "%x", payload)
+	}
+
+	decoded, err := ReadMap(nil, DefaultLimits)
+	assert.NoError(t, err)
+	assert.Empty(t, decoded)
+}
+
+func TestSignedVarInt(t *testing.T) {
+	for _, value := range []SignedVarInt{0, 1, -1, 63, -64, 1 << 20, -(1 << 20), 1<<31 - 1, -1 << 31} {
+		buffer := &bytes.Buffer{}
+		_, err := value.WriteTo(buffer)
+		assert.NoError(t, err)
+
+		var decoded SignedVarInt
+		_, err = decoded.ReadFrom(buffer)
+		assert.NoError(t, err)
+		assert.Equal(t, value, decoded)
+	}
+}
+
+func TestEncodeMap_RoundTrip(t *testing.T) {
+	property := func(i32 int32, i64 int64, s string, f32 float32, f64 float64, b bool, i16 int16, u8 uint8, key string) bool {
+		values := map[string]interface{}{
+			"int":    i32,
+			"long":   i64,
+			"string": s,
+			"float":  f32,
+			"double": f64,
+			"bool":   b,
+			"short":  i16,
+			"byte":   u8,
+			"nested": map[string]interface{}{key: s},
+		}
+
+		encoded, err := EncodeMap(values)
+		if !assert.NoError(t, err) {
+			return false
+		}
+
+		decoded, err := ReadMap(encoded, DefaultLimits)
+		return assert.NoError(t, err) && assert.Equal(t, values, decoded)
+	}
+
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
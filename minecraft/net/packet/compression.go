@@ -0,0 +1,67 @@
+package packet
+
+import (
+	"compress/zlib"
+	"io"
+	"sync"
+)
+
+// Compressor pools the zlib writers and readers of compressed packets, they are reset for every packet
+// instead of being allocated again. A Compressor can be shared by any number of connections
+type Compressor struct {
+	level   int
+	writers sync.Pool
+	readers sync.Pool
+}
+
+// DefaultCompressor is used by Pack and UnPack
+var DefaultCompressor, _ = NewCompressor(zlib.DefaultCompression)
+
+// NewCompressor returns a Compressor writing packets with the zlib level,
+// from zlib.HuffmanOnly to zlib.BestCompression
+func NewCompressor(level int) (*Compressor, error) {
+	if _, err := zlib.NewWriterLevel(io.Discard, level); err != nil {
+		return nil, err
+	}
+
+	return &Compressor{level: level}, nil
+}
+
+func (c *Compressor) Level() int {
+	return c.level
+}
+
+func (c *Compressor) getWriter(w io.Writer) *zlib.Writer {
+	if zw, ok := c.writers.Get().(*zlib.Writer); ok {
+		zw.Reset(w)
+		return zw
+	}
+
+	// The level has been checked by NewCompressor
+	zw, _ := zlib.NewWriterLevel(w, c.level)
+	return zw
+}
+
+// putWriter returns the writer to the pool, it must have been closed
+func (c *Compressor) putWriter(zw *zlib.Writer) {
+	c.writers.Put(zw)
+}
+
+// getReader returns a reader of the zlib stream, reading its header
+func (c *Compressor) getReader(r io.Reader) (io.ReadCloser, error) {
+	if zr, ok := c.readers.Get().(io.ReadCloser); ok {
+		if err := zr.(zlib.Resetter).Reset(r, nil); err != nil {
+			// The reader is left with the error, it can still be reset later
+			c.readers.Put(zr)
+			return nil, err
+		}
+
+		return zr, nil
+	}
+
+	return zlib.NewReader(r)
+}
+
+func (c *Compressor) putReader(zr io.ReadCloser) {
+	c.readers.Put(zr)
+}
@@ -0,0 +1,142 @@
+package packet
+
+import (
+	"bytes"
+	"compress/zlib"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// chunkData resembles a chunk column, long runs of the same blocks with some noise
+func chunkData(size int) []byte {
+	random := rand.New(rand.NewSource(1))
+	data := make([]byte, size)
+	for i := range data {
+		if random.Intn(8) == 0 {
+			data[i] = byte(random.Intn(256))
+		}
+	}
+
+	return data
+}
+
+func TestNewCompressor(t *testing.T) {
+	for _, level := range []int{zlib.HuffmanOnly, zlib.DefaultCompression, zlib.NoCompression, zlib.BestSpeed, zlib.BestCompression} {
+		compressor, err := NewCompressor(level)
+		assert.NoError(t, err)
+		assert.Equal(t, level, compressor.Level())
+	}
+
+	_, err := NewCompressor(10)
+	assert.Error(t, err)
+}
+
+func TestCompressor_RoundTrip(t *testing.T) {
+	packet := Packet{ID: 0x21, Data: chunkData(4096)}
+	sizes := make(map[int]int)
+	for _, level := range []int{zlib.HuffmanOnly, zlib.BestSpeed, zlib.BestCompression} {
+		compressor, _ := NewCompressor(level)
+
+		// Writers and readers are reused by packets written one after another and at once
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 4; j++ {
+					var buf bytes.Buffer
+					assert.NoError(t, packet.PackWith(&buf, 256, bufferPool, compressor))
+
+					var decoded Packet
+					assert.NoError(t, decoded.UnPackWith(&buf, 256, bufferPool, compressor))
+					assert.Equal(t, packet, decoded)
+				}
+			}()
+		}
+		wg.Wait()
+
+		var buf bytes.Buffer
+		assert.NoError(t, packet.PackWith(&buf, 256, bufferPool, compressor))
+		sizes[level] = buf.Len()
+	}
+
+	assert.Less(t, sizes[zlib.BestCompression], sizes[zlib.HuffmanOnly])
+}
+
+func TestCompressor_CorruptStream(t *testing.T) {
+	compressor, _ := NewCompressor(zlib.DefaultCompression)
+	packet := Packet{ID: 0x21, Data: chunkData(1024)}
+
+	var buf bytes.Buffer
+	assert.NoError(t, packet.PackWith(&buf, 256, bufferPool, compressor))
+	valid := buf.Bytes()
+
+	// The zlib header starts after the packet and data lengths, both take two bytes here
+	corrupt := append([]byte{}, valid...)
+	corrupt[4] = 0xFF
+	var decoded Packet
+	assert.Error(t, decoded.UnPackWith(bytes.NewReader(corrupt), 256, bufferPool, compressor))
+
+	// A reader that has failed is reset for the next packet
+	assert.NoError(t, decoded.UnPackWith(bytes.NewReader(valid), 256, bufferPool, compressor))
+	assert.Equal(t, packet, decoded)
+}
+
+// BenchmarkCompressor_Pack compares a pooled compressor with a fresh writer for every packet
+func BenchmarkCompressor_Pack(b *testing.B) {
+	packet := Packet{ID: 0x21, Data: chunkData(16384)}
+	for _, benchmark := range []struct {
+		name       string
+		compressor func() *Compressor
+	}{
+		{"unpooled", func() *Compressor { compressor, _ := NewCompressor(zlib.DefaultCompression); return compressor }},
+		{"pooled", func() *Compressor { return DefaultCompressor }},
+		{"pooled-speed", func() *Compressor { compressor, _ := NewCompressor(zlib.BestSpeed); return compressor }},
+	} {
+		b.Run(benchmark.name, func(b *testing.B) {
+			compressor := benchmark.compressor()
+			var buf bytes.Buffer
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if benchmark.name == "unpooled" {
+					compressor = benchmark.compressor()
+				}
+
+				buf.Reset()
+				_ = packet.PackWith(&buf, 256, bufferPool, compressor)
+			}
+		})
+	}
+}
+
+func BenchmarkCompressor_UnPack(b *testing.B) {
+	packet := Packet{ID: 0x21, Data: chunkData(16384)}
+	var buf bytes.Buffer
+	_ = packet.Pack(&buf, 256, bufferPool)
+	compressed := buf.Bytes()
+
+	for _, pooled := range []bool{false, true} {
+		name := "unpooled"
+		if pooled {
+			name = "pooled"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			compressor := DefaultCompressor
+			var decoded Packet
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if !pooled {
+					compressor, _ = NewCompressor(zlib.DefaultCompression)
+				}
+
+				_ = decoded.UnPackWith(bytes.NewReader(compressed), 256, bufferPool, compressor)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+//go:build go1.18
+// +build go1.18
+
+package packet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fuzzUnPack checks that whatever is unpacked is packed and unpacked back into the same packet
+func fuzzUnPack(f *testing.F, threshold int) {
+	for _, packet := range []Packet{
+		{ID: 0x00},
+		{ID: 0x3F, Data: []byte("Texteria")},
+		{ID: 0x26, Data: bytes.Repeat([]byte{0x01, 0x02}, 512)},
+	} {
+		var buf bytes.Buffer
+		_ = packet.Pack(&buf, threshold, bufferPool)
+		f.Add(buf.Bytes())
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var packet Packet
+		if err := packet.UnPack(bytes.NewReader(data), threshold, bufferPool); err != nil {
+			return
+		}
+
+		var buf bytes.Buffer
+		assert.NoError(t, packet.Pack(&buf, threshold, bufferPool))
+
+		var unpacked Packet
+		assert.NoError(t, unpacked.UnPack(&buf, threshold, bufferPool))
+		assert.Equal(t, packet.ID, unpacked.ID)
+		assert.Equal(t, len(packet.Data), len(unpacked.Data))
+		assert.True(t, bytes.Equal(packet.Data, unpacked.Data))
+	})
+}
+
+func FuzzPacket_UnPack(f *testing.F) {
+	fuzzUnPack(f, -1)
+}
+
+func FuzzPacket_UnPackCompressed(f *testing.F) {
+	fuzzUnPack(f, 256)
+}
+
+// FuzzReadMap checks that decoded maps are encoded losslessly
+func FuzzReadMap(f *testing.F) {
+	for _, golden := range goldenMessages {
+		for _, action := range readGoldenActions(f, golden.name) {
+			f.Add(action)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		decoded, err := ReadMap(data, DefaultLimits)
+		if err != nil {
+			return
+		}
+
+		encoded, err := EncodeMap(decoded)
+		assert.NoError(t, err)
+
+		again, err := ReadMap(encoded, DefaultLimits)
+		assert.NoError(t, err)
+
+		reencoded, err := EncodeMap(again)
+		assert.NoError(t, err)
+		assert.Equal(t, encoded, reencoded)
+	})
+}
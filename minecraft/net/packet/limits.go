@@ -0,0 +1,80 @@
+package packet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrLimitExceeded is matched by every LimitError, use errors.As to get the limit that was exceeded
+var ErrLimitExceeded = errors.New("decoding limit exceeded")
+
+// Limits bound what a peer can make the decoder allocate. A limit of zero forbids the respective data,
+// e.g. NBT in states which have no item stacks
+type Limits struct {
+	// MaxPacketLength bounds frames and the uncompressed length of compressed packets, and so byte arrays
+	MaxPacketLength int
+	// MaxStringLength is in bytes, not in characters
+	MaxStringLength int
+	MaxArrayLength  int
+	// MaxNBTDepth bounds nesting of NBT lists and compounds, and of ByteMaps
+	MaxNBTDepth int
+	MaxNBTSize  int
+}
+
+// DefaultLimits are the limits of the play state, they apply whenever a packet has no limits of its own
+var DefaultLimits = &Limits{
+	MaxPacketLength: MaxPacketLength,
+	MaxStringLength: 32767 * 4,
+	MaxArrayLength:  1 << 16,
+	MaxNBTDepth:     512,
+	MaxNBTSize:      MaxPacketLength,
+}
+
+// LimitError is returned when a peer sends more than the Limits allow
+type LimitError struct {
+	Limit string
+	Value int
+	Max   int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: %s of %d is over %d", ErrLimitExceeded, e.Limit, e.Value, e.Max)
+}
+
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// checkLimit returns a LimitError if the value is over the maximum
+func checkLimit(limit string, value, max int) error {
+	if value > max {
+		return &LimitError{Limit: limit, Value: value, Max: max}
+	}
+
+	return nil
+}
+
+// limitedReader passes the limits of the packet to the fields Packet.Scan decodes
+type limitedReader struct {
+	*bytes.Reader
+	limits *Limits
+}
+
+// limitsOf returns the limits fields read from the reader are decoded with
+func limitsOf(r io.Reader) *Limits {
+	if reader, ok := r.(*limitedReader); ok {
+		return reader.limits
+	}
+
+	return DefaultLimits
+}
+
+func (p *Packet) limits() *Limits {
+	if p.Limits == nil {
+		return DefaultLimits
+	}
+
+	return p.Limits
+}
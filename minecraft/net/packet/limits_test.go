@@ -0,0 +1,132 @@
+package packet
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testLimits = &Limits{
+	MaxPacketLength: 64,
+	MaxStringLength: 8,
+	MaxArrayLength:  4,
+	MaxNBTDepth:     2,
+	MaxNBTSize:      32,
+}
+
+// assertLimit checks that the error is a LimitError of the limit
+func assertLimit(t *testing.T, err error, limit string) {
+	var limitErr *LimitError
+	if assert.True(t, errors.As(err, &limitErr), "%v", err) {
+		assert.Equal(t, limit, limitErr.Limit)
+		assert.True(t, errors.Is(err, ErrLimitExceeded))
+	}
+}
+
+func TestLimits_UnPack(t *testing.T) {
+	for _, threshold := range []int{-1, 256} {
+		var buf bytes.Buffer
+		packet := Packet{ID: 0x01, Data: make([]byte, 128)}
+		assert.NoError(t, packet.Pack(&buf, threshold, bufferPool))
+
+		limited := Packet{Limits: testLimits}
+		assertLimit(t, limited.UnPack(bytes.NewReader(buf.Bytes()), threshold, bufferPool), "packet length")
+
+		var decoded Packet
+		assert.NoError(t, decoded.UnPack(bytes.NewReader(buf.Bytes()), threshold, bufferPool))
+	}
+
+	// The uncompressed length is checked before the packet is inflated
+	var buf bytes.Buffer
+	packet := Packet{ID: 0x01, Data: make([]byte, 1024)}
+	assert.NoError(t, packet.Pack(&buf, 256, bufferPool))
+	assert.Less(t, buf.Len(), 64)
+
+	limited := Packet{Limits: testLimits}
+	assertLimit(t, limited.UnPack(&buf, 256, bufferPool), "uncompressed packet length")
+}
+
+func TestLimits_Scan(t *testing.T) {
+	scan := func(fields []FieldEncoder, decoders ...FieldDecoder) error {
+		packet := Marshal(0x00, fields...)
+		packet.Limits = testLimits
+		return packet.Scan(decoders...)
+	}
+
+	var str String
+	assert.NoError(t, scan([]FieldEncoder{String("kogtevra")}, &str))
+	assertLimit(t, scan([]FieldEncoder{String("kogtevran")}, &str), "string length")
+
+	var byteArray ByteArray
+	assertLimit(t, scan([]FieldEncoder{ByteArray(make([]byte, 65))}, &byteArray), "byte array length")
+
+	var (
+		length VarInt
+		array  []Int
+	)
+
+	assert.NoError(t, scan([]FieldEncoder{Array([]Int{1, 2, 3, 4})}, &length, &Ary{Len: &length, Ary: &array}))
+	assertLimit(t, scan([]FieldEncoder{Array([]Int{1, 2, 3, 4, 5})}, &length, &Ary{Len: &length, Ary: &array}), "array length")
+
+	// Nested fields are decoded with the limits of the packet as well
+	var strings []String
+	assertLimit(t, scan([]FieldEncoder{Array([]String{"kogtevran"})}, &length, &Ary{Len: &length, Ary: &strings}), "string length")
+
+	// Fields read from plain readers are decoded with the default limits
+	_, err := str.ReadFrom(bytes.NewReader([]byte{0xFF, 0xFF, 0x7F}))
+	assertLimit(t, err, "string length")
+}
+
+func TestLimits_NBT(t *testing.T) {
+	scan := func(data ...byte) error {
+		var slot Slot
+		packet := Marshal(0x00, Short(1), Byte(1), Short(0))
+		packet.Data = append(packet.Data, data...)
+		packet.Limits = testLimits
+		return packet.Scan(&slot)
+	}
+
+	// An empty compound within a compound, and another one
+	assert.NoError(t, scan(0x0A, 0, 0, 0x0A, 0, 1, 'a', 0x00, 0x00))
+	assertLimit(t, scan(0x0A, 0, 0, 0x0A, 0, 1, 'a', 0x0A, 0, 1, 'b', 0x00, 0x00, 0x00), "NBT depth")
+
+	// Lists of lists are nested just the same
+	assertLimit(t, scan(0x09, 0, 0, 0x09, 0, 0, 0, 1, 0x09, 0, 0, 0, 1, 0x01, 0, 0, 0, 0), "NBT depth")
+
+	// Arrays are checked before they are read
+	assertLimit(t, scan(0x0B, 0, 0, 0x7F, 0xFF, 0xFF, 0xFF), "NBT size")
+	assertLimit(t, scan(0x08, 0, 0, 0x00, 0x40), "NBT size")
+
+	assert.True(t, errors.Is(scan(0x07, 0, 0, 0xFF, 0xFF, 0xFF, 0xFF), ErrInvalidLength))
+	assert.Error(t, scan(0x09, 0, 0, 0x00, 0, 0, 0, 1))
+	assert.Error(t, scan(0x0D, 0, 0))
+}
+
+func TestLimits_ReadMap(t *testing.T) {
+	// Maps nested deeper than the limit are not decoded, whatever the length of the payload
+	nested := []byte{}
+	for i := 0; i < DefaultLimits.MaxNBTDepth; i++ {
+		var buf bytes.Buffer
+		_, _ = String("m").WriteTo(&buf)
+		_, _ = Byte(9).WriteTo(&buf)
+		_, _ = ByteArray(nested).WriteTo(&buf)
+		nested = buf.Bytes()
+	}
+
+	_, err := ReadMap(nested, DefaultLimits)
+	assertLimit(t, err, "byte map depth")
+
+	_, err = ReadMap(nested[len(nested)-16:], DefaultLimits)
+	assert.NoError(t, err)
+
+	// The limits are those of the packet carrying the map
+	_, err = ReadMap(nested[len(nested)-16:], testLimits)
+	assertLimit(t, err, "byte map depth")
+
+	encoded, err := EncodeMap(map[string]interface{}{"ids": []string{"a", "b", "c", "d", "e"}})
+	assert.NoError(t, err)
+	_, err = ReadMap(encoded, testLimits)
+	assertLimit(t, err, "byte map array length")
+}
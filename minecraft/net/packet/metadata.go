@@ -3,6 +3,7 @@ package packet
 import (
 	"bytes"
 	"io"
+	"sort"
 )
 
 type MetadataFieldType int8
@@ -136,12 +137,23 @@ func (p *MetadataFloatPos) ReadFrom(r io.Reader) (n int64, err error) {
 	return
 }
 
+// MetadataEnd terminates the list of metadata fields
+const MetadataEnd = 0x7f
+
 type EntityMetadata map[byte]interface{}
 
 func (e EntityMetadata) WriteTo(w io.Writer) (n int64, err error) {
 	var m int64
 
-	for index, value := range e {
+	// Fields are written in order of their indexes, so that the same metadata is always encoded the same way
+	indexes := make([]int, 0, len(e))
+	for index := range e {
+		indexes = append(indexes, int(index))
+	}
+	sort.Ints(indexes)
+
+	for _, i := range indexes {
+		index, value := byte(i), e[byte(i)]
 		var fType MetadataFieldType
 		b := &bytes.Buffer{}
 
@@ -185,7 +197,7 @@ func (e EntityMetadata) WriteTo(w io.Writer) (n int64, err error) {
 		}
 	}
 
-	m, err = UnsignedByte(0xff).WriteTo(w)
+	m, err = UnsignedByte(MetadataEnd).WriteTo(w)
 	n += m
 	return
 }
@@ -194,12 +206,13 @@ func (e *EntityMetadata) ReadFrom(r io.Reader) (n int64, err error) {
 	var m int64
 	for {
 		var index UnsignedByte
-		n, err = index.ReadFrom(r)
+		m, err = index.ReadFrom(r)
+		n += m
 		if err != nil {
 			return
 		}
 
-		if index == 127 {
+		if index == MetadataEnd {
 			return
 		}
 
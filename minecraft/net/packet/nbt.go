@@ -0,0 +1,27 @@
+package packet
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/destructiqn/kogtevran/minecraft/tag"
+)
+
+// readNBT returns the bytes of the tag the reader starts with, a single TagEnd if there is no tag.
+// The tag is checked against the NBT limits before it is decoded
+func readNBT(r io.Reader, limits *Limits) ([]byte, error) {
+	data, err := tag.Read(r, tag.Limits{MaxDepth: limits.MaxNBTDepth, MaxSize: limits.MaxNBTSize})
+
+	var limitErr *tag.LimitError
+	switch {
+	case errors.As(err, &limitErr) && errors.Is(err, tag.ErrTooDeep):
+		return data, &LimitError{Limit: "NBT depth", Value: limitErr.Value, Max: limitErr.Max}
+	case errors.As(err, &limitErr):
+		return data, &LimitError{Limit: "NBT size", Value: limitErr.Value, Max: limitErr.Max}
+	case errors.Is(err, tag.ErrInvalidLength):
+		return data, fmt.Errorf("%w: %v", ErrInvalidLength, err)
+	}
+
+	return data, err
+}
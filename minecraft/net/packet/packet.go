@@ -2,16 +2,23 @@ package packet
 
 import (
 	"bytes"
-	"compress/zlib"
+	"errors"
 	"fmt"
 	"io"
 	"sync"
 )
 
+// MaxPacketLength is the largest length a packet can have, its prefix is a VarInt of three bytes at most
+const MaxPacketLength = 2097151
+
+var ErrInvalidPacketLength = errors.New("invalid packet length")
+
 // Packet define a net data package
 type Packet struct {
 	ID   int32
 	Data []byte
+	// Limits the packet is unpacked and scanned with, DefaultLimits if nil
+	Limits *Limits
 }
 
 //Marshal generate Packet with the ID and Fields
@@ -25,7 +32,7 @@ func Marshal(id int32, fields ...FieldEncoder) (pk Packet) {
 
 //Scan decode the packet and fill data into fields
 func (p Packet) Scan(fields ...FieldDecoder) error {
-	r := bytes.NewReader(p.Data)
+	r := &limitedReader{Reader: bytes.NewReader(p.Data), limits: p.limits()}
 	for _, v := range fields {
 		_, err := v.ReadFrom(r)
 		if err != nil {
@@ -37,8 +44,13 @@ func (p Packet) Scan(fields ...FieldDecoder) error {
 
 // Pack 打包一个数据包
 func (p *Packet) Pack(w io.Writer, threshold int, bufPool *sync.Pool) error {
+	return p.PackWith(w, threshold, bufPool, DefaultCompressor)
+}
+
+// PackWith acts like Pack but compresses the packet with the compressor
+func (p *Packet) PackWith(w io.Writer, threshold int, bufPool *sync.Pool, compressor *Compressor) error {
 	if threshold >= 0 {
-		return p.packWithCompression(w, threshold, bufPool)
+		return p.packWithCompression(w, threshold, bufPool, compressor)
 	} else {
 		return p.packWithoutCompression(w, bufPool)
 	}
@@ -70,7 +82,7 @@ func (p *Packet) packWithoutCompression(w io.Writer, bufPool *sync.Pool) error {
 	return nil
 }
 
-func (p *Packet) packWithCompression(w io.Writer, threshold int, bufPool *sync.Pool) error {
+func (p *Packet) packWithCompression(w io.Writer, threshold int, bufPool *sync.Pool, compressor *Compressor) error {
 	buff := bufPool.Get().(*bytes.Buffer)
 	defer bufPool.Put(buff)
 	buff.Reset()
@@ -99,7 +111,7 @@ func (p *Packet) packWithCompression(w io.Writer, threshold int, bufPool *sync.P
 			return err
 		}
 	} else {
-		zw := zlib.NewWriter(buff)
+		zw := compressor.getWriter(buff)
 		n1, err := VarInt(p.ID).WriteTo(zw)
 		if err != nil {
 			return err
@@ -112,6 +124,7 @@ func (p *Packet) packWithCompression(w io.Writer, threshold int, bufPool *sync.P
 		if err != nil {
 			return err
 		}
+		compressor.putWriter(zw)
 
 		dataLength := bufPool.Get().(*bytes.Buffer)
 		defer bufPool.Put(dataLength)
@@ -140,10 +153,15 @@ func (p *Packet) packWithCompression(w io.Writer, threshold int, bufPool *sync.P
 	return nil
 }
 
-// UnPack in-place decompression a packet
+// UnPack in-place decompression a packet, lengths are checked against the limits of the packet
 func (p *Packet) UnPack(r io.Reader, threshold int, bufPool *sync.Pool) error {
+	return p.UnPackWith(r, threshold, bufPool, DefaultCompressor)
+}
+
+// UnPackWith acts like UnPack but decompresses the packet with the compressor
+func (p *Packet) UnPackWith(r io.Reader, threshold int, bufPool *sync.Pool, compressor *Compressor) error {
 	if threshold >= 0 {
-		return p.unpackWithCompression(r, threshold, bufPool)
+		return p.unpackWithCompression(r, threshold, bufPool, compressor)
 	} else {
 		return p.unpackWithoutCompression(r)
 	}
@@ -164,6 +182,14 @@ func (p *Packet) unpackWithoutCompression(r io.Reader) error {
 	p.ID = int32(PacketID)
 
 	lengthOfData := int(Length) - int(n)
+	if Length > MaxPacketLength || lengthOfData < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidPacketLength, Length)
+	}
+
+	if err := checkLimit("packet length", int(Length), p.limits().MaxPacketLength); err != nil {
+		return err
+	}
+
 	if cap(p.Data) < lengthOfData {
 		p.Data = make([]byte, lengthOfData)
 	} else {
@@ -176,13 +202,22 @@ func (p *Packet) unpackWithoutCompression(r io.Reader) error {
 	return nil
 }
 
-func (p *Packet) unpackWithCompression(r io.Reader, threshold int, bufPool *sync.Pool) error {
+func (p *Packet) unpackWithCompression(r io.Reader, threshold int, bufPool *sync.Pool, compressor *Compressor) error {
 	var PacketLength VarInt
 	_, err := PacketLength.ReadFrom(r)
 	if err != nil {
 		return err
 	}
 
+	if PacketLength < 0 || PacketLength > MaxPacketLength {
+		return fmt.Errorf("%w: %d", ErrInvalidPacketLength, PacketLength)
+	}
+
+	limits := p.limits()
+	if err := checkLimit("packet length", int(PacketLength), limits.MaxPacketLength); err != nil {
+		return err
+	}
+
 	buff := bufPool.Get().(*bytes.Buffer)
 	defer bufPool.Put(buff)
 	buff.Reset()
@@ -208,11 +243,14 @@ func (p *Packet) unpackWithCompression(r io.Reader, threshold int, bufPool *sync
 		if DataLength > MaxDataLength {
 			return fmt.Errorf("compressed packet error: size of %d is larger than protocol maximum of %d", DataLength, MaxDataLength)
 		}
-		zr, err := zlib.NewReader(r)
+		if err := checkLimit("uncompressed packet length", int(DataLength), limits.MaxPacketLength); err != nil {
+			return err
+		}
+		zr, err := compressor.getReader(r)
 		if err != nil {
 			return err
 		}
-		defer zr.Close()
+		defer compressor.putReader(zr)
 		r = zr
 		n3, err := PacketID.ReadFrom(r)
 		if err != nil {
@@ -226,6 +264,10 @@ func (p *Packet) unpackWithCompression(r io.Reader, threshold int, bufPool *sync
 		}
 		DataLength = VarInt(int64(PacketLength) - n2 - n3)
 	}
+	if DataLength < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidPacketLength, DataLength)
+	}
+
 	if cap(p.Data) < int(DataLength) {
 		p.Data = make([]byte, DataLength)
 	} else {
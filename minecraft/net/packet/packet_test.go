@@ -0,0 +1,55 @@
+package packet
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var bufferPool = &sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+func TestPacket_RoundTrip(t *testing.T) {
+	for _, threshold := range []int{-1, 0, 256} {
+		property := func(id int32, data []byte) bool {
+			packet := Packet{ID: id, Data: data}
+
+			var buf bytes.Buffer
+			if !assert.NoError(t, packet.Pack(&buf, threshold, bufferPool)) {
+				return false
+			}
+
+			var decoded Packet
+			return assert.NoError(t, decoded.UnPack(&buf, threshold, bufferPool)) &&
+				decoded.ID == id && bytes.Equal(decoded.Data, data) && assert.Zero(t, buf.Len())
+		}
+
+		if err := quick.Check(property, nil); err != nil {
+			t.Errorf("threshold %d: %v", threshold, err)
+		}
+
+		// Packets larger than the threshold are compressed, and the smaller ones are not
+		assert.True(t, property(0x26, bytes.Repeat([]byte{0x2A}, 4096)), "threshold %d", threshold)
+		assert.True(t, property(0x00, nil), "threshold %d", threshold)
+	}
+}
+
+func TestPacket_UnPackInvalidLength(t *testing.T) {
+	for _, threshold := range []int{-1, 256} {
+		var packet Packet
+		err := packet.UnPack(bytes.NewReader([]byte{0xFF, 0xFF, 0xFF, 0x07, 0x00}), threshold, bufferPool)
+		assert.True(t, errors.Is(err, ErrInvalidPacketLength), "threshold %d", threshold)
+	}
+
+	// The ID takes more bytes than the whole packet
+	var packet Packet
+	err := packet.UnPack(bytes.NewReader([]byte{0x01, 0x80, 0x01}), -1, bufferPool)
+	assert.True(t, errors.Is(err, ErrInvalidPacketLength))
+}
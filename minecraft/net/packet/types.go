@@ -3,6 +3,7 @@ package packet
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"math"
 
@@ -97,6 +98,32 @@ type (
 		Amount    Double
 		Operation Byte
 	}
+
+	// PlayerProperty is a property of a player profile, e.g. textures
+	PlayerProperty struct {
+		Name      String
+		Value     String
+		IsSigned  Boolean
+		Signature String
+	}
+
+	// ChunkMeta describes a single chunk column of Map Chunk Bulk
+	ChunkMeta struct {
+		ChunkX, ChunkZ Int
+		PrimaryBitMask UnsignedShort
+	}
+
+	// BlockRecord is a block changed by Multi Block Change, the horizontal position is encoded as x << 4 | z
+	BlockRecord struct {
+		HorizontalPosition UnsignedByte
+		Y                  UnsignedByte
+		BlockID            VarInt
+	}
+
+	// ExplosionRecord is an offset of a destroyed block relative to the explosion center
+	ExplosionRecord struct {
+		X, Y, Z Byte
+	}
 )
 
 func (p Property) WriteTo(w io.Writer) (n int64, err error) {
@@ -152,7 +179,7 @@ func (p *Property) ReadFrom(r io.Reader) (n int64, err error) {
 
 	m, err = Ary{
 		Len: modifiersLen,
-		Ary: p.Modifiers,
+		Ary: &p.Modifiers,
 	}.ReadFrom(r)
 	n += m
 	if err != nil {
@@ -206,6 +233,44 @@ func (a *AttributeModifier) ReadFrom(r io.Reader) (n int64, err error) {
 	return
 }
 
+func (p PlayerProperty) WriteTo(w io.Writer) (n int64, err error) {
+	return Tuple{p.Name, p.Value, p.IsSigned, Opt{
+		Has:   p.IsSigned,
+		Field: p.Signature,
+	}}.WriteTo(w)
+}
+
+func (p *PlayerProperty) ReadFrom(r io.Reader) (n int64, err error) {
+	return Tuple{&p.Name, &p.Value, &p.IsSigned, Opt{
+		Has:   &p.IsSigned,
+		Field: &p.Signature,
+	}}.ReadFrom(r)
+}
+
+func (c ChunkMeta) WriteTo(w io.Writer) (n int64, err error) {
+	return Tuple{c.ChunkX, c.ChunkZ, c.PrimaryBitMask}.WriteTo(w)
+}
+
+func (c *ChunkMeta) ReadFrom(r io.Reader) (n int64, err error) {
+	return Tuple{&c.ChunkX, &c.ChunkZ, &c.PrimaryBitMask}.ReadFrom(r)
+}
+
+func (b BlockRecord) WriteTo(w io.Writer) (n int64, err error) {
+	return Tuple{b.HorizontalPosition, b.Y, b.BlockID}.WriteTo(w)
+}
+
+func (b *BlockRecord) ReadFrom(r io.Reader) (n int64, err error) {
+	return Tuple{&b.HorizontalPosition, &b.Y, &b.BlockID}.ReadFrom(r)
+}
+
+func (e ExplosionRecord) WriteTo(w io.Writer) (n int64, err error) {
+	return Tuple{e.X, e.Y, e.Z}.WriteTo(w)
+}
+
+func (e *ExplosionRecord) ReadFrom(r io.Reader) (n int64, err error) {
+	return Tuple{&e.X, &e.Y, &e.Z}.ReadFrom(r)
+}
+
 const MaxVarIntLen = 5
 const MaxVarLongLen = 10
 
@@ -249,13 +314,23 @@ func (s *String) ReadFrom(r io.Reader) (n int64, err error) {
 	}
 	n += nn
 
-	bs := make([]byte, l)
-	if _, err := io.ReadFull(r, bs); err != nil {
+	if l < 0 {
+		return n, fmt.Errorf("%w: string of %d bytes", ErrInvalidLength, l)
+	}
+
+	if err := checkLimit("string length", int(l), limitsOf(r).MaxStringLength); err != nil {
 		return n, err
 	}
-	n += int64(l)
 
-	*s = String(bs)
+	// The buffer grows with the bytes actually read, so a broken length does not allocate all of it at once
+	var bs bytes.Buffer
+	nn, err = io.CopyN(&bs, r, int64(l))
+	n += nn
+	if err != nil {
+		return n, err
+	}
+
+	*s = String(bs.Bytes())
 	return n, nil
 }
 
@@ -421,8 +496,7 @@ func (s *SignedVarInt) ReadFrom(r io.Reader) (n int64, err error) {
 		return n, err
 	}
 
-	j := (i<<31>>31 ^ i) >> 1
-	*s = SignedVarInt(j ^ i)
+	*s = SignedVarInt(int32(uint32(i)>>1) ^ -(int32(i) & 1))
 	return
 }
 
@@ -573,13 +647,17 @@ func (n nbtField) WriteTo(w io.Writer) (int64, error) {
 }
 
 func (n nbtField) ReadFrom(r io.Reader) (int64, error) {
-	// LimitReader is used to count reader length
-	lr := &io.LimitedReader{R: r, N: math.MaxInt64}
-	_, err := nbt.NewDecoder(lr).Decode(n.V)
+	// The tag is checked against the limits before it is decoded
+	data, err := readNBT(r, limitsOf(r))
+	if err != nil {
+		return int64(len(data)), err
+	}
+
+	_, err = nbt.NewDecoder(bytes.NewReader(data)).Decode(n.V)
 	if err != nil && errors.Is(err, nbt.ErrEND) {
 		err = nil
 	}
-	return math.MaxInt64 - lr.N, err
+	return int64(len(data)), err
 }
 
 func (b ByteArray) WriteTo(w io.Writer) (n int64, err error) {
@@ -597,6 +675,12 @@ func (b *ByteArray) ReadFrom(r io.Reader) (n int64, err error) {
 	if err != nil {
 		return n1, err
 	}
+	if Len < 0 {
+		return n1, fmt.Errorf("%w: byte array of %d bytes", ErrInvalidLength, Len)
+	}
+	if err := checkLimit("byte array length", int(Len), limitsOf(r).MaxPacketLength); err != nil {
+		return n1, err
+	}
 	buf := bytes.NewBuffer(*b)
 	buf.Reset()
 	n2, err := io.CopyN(buf, r, int64(Len))
@@ -2,7 +2,10 @@ package packet
 
 import (
 	"bytes"
+	"errors"
+	"math"
 	"testing"
+	"testing/quick"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -29,3 +32,228 @@ func TestSlot_ReadFrom(t *testing.T) {
 	assert.Equal(t, int8(1), slot.ItemCount)
 	assert.Equal(t, int16(0), slot.ItemDamage)
 }
+
+// roundTrip writes the field and reads it back into the decoder, all of the bytes written must be read
+func roundTrip(t *testing.T, field FieldEncoder, decoded FieldDecoder) bool {
+	var buf bytes.Buffer
+	written, err := field.WriteTo(&buf)
+	if !assert.NoError(t, err) || !assert.Equal(t, int64(buf.Len()), written) {
+		return false
+	}
+
+	read, err := decoded.ReadFrom(&buf)
+	return assert.NoError(t, err) && assert.Equal(t, written, read) && assert.Zero(t, buf.Len())
+}
+
+func check(t *testing.T, property interface{}) {
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestVarInt_RoundTrip(t *testing.T) {
+	for value, length := range map[VarInt]int{0: 1, 127: 1, 128: 2, math.MaxInt32: 5, -1: 5, math.MinInt32: 5} {
+		var buf bytes.Buffer
+		_, _ = value.WriteTo(&buf)
+		assert.Equal(t, length, buf.Len(), "%d", value)
+	}
+
+	check(t, func(v int32) bool {
+		var decoded VarInt
+		return roundTrip(t, VarInt(v), &decoded) && decoded == VarInt(v)
+	})
+
+	check(t, func(v int64) bool {
+		var decoded VarLong
+		return roundTrip(t, VarLong(v), &decoded) && decoded == VarLong(v)
+	})
+
+	check(t, func(v int32) bool {
+		var decoded SignedVarInt
+		return roundTrip(t, SignedVarInt(v), &decoded) && decoded == SignedVarInt(v)
+	})
+
+	// Five bytes with the continuation bit set are not a VarInt
+	var decoded VarInt
+	_, err := decoded.ReadFrom(bytes.NewReader([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}))
+	assert.Error(t, err)
+}
+
+func TestNumbers_RoundTrip(t *testing.T) {
+	check(t, func(b bool, i8 int8, u8 uint8, i16 int16, u16 uint16, i32 int32, i64 int64, a int8) bool {
+		var (
+			boolean       Boolean
+			byteValue     Byte
+			unsignedByte  UnsignedByte
+			short         Short
+			unsignedShort UnsignedShort
+			integer       Int
+			long          Long
+			angle         Angle
+		)
+
+		return roundTrip(t, Boolean(b), &boolean) && boolean == Boolean(b) &&
+			roundTrip(t, Byte(i8), &byteValue) && byteValue == Byte(i8) &&
+			roundTrip(t, UnsignedByte(u8), &unsignedByte) && unsignedByte == UnsignedByte(u8) &&
+			roundTrip(t, Short(i16), &short) && short == Short(i16) &&
+			roundTrip(t, UnsignedShort(u16), &unsignedShort) && unsignedShort == UnsignedShort(u16) &&
+			roundTrip(t, Int(i32), &integer) && integer == Int(i32) &&
+			roundTrip(t, Long(i64), &long) && long == Long(i64) &&
+			roundTrip(t, Angle(a), &angle) && angle == Angle(a)
+	})
+
+	// Floats are compared bit by bit, so NaNs are covered as well
+	check(t, func(f32 uint32, f64 uint64) bool {
+		var (
+			float  Float
+			double Double
+		)
+
+		return roundTrip(t, Float(math.Float32frombits(f32)), &float) && math.Float32bits(float32(float)) == f32 &&
+			roundTrip(t, Double(math.Float64frombits(f64)), &double) && math.Float64bits(float64(double)) == f64
+	})
+}
+
+func TestPosition_RoundTrip(t *testing.T) {
+	check(t, func(x, y, z int32) bool {
+		// X and Z take 26 bits, Y takes 12 bits
+		position := Position{X: int(x) % (1 << 25), Y: int(y) % (1 << 11), Z: int(z) % (1 << 25)}
+
+		var decoded Position
+		return roundTrip(t, position, &decoded) && decoded == position
+	})
+
+	var decoded Position
+	roundTrip(t, Position{X: -33554432, Y: -2048, Z: 33554431}, &decoded)
+	assert.Equal(t, Position{X: -33554432, Y: -2048, Z: 33554431}, decoded)
+}
+
+func TestStrings_RoundTrip(t *testing.T) {
+	check(t, func(s string, b []byte, u [16]byte) bool {
+		var (
+			str       String
+			byteArray ByteArray
+			id        UUID
+		)
+
+		return roundTrip(t, String(s), &str) && str == String(s) &&
+			roundTrip(t, ByteArray(b), &byteArray) && bytes.Equal(byteArray, b) &&
+			roundTrip(t, UUID(u), &id) && id == UUID(u)
+	})
+
+	// Lengths are checked before anything is allocated
+	var str String
+	_, err := str.ReadFrom(bytes.NewReader([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x0F}))
+	assert.True(t, errors.Is(err, ErrInvalidLength))
+
+	_, err = str.ReadFrom(bytes.NewReader([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x07, 'a'}))
+	assert.Error(t, err)
+}
+
+func TestSlot_RoundTrip(t *testing.T) {
+	check(t, func(id int16, count int8, damage int16) bool {
+		slot := Slot{BlockID: id, ItemCount: count, ItemDamage: damage}
+		if slot.IsEmpty() {
+			slot = Slot{BlockID: -1}
+		}
+
+		var decoded Slot
+		return roundTrip(t, slot, &decoded) &&
+			decoded.BlockID == slot.BlockID && decoded.ItemCount == slot.ItemCount &&
+			decoded.ItemDamage == slot.ItemDamage && decoded.ItemData.Type == slot.ItemData.Type
+	})
+}
+
+func TestEntityMetadata_RoundTrip(t *testing.T) {
+	check(t, func(b int8, s int16, i int32, f float32, str string, x, y, z int32) bool {
+		metadata := EntityMetadata{
+			0:  MetadataByte(b),
+			1:  MetadataShort(s),
+			2:  MetadataInt(i),
+			3:  MetadataFloat(f),
+			10: MetadataString(str),
+			11: MetadataSlot{BlockID: -1},
+			12: MetadataIntPos{X: Int(x), Y: Int(y), Z: Int(z)},
+			31: MetadataFloatPos{X: Float(f), Y: Float(f), Z: Float(f)},
+		}
+
+		decoded := make(EntityMetadata)
+		return roundTrip(t, metadata, &decoded) && assert.Equal(t, metadata, decoded)
+	})
+
+	// Encoding does not depend on the order of the map
+	var first, second bytes.Buffer
+	metadata := EntityMetadata{0: MetadataByte(1), 1: MetadataShort(2), 2: MetadataInt(3), 6: MetadataFloat(4)}
+	_, _ = metadata.WriteTo(&first)
+	for i := 0; i < 16; i++ {
+		second.Reset()
+		_, _ = metadata.WriteTo(&second)
+		assert.Equal(t, first.Bytes(), second.Bytes())
+	}
+}
+
+func TestAry_RoundTrip(t *testing.T) {
+	check(t, func(values []int32) bool {
+		array := make([]VarInt, 0, len(values))
+		for _, v := range values {
+			array = append(array, VarInt(v))
+		}
+
+		var (
+			length  VarInt
+			decoded []VarInt
+		)
+
+		return roundTrip(t, Tuple{VarInt(len(array)), Ary{Ary: array}}, Tuple{&length, &Ary{Len: &length, Ary: &decoded}}) &&
+			len(decoded) == len(array) && (len(array) == 0 || assert.Equal(t, array, decoded))
+	})
+
+	// A slice with enough capacity is reused, and elements are read only as long as there is data
+	decoded := make([]Int, 1, 8)
+	_, err := Ary{Len: 2, Ary: &decoded}.ReadFrom(bytes.NewReader([]byte{0, 0, 0, 1, 0, 0, 0, 2}))
+	assert.NoError(t, err)
+	assert.Equal(t, []Int{1, 2}, decoded)
+
+	_, err = Ary{Len: math.MaxInt32, Ary: &decoded}.ReadFrom(bytes.NewReader([]byte{0, 0, 0, 1}))
+	assert.Error(t, err)
+
+	_, err = Ary{Len: -1, Ary: &decoded}.ReadFrom(bytes.NewReader(nil))
+	assert.True(t, errors.Is(err, ErrInvalidLength))
+}
+
+func TestOpt_RoundTrip(t *testing.T) {
+	check(t, func(has bool, v int32) bool {
+		var decoded Int
+		ok := roundTrip(t, Tuple{Boolean(has), Opt{Has: has, Field: Int(v)}}, Tuple{(*Boolean)(&has), Opt{Has: &has, Field: &decoded}})
+		if !has {
+			return ok && decoded == 0
+		}
+
+		return ok && decoded == Int(v)
+	})
+
+	// Conditions are evaluated when the field is reached
+	var (
+		kind  String
+		value Int
+	)
+
+	_, err := Tuple{&kind, Opt{Has: func() bool { return kind == "EntityHorse" }, Field: &value}}.
+		ReadFrom(bytes.NewReader([]byte{11, 'E', 'n', 't', 'i', 't', 'y', 'H', 'o', 'r', 's', 'e', 0, 0, 0, 7}))
+	assert.NoError(t, err)
+	assert.Equal(t, Int(7), value)
+}
+
+func TestTuple_RoundTrip(t *testing.T) {
+	check(t, func(i int32, s string, b bool, d float64) bool {
+		var (
+			integer Int
+			str     String
+			boolean Boolean
+			double  Double
+		)
+
+		return roundTrip(t, Tuple{Int(i), String(s), Boolean(b), Double(d)}, Tuple{&integer, &str, &boolean, &double}) &&
+			integer == Int(i) && str == String(s) && boolean == Boolean(b) && double == Double(d)
+	})
+}
@@ -2,10 +2,14 @@ package packet
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"reflect"
 )
 
+// aryPreallocation is the number of elements allocated before any of them is read
+const aryPreallocation = 1024
+
 // Ary is used to send or receive the packet field like "Array of X"
 // which has a count must be known from the context.
 //
@@ -64,16 +68,31 @@ func (a Ary) ReadFrom(r io.Reader) (n int64, err error) {
 	if !array.CanAddr() {
 		panic(errors.New("the contents of the Ary are not addressable"))
 	}
-	if array.Cap() < length {
-		array.Set(reflect.MakeSlice(array.Type(), length, length))
+	if length < 0 {
+		return 0, fmt.Errorf("%w: array of %d elements", ErrInvalidLength, length)
+	}
+	if err := checkLimit("array length", length, limitsOf(r).MaxArrayLength); err != nil {
+		return 0, err
+	}
+
+	// Elements are appended as they are read, so a broken length does not allocate all of them at once
+	size := length
+	if size > aryPreallocation {
+		size = aryPreallocation
+	}
+	if array.Cap() < size {
+		array.Set(reflect.MakeSlice(array.Type(), 0, size))
+	} else {
+		array.Set(array.Slice(0, 0))
 	}
 	for i := 0; i < length; i++ {
-		elem := array.Index(i)
-		nn, err := elem.Addr().Interface().(FieldDecoder).ReadFrom(r)
+		elem := reflect.New(array.Type().Elem())
+		nn, err := elem.Interface().(FieldDecoder).ReadFrom(r)
 		n += nn
 		if err != nil {
 			return n, err
 		}
+		array.Set(reflect.Append(array, elem.Elem()))
 	}
 	return n, err
 }
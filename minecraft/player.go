@@ -0,0 +1,156 @@
+package minecraft
+
+import (
+	"time"
+
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/google/uuid"
+)
+
+// TickDuration is the duration of a single server tick
+const TickDuration = 50 * time.Millisecond
+
+type GameMode int
+
+const (
+	Survival GameMode = iota
+	Creative
+	Adventure
+	Spectator
+)
+
+// GameModeHardcore is set in the game mode of Join Game when the world is hardcore
+const GameModeHardcore = 0x08
+
+type AbilityFlags byte
+
+const (
+	AbilityInvulnerable AbilityFlags = 1 << iota
+	AbilityFlying
+	AbilityAllowFlying
+	AbilityCreativeMode
+)
+
+func (f AbilityFlags) Has(flag AbilityFlags) bool {
+	return f&flag != 0
+}
+
+type Abilities struct {
+	Flags               AbilityFlags
+	FlyingSpeed         float64
+	FieldOfViewModifier float64
+}
+
+type Experience struct {
+	// Bar is the progress towards the next level, from 0 to 1
+	Bar   float64
+	Level int
+	Total int
+}
+
+// InfiniteEffectDuration is the duration in ticks of effects that never expire
+const InfiniteEffectDuration = 32767
+
+type PotionEffect struct {
+	EffectID      int
+	Amplifier     int
+	HideParticles bool
+	// Expiry is zero for infinite effects
+	Expiry time.Time
+}
+
+func NewPotionEffect(effectID, amplifier, duration int, hideParticles bool) PotionEffect {
+	effect := PotionEffect{
+		EffectID:      effectID,
+		Amplifier:     amplifier,
+		HideParticles: hideParticles,
+	}
+
+	if duration < InfiniteEffectDuration {
+		effect.Expiry = time.Now().Add(time.Duration(duration) * TickDuration)
+	}
+
+	return effect
+}
+
+func (e PotionEffect) IsInfinite() bool {
+	return e.Expiry.IsZero()
+}
+
+func (e PotionEffect) IsExpired() bool {
+	return !e.IsInfinite() && !time.Now().Before(e.Expiry)
+}
+
+// Remaining returns the time left until the effect expires, it is meaningless for infinite effects
+func (e PotionEffect) Remaining() time.Duration {
+	if e.IsExpired() {
+		return 0
+	}
+
+	return time.Until(e.Expiry)
+}
+
+type AttributeOperation int
+
+const (
+	AttributeAdd AttributeOperation = iota
+	AttributeAddMultiplied
+	AttributeMultiply
+)
+
+type AttributeModifier struct {
+	UUID      uuid.UUID
+	Amount    float64
+	Operation AttributeOperation
+}
+
+type Attribute struct {
+	Key       string
+	Base      float64
+	Modifiers []AttributeModifier
+}
+
+// Value applies the modifiers to the base value the same way the client does
+func (a Attribute) Value() float64 {
+	base := a.Base
+	for _, modifier := range a.Modifiers {
+		if modifier.Operation == AttributeAdd {
+			base += modifier.Amount
+		}
+	}
+
+	value := base
+	for _, modifier := range a.Modifiers {
+		if modifier.Operation == AttributeAddMultiplied {
+			value += base * modifier.Amount
+		}
+	}
+
+	for _, modifier := range a.Modifiers {
+		if modifier.Operation == AttributeMultiply {
+			value *= 1 + modifier.Amount
+		}
+	}
+
+	return value
+}
+
+func NewAttribute(property pk.Property) Attribute {
+	attribute := Attribute{Key: string(property.Key), Base: float64(property.Value)}
+	for _, modifier := range property.Modifiers {
+		attribute.Modifiers = append(attribute.Modifiers, AttributeModifier{
+			UUID:      uuid.UUID(modifier.UUID),
+			Amount:    float64(modifier.Amount),
+			Operation: AttributeOperation(modifier.Operation),
+		})
+	}
+
+	return attribute
+}
+
+// Copy returns an attribute that does not share modifiers with the original one
+func (a Attribute) Copy() Attribute {
+	attribute := a
+	attribute.Modifiers = append([]AttributeModifier(nil), a.Modifiers...)
+	return attribute
+}
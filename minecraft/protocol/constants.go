@@ -8,6 +8,32 @@ const (
 	ChatPositionAboveHotbar
 )
 
+type PlayerListAction int32
+
+const (
+	PlayerListAddPlayer PlayerListAction = iota
+	PlayerListUpdateGameMode
+	PlayerListUpdateLatency
+	PlayerListUpdateDisplayName
+	PlayerListRemovePlayer
+)
+
+type GameStateReason byte
+
+const (
+	GameStateInvalidBed GameStateReason = iota
+	GameStateEndRaining
+	GameStateBeginRaining
+	GameStateChangeGameMode
+	GameStateEnterCredits
+	GameStateDemoMessage
+	GameStateArrowHitPlayer
+	GameStateFadeValue
+	GameStateFadeTime
+	_
+	GameStateMobAppearance
+)
+
 type ConnectionState int
 
 const (
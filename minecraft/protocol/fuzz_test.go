@@ -0,0 +1,32 @@
+//go:build go1.18
+// +build go1.18
+
+package protocol
+
+import (
+	"testing"
+
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+// FuzzPackets checks Read(Marshal(x)) == x for every structure x that could be read from some data
+func FuzzPackets(f *testing.F) {
+	for i, sample := range samples {
+		f.Add(uint(i), sample.packet.Marshal().Data)
+	}
+
+	f.Fuzz(func(t *testing.T, index uint, data []byte) {
+		sample := samples[index%uint(len(samples))]
+		decoded, _ := NewPacket(sample.state, sample.direction, sample.id)
+		if err := decoded.Read(pk.Packet{ID: sample.id, Data: data}); err != nil {
+			return
+		}
+
+		// Values are compared by their encoding, as NaN floats are never equal
+		marshalled := decoded.Marshal()
+		again, _ := NewPacket(sample.state, sample.direction, sample.id)
+		assert.NoError(t, again.Read(marshalled), "%T", decoded)
+		assert.Equal(t, marshalled.Data, again.Marshal().Data, "%T", decoded)
+	})
+}
@@ -0,0 +1,418 @@
+//go:build generate
+// +build generate
+
+// gen_packets.go generates packet IDs, names, structures and their registry from the catalogue in packets.json,
+// as well as round-trip tests for every structure
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+const (
+	//language=gohtml
+	packetsTmpl = `// Code generated by gen_packets.go DO NOT EDIT.
+
+package protocol
+
+import (
+	{{- if .UsesChat }}
+	"github.com/Tnze/go-mc/chat"{{ end }}
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+)
+{{ range .Groups }}
+// {{ .Direction }} packets of the {{ lower .State }} state
+const (
+	{{- range .Packets }}
+	{{ .Const }} = {{ hex .ID }}{{ end }}
+)
+{{ end }}
+var (
+	{{- range .Groups }}{{ if .Names }}
+	{{ .Names }} = PacketMap{
+		{{- range .Packets }}
+		{{ hex .ID }}: {Name: "{{ .Name }}"},{{ if .Comment }} // {{ .Comment }}{{ end }}{{ end }}
+	}
+	{{ end }}{{ end }}
+)
+{{ range .Structs }}
+{{ .Doc }}type {{ .Struct }} struct{{ if or .Embeds .Fields }} {
+	{{- if .Embeds }}
+	{{ .Embeds }}{{ end }}
+	{{- range .Fields }}
+	{{ .Name }} {{ .GoType }}{{ end }}
+}{{ else }}{}{{ end }}
+{{ if .Embeds }}
+func ({{ .Receiver }} *{{ .Struct }}) Marshal() pk.Packet {
+	packet := {{ .Receiver }}.{{ .Embeds }}.Marshal()
+	packet.ID = {{ .Const }}
+	return packet
+}
+{{ else if not .Custom }}
+{{- if .Fields }}
+func ({{ .Receiver }} *{{ .Struct }}) Read(packet pk.Packet) error {
+	{{- range .Prelude }}
+	{{ . }}{{ end }}
+	return packet.Scan({{ .ReadArgs }})
+}
+{{ else }}
+func ({{ .Receiver }} *{{ .Struct }}) Read(_ pk.Packet) error {
+	return nil
+}
+{{ end }}
+func ({{ .Receiver }} *{{ .Struct }}) Marshal() pk.Packet {
+	return pk.Marshal({{ .Const }}{{ .MarshalArgs }})
+}
+{{ end }}{{ end }}
+type packetKey struct {
+	state     ConnectionState
+	direction int
+	id        int32
+}
+
+var packets = map[packetKey]func() Packet{
+	{{- range .Structs }}
+	{ConnState{{ .State }}, {{ .Conn }}, {{ .Const }}}: func() Packet { return &{{ .Struct }}{} },{{ end }}
+}
+
+// NewPacket returns an empty structure of the packet, which is ready to be read
+func NewPacket(state ConnectionState, direction int, id int32) (Packet, bool) {
+	newPacket, ok := packets[packetKey{state: state, direction: direction, id: id}]
+	if !ok {
+		return nil, false
+	}
+
+	return newPacket(), true
+}
+`
+
+	//language=gohtml
+	testsTmpl = `// Code generated by gen_packets.go DO NOT EDIT.
+
+package protocol
+
+import (
+	"testing"
+	{{ if .UsesChat }}
+	"github.com/Tnze/go-mc/chat"{{ end }}
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+var samples = []struct {
+	state     ConnectionState
+	direction int
+	id        int32
+	packet    Packet
+}{
+	{{- range .Structs }}
+	{ConnState{{ .State }}, {{ .Conn }}, {{ .Const }}, &{{ .Struct }}{ {{- .Sample -}} }},{{ end }}
+}
+
+func TestPackets_RoundTrip(t *testing.T) {
+	assert.Len(t, samples, len(packets))
+	for _, sample := range samples {
+		packet := sample.packet.Marshal()
+		assert.Equal(t, sample.id, packet.ID)
+
+		decoded, ok := NewPacket(sample.state, sample.direction, sample.id)
+		assert.True(t, ok)
+		assert.NoError(t, decoded.Read(packet), "%T", sample.packet)
+		assert.Equal(t, sample.packet, decoded)
+		assert.Equal(t, packet.Data, decoded.Marshal().Data, "%T", sample.packet)
+	}
+}
+`
+)
+
+// fieldType describes how a type of the catalogue is declared and which value it is tested with
+type fieldType struct {
+	GoType string
+	Sample string
+	// Pointer is set for types that are written through a pointer
+	Pointer bool
+}
+
+var fieldTypes = map[string]fieldType{
+	"Boolean":           {GoType: "pk.Boolean", Sample: "true"},
+	"Byte":              {GoType: "pk.Byte", Sample: "7"},
+	"UnsignedByte":      {GoType: "pk.UnsignedByte", Sample: "7"},
+	"Short":             {GoType: "pk.Short", Sample: "7"},
+	"UnsignedShort":     {GoType: "pk.UnsignedShort", Sample: "7"},
+	"Int":               {GoType: "pk.Int", Sample: "7"},
+	"Long":              {GoType: "pk.Long", Sample: "7"},
+	"Float":             {GoType: "pk.Float", Sample: "7"},
+	"Double":            {GoType: "pk.Double", Sample: "7"},
+	"VarInt":            {GoType: "pk.VarInt", Sample: "7"},
+	"VarLong":           {GoType: "pk.VarLong", Sample: "7"},
+	"Angle":             {GoType: "pk.Angle", Sample: "7"},
+	"String":            {GoType: "pk.String", Sample: `"kogtevran"`},
+	"ByteArray":         {GoType: "pk.ByteArray", Sample: "pk.ByteArray{1, 2, 3}"},
+	"PluginMessageData": {GoType: "pk.PluginMessageData", Sample: "pk.PluginMessageData{1, 2, 3}", Pointer: true},
+	"UUID":              {GoType: "pk.UUID", Sample: "pk.UUID{1, 2, 3}"},
+	"Position":          {GoType: "pk.Position", Sample: "pk.Position{X: 1, Y: 2, Z: 3}"},
+	"Slot":              {GoType: "pk.Slot", Sample: "pk.Slot{BlockID: -1}"},
+	"EntityMetadata":    {GoType: "pk.EntityMetadata", Sample: "pk.EntityMetadata{}"},
+	"Chat":              {GoType: "chat.Message", Sample: "chat.Message{}"},
+	"Property":          {GoType: "pk.Property", Sample: `pk.Property{Key: "kogtevran", Value: 7}`},
+	"BlockRecord":       {GoType: "pk.BlockRecord", Sample: "pk.BlockRecord{HorizontalPosition: 7, Y: 7, BlockID: 7}"},
+	"ChunkMeta":         {GoType: "pk.ChunkMeta", Sample: "pk.ChunkMeta{ChunkX: 7, ChunkZ: 7, PrimaryBitMask: 7}"},
+	"ExplosionRecord":   {GoType: "pk.ExplosionRecord", Sample: "pk.ExplosionRecord{X: 7, Y: 7, Z: 7}"},
+}
+
+type Field struct {
+	// Name is either a single name or several names of the same type, e.g. "X, Y, Z"
+	Name string `json:"name"`
+	// Type is a name of fieldTypes, a slice of one, or a type of the package
+	Type string `json:"type"`
+	// Length is the type of the length that precedes a slice
+	Length string `json:"length"`
+	// Optional is a method of the structure that tells if the field is sent
+	Optional string `json:"optional"`
+
+	GoType string `json:"-"`
+}
+
+type PacketInfo struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Comment string `json:"comment"`
+	// Const is the name of the ID constant without the direction, the name of the packet is used by default
+	Const  string   `json:"const"`
+	Struct string   `json:"struct"`
+	Doc    string   `json:"doc"`
+	Fields []*Field `json:"fields"`
+	// Embeds is a structure of the other direction that is encoded the same way
+	Embeds string `json:"embeds"`
+	// Custom structures have their Read and Marshal methods written by hand
+	Custom bool `json:"custom"`
+
+	State, Conn string   `json:"-"`
+	Receiver    string   `json:"-"`
+	Prelude     []string `json:"-"`
+	ReadArgs    string   `json:"-"`
+	MarshalArgs string   `json:"-"`
+	Sample      string   `json:"-"`
+}
+
+type Group struct {
+	State     string        `json:"state"`
+	Direction string        `json:"direction"`
+	Names     string        `json:"names"`
+	Packets   []*PacketInfo `json:"packets"`
+}
+
+type Catalogue struct {
+	Groups   []*Group
+	Structs  []*PacketInfo
+	UsesChat bool
+}
+
+var nonAlphanumeric = regexp.MustCompile("[^A-Za-z0-9]")
+
+func names(field *Field) []string {
+	return strings.Split(strings.ReplaceAll(field.Name, " ", ""), ",")
+}
+
+func lowerFirst(s string) string {
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func resolveType(name string) (fieldType, bool) {
+	t, ok := fieldTypes[name]
+	if !ok {
+		// Types of the protocol package itself
+		return fieldType{GoType: name, Sample: name + "{}"}, false
+	}
+
+	return t, true
+}
+
+func prepareStruct(p *PacketInfo, structs map[string]*PacketInfo, usesChat *bool) error {
+	p.Receiver = lowerFirst(p.Struct[:1])
+	if p.Embeds != "" {
+		embedded, ok := structs[p.Embeds]
+		if !ok {
+			return fmt.Errorf("%s embeds unknown structure %s", p.Struct, p.Embeds)
+		}
+
+		p.Sample = fmt.Sprintf("%s: %s{%s}", p.Embeds, p.Embeds, embedded.Sample)
+		return nil
+	}
+
+	var readArgs, marshalArgs, samples []string
+	for _, field := range p.Fields {
+		elementType := strings.TrimPrefix(field.Type, "[]")
+		isSlice := elementType != field.Type
+		t, _ := resolveType(elementType)
+		if elementType == "Chat" {
+			*usesChat = true
+		}
+
+		field.GoType = t.GoType
+		sample := t.Sample
+		if isSlice {
+			field.GoType = "[]" + t.GoType
+			sample = fmt.Sprintf("%s{%s}", field.GoType, t.Sample)
+		}
+
+		var reads, writes []string
+		for _, name := range names(field) {
+			reads = append(reads, fmt.Sprintf("&%s.%s", p.Receiver, name))
+			if t.Pointer {
+				writes = append(writes, fmt.Sprintf("&%s.%s", p.Receiver, name))
+			} else {
+				writes = append(writes, fmt.Sprintf("%s.%s", p.Receiver, name))
+			}
+
+			if field.Optional == "" {
+				samples = append(samples, fmt.Sprintf("%s: %s", name, sample))
+			}
+
+			if elementType == "EntityMetadata" {
+				p.Prelude = append(p.Prelude, fmt.Sprintf("%s.%s = make(pk.EntityMetadata)", p.Receiver, name))
+			}
+		}
+
+		switch {
+		case isSlice && field.Length != "":
+			if len(reads) > 1 {
+				return fmt.Errorf("%s: slices must be declared one by one", p.Struct)
+			}
+
+			length, ok := resolveType(field.Length)
+			if !ok {
+				return fmt.Errorf("%s: unknown length type %s", p.Struct, field.Length)
+			}
+
+			lengthVar := lowerFirst(names(field)[0]) + "Len"
+			p.Prelude = append(p.Prelude, fmt.Sprintf("var %s %s", lengthVar, length.GoType))
+			reads = []string{"&" + lengthVar, fmt.Sprintf("&pk.Ary{Len: &%s, Ary: %s}", lengthVar, reads[0])}
+			writes = []string{fmt.Sprintf("%s(len(%s))", length.GoType, writes[0]), fmt.Sprintf("pk.Ary{Ary: %s}", writes[0])}
+		case isSlice && !p.Custom:
+			return fmt.Errorf("%s: length of %s is not known", p.Struct, field.Name)
+		case field.Optional != "":
+			has := fmt.Sprintf("%s.%s", p.Receiver, field.Optional)
+			read, write := reads[0], writes[0]
+			if len(reads) > 1 {
+				read = fmt.Sprintf("pk.Tuple{%s}", strings.Join(reads, ", "))
+				write = fmt.Sprintf("pk.Tuple{%s}", strings.Join(writes, ", "))
+			}
+
+			reads = []string{fmt.Sprintf("pk.Opt{Has: %s, Field: %s}", has, read)}
+			writes = []string{fmt.Sprintf("pk.Opt{Has: %s, Field: %s}", has, write)}
+		}
+
+		readArgs = append(readArgs, reads...)
+		marshalArgs = append(marshalArgs, writes...)
+	}
+
+	p.ReadArgs = strings.Join(readArgs, ", ")
+	if len(marshalArgs) > 0 {
+		p.MarshalArgs = ", " + strings.Join(marshalArgs, ", ")
+	}
+
+	p.Sample = strings.Join(samples, ", ")
+	if p.Doc != "" {
+		p.Doc = "// " + strings.ReplaceAll(p.Doc, "\n", "\n// ") + "\n"
+	}
+
+	return nil
+}
+
+func readCatalogue(path string) (*Catalogue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	catalogue := &Catalogue{}
+	if err := json.Unmarshal(data, &catalogue.Groups); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	structs := make(map[string]*PacketInfo)
+	for _, group := range catalogue.Groups {
+		conn := map[string]string{"Clientbound": "ConnS2C", "Serverbound": "ConnC2S"}[group.Direction]
+		if conn == "" {
+			return nil, fmt.Errorf("unknown direction %s", group.Direction)
+		}
+
+		ids := make(map[int]bool)
+		for _, packet := range group.Packets {
+			if ids[packet.ID] {
+				return nil, fmt.Errorf("%s %s packet %#x is declared twice", group.State, group.Direction, packet.ID)
+			}
+
+			ids[packet.ID] = true
+			if packet.Const == "" {
+				packet.Const = nonAlphanumeric.ReplaceAllString(packet.Name, "")
+			}
+
+			packet.Const = group.Direction + packet.Const
+			packet.State, packet.Conn = group.State, conn
+			if packet.Struct == "" {
+				continue
+			}
+
+			if _, ok := structs[packet.Struct]; ok {
+				return nil, fmt.Errorf("structure %s is declared twice", packet.Struct)
+			}
+
+			// Embedded structures are declared before the ones embedding them
+			if err := prepareStruct(packet, structs, &catalogue.UsesChat); err != nil {
+				return nil, err
+			}
+
+			structs[packet.Struct] = packet
+			catalogue.Structs = append(catalogue.Structs, packet)
+		}
+	}
+
+	return catalogue, nil
+}
+
+func generate(name, tmpl string, catalogue *Catalogue) error {
+	funcs := template.FuncMap{
+		"lower": strings.ToLower,
+		"hex": func(id int) string {
+			return fmt.Sprintf("0x%02X", id)
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := template.Must(template.New(name).Funcs(funcs).Parse(tmpl)).Execute(&buf, catalogue); err != nil {
+		return err
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return os.WriteFile(name, source, 0644)
+}
+
+//go:generate go run $GOFILE
+func main() {
+	catalogue, err := readCatalogue("packets.json")
+	if err == nil {
+		err = generate("packets_gen.go", packetsTmpl, catalogue)
+	}
+
+	if err == nil {
+		err = generate("packets_gen_test.go", testsTmpl, catalogue)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,52 @@
+package protocol
+
+import (
+	"sync"
+
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+)
+
+// DefaultLimits bound the packets of each state, both directions are decoded with the same limits.
+// Packets before the play state are small, so a peer cannot make the proxy allocate much before it logs in.
+// Those of the play state are pk.DefaultLimits
+var DefaultLimits = map[ConnectionState]*pk.Limits{
+	// The address takes 255 characters at most, Forge appends its marker to it
+	ConnStateHandshake: {
+		MaxPacketLength: 1 << 12,
+		MaxStringLength: 1 << 11,
+	},
+	// The status response carries the favicon
+	ConnStateStatus: {
+		MaxPacketLength: 1 << 18,
+		MaxStringLength: 32767 * 4,
+	},
+	ConnStateLogin: {
+		MaxPacketLength: 1 << 18,
+		MaxStringLength: 32767 * 4,
+	},
+	ConnStatePlay: pk.DefaultLimits,
+}
+
+var (
+	limitsLock sync.RWMutex
+	limits     = DefaultLimits
+)
+
+// SetLimits replaces the limits of every state, connections are checked against them from their next state on.
+// States left out have pk.DefaultLimits
+func SetLimits(states map[ConnectionState]*pk.Limits) {
+	limitsLock.Lock()
+	defer limitsLock.Unlock()
+	limits = states
+}
+
+// GetLimits returns the limits of the state, pk.DefaultLimits if the state has none
+func GetLimits(state ConnectionState) *pk.Limits {
+	limitsLock.RLock()
+	defer limitsLock.RUnlock()
+	if stateLimits, ok := limits[state]; ok {
+		return stateLimits
+	}
+
+	return pk.DefaultLimits
+}
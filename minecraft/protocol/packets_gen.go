@@ -0,0 +1,1174 @@
+// Code generated by gen_packets.go DO NOT EDIT.
+
+package protocol
+
+import (
+	"github.com/Tnze/go-mc/chat"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+)
+
+// Serverbound packets of the handshake state
+const (
+	ServerboundHandshake = 0x00
+)
+
+// Clientbound packets of the status state
+const (
+	ClientboundStatusResponse = 0x00
+	ClientboundStatusPong     = 0x01
+)
+
+// Serverbound packets of the status state
+const (
+	ServerboundStatusRequest = 0x00
+	ServerboundStatusPing    = 0x01
+)
+
+// Clientbound packets of the login state
+const (
+	ClientboundLoginDisconnect     = 0x00
+	ClientboundEncryptionRequest   = 0x01
+	ClientboundLoginSuccess        = 0x02
+	ClientboundLoginSetCompression = 0x03
+)
+
+// Serverbound packets of the login state
+const (
+	ServerboundLoginStart         = 0x00
+	ServerboundEncryptionResponse = 0x01
+)
+
+// Clientbound packets of the play state
+const (
+	ClientboundKeepAlive                 = 0x00
+	ClientboundJoinGame                  = 0x01
+	ClientboundChatMessage               = 0x02
+	ClientboundTimeUpdate                = 0x03
+	ClientboundEntityEquipment           = 0x04
+	ClientboundSpawnPosition             = 0x05
+	ClientboundUpdateHealth              = 0x06
+	ClientboundRespawn                   = 0x07
+	ClientboundPlayerPositionAndLook     = 0x08
+	ClientboundHeldItemChange            = 0x09
+	ClientboundUseBed                    = 0x0A
+	ClientboundAnimation                 = 0x0B
+	ClientboundSpawnPlayer               = 0x0C
+	ClientboundCollectItem               = 0x0D
+	ClientboundSpawnObject               = 0x0E
+	ClientboundSpawnMob                  = 0x0F
+	ClientboundSpawnPainting             = 0x10
+	ClientboundSpawnExperienceOrb        = 0x11
+	ClientboundEntityVelocity            = 0x12
+	ClientboundDestroyEntities           = 0x13
+	ClientboundEntity                    = 0x14
+	ClientboundEntityRelativeMove        = 0x15
+	ClientboundEntityLook                = 0x16
+	ClientboundEntityLookAndRelativeMove = 0x17
+	ClientboundEntityTeleport            = 0x18
+	ClientboundEntityHeadLook            = 0x19
+	ClientboundEntityStatus              = 0x1A
+	ClientboundAttachEntity              = 0x1B
+	ClientboundEntityMetadata            = 0x1C
+	ClientboundEntityEffect              = 0x1D
+	ClientboundRemoveEntityEffect        = 0x1E
+	ClientboundSetExperience             = 0x1F
+	ClientboundEntityProperties          = 0x20
+	ClientboundChunkData                 = 0x21
+	ClientboundMultiBlockChange          = 0x22
+	ClientboundBlockChange               = 0x23
+	ClientboundBlockAction               = 0x24
+	ClientboundBlockBreakAnimation       = 0x25
+	ClientboundMapChunkBulk              = 0x26
+	ClientboundExplosion                 = 0x27
+	ClientboundEffect                    = 0x28
+	ClientboundSoundEffect               = 0x29
+	ClientboundParticle                  = 0x2A
+	ClientboundChangeGameState           = 0x2B
+	ClientboundSpawnGlobalEntity         = 0x2C
+	ClientboundOpenWindow                = 0x2D
+	ClientboundCloseWindow               = 0x2E
+	ClientboundSetSlot                   = 0x2F
+	ClientboundWindowItems               = 0x30
+	ClientboundWindowProperty            = 0x31
+	ClientboundConfirmTransaction        = 0x32
+	ClientboundUpdateSign                = 0x33
+	ClientboundMap                       = 0x34
+	ClientboundUpdateBlockEntity         = 0x35
+	ClientboundOpenSignEditor            = 0x36
+	ClientboundStatistics                = 0x37
+	ClientboundPlayerListItem            = 0x38
+	ClientboundPlayerAbilities           = 0x39
+	ClientboundTabComplete               = 0x3A
+	ClientboundScoreboardObjective       = 0x3B
+	ClientboundUpdateScore               = 0x3C
+	ClientboundDisplayScoreboard         = 0x3D
+	ClientboundTeams                     = 0x3E
+	ClientboundPluginMessage             = 0x3F
+	ClientboundDisconnect                = 0x40
+	ClientboundServerDifficulty          = 0x41
+	ClientboundCombatEvent               = 0x42
+	ClientboundCamera                    = 0x43
+	ClientboundWorldBorder               = 0x44
+	ClientboundTitle                     = 0x45
+	ClientboundSetCompression            = 0x46
+	ClientboundPlayerListHeaderAndFooter = 0x47
+	ClientboundResourcePackSend          = 0x48
+	ClientboundUpdateEntityNBT           = 0x49
+)
+
+// Serverbound packets of the play state
+const (
+	ServerboundKeepAlive               = 0x00
+	ServerboundChatMessage             = 0x01
+	ServerboundUseEntity               = 0x02
+	ServerboundPlayer                  = 0x03
+	ServerboundPlayerPosition          = 0x04
+	ServerboundPlayerLook              = 0x05
+	ServerboundPlayerPositionAndLook   = 0x06
+	ServerboundPlayerDigging           = 0x07
+	ServerboundPlayerBlockPlacement    = 0x08
+	ServerboundHeldItemChange          = 0x09
+	ServerboundAnimation               = 0x0A
+	ServerboundEntityAction            = 0x0B
+	ServerboundSteerVehicle            = 0x0C
+	ServerboundCloseWindow             = 0x0D
+	ServerboundClickWindow             = 0x0E
+	ServerboundConfirmTransaction      = 0x0F
+	ServerboundCreativeInventoryAction = 0x10
+	ServerboundEnchantItem             = 0x11
+	ServerboundUpdateSign              = 0x12
+	ServerboundPlayerAbilities         = 0x13
+	ServerboundTabComplete             = 0x14
+	ServerboundClientSettings          = 0x15
+	ServerboundClientStatus            = 0x16
+	ServerboundPluginMessage           = 0x17
+	ServerboundSpectate                = 0x18
+	ServerboundResourcePackStatus      = 0x19
+)
+
+var (
+	PlayPacketsS2C = PacketMap{
+		0x00: {Name: "Keep Alive"},
+		0x01: {Name: "Join Game"},
+		0x02: {Name: "Chat Message"},
+		0x03: {Name: "Time Update"},
+		0x04: {Name: "Entity Equipment"},
+		0x05: {Name: "Spawn Position"},
+		0x06: {Name: "Update Health"},
+		0x07: {Name: "Respawn"},
+		0x08: {Name: "Player Position And Look"},
+		0x09: {Name: "Held Item Change"},
+		0x0A: {Name: "Use Bed"},
+		0x0B: {Name: "Animation"},
+		0x0C: {Name: "Spawn Player"},
+		0x0D: {Name: "Collect Item"},
+		0x0E: {Name: "Spawn Object"},
+		0x0F: {Name: "Spawn Mob"},
+		0x10: {Name: "Spawn Painting"},
+		0x11: {Name: "Spawn Experience Orb"},
+		0x12: {Name: "Entity Velocity"},
+		0x13: {Name: "Destroy Entities"},
+		0x14: {Name: "Entity"},
+		0x15: {Name: "Entity Relative Move"},
+		0x16: {Name: "Entity Look"},
+		0x17: {Name: "Entity Look And Relative Move"},
+		0x18: {Name: "Entity Teleport"},
+		0x19: {Name: "Entity Head Look"},
+		0x1A: {Name: "Entity Status"},
+		0x1B: {Name: "Attach Entity"},
+		0x1C: {Name: "Entity Metadata"},
+		0x1D: {Name: "Entity Effect"},
+		0x1E: {Name: "Remove Entity Effect"},
+		0x1F: {Name: "Set Experience"},
+		0x20: {Name: "Entity Properties"},
+		0x21: {Name: "Chunk Data"},
+		0x22: {Name: "Multi Block Change"},
+		0x23: {Name: "Block Change"},
+		0x24: {Name: "Block Action"},
+		0x25: {Name: "Block Break Animation"},
+		0x26: {Name: "Map Chunk Bulk"},
+		0x27: {Name: "Explosion"},
+		0x28: {Name: "Effect"},
+		0x29: {Name: "Sound Effect"},
+		0x2A: {Name: "Particle"},
+		0x2B: {Name: "Change Game State"},
+		0x2C: {Name: "Spawn Global Entity"},
+		0x2D: {Name: "Open Window"},
+		0x2E: {Name: "Close Window"},
+		0x2F: {Name: "Set Slot"},
+		0x30: {Name: "Window Items"},
+		0x31: {Name: "Window Property"},
+		0x32: {Name: "Confirm Transaction"},
+		0x33: {Name: "Update Sign"},
+		0x34: {Name: "Map"},
+		0x35: {Name: "Update Block Entity"},
+		0x36: {Name: "Open Sign Editor"},
+		0x37: {Name: "Statistics"},
+		0x38: {Name: "Player List Item"},
+		0x39: {Name: "Player Abilities"},
+		0x3A: {Name: "Tab-Complete"},
+		0x3B: {Name: "Scoreboard Objective"},
+		0x3C: {Name: "Update Score"},
+		0x3D: {Name: "Display Scoreboard"},
+		0x3E: {Name: "Teams"},
+		0x3F: {Name: "Plugin Message"},
+		0x40: {Name: "Disconnect"},
+		0x41: {Name: "Server Difficulty"},
+		0x42: {Name: "Combat Event"},
+		0x43: {Name: "Camera"},
+		0x44: {Name: "World Border"},
+		0x45: {Name: "Title"},
+		0x46: {Name: "Set Compression"}, // Broken
+		0x47: {Name: "Player List Header And Footer"},
+		0x48: {Name: "Resource Pack Send"},
+		0x49: {Name: "Update Entity NBT"},
+	}
+
+	PlayPacketsC2S = PacketMap{
+		0x00: {Name: "Keep Alive"},
+		0x01: {Name: "Chat Message"},
+		0x02: {Name: "Use Entity"},
+		0x03: {Name: "Player"},
+		0x04: {Name: "Player Position"},
+		0x05: {Name: "Player Look"},
+		0x06: {Name: "Player Position And Look"},
+		0x07: {Name: "Player Digging"},
+		0x08: {Name: "Player Block Placement"},
+		0x09: {Name: "Held Item Change"},
+		0x0A: {Name: "Animation"},
+		0x0B: {Name: "Entity Action"},
+		0x0C: {Name: "Steer Vehicle"},
+		0x0D: {Name: "Close Window"},
+		0x0E: {Name: "Click Window"},
+		0x0F: {Name: "Confirm Transaction"},
+		0x10: {Name: "Creative Inventory Action"},
+		0x11: {Name: "Enchant Item"},
+		0x12: {Name: "Update Sign"},
+		0x13: {Name: "Player Abilities"},
+		0x14: {Name: "Tab-Complete"},
+		0x15: {Name: "Client Settings"},
+		0x16: {Name: "Client Status"},
+		0x17: {Name: "Plugin Message"},
+		0x18: {Name: "Spectate"},
+		0x19: {Name: "Resource Pack Status"},
+	}
+)
+
+type Handshake struct {
+	ProtocolVersion pk.VarInt
+	ServerAddress   pk.String
+	ServerPort      pk.UnsignedShort
+	NextState       pk.VarInt
+}
+
+func (h *Handshake) Read(packet pk.Packet) error {
+	return packet.Scan(&h.ProtocolVersion, &h.ServerAddress, &h.ServerPort, &h.NextState)
+}
+
+func (h *Handshake) Marshal() pk.Packet {
+	return pk.Marshal(ServerboundHandshake, h.ProtocolVersion, h.ServerAddress, h.ServerPort, h.NextState)
+}
+
+type StatusResponse struct {
+	Response pk.String
+}
+
+func (s *StatusResponse) Read(packet pk.Packet) error {
+	return packet.Scan(&s.Response)
+}
+
+func (s *StatusResponse) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundStatusResponse, s.Response)
+}
+
+type StatusPong struct {
+	Payload pk.Long
+}
+
+func (s *StatusPong) Read(packet pk.Packet) error {
+	return packet.Scan(&s.Payload)
+}
+
+func (s *StatusPong) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundStatusPong, s.Payload)
+}
+
+type StatusRequest struct{}
+
+func (s *StatusRequest) Read(_ pk.Packet) error {
+	return nil
+}
+
+func (s *StatusRequest) Marshal() pk.Packet {
+	return pk.Marshal(ServerboundStatusRequest)
+}
+
+type StatusPing struct {
+	Payload pk.Long
+}
+
+func (s *StatusPing) Read(packet pk.Packet) error {
+	return packet.Scan(&s.Payload)
+}
+
+func (s *StatusPing) Marshal() pk.Packet {
+	return pk.Marshal(ServerboundStatusPing, s.Payload)
+}
+
+type LoginDisconnect struct {
+	Reason chat.Message
+}
+
+func (l *LoginDisconnect) Read(packet pk.Packet) error {
+	return packet.Scan(&l.Reason)
+}
+
+func (l *LoginDisconnect) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundLoginDisconnect, l.Reason)
+}
+
+type EncryptionRequest struct {
+	ServerID    pk.String
+	PublicKey   pk.ByteArray
+	VerifyToken pk.ByteArray
+}
+
+func (e *EncryptionRequest) Read(packet pk.Packet) error {
+	return packet.Scan(&e.ServerID, &e.PublicKey, &e.VerifyToken)
+}
+
+func (e *EncryptionRequest) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundEncryptionRequest, e.ServerID, e.PublicKey, e.VerifyToken)
+}
+
+type LoginSuccess struct {
+	UUID     pk.String
+	Username pk.String
+}
+
+func (l *LoginSuccess) Read(packet pk.Packet) error {
+	return packet.Scan(&l.UUID, &l.Username)
+}
+
+func (l *LoginSuccess) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundLoginSuccess, l.UUID, l.Username)
+}
+
+type SetCompression struct {
+	Threshold pk.VarInt
+}
+
+func (s *SetCompression) Read(packet pk.Packet) error {
+	return packet.Scan(&s.Threshold)
+}
+
+func (s *SetCompression) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundLoginSetCompression, s.Threshold)
+}
+
+type LoginStart struct {
+	Name pk.String
+}
+
+func (l *LoginStart) Read(packet pk.Packet) error {
+	return packet.Scan(&l.Name)
+}
+
+func (l *LoginStart) Marshal() pk.Packet {
+	return pk.Marshal(ServerboundLoginStart, l.Name)
+}
+
+type EncryptionResponse struct {
+	SharedSecret pk.ByteArray
+	VerifyToken  pk.ByteArray
+}
+
+func (e *EncryptionResponse) Read(packet pk.Packet) error {
+	return packet.Scan(&e.SharedSecret, &e.VerifyToken)
+}
+
+func (e *EncryptionResponse) Marshal() pk.Packet {
+	return pk.Marshal(ServerboundEncryptionResponse, e.SharedSecret, e.VerifyToken)
+}
+
+type JoinGame struct {
+	EntityID         pk.Int
+	GameMode         pk.UnsignedByte
+	Dimension        pk.Byte
+	Difficulty       pk.UnsignedByte
+	MaxPlayers       pk.UnsignedByte
+	LevelType        pk.String
+	ReducedDebugInfo pk.Boolean
+}
+
+func (j *JoinGame) Read(packet pk.Packet) error {
+	return packet.Scan(&j.EntityID, &j.GameMode, &j.Dimension, &j.Difficulty, &j.MaxPlayers, &j.LevelType, &j.ReducedDebugInfo)
+}
+
+func (j *JoinGame) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundJoinGame, j.EntityID, j.GameMode, j.Dimension, j.Difficulty, j.MaxPlayers, j.LevelType, j.ReducedDebugInfo)
+}
+
+type EntityEquipment struct {
+	EntityID pk.VarInt
+	Slot     pk.Short
+	Item     pk.Slot
+}
+
+func (e *EntityEquipment) Read(packet pk.Packet) error {
+	return packet.Scan(&e.EntityID, &e.Slot, &e.Item)
+}
+
+func (e *EntityEquipment) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundEntityEquipment, e.EntityID, e.Slot, e.Item)
+}
+
+type SpawnPosition struct {
+	Location pk.Position
+}
+
+func (s *SpawnPosition) Read(packet pk.Packet) error {
+	return packet.Scan(&s.Location)
+}
+
+func (s *SpawnPosition) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundSpawnPosition, s.Location)
+}
+
+type UpdateHealth struct {
+	Health         pk.Float
+	Food           pk.VarInt
+	FoodSaturation pk.Float
+}
+
+func (u *UpdateHealth) Read(packet pk.Packet) error {
+	return packet.Scan(&u.Health, &u.Food, &u.FoodSaturation)
+}
+
+func (u *UpdateHealth) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundUpdateHealth, u.Health, u.Food, u.FoodSaturation)
+}
+
+type Respawn struct {
+	Dimension  pk.Int
+	Difficulty pk.UnsignedByte
+	GameMode   pk.UnsignedByte
+	LevelType  pk.String
+}
+
+func (r *Respawn) Read(packet pk.Packet) error {
+	return packet.Scan(&r.Dimension, &r.Difficulty, &r.GameMode, &r.LevelType)
+}
+
+func (r *Respawn) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundRespawn, r.Dimension, r.Difficulty, r.GameMode, r.LevelType)
+}
+
+type PlayerPositionAndLook struct {
+	X, Y, Z    pk.Double
+	Yaw, Pitch pk.Float
+	Flags      pk.Byte
+}
+
+func (p *PlayerPositionAndLook) Read(packet pk.Packet) error {
+	return packet.Scan(&p.X, &p.Y, &p.Z, &p.Yaw, &p.Pitch, &p.Flags)
+}
+
+func (p *PlayerPositionAndLook) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundPlayerPositionAndLook, p.X, p.Y, p.Z, p.Yaw, p.Pitch, p.Flags)
+}
+
+type HeldItemChange struct {
+	Slot pk.Byte
+}
+
+func (h *HeldItemChange) Read(packet pk.Packet) error {
+	return packet.Scan(&h.Slot)
+}
+
+func (h *HeldItemChange) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundHeldItemChange, h.Slot)
+}
+
+type SpawnPlayer struct {
+	EntityID    pk.VarInt
+	PlayerUUID  pk.UUID
+	X, Y, Z     pk.Int
+	Yaw, Pitch  pk.Angle
+	CurrentItem pk.Short
+	Metadata    pk.EntityMetadata
+}
+
+func (s *SpawnPlayer) Read(packet pk.Packet) error {
+	s.Metadata = make(pk.EntityMetadata)
+	return packet.Scan(&s.EntityID, &s.PlayerUUID, &s.X, &s.Y, &s.Z, &s.Yaw, &s.Pitch, &s.CurrentItem, &s.Metadata)
+}
+
+func (s *SpawnPlayer) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundSpawnPlayer, s.EntityID, s.PlayerUUID, s.X, s.Y, s.Z, s.Yaw, s.Pitch, s.CurrentItem, s.Metadata)
+}
+
+type SpawnObject struct {
+	EntityID   pk.VarInt
+	Type       pk.Byte
+	X, Y, Z    pk.Int
+	Pitch, Yaw pk.Angle
+	Data       pk.Int
+	VX, VY, VZ pk.Short
+}
+
+func (s *SpawnObject) Read(packet pk.Packet) error {
+	return packet.Scan(&s.EntityID, &s.Type, &s.X, &s.Y, &s.Z, &s.Pitch, &s.Yaw, &s.Data, pk.Opt{Has: s.hasVelocity, Field: pk.Tuple{&s.VX, &s.VY, &s.VZ}})
+}
+
+func (s *SpawnObject) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundSpawnObject, s.EntityID, s.Type, s.X, s.Y, s.Z, s.Pitch, s.Yaw, s.Data, pk.Opt{Has: s.hasVelocity, Field: pk.Tuple{s.VX, s.VY, s.VZ}})
+}
+
+type SpawnMob struct {
+	EntityID   pk.VarInt
+	Type       pk.UnsignedByte
+	X, Y, Z    pk.Int
+	Yaw, Pitch pk.Angle
+	HeadPitch  pk.Angle
+	VX, VY, VZ pk.Short
+	Metadata   pk.EntityMetadata
+}
+
+func (s *SpawnMob) Read(packet pk.Packet) error {
+	s.Metadata = make(pk.EntityMetadata)
+	return packet.Scan(&s.EntityID, &s.Type, &s.X, &s.Y, &s.Z, &s.Yaw, &s.Pitch, &s.HeadPitch, &s.VX, &s.VY, &s.VZ, &s.Metadata)
+}
+
+func (s *SpawnMob) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundSpawnMob, s.EntityID, s.Type, s.X, s.Y, s.Z, s.Yaw, s.Pitch, s.HeadPitch, s.VX, s.VY, s.VZ, s.Metadata)
+}
+
+type EntityVelocity struct {
+	EntityID   pk.VarInt
+	VX, VY, VZ pk.Short
+}
+
+func (e *EntityVelocity) Read(packet pk.Packet) error {
+	return packet.Scan(&e.EntityID, &e.VX, &e.VY, &e.VZ)
+}
+
+func (e *EntityVelocity) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundEntityVelocity, e.EntityID, e.VX, e.VY, e.VZ)
+}
+
+type DestroyEntities struct {
+	EntityIDs []pk.VarInt
+}
+
+func (d *DestroyEntities) Read(packet pk.Packet) error {
+	var entityIDsLen pk.VarInt
+	return packet.Scan(&entityIDsLen, &pk.Ary{Len: &entityIDsLen, Ary: &d.EntityIDs})
+}
+
+func (d *DestroyEntities) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundDestroyEntities, pk.VarInt(len(d.EntityIDs)), pk.Ary{Ary: d.EntityIDs})
+}
+
+type EntityRelativeMove struct {
+	EntityID   pk.VarInt
+	DX, DY, DZ pk.Byte
+	OnGround   pk.Boolean
+}
+
+func (e *EntityRelativeMove) Read(packet pk.Packet) error {
+	return packet.Scan(&e.EntityID, &e.DX, &e.DY, &e.DZ, &e.OnGround)
+}
+
+func (e *EntityRelativeMove) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundEntityRelativeMove, e.EntityID, e.DX, e.DY, e.DZ, e.OnGround)
+}
+
+type EntityLookAndRelativeMove struct {
+	EntityID   pk.VarInt
+	DX, DY, DZ pk.Byte
+	Yaw, Pitch pk.Angle
+	OnGround   pk.Boolean
+}
+
+func (e *EntityLookAndRelativeMove) Read(packet pk.Packet) error {
+	return packet.Scan(&e.EntityID, &e.DX, &e.DY, &e.DZ, &e.Yaw, &e.Pitch, &e.OnGround)
+}
+
+func (e *EntityLookAndRelativeMove) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundEntityLookAndRelativeMove, e.EntityID, e.DX, e.DY, e.DZ, e.Yaw, e.Pitch, e.OnGround)
+}
+
+type EntityTeleport struct {
+	EntityID   pk.VarInt
+	X, Y, Z    pk.Int
+	Yaw, Pitch pk.Angle
+	OnGround   pk.Boolean
+}
+
+func (e *EntityTeleport) Read(packet pk.Packet) error {
+	return packet.Scan(&e.EntityID, &e.X, &e.Y, &e.Z, &e.Yaw, &e.Pitch, &e.OnGround)
+}
+
+func (e *EntityTeleport) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundEntityTeleport, e.EntityID, e.X, e.Y, e.Z, e.Yaw, e.Pitch, e.OnGround)
+}
+
+type EntityHeadLook struct {
+	EntityID pk.VarInt
+	HeadYaw  pk.Angle
+}
+
+func (e *EntityHeadLook) Read(packet pk.Packet) error {
+	return packet.Scan(&e.EntityID, &e.HeadYaw)
+}
+
+func (e *EntityHeadLook) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundEntityHeadLook, e.EntityID, e.HeadYaw)
+}
+
+type AttachEntity struct {
+	EntityID  pk.Int
+	VehicleID pk.Int
+	Leash     pk.Boolean
+}
+
+func (a *AttachEntity) Read(packet pk.Packet) error {
+	return packet.Scan(&a.EntityID, &a.VehicleID, &a.Leash)
+}
+
+func (a *AttachEntity) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundAttachEntity, a.EntityID, a.VehicleID, a.Leash)
+}
+
+type EntityMetadata struct {
+	EntityID pk.VarInt
+	Metadata pk.EntityMetadata
+}
+
+func (e *EntityMetadata) Read(packet pk.Packet) error {
+	e.Metadata = make(pk.EntityMetadata)
+	return packet.Scan(&e.EntityID, &e.Metadata)
+}
+
+func (e *EntityMetadata) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundEntityMetadata, e.EntityID, e.Metadata)
+}
+
+type EntityEffect struct {
+	EntityID      pk.VarInt
+	EffectID      pk.Byte
+	Amplifier     pk.Byte
+	Duration      pk.VarInt
+	HideParticles pk.Boolean
+}
+
+func (e *EntityEffect) Read(packet pk.Packet) error {
+	return packet.Scan(&e.EntityID, &e.EffectID, &e.Amplifier, &e.Duration, &e.HideParticles)
+}
+
+func (e *EntityEffect) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundEntityEffect, e.EntityID, e.EffectID, e.Amplifier, e.Duration, e.HideParticles)
+}
+
+type RemoveEntityEffect struct {
+	EntityID pk.VarInt
+	EffectID pk.Byte
+}
+
+func (r *RemoveEntityEffect) Read(packet pk.Packet) error {
+	return packet.Scan(&r.EntityID, &r.EffectID)
+}
+
+func (r *RemoveEntityEffect) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundRemoveEntityEffect, r.EntityID, r.EffectID)
+}
+
+type SetExperience struct {
+	ExperienceBar   pk.Float
+	Level           pk.VarInt
+	TotalExperience pk.VarInt
+}
+
+func (s *SetExperience) Read(packet pk.Packet) error {
+	return packet.Scan(&s.ExperienceBar, &s.Level, &s.TotalExperience)
+}
+
+func (s *SetExperience) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundSetExperience, s.ExperienceBar, s.Level, s.TotalExperience)
+}
+
+type EntityProperties struct {
+	EntityID   pk.VarInt
+	Properties []pk.Property
+}
+
+func (e *EntityProperties) Read(packet pk.Packet) error {
+	var propertiesLen pk.Int
+	return packet.Scan(&e.EntityID, &propertiesLen, &pk.Ary{Len: &propertiesLen, Ary: &e.Properties})
+}
+
+func (e *EntityProperties) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundEntityProperties, e.EntityID, pk.Int(len(e.Properties)), pk.Ary{Ary: e.Properties})
+}
+
+type ChunkData struct {
+	ChunkX, ChunkZ     pk.Int
+	GroundUpContinuous pk.Boolean
+	PrimaryBitMask     pk.UnsignedShort
+	Data               pk.ByteArray
+}
+
+func (c *ChunkData) Read(packet pk.Packet) error {
+	return packet.Scan(&c.ChunkX, &c.ChunkZ, &c.GroundUpContinuous, &c.PrimaryBitMask, &c.Data)
+}
+
+func (c *ChunkData) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundChunkData, c.ChunkX, c.ChunkZ, c.GroundUpContinuous, c.PrimaryBitMask, c.Data)
+}
+
+type MultiBlockChange struct {
+	ChunkX, ChunkZ pk.Int
+	Records        []pk.BlockRecord
+}
+
+func (m *MultiBlockChange) Read(packet pk.Packet) error {
+	var recordsLen pk.VarInt
+	return packet.Scan(&m.ChunkX, &m.ChunkZ, &recordsLen, &pk.Ary{Len: &recordsLen, Ary: &m.Records})
+}
+
+func (m *MultiBlockChange) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundMultiBlockChange, m.ChunkX, m.ChunkZ, pk.VarInt(len(m.Records)), pk.Ary{Ary: m.Records})
+}
+
+type BlockChange struct {
+	Location pk.Position
+	BlockID  pk.VarInt
+}
+
+func (b *BlockChange) Read(packet pk.Packet) error {
+	return packet.Scan(&b.Location, &b.BlockID)
+}
+
+func (b *BlockChange) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundBlockChange, b.Location, b.BlockID)
+}
+
+// MapChunkBulk carries data of all chunks one after another, in the same format as Chunk Data.
+// Ground-up continuous is always implied
+type MapChunkBulk struct {
+	SkyLightSent pk.Boolean
+	Meta         []pk.ChunkMeta
+	Data         pk.PluginMessageData
+}
+
+func (m *MapChunkBulk) Read(packet pk.Packet) error {
+	var metaLen pk.VarInt
+	return packet.Scan(&m.SkyLightSent, &metaLen, &pk.Ary{Len: &metaLen, Ary: &m.Meta}, &m.Data)
+}
+
+func (m *MapChunkBulk) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundMapChunkBulk, m.SkyLightSent, pk.VarInt(len(m.Meta)), pk.Ary{Ary: m.Meta}, &m.Data)
+}
+
+type Explosion struct {
+	X, Y, Z                   pk.Float
+	Radius                    pk.Float
+	Records                   []pk.ExplosionRecord
+	MotionX, MotionY, MotionZ pk.Float
+}
+
+func (e *Explosion) Read(packet pk.Packet) error {
+	var recordsLen pk.Int
+	return packet.Scan(&e.X, &e.Y, &e.Z, &e.Radius, &recordsLen, &pk.Ary{Len: &recordsLen, Ary: &e.Records}, &e.MotionX, &e.MotionY, &e.MotionZ)
+}
+
+func (e *Explosion) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundExplosion, e.X, e.Y, e.Z, e.Radius, pk.Int(len(e.Records)), pk.Ary{Ary: e.Records}, e.MotionX, e.MotionY, e.MotionZ)
+}
+
+type ChangeGameState struct {
+	Reason pk.UnsignedByte
+	Value  pk.Float
+}
+
+func (c *ChangeGameState) Read(packet pk.Packet) error {
+	return packet.Scan(&c.Reason, &c.Value)
+}
+
+func (c *ChangeGameState) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundChangeGameState, c.Reason, c.Value)
+}
+
+type OpenWindow struct {
+	WindowID      pk.UnsignedByte
+	WindowType    pk.String
+	WindowTitle   chat.Message
+	NumberOfSlots pk.UnsignedByte
+	EntityID      pk.Int
+}
+
+func (o *OpenWindow) Read(packet pk.Packet) error {
+	return packet.Scan(&o.WindowID, &o.WindowType, &o.WindowTitle, &o.NumberOfSlots, pk.Opt{Has: o.hasEntityID, Field: &o.EntityID})
+}
+
+func (o *OpenWindow) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundOpenWindow, o.WindowID, o.WindowType, o.WindowTitle, o.NumberOfSlots, pk.Opt{Has: o.hasEntityID, Field: o.EntityID})
+}
+
+type CloseWindow struct {
+	WindowID pk.UnsignedByte
+}
+
+func (c *CloseWindow) Read(packet pk.Packet) error {
+	return packet.Scan(&c.WindowID)
+}
+
+func (c *CloseWindow) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundCloseWindow, c.WindowID)
+}
+
+type SetSlot struct {
+	WindowID pk.Byte
+	Slot     pk.Short
+	SlotData pk.Slot
+}
+
+func (s *SetSlot) Read(packet pk.Packet) error {
+	return packet.Scan(&s.WindowID, &s.Slot, &s.SlotData)
+}
+
+func (s *SetSlot) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundSetSlot, s.WindowID, s.Slot, s.SlotData)
+}
+
+type WindowItems struct {
+	WindowID pk.UnsignedByte
+	SlotData []pk.Slot
+}
+
+func (w *WindowItems) Read(packet pk.Packet) error {
+	var slotDataLen pk.Short
+	return packet.Scan(&w.WindowID, &slotDataLen, &pk.Ary{Len: &slotDataLen, Ary: &w.SlotData})
+}
+
+func (w *WindowItems) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundWindowItems, w.WindowID, pk.Short(len(w.SlotData)), pk.Ary{Ary: w.SlotData})
+}
+
+type ConfirmTransaction struct {
+	WindowID     pk.Byte
+	ActionNumber pk.Short
+	Accepted     pk.Boolean
+}
+
+func (c *ConfirmTransaction) Read(packet pk.Packet) error {
+	return packet.Scan(&c.WindowID, &c.ActionNumber, &c.Accepted)
+}
+
+func (c *ConfirmTransaction) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundConfirmTransaction, c.WindowID, c.ActionNumber, c.Accepted)
+}
+
+type PlayerListItem struct {
+	Action  pk.VarInt
+	Players []PlayerListItemEntry
+}
+
+type PlayerAbilities struct {
+	Flags               pk.Byte
+	FlyingSpeed         pk.Float
+	FieldOfViewModifier pk.Float
+}
+
+func (p *PlayerAbilities) Read(packet pk.Packet) error {
+	return packet.Scan(&p.Flags, &p.FlyingSpeed, &p.FieldOfViewModifier)
+}
+
+func (p *PlayerAbilities) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundPlayerAbilities, p.Flags, p.FlyingSpeed, p.FieldOfViewModifier)
+}
+
+type PluginMessage struct {
+	Channel pk.String
+	Data    pk.PluginMessageData
+}
+
+func (p *PluginMessage) Read(packet pk.Packet) error {
+	return packet.Scan(&p.Channel, &p.Data)
+}
+
+func (p *PluginMessage) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundPluginMessage, p.Channel, &p.Data)
+}
+
+type Disconnect struct {
+	Reason chat.Message
+}
+
+func (d *Disconnect) Read(packet pk.Packet) error {
+	return packet.Scan(&d.Reason)
+}
+
+func (d *Disconnect) Marshal() pk.Packet {
+	return pk.Marshal(ClientboundDisconnect, d.Reason)
+}
+
+type ChatMessage struct {
+	Message pk.String
+}
+
+func (c *ChatMessage) Read(packet pk.Packet) error {
+	return packet.Scan(&c.Message)
+}
+
+func (c *ChatMessage) Marshal() pk.Packet {
+	return pk.Marshal(ServerboundChatMessage, c.Message)
+}
+
+type Player struct {
+	OnGround pk.Boolean
+}
+
+func (p *Player) Read(packet pk.Packet) error {
+	return packet.Scan(&p.OnGround)
+}
+
+func (p *Player) Marshal() pk.Packet {
+	return pk.Marshal(ServerboundPlayer, p.OnGround)
+}
+
+type PlayerPosition struct {
+	X, Y, Z  pk.Double
+	OnGround pk.Boolean
+}
+
+func (p *PlayerPosition) Read(packet pk.Packet) error {
+	return packet.Scan(&p.X, &p.Y, &p.Z, &p.OnGround)
+}
+
+func (p *PlayerPosition) Marshal() pk.Packet {
+	return pk.Marshal(ServerboundPlayerPosition, p.X, p.Y, p.Z, p.OnGround)
+}
+
+type PlayerLook struct {
+	Yaw, Pitch pk.Float
+	OnGround   pk.Boolean
+}
+
+func (p *PlayerLook) Read(packet pk.Packet) error {
+	return packet.Scan(&p.Yaw, &p.Pitch, &p.OnGround)
+}
+
+func (p *PlayerLook) Marshal() pk.Packet {
+	return pk.Marshal(ServerboundPlayerLook, p.Yaw, p.Pitch, p.OnGround)
+}
+
+type ServerPlayerPositionAndLook struct {
+	X, Y, Z    pk.Double
+	Yaw, Pitch pk.Float
+	OnGround   pk.Boolean
+}
+
+func (s *ServerPlayerPositionAndLook) Read(packet pk.Packet) error {
+	return packet.Scan(&s.X, &s.Y, &s.Z, &s.Yaw, &s.Pitch, &s.OnGround)
+}
+
+func (s *ServerPlayerPositionAndLook) Marshal() pk.Packet {
+	return pk.Marshal(ServerboundPlayerPositionAndLook, s.X, s.Y, s.Z, s.Yaw, s.Pitch, s.OnGround)
+}
+
+type PlayerDigging struct {
+	Status   pk.Byte
+	Location pk.Position
+	Face     pk.Byte
+}
+
+func (p *PlayerDigging) Read(packet pk.Packet) error {
+	return packet.Scan(&p.Status, &p.Location, &p.Face)
+}
+
+func (p *PlayerDigging) Marshal() pk.Packet {
+	return pk.Marshal(ServerboundPlayerDigging, p.Status, p.Location, p.Face)
+}
+
+type PlayerBlockPlacement struct {
+	Location        pk.Position
+	Face            pk.Byte
+	HeldItem        pk.Slot
+	CursorPositionX pk.Byte
+	CursorPositionY pk.Byte
+	CursorPositionZ pk.Byte
+}
+
+func (p *PlayerBlockPlacement) Read(packet pk.Packet) error {
+	return packet.Scan(&p.Location, &p.Face, &p.HeldItem, &p.CursorPositionX, &p.CursorPositionY, &p.CursorPositionZ)
+}
+
+func (p *PlayerBlockPlacement) Marshal() pk.Packet {
+	return pk.Marshal(ServerboundPlayerBlockPlacement, p.Location, p.Face, p.HeldItem, p.CursorPositionX, p.CursorPositionY, p.CursorPositionZ)
+}
+
+type ServerHeldItemChange struct {
+	Slot pk.Short
+}
+
+func (s *ServerHeldItemChange) Read(packet pk.Packet) error {
+	return packet.Scan(&s.Slot)
+}
+
+func (s *ServerHeldItemChange) Marshal() pk.Packet {
+	return pk.Marshal(ServerboundHeldItemChange, s.Slot)
+}
+
+type ServerAnimation struct{}
+
+func (s *ServerAnimation) Read(_ pk.Packet) error {
+	return nil
+}
+
+func (s *ServerAnimation) Marshal() pk.Packet {
+	return pk.Marshal(ServerboundAnimation)
+}
+
+type ServerCloseWindow struct {
+	CloseWindow
+}
+
+func (s *ServerCloseWindow) Marshal() pk.Packet {
+	packet := s.CloseWindow.Marshal()
+	packet.ID = ServerboundCloseWindow
+	return packet
+}
+
+type ClickWindow struct {
+	WindowID     pk.UnsignedByte
+	Slot         pk.Short
+	Button       pk.Byte
+	ActionNumber pk.Short
+	Mode         pk.Byte
+	ClickedItem  pk.Slot
+}
+
+func (c *ClickWindow) Read(packet pk.Packet) error {
+	return packet.Scan(&c.WindowID, &c.Slot, &c.Button, &c.ActionNumber, &c.Mode, &c.ClickedItem)
+}
+
+func (c *ClickWindow) Marshal() pk.Packet {
+	return pk.Marshal(ServerboundClickWindow, c.WindowID, c.Slot, c.Button, c.ActionNumber, c.Mode, c.ClickedItem)
+}
+
+type ServerConfirmTransaction struct {
+	ConfirmTransaction
+}
+
+func (s *ServerConfirmTransaction) Marshal() pk.Packet {
+	packet := s.ConfirmTransaction.Marshal()
+	packet.ID = ServerboundConfirmTransaction
+	return packet
+}
+
+type ServerPlayerAbilities struct {
+	Flags        pk.Byte
+	FlyingSpeed  pk.Float
+	WalkingSpeed pk.Float
+}
+
+func (s *ServerPlayerAbilities) Read(packet pk.Packet) error {
+	return packet.Scan(&s.Flags, &s.FlyingSpeed, &s.WalkingSpeed)
+}
+
+func (s *ServerPlayerAbilities) Marshal() pk.Packet {
+	return pk.Marshal(ServerboundPlayerAbilities, s.Flags, s.FlyingSpeed, s.WalkingSpeed)
+}
+
+type ServerPluginMessage struct {
+	PluginMessage
+}
+
+func (s *ServerPluginMessage) Marshal() pk.Packet {
+	packet := s.PluginMessage.Marshal()
+	packet.ID = ServerboundPluginMessage
+	return packet
+}
+
+type packetKey struct {
+	state     ConnectionState
+	direction int
+	id        int32
+}
+
+var packets = map[packetKey]func() Packet{
+	{ConnStateHandshake, ConnC2S, ServerboundHandshake}:            func() Packet { return &Handshake{} },
+	{ConnStateStatus, ConnS2C, ClientboundStatusResponse}:          func() Packet { return &StatusResponse{} },
+	{ConnStateStatus, ConnS2C, ClientboundStatusPong}:              func() Packet { return &StatusPong{} },
+	{ConnStateStatus, ConnC2S, ServerboundStatusRequest}:           func() Packet { return &StatusRequest{} },
+	{ConnStateStatus, ConnC2S, ServerboundStatusPing}:              func() Packet { return &StatusPing{} },
+	{ConnStateLogin, ConnS2C, ClientboundLoginDisconnect}:          func() Packet { return &LoginDisconnect{} },
+	{ConnStateLogin, ConnS2C, ClientboundEncryptionRequest}:        func() Packet { return &EncryptionRequest{} },
+	{ConnStateLogin, ConnS2C, ClientboundLoginSuccess}:             func() Packet { return &LoginSuccess{} },
+	{ConnStateLogin, ConnS2C, ClientboundLoginSetCompression}:      func() Packet { return &SetCompression{} },
+	{ConnStateLogin, ConnC2S, ServerboundLoginStart}:               func() Packet { return &LoginStart{} },
+	{ConnStateLogin, ConnC2S, ServerboundEncryptionResponse}:       func() Packet { return &EncryptionResponse{} },
+	{ConnStatePlay, ConnS2C, ClientboundJoinGame}:                  func() Packet { return &JoinGame{} },
+	{ConnStatePlay, ConnS2C, ClientboundEntityEquipment}:           func() Packet { return &EntityEquipment{} },
+	{ConnStatePlay, ConnS2C, ClientboundSpawnPosition}:             func() Packet { return &SpawnPosition{} },
+	{ConnStatePlay, ConnS2C, ClientboundUpdateHealth}:              func() Packet { return &UpdateHealth{} },
+	{ConnStatePlay, ConnS2C, ClientboundRespawn}:                   func() Packet { return &Respawn{} },
+	{ConnStatePlay, ConnS2C, ClientboundPlayerPositionAndLook}:     func() Packet { return &PlayerPositionAndLook{} },
+	{ConnStatePlay, ConnS2C, ClientboundHeldItemChange}:            func() Packet { return &HeldItemChange{} },
+	{ConnStatePlay, ConnS2C, ClientboundSpawnPlayer}:               func() Packet { return &SpawnPlayer{} },
+	{ConnStatePlay, ConnS2C, ClientboundSpawnObject}:               func() Packet { return &SpawnObject{} },
+	{ConnStatePlay, ConnS2C, ClientboundSpawnMob}:                  func() Packet { return &SpawnMob{} },
+	{ConnStatePlay, ConnS2C, ClientboundEntityVelocity}:            func() Packet { return &EntityVelocity{} },
+	{ConnStatePlay, ConnS2C, ClientboundDestroyEntities}:           func() Packet { return &DestroyEntities{} },
+	{ConnStatePlay, ConnS2C, ClientboundEntityRelativeMove}:        func() Packet { return &EntityRelativeMove{} },
+	{ConnStatePlay, ConnS2C, ClientboundEntityLookAndRelativeMove}: func() Packet { return &EntityLookAndRelativeMove{} },
+	{ConnStatePlay, ConnS2C, ClientboundEntityTeleport}:            func() Packet { return &EntityTeleport{} },
+	{ConnStatePlay, ConnS2C, ClientboundEntityHeadLook}:            func() Packet { return &EntityHeadLook{} },
+	{ConnStatePlay, ConnS2C, ClientboundAttachEntity}:              func() Packet { return &AttachEntity{} },
+	{ConnStatePlay, ConnS2C, ClientboundEntityMetadata}:            func() Packet { return &EntityMetadata{} },
+	{ConnStatePlay, ConnS2C, ClientboundEntityEffect}:              func() Packet { return &EntityEffect{} },
+	{ConnStatePlay, ConnS2C, ClientboundRemoveEntityEffect}:        func() Packet { return &RemoveEntityEffect{} },
+	{ConnStatePlay, ConnS2C, ClientboundSetExperience}:             func() Packet { return &SetExperience{} },
+	{ConnStatePlay, ConnS2C, ClientboundEntityProperties}:          func() Packet { return &EntityProperties{} },
+	{ConnStatePlay, ConnS2C, ClientboundChunkData}:                 func() Packet { return &ChunkData{} },
+	{ConnStatePlay, ConnS2C, ClientboundMultiBlockChange}:          func() Packet { return &MultiBlockChange{} },
+	{ConnStatePlay, ConnS2C, ClientboundBlockChange}:               func() Packet { return &BlockChange{} },
+	{ConnStatePlay, ConnS2C, ClientboundMapChunkBulk}:              func() Packet { return &MapChunkBulk{} },
+	{ConnStatePlay, ConnS2C, ClientboundExplosion}:                 func() Packet { return &Explosion{} },
+	{ConnStatePlay, ConnS2C, ClientboundChangeGameState}:           func() Packet { return &ChangeGameState{} },
+	{ConnStatePlay, ConnS2C, ClientboundOpenWindow}:                func() Packet { return &OpenWindow{} },
+	{ConnStatePlay, ConnS2C, ClientboundCloseWindow}:               func() Packet { return &CloseWindow{} },
+	{ConnStatePlay, ConnS2C, ClientboundSetSlot}:                   func() Packet { return &SetSlot{} },
+	{ConnStatePlay, ConnS2C, ClientboundWindowItems}:               func() Packet { return &WindowItems{} },
+	{ConnStatePlay, ConnS2C, ClientboundConfirmTransaction}:        func() Packet { return &ConfirmTransaction{} },
+	{ConnStatePlay, ConnS2C, ClientboundPlayerListItem}:            func() Packet { return &PlayerListItem{} },
+	{ConnStatePlay, ConnS2C, ClientboundPlayerAbilities}:           func() Packet { return &PlayerAbilities{} },
+	{ConnStatePlay, ConnS2C, ClientboundPluginMessage}:             func() Packet { return &PluginMessage{} },
+	{ConnStatePlay, ConnS2C, ClientboundDisconnect}:                func() Packet { return &Disconnect{} },
+	{ConnStatePlay, ConnC2S, ServerboundChatMessage}:               func() Packet { return &ChatMessage{} },
+	{ConnStatePlay, ConnC2S, ServerboundPlayer}:                    func() Packet { return &Player{} },
+	{ConnStatePlay, ConnC2S, ServerboundPlayerPosition}:            func() Packet { return &PlayerPosition{} },
+	{ConnStatePlay, ConnC2S, ServerboundPlayerLook}:                func() Packet { return &PlayerLook{} },
+	{ConnStatePlay, ConnC2S, ServerboundPlayerPositionAndLook}:     func() Packet { return &ServerPlayerPositionAndLook{} },
+	{ConnStatePlay, ConnC2S, ServerboundPlayerDigging}:             func() Packet { return &PlayerDigging{} },
+	{ConnStatePlay, ConnC2S, ServerboundPlayerBlockPlacement}:      func() Packet { return &PlayerBlockPlacement{} },
+	{ConnStatePlay, ConnC2S, ServerboundHeldItemChange}:            func() Packet { return &ServerHeldItemChange{} },
+	{ConnStatePlay, ConnC2S, ServerboundAnimation}:                 func() Packet { return &ServerAnimation{} },
+	{ConnStatePlay, ConnC2S, ServerboundCloseWindow}:               func() Packet { return &ServerCloseWindow{} },
+	{ConnStatePlay, ConnC2S, ServerboundClickWindow}:               func() Packet { return &ClickWindow{} },
+	{ConnStatePlay, ConnC2S, ServerboundConfirmTransaction}:        func() Packet { return &ServerConfirmTransaction{} },
+	{ConnStatePlay, ConnC2S, ServerboundPlayerAbilities}:           func() Packet { return &ServerPlayerAbilities{} },
+	{ConnStatePlay, ConnC2S, ServerboundPluginMessage}:             func() Packet { return &ServerPluginMessage{} },
+}
+
+// NewPacket returns an empty structure of the packet, which is ready to be read
+func NewPacket(state ConnectionState, direction int, id int32) (Packet, bool) {
+	newPacket, ok := packets[packetKey{state: state, direction: direction, id: id}]
+	if !ok {
+		return nil, false
+	}
+
+	return newPacket(), true
+}
@@ -0,0 +1,95 @@
+// Code generated by gen_packets.go DO NOT EDIT.
+
+package protocol
+
+import (
+	"testing"
+
+	"github.com/Tnze/go-mc/chat"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+var samples = []struct {
+	state     ConnectionState
+	direction int
+	id        int32
+	packet    Packet
+}{
+	{ConnStateHandshake, ConnC2S, ServerboundHandshake, &Handshake{ProtocolVersion: 7, ServerAddress: "kogtevran", ServerPort: 7, NextState: 7}},
+	{ConnStateStatus, ConnS2C, ClientboundStatusResponse, &StatusResponse{Response: "kogtevran"}},
+	{ConnStateStatus, ConnS2C, ClientboundStatusPong, &StatusPong{Payload: 7}},
+	{ConnStateStatus, ConnC2S, ServerboundStatusRequest, &StatusRequest{}},
+	{ConnStateStatus, ConnC2S, ServerboundStatusPing, &StatusPing{Payload: 7}},
+	{ConnStateLogin, ConnS2C, ClientboundLoginDisconnect, &LoginDisconnect{Reason: chat.Message{}}},
+	{ConnStateLogin, ConnS2C, ClientboundEncryptionRequest, &EncryptionRequest{ServerID: "kogtevran", PublicKey: pk.ByteArray{1, 2, 3}, VerifyToken: pk.ByteArray{1, 2, 3}}},
+	{ConnStateLogin, ConnS2C, ClientboundLoginSuccess, &LoginSuccess{UUID: "kogtevran", Username: "kogtevran"}},
+	{ConnStateLogin, ConnS2C, ClientboundLoginSetCompression, &SetCompression{Threshold: 7}},
+	{ConnStateLogin, ConnC2S, ServerboundLoginStart, &LoginStart{Name: "kogtevran"}},
+	{ConnStateLogin, ConnC2S, ServerboundEncryptionResponse, &EncryptionResponse{SharedSecret: pk.ByteArray{1, 2, 3}, VerifyToken: pk.ByteArray{1, 2, 3}}},
+	{ConnStatePlay, ConnS2C, ClientboundJoinGame, &JoinGame{EntityID: 7, GameMode: 7, Dimension: 7, Difficulty: 7, MaxPlayers: 7, LevelType: "kogtevran", ReducedDebugInfo: true}},
+	{ConnStatePlay, ConnS2C, ClientboundEntityEquipment, &EntityEquipment{EntityID: 7, Slot: 7, Item: pk.Slot{BlockID: -1}}},
+	{ConnStatePlay, ConnS2C, ClientboundSpawnPosition, &SpawnPosition{Location: pk.Position{X: 1, Y: 2, Z: 3}}},
+	{ConnStatePlay, ConnS2C, ClientboundUpdateHealth, &UpdateHealth{Health: 7, Food: 7, FoodSaturation: 7}},
+	{ConnStatePlay, ConnS2C, ClientboundRespawn, &Respawn{Dimension: 7, Difficulty: 7, GameMode: 7, LevelType: "kogtevran"}},
+	{ConnStatePlay, ConnS2C, ClientboundPlayerPositionAndLook, &PlayerPositionAndLook{X: 7, Y: 7, Z: 7, Yaw: 7, Pitch: 7, Flags: 7}},
+	{ConnStatePlay, ConnS2C, ClientboundHeldItemChange, &HeldItemChange{Slot: 7}},
+	{ConnStatePlay, ConnS2C, ClientboundSpawnPlayer, &SpawnPlayer{EntityID: 7, PlayerUUID: pk.UUID{1, 2, 3}, X: 7, Y: 7, Z: 7, Yaw: 7, Pitch: 7, CurrentItem: 7, Metadata: pk.EntityMetadata{}}},
+	{ConnStatePlay, ConnS2C, ClientboundSpawnObject, &SpawnObject{EntityID: 7, Type: 7, X: 7, Y: 7, Z: 7, Pitch: 7, Yaw: 7, Data: 7}},
+	{ConnStatePlay, ConnS2C, ClientboundSpawnMob, &SpawnMob{EntityID: 7, Type: 7, X: 7, Y: 7, Z: 7, Yaw: 7, Pitch: 7, HeadPitch: 7, VX: 7, VY: 7, VZ: 7, Metadata: pk.EntityMetadata{}}},
+	{ConnStatePlay, ConnS2C, ClientboundEntityVelocity, &EntityVelocity{EntityID: 7, VX: 7, VY: 7, VZ: 7}},
+	{ConnStatePlay, ConnS2C, ClientboundDestroyEntities, &DestroyEntities{EntityIDs: []pk.VarInt{7}}},
+	{ConnStatePlay, ConnS2C, ClientboundEntityRelativeMove, &EntityRelativeMove{EntityID: 7, DX: 7, DY: 7, DZ: 7, OnGround: true}},
+	{ConnStatePlay, ConnS2C, ClientboundEntityLookAndRelativeMove, &EntityLookAndRelativeMove{EntityID: 7, DX: 7, DY: 7, DZ: 7, Yaw: 7, Pitch: 7, OnGround: true}},
+	{ConnStatePlay, ConnS2C, ClientboundEntityTeleport, &EntityTeleport{EntityID: 7, X: 7, Y: 7, Z: 7, Yaw: 7, Pitch: 7, OnGround: true}},
+	{ConnStatePlay, ConnS2C, ClientboundEntityHeadLook, &EntityHeadLook{EntityID: 7, HeadYaw: 7}},
+	{ConnStatePlay, ConnS2C, ClientboundAttachEntity, &AttachEntity{EntityID: 7, VehicleID: 7, Leash: true}},
+	{ConnStatePlay, ConnS2C, ClientboundEntityMetadata, &EntityMetadata{EntityID: 7, Metadata: pk.EntityMetadata{}}},
+	{ConnStatePlay, ConnS2C, ClientboundEntityEffect, &EntityEffect{EntityID: 7, EffectID: 7, Amplifier: 7, Duration: 7, HideParticles: true}},
+	{ConnStatePlay, ConnS2C, ClientboundRemoveEntityEffect, &RemoveEntityEffect{EntityID: 7, EffectID: 7}},
+	{ConnStatePlay, ConnS2C, ClientboundSetExperience, &SetExperience{ExperienceBar: 7, Level: 7, TotalExperience: 7}},
+	{ConnStatePlay, ConnS2C, ClientboundEntityProperties, &EntityProperties{EntityID: 7, Properties: []pk.Property{pk.Property{Key: "kogtevran", Value: 7}}}},
+	{ConnStatePlay, ConnS2C, ClientboundChunkData, &ChunkData{ChunkX: 7, ChunkZ: 7, GroundUpContinuous: true, PrimaryBitMask: 7, Data: pk.ByteArray{1, 2, 3}}},
+	{ConnStatePlay, ConnS2C, ClientboundMultiBlockChange, &MultiBlockChange{ChunkX: 7, ChunkZ: 7, Records: []pk.BlockRecord{pk.BlockRecord{HorizontalPosition: 7, Y: 7, BlockID: 7}}}},
+	{ConnStatePlay, ConnS2C, ClientboundBlockChange, &BlockChange{Location: pk.Position{X: 1, Y: 2, Z: 3}, BlockID: 7}},
+	{ConnStatePlay, ConnS2C, ClientboundMapChunkBulk, &MapChunkBulk{SkyLightSent: true, Meta: []pk.ChunkMeta{pk.ChunkMeta{ChunkX: 7, ChunkZ: 7, PrimaryBitMask: 7}}, Data: pk.PluginMessageData{1, 2, 3}}},
+	{ConnStatePlay, ConnS2C, ClientboundExplosion, &Explosion{X: 7, Y: 7, Z: 7, Radius: 7, Records: []pk.ExplosionRecord{pk.ExplosionRecord{X: 7, Y: 7, Z: 7}}, MotionX: 7, MotionY: 7, MotionZ: 7}},
+	{ConnStatePlay, ConnS2C, ClientboundChangeGameState, &ChangeGameState{Reason: 7, Value: 7}},
+	{ConnStatePlay, ConnS2C, ClientboundOpenWindow, &OpenWindow{WindowID: 7, WindowType: "kogtevran", WindowTitle: chat.Message{}, NumberOfSlots: 7}},
+	{ConnStatePlay, ConnS2C, ClientboundCloseWindow, &CloseWindow{WindowID: 7}},
+	{ConnStatePlay, ConnS2C, ClientboundSetSlot, &SetSlot{WindowID: 7, Slot: 7, SlotData: pk.Slot{BlockID: -1}}},
+	{ConnStatePlay, ConnS2C, ClientboundWindowItems, &WindowItems{WindowID: 7, SlotData: []pk.Slot{pk.Slot{BlockID: -1}}}},
+	{ConnStatePlay, ConnS2C, ClientboundConfirmTransaction, &ConfirmTransaction{WindowID: 7, ActionNumber: 7, Accepted: true}},
+	{ConnStatePlay, ConnS2C, ClientboundPlayerListItem, &PlayerListItem{Action: 7, Players: []PlayerListItemEntry{PlayerListItemEntry{}}}},
+	{ConnStatePlay, ConnS2C, ClientboundPlayerAbilities, &PlayerAbilities{Flags: 7, FlyingSpeed: 7, FieldOfViewModifier: 7}},
+	{ConnStatePlay, ConnS2C, ClientboundPluginMessage, &PluginMessage{Channel: "kogtevran", Data: pk.PluginMessageData{1, 2, 3}}},
+	{ConnStatePlay, ConnS2C, ClientboundDisconnect, &Disconnect{Reason: chat.Message{}}},
+	{ConnStatePlay, ConnC2S, ServerboundChatMessage, &ChatMessage{Message: "kogtevran"}},
+	{ConnStatePlay, ConnC2S, ServerboundPlayer, &Player{OnGround: true}},
+	{ConnStatePlay, ConnC2S, ServerboundPlayerPosition, &PlayerPosition{X: 7, Y: 7, Z: 7, OnGround: true}},
+	{ConnStatePlay, ConnC2S, ServerboundPlayerLook, &PlayerLook{Yaw: 7, Pitch: 7, OnGround: true}},
+	{ConnStatePlay, ConnC2S, ServerboundPlayerPositionAndLook, &ServerPlayerPositionAndLook{X: 7, Y: 7, Z: 7, Yaw: 7, Pitch: 7, OnGround: true}},
+	{ConnStatePlay, ConnC2S, ServerboundPlayerDigging, &PlayerDigging{Status: 7, Location: pk.Position{X: 1, Y: 2, Z: 3}, Face: 7}},
+	{ConnStatePlay, ConnC2S, ServerboundPlayerBlockPlacement, &PlayerBlockPlacement{Location: pk.Position{X: 1, Y: 2, Z: 3}, Face: 7, HeldItem: pk.Slot{BlockID: -1}, CursorPositionX: 7, CursorPositionY: 7, CursorPositionZ: 7}},
+	{ConnStatePlay, ConnC2S, ServerboundHeldItemChange, &ServerHeldItemChange{Slot: 7}},
+	{ConnStatePlay, ConnC2S, ServerboundAnimation, &ServerAnimation{}},
+	{ConnStatePlay, ConnC2S, ServerboundCloseWindow, &ServerCloseWindow{CloseWindow: CloseWindow{WindowID: 7}}},
+	{ConnStatePlay, ConnC2S, ServerboundClickWindow, &ClickWindow{WindowID: 7, Slot: 7, Button: 7, ActionNumber: 7, Mode: 7, ClickedItem: pk.Slot{BlockID: -1}}},
+	{ConnStatePlay, ConnC2S, ServerboundConfirmTransaction, &ServerConfirmTransaction{ConfirmTransaction: ConfirmTransaction{WindowID: 7, ActionNumber: 7, Accepted: true}}},
+	{ConnStatePlay, ConnC2S, ServerboundPlayerAbilities, &ServerPlayerAbilities{Flags: 7, FlyingSpeed: 7, WalkingSpeed: 7}},
+	{ConnStatePlay, ConnC2S, ServerboundPluginMessage, &ServerPluginMessage{PluginMessage: PluginMessage{Channel: "kogtevran", Data: pk.PluginMessageData{1, 2, 3}}}},
+}
+
+func TestPackets_RoundTrip(t *testing.T) {
+	assert.Len(t, samples, len(packets))
+	for _, sample := range samples {
+		packet := sample.packet.Marshal()
+		assert.Equal(t, sample.id, packet.ID)
+
+		decoded, ok := NewPacket(sample.state, sample.direction, sample.id)
+		assert.True(t, ok)
+		assert.NoError(t, decoded.Read(packet), "%T", sample.packet)
+		assert.Equal(t, sample.packet, decoded)
+		assert.Equal(t, packet.Data, decoded.Marshal().Data, "%T", sample.packet)
+	}
+}
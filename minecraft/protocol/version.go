@@ -0,0 +1,211 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var ErrUnsupportedPacket = errors.New("packet is not supported by the protocol version")
+
+// Version maps logical packet IDs to the IDs of a protocol version. Logical IDs are the IDs of protocol 47 (1.8),
+// which is what the constants and the structures of this package describe. Only the states that differ between
+// versions are mapped, the handshake, status and login packets are the same for all supported versions
+type Version struct {
+	Protocol int32
+	Name     string
+
+	clientbound, serverbound map[int32]int32
+	// Reverse indexes of the mappings above, by the ID of the version
+	clientboundLogical, serverboundLogical map[int32]int32
+}
+
+func newVersion(protocol int32, name string, clientbound, serverbound map[int32]int32) *Version {
+	version := &Version{
+		Protocol:           protocol,
+		Name:               name,
+		clientbound:        clientbound,
+		serverbound:        serverbound,
+		clientboundLogical: make(map[int32]int32, len(clientbound)),
+		serverboundLogical: make(map[int32]int32, len(serverbound)),
+	}
+
+	for logical, id := range clientbound {
+		version.clientboundLogical[id] = logical
+	}
+
+	for logical, id := range serverbound {
+		version.serverboundLogical[id] = logical
+	}
+
+	return version
+}
+
+func (v *Version) String() string {
+	return fmt.Sprintf("%s (%d)", v.Name, v.Protocol)
+}
+
+func (v *Version) mapping(direction int, logical bool) map[int32]int32 {
+	switch {
+	case direction == ConnS2C && logical:
+		return v.clientboundLogical
+	case direction == ConnS2C:
+		return v.clientbound
+	case direction == ConnC2S && logical:
+		return v.serverboundLogical
+	case direction == ConnC2S:
+		return v.serverbound
+	}
+
+	panic("unsupported direction")
+}
+
+// ID returns the ID of the logical packet in this version
+func (v *Version) ID(state ConnectionState, direction int, logical int32) (int32, bool) {
+	if state != ConnStatePlay {
+		return logical, true
+	}
+
+	id, ok := v.mapping(direction, false)[logical]
+	return id, ok
+}
+
+// Supports reports whether all of the play state packets sent in the direction are mapped on this version
+func (v *Version) Supports(direction int, logical ...int32) bool {
+	for _, id := range logical {
+		if _, ok := v.ID(ConnStatePlay, direction, id); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// LogicalID resolves the packet of this version to its logical ID. Packets that do not exist in protocol 47,
+// or that are encoded differently, have no logical ID and are meant to be passed as they are
+func (v *Version) LogicalID(state ConnectionState, direction int, id int32) (int32, bool) {
+	if state != ConnStatePlay {
+		return id, true
+	}
+
+	logical, ok := v.mapping(direction, true)[id]
+	return logical, ok
+}
+
+// identity maps all of the packets of the map to themselves
+func identity(packets PacketMap) map[int32]int32 {
+	ids := make(map[int32]int32, len(packets))
+	for id := range packets {
+		ids[int32(id)] = int32(id)
+	}
+
+	return ids
+}
+
+var (
+	V1_8 = newVersion(47, "1.8", identity(PlayPacketsS2C), identity(PlayPacketsC2S))
+
+	// V1_12_2 maps the packets which are encoded the same way as in 1.8. Entity spawning and movement,
+	// metadata, chunks and keep alives have changed and are not decoded on this version. Neither are
+	// interactions, which carry the hand since 1.9, nor entity actions, whose action IDs have changed
+	V1_12_2 = newVersion(340, "1.12.2", map[int32]int32{
+		ClientboundChatMessage:               0x0F,
+		ClientboundTimeUpdate:                0x47,
+		ClientboundSpawnPosition:             0x46,
+		ClientboundUpdateHealth:              0x41,
+		ClientboundRespawn:                   0x35,
+		ClientboundHeldItemChange:            0x3A,
+		ClientboundUseBed:                    0x30,
+		ClientboundAnimation:                 0x06,
+		ClientboundEntityVelocity:            0x3E,
+		ClientboundDestroyEntities:           0x32,
+		ClientboundEntity:                    0x25,
+		ClientboundEntityLook:                0x28,
+		ClientboundEntityHeadLook:            0x36,
+		ClientboundEntityStatus:              0x1B,
+		ClientboundRemoveEntityEffect:        0x33,
+		ClientboundSetExperience:             0x40,
+		ClientboundEntityProperties:          0x4E,
+		ClientboundMultiBlockChange:          0x10,
+		ClientboundBlockChange:               0x0B,
+		ClientboundBlockAction:               0x0A,
+		ClientboundBlockBreakAnimation:       0x08,
+		ClientboundExplosion:                 0x1C,
+		ClientboundEffect:                    0x21,
+		ClientboundParticle:                  0x22,
+		ClientboundChangeGameState:           0x1E,
+		ClientboundOpenWindow:                0x13,
+		ClientboundCloseWindow:               0x12,
+		ClientboundSetSlot:                   0x16,
+		ClientboundWindowItems:               0x14,
+		ClientboundWindowProperty:            0x15,
+		ClientboundConfirmTransaction:        0x11,
+		ClientboundUpdateBlockEntity:         0x09,
+		ClientboundOpenSignEditor:            0x2A,
+		ClientboundStatistics:                0x07,
+		ClientboundPlayerListItem:            0x2E,
+		ClientboundPlayerAbilities:           0x2C,
+		ClientboundTabComplete:               0x0E,
+		ClientboundScoreboardObjective:       0x42,
+		ClientboundUpdateScore:               0x45,
+		ClientboundDisplayScoreboard:         0x3B,
+		ClientboundPluginMessage:             0x18,
+		ClientboundDisconnect:                0x1A,
+		ClientboundServerDifficulty:          0x0D,
+		ClientboundCombatEvent:               0x2D,
+		ClientboundCamera:                    0x39,
+		ClientboundWorldBorder:               0x38,
+		ClientboundPlayerListHeaderAndFooter: 0x4A,
+		ClientboundResourcePackSend:          0x34,
+	}, map[int32]int32{
+		ServerboundChatMessage:             0x02,
+		ServerboundPlayer:                  0x0C,
+		ServerboundPlayerPosition:          0x0D,
+		ServerboundPlayerLook:              0x0F,
+		ServerboundPlayerPositionAndLook:   0x0E,
+		ServerboundPlayerDigging:           0x14,
+		ServerboundHeldItemChange:          0x1A,
+		ServerboundSteerVehicle:            0x16,
+		ServerboundCloseWindow:             0x08,
+		ServerboundClickWindow:             0x07,
+		ServerboundConfirmTransaction:      0x05,
+		ServerboundCreativeInventoryAction: 0x1B,
+		ServerboundEnchantItem:             0x06,
+		ServerboundPlayerAbilities:         0x13,
+		ServerboundClientStatus:            0x03,
+		ServerboundPluginMessage:           0x09,
+		ServerboundSpectate:                0x1E,
+	})
+
+	Versions = map[int32]*Version{
+		V1_8.Protocol:    V1_8,
+		V1_12_2.Protocol: V1_12_2,
+	}
+
+	DefaultVersion = V1_8
+)
+
+func GetVersion(protocol int32) (*Version, bool) {
+	version, ok := Versions[protocol]
+	return version, ok
+}
+
+// SupportedVersions lists names of the supported versions, e.g. for a kick message
+func SupportedVersions() string {
+	versions := make([]*Version, 0, len(Versions))
+	for _, version := range Versions {
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Protocol < versions[j].Protocol
+	})
+
+	names := make([]string, 0, len(versions))
+	for _, version := range versions {
+		names = append(names, version.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,69 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersion_Mapping(t *testing.T) {
+	for _, version := range Versions {
+		for _, direction := range []int{ConnS2C, ConnC2S} {
+			ids := make(map[int32]int32)
+			for logical, id := range version.mapping(direction, false) {
+				_, ok := GetPacketDescription(int(logical), direction)
+				assert.True(t, ok, "%s: unknown logical packet %d", version, logical)
+
+				other, ok := ids[id]
+				assert.False(t, ok, "%s: %s and %s share ID %d", version,
+					GetPacketName(logical, direction), GetPacketName(other, direction), id)
+				ids[id] = logical
+
+				resolved, ok := version.LogicalID(ConnStatePlay, direction, id)
+				assert.True(t, ok)
+				assert.Equal(t, logical, resolved)
+			}
+		}
+	}
+}
+
+func TestVersion_ID(t *testing.T) {
+	id, ok := V1_12_2.ID(ConnStatePlay, ConnS2C, ClientboundPluginMessage)
+	assert.True(t, ok)
+	assert.Equal(t, int32(0x18), id)
+
+	id, ok = V1_12_2.ID(ConnStatePlay, ConnC2S, ServerboundPlayerPosition)
+	assert.True(t, ok)
+	assert.Equal(t, int32(0x0D), id)
+
+	// Packets with a different layout are not mapped
+	_, ok = V1_12_2.ID(ConnStatePlay, ConnS2C, ClientboundJoinGame)
+	assert.False(t, ok)
+	_, ok = V1_12_2.LogicalID(ConnStatePlay, ConnS2C, 0x4D)
+	assert.False(t, ok)
+	_, ok = V1_12_2.ID(ConnStatePlay, ConnC2S, ServerboundUseEntity)
+	assert.False(t, ok)
+	_, ok = V1_12_2.LogicalID(ConnStatePlay, ConnC2S, 0x15)
+	assert.False(t, ok)
+	assert.True(t, V1_12_2.Supports(ConnC2S, ServerboundPlayerPosition, ServerboundChatMessage))
+	assert.False(t, V1_12_2.Supports(ConnC2S, ServerboundPlayerPosition, ServerboundUseEntity))
+
+	// Login is the same for all versions
+	id, ok = V1_12_2.ID(ConnStateLogin, ConnS2C, ClientboundLoginSuccess)
+	assert.True(t, ok)
+	assert.Equal(t, int32(ClientboundLoginSuccess), id)
+
+	id, ok = V1_8.LogicalID(ConnStatePlay, ConnS2C, ClientboundJoinGame)
+	assert.True(t, ok)
+	assert.Equal(t, int32(ClientboundJoinGame), id)
+}
+
+func TestGetVersion(t *testing.T) {
+	version, ok := GetVersion(340)
+	assert.True(t, ok)
+	assert.Equal(t, V1_12_2, version)
+
+	_, ok = GetVersion(5)
+	assert.False(t, ok)
+	assert.Equal(t, "1.8, 1.12.2", SupportedVersions())
+}
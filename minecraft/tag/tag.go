@@ -0,0 +1,479 @@
+// Package tag decodes Named Binary Tags into a tree that keeps the order and the types of the tags,
+// so that data edited through it is encoded back unchanged apart from the edits.
+package tag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	TagEnd byte = iota
+	TagByte
+	TagShort
+	TagInt
+	TagLong
+	TagFloat
+	TagDouble
+	TagByteArray
+	TagString
+	TagList
+	TagCompound
+	TagIntArray
+	TagLongArray
+)
+
+// Limits bound what is decoded, so that tags read from peers are checked before anything is allocated for them
+type Limits struct {
+	// MaxDepth bounds nesting of lists and compounds, the root is at depth zero
+	MaxDepth int
+	// MaxSize bounds the bytes of the tag, including its type and name
+	MaxSize int
+}
+
+// DefaultLimits bound tags which are in memory already, their size is bounded by the data only
+var DefaultLimits = Limits{MaxDepth: 512, MaxSize: int(^uint(0) >> 1)}
+
+var (
+	ErrTooDeep         = errors.New("tags are nested too deep")
+	ErrTooLarge        = errors.New("tag is too large")
+	ErrInvalidLength   = errors.New("invalid length")
+	ErrUnknownTag      = errors.New("unknown tag type")
+	ErrMixedList       = errors.New("list values are of different types")
+	ErrUnsupportedType = errors.New("value has no tag type")
+)
+
+// LimitError is returned when a tag is over the Limits, it matches ErrTooDeep or ErrTooLarge
+type LimitError struct {
+	Err   error
+	Value int
+	Max   int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: %d is over %d", e.Err, e.Value, e.Max)
+}
+
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+// Entry is a named tag of a compound. Values are int8, int16, int32, int64, float32, float64, []byte,
+// string, List, Compound, []int32 or []int64 for the respective tag types
+type Entry struct {
+	Name  string
+	Value interface{}
+}
+
+// Compound keeps the tags in the order they are encoded in
+type Compound []Entry
+
+type List struct {
+	// Type is the type of the values, it is kept for empty lists as well
+	Type   byte
+	Values []interface{}
+}
+
+func (c Compound) Get(name string) (interface{}, bool) {
+	for _, entry := range c {
+		if entry.Name == name {
+			return entry.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+func (c Compound) GetCompound(name string) (Compound, bool) {
+	value, _ := c.Get(name)
+	compound, ok := value.(Compound)
+	return compound, ok
+}
+
+func (c Compound) GetList(name string) (List, bool) {
+	value, _ := c.Get(name)
+	list, ok := value.(List)
+	return list, ok
+}
+
+func (c Compound) GetString(name string) (string, bool) {
+	value, _ := c.Get(name)
+	s, ok := value.(string)
+	return s, ok
+}
+
+// Set replaces the value of the tag in place, or appends the tag if there is none
+func (c *Compound) Set(name string, value interface{}) {
+	for i, entry := range *c {
+		if entry.Name == name {
+			(*c)[i].Value = value
+			return
+		}
+	}
+
+	*c = append(*c, Entry{Name: name, Value: value})
+}
+
+func (c *Compound) Delete(name string) {
+	for i, entry := range *c {
+		if entry.Name == name {
+			*c = append((*c)[:i], (*c)[i+1:]...)
+			return
+		}
+	}
+}
+
+// TypeOf returns the tag type of the value
+func TypeOf(value interface{}) (byte, error) {
+	switch value.(type) {
+	case int8:
+		return TagByte, nil
+	case int16:
+		return TagShort, nil
+	case int32:
+		return TagInt, nil
+	case int64:
+		return TagLong, nil
+	case float32:
+		return TagFloat, nil
+	case float64:
+		return TagDouble, nil
+	case []byte:
+		return TagByteArray, nil
+	case string:
+		return TagString, nil
+	case List:
+		return TagList, nil
+	case Compound:
+		return TagCompound, nil
+	case []int32:
+		return TagIntArray, nil
+	case []int64:
+		return TagLongArray, nil
+	}
+
+	return 0, fmt.Errorf("%w: %T", ErrUnsupportedType, value)
+}
+
+// Decode reads the payload of a compound, which is what follows its type and name
+func Decode(data []byte) (Compound, error) {
+	r := bytes.NewReader(data)
+	d := &decoder{r: r, limits: DefaultLimits}
+	compound, err := d.compound(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d bytes left after the compound", r.Len())
+	}
+
+	return compound, nil
+}
+
+// Read reads a whole tag of any type from the reader and returns its bytes, a single TagEnd if there is no tag.
+// The tag is checked against the limits as it is read
+func Read(r io.Reader, limits Limits) ([]byte, error) {
+	d := &decoder{r: r, limits: limits, raw: &bytes.Buffer{}}
+	var tagType byte
+	if err := d.read(&tagType); err != nil || tagType == TagEnd {
+		return d.raw.Bytes(), err
+	}
+
+	if _, err := d.string(); err != nil {
+		return d.raw.Bytes(), err
+	}
+
+	// The root is not held by a compound, what it holds is at depth zero like the entries of a compound Decode reads
+	_, err := d.value(tagType, -1)
+	return d.raw.Bytes(), err
+}
+
+// Encode writes the payload of the compound
+func Encode(compound Compound) ([]byte, error) {
+	var buf bytes.Buffer
+	err := encode(&buf, compound, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+type decoder struct {
+	r      io.Reader
+	limits Limits
+	// size is the amount of bytes read, raw keeps them if it is set
+	size int
+	raw  *bytes.Buffer
+}
+
+// take checks that n more bytes can be read, both against the limits and against the bytes left in the reader
+func (d *decoder) take(n int64, short error) error {
+	if int64(d.size)+n > int64(d.limits.MaxSize) {
+		return &LimitError{Err: ErrTooLarge, Value: int(int64(d.size) + n), Max: d.limits.MaxSize}
+	}
+
+	if r, ok := d.r.(interface{ Len() int }); ok && n > int64(r.Len()) {
+		return short
+	}
+
+	return nil
+}
+
+func (d *decoder) read(v interface{}) error {
+	size := binary.Size(v)
+	if err := d.take(int64(size), io.ErrUnexpectedEOF); err != nil {
+		return err
+	}
+
+	r := d.r
+	if d.raw != nil {
+		r = io.TeeReader(d.r, d.raw)
+	}
+
+	err := binary.Read(r, binary.BigEndian, v)
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	d.size += size
+	return err
+}
+
+// length reads the length of an array and checks it against the bytes left, elements take size bytes each
+func (d *decoder) length(size int) (int, error) {
+	var length int32
+	if err := d.read(&length); err != nil {
+		return 0, err
+	}
+
+	if length < 0 {
+		return 0, ErrInvalidLength
+	}
+
+	return int(length), d.take(int64(length)*int64(size), ErrInvalidLength)
+}
+
+func (d *decoder) string() (string, error) {
+	var length uint16
+	if err := d.read(&length); err != nil {
+		return "", err
+	}
+
+	if err := d.take(int64(length), io.ErrUnexpectedEOF); err != nil {
+		return "", err
+	}
+
+	s := make([]byte, length)
+	if err := d.read(s); err != nil {
+		return "", err
+	}
+
+	return string(s), nil
+}
+
+// tooDeep checks the depth of a list or a compound against the limits
+func (d *decoder) tooDeep(depth int) error {
+	if depth >= d.limits.MaxDepth {
+		return &LimitError{Err: ErrTooDeep, Value: depth + 1, Max: d.limits.MaxDepth}
+	}
+
+	return nil
+}
+
+func (d *decoder) compound(depth int) (Compound, error) {
+	if err := d.tooDeep(depth); err != nil {
+		return nil, err
+	}
+
+	compound := Compound{}
+	for {
+		var tagType byte
+		if err := d.read(&tagType); err != nil {
+			return nil, err
+		}
+
+		if tagType == TagEnd {
+			return compound, nil
+		}
+
+		name, err := d.string()
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := d.value(tagType, depth)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		compound = append(compound, Entry{Name: name, Value: value})
+	}
+}
+
+func (d *decoder) value(tagType byte, depth int) (interface{}, error) {
+	switch tagType {
+	case TagByte:
+		var v int8
+		return v, d.read(&v)
+	case TagShort:
+		var v int16
+		return v, d.read(&v)
+	case TagInt:
+		var v int32
+		return v, d.read(&v)
+	case TagLong:
+		var v int64
+		return v, d.read(&v)
+	case TagFloat:
+		var v float32
+		return v, d.read(&v)
+	case TagDouble:
+		var v float64
+		return v, d.read(&v)
+	case TagByteArray:
+		length, err := d.length(1)
+		if err != nil {
+			return nil, err
+		}
+
+		v := make([]byte, length)
+		return v, d.read(v)
+	case TagString:
+		return d.string()
+	case TagList:
+		return d.list(depth + 1)
+	case TagCompound:
+		return d.compound(depth + 1)
+	case TagIntArray:
+		length, err := d.length(4)
+		if err != nil {
+			return nil, err
+		}
+
+		v := make([]int32, length)
+		return v, d.read(v)
+	case TagLongArray:
+		length, err := d.length(8)
+		if err != nil {
+			return nil, err
+		}
+
+		v := make([]int64, length)
+		return v, d.read(v)
+	}
+
+	return nil, fmt.Errorf("%w %d", ErrUnknownTag, tagType)
+}
+
+func (d *decoder) list(depth int) (List, error) {
+	if err := d.tooDeep(depth); err != nil {
+		return List{}, err
+	}
+
+	var list List
+	if err := d.read(&list.Type); err != nil {
+		return List{}, err
+	}
+
+	// Every value takes at least a byte, except for compounds which take the end tag
+	length, err := d.length(1)
+	if err != nil {
+		return List{}, err
+	}
+
+	if length > 0 && list.Type == TagEnd {
+		return List{}, ErrUnknownTag
+	}
+
+	list.Values = make([]interface{}, 0, length)
+	for i := 0; i < length; i++ {
+		value, err := d.value(list.Type, depth)
+		if err != nil {
+			return List{}, err
+		}
+
+		list.Values = append(list.Values, value)
+	}
+
+	return list, nil
+}
+
+func writeString(buf *bytes.Buffer, s string) error {
+	if len(s) > math.MaxUint16 {
+		return ErrInvalidLength
+	}
+
+	_ = binary.Write(buf, binary.BigEndian, uint16(len(s)))
+	buf.WriteString(s)
+	return nil
+}
+
+func encode(buf *bytes.Buffer, value interface{}, depth int) error {
+	if depth >= DefaultLimits.MaxDepth {
+		return ErrTooDeep
+	}
+
+	switch v := value.(type) {
+	case int8, int16, int32, int64, float32, float64:
+		_ = binary.Write(buf, binary.BigEndian, v)
+	case []byte:
+		_ = binary.Write(buf, binary.BigEndian, int32(len(v)))
+		buf.Write(v)
+	case string:
+		return writeString(buf, v)
+	case List:
+		buf.WriteByte(v.Type)
+		_ = binary.Write(buf, binary.BigEndian, int32(len(v.Values)))
+		for _, element := range v.Values {
+			tagType, err := TypeOf(element)
+			if err != nil {
+				return err
+			}
+
+			if tagType != v.Type {
+				return ErrMixedList
+			}
+
+			err = encode(buf, element, depth+1)
+			if err != nil {
+				return err
+			}
+		}
+	case Compound:
+		for _, entry := range v {
+			tagType, err := TypeOf(entry.Value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", entry.Name, err)
+			}
+
+			buf.WriteByte(tagType)
+			err = writeString(buf, entry.Name)
+			if err != nil {
+				return err
+			}
+
+			err = encode(buf, entry.Value, depth+1)
+			if err != nil {
+				return fmt.Errorf("%s: %w", entry.Name, err)
+			}
+		}
+
+		buf.WriteByte(TagEnd)
+	case []int32:
+		_ = binary.Write(buf, binary.BigEndian, int32(len(v)))
+		_ = binary.Write(buf, binary.BigEndian, v)
+	case []int64:
+		_ = binary.Write(buf, binary.BigEndian, int32(len(v)))
+		_ = binary.Write(buf, binary.BigEndian, v)
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedType, value)
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package tag
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompound_RoundTrip(t *testing.T) {
+	compound := Compound{
+		{Name: "byte", Value: int8(-1)},
+		{Name: "short", Value: int16(300)},
+		{Name: "int", Value: int32(-70000)},
+		{Name: "long", Value: int64(1) << 40},
+		{Name: "float", Value: float32(0.5)},
+		{Name: "double", Value: -0.25},
+		{Name: "bytes", Value: []byte{1, 2, 3}},
+		{Name: "string", Value: "строка"},
+		{Name: "empty", Value: List{Type: TagShort, Values: []interface{}{}}},
+		{Name: "list", Value: List{Type: TagCompound, Values: []interface{}{Compound{}, Compound{{Name: "a", Value: "b"}}}}},
+		{Name: "compound", Value: Compound{{Name: "nested", Value: Compound{}}}},
+		{Name: "ints", Value: []int32{-1, 0, 1}},
+		{Name: "longs", Value: []int64{1 << 62}},
+	}
+
+	data, err := Encode(compound)
+	assert.NoError(t, err)
+
+	decoded, err := Decode(data)
+	assert.NoError(t, err)
+	assert.Equal(t, compound, decoded)
+
+	encoded, err := Encode(decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, data, encoded)
+}
+
+func TestCompound_Set(t *testing.T) {
+	compound := Compound{{Name: "a", Value: int8(1)}, {Name: "b", Value: int8(2)}}
+	compound.Set("a", "replaced")
+	compound.Set("c", int8(3))
+	compound.Delete("b")
+	compound.Delete("missing")
+
+	assert.Equal(t, Compound{{Name: "a", Value: "replaced"}, {Name: "c", Value: int8(3)}}, compound)
+
+	s, ok := compound.GetString("a")
+	assert.True(t, ok)
+	assert.Equal(t, "replaced", s)
+
+	_, ok = compound.GetCompound("a")
+	assert.False(t, ok)
+}
+
+func TestDecode_Malformed(t *testing.T) {
+	deep := bytes.Repeat([]byte{TagCompound, 0, 0}, DefaultLimits.MaxDepth+1)
+
+	for _, test := range []struct {
+		data []byte
+		err  error
+	}{
+		{[]byte{}, io.ErrUnexpectedEOF},
+		{[]byte{TagString, 0, 1, 'a', 0, 5, 'b'}, io.ErrUnexpectedEOF},
+		{[]byte{TagByteArray, 0, 0, 0x7F, 0xFF, 0xFF, 0xFF, 0}, ErrInvalidLength},
+		{[]byte{TagIntArray, 0, 0, 0xFF, 0xFF, 0xFF, 0xFF, 0}, ErrInvalidLength},
+		{[]byte{TagList, 0, 0, TagEnd, 0, 0, 0, 1, 0}, ErrUnknownTag},
+		{[]byte{0x20, 0, 0, 0}, ErrUnknownTag},
+		{deep, ErrTooDeep},
+	} {
+		_, err := Decode(test.data)
+		assert.True(t, errors.Is(err, test.err), "%v is not %v", err, test.err)
+	}
+
+	_, err := Decode([]byte{TagEnd, TagEnd})
+	assert.Error(t, err)
+}
+
+func TestEncode_Invalid(t *testing.T) {
+	_, err := Encode(Compound{{Name: "int", Value: 1}})
+	assert.True(t, errors.Is(err, ErrUnsupportedType))
+
+	_, err = Encode(Compound{{Name: "list", Value: List{Type: TagByte, Values: []interface{}{int8(1), "a"}}}})
+	assert.Equal(t, ErrMixedList, errors.Unwrap(err))
+}
+
+func TestRead(t *testing.T) {
+	payload, err := Encode(Compound{{Name: "list", Value: List{Type: TagCompound, Values: []interface{}{Compound{}}}}})
+	assert.NoError(t, err)
+
+	// The tag is read up to its end, whatever follows is left in the reader
+	data := append([]byte{TagCompound, 0, 1, 'a'}, payload...)
+	r := bytes.NewReader(append(data, 0xFF))
+	raw, err := Read(r, Limits{MaxDepth: 3, MaxSize: len(data)})
+	assert.NoError(t, err)
+	assert.Equal(t, data, raw)
+	assert.Equal(t, 1, r.Len())
+
+	raw, err = Read(bytes.NewReader([]byte{TagEnd}), DefaultLimits)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{TagEnd}, raw)
+
+	var limitErr *LimitError
+	_, err = Read(bytes.NewReader(data), Limits{MaxDepth: 2, MaxSize: len(data)})
+	assert.True(t, errors.As(err, &limitErr) && errors.Is(err, ErrTooDeep), "%v", err)
+
+	_, err = Read(bytes.NewReader(data), Limits{MaxDepth: 3, MaxSize: len(data) - 1})
+	assert.True(t, errors.As(err, &limitErr) && errors.Is(err, ErrTooLarge), "%v", err)
+}
@@ -20,6 +20,11 @@ func (a *AntiKnockback) GetIdentifier() string {
 	return modules.ModuleAntiKnockback
 }
 
+func (a *AntiKnockback) Register(tunnel generic.Tunnel) {
+	a.SimpleModule.Register(tunnel)
+	a.RegisterHandler(protocol.ConnS2C, protocol.ClientboundEntityVelocity, &protocol.EntityVelocity{}, HandleEntityVelocity)
+}
+
 func HandleEntityVelocity(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	entityVelocity := packet.(*protocol.EntityVelocity)
 	moduleHandler := tunnel.GetModuleHandler()
@@ -31,7 +36,8 @@ func HandleEntityVelocity(packet protocol.Packet, tunnel generic.Tunnel) (result
 		entityVelocity.VX = pk.Short(antiKnockback.X)
 		entityVelocity.VY = pk.Short(antiKnockback.Y)
 		entityVelocity.VZ = pk.Short(antiKnockback.Z)
+		return generic.ModifyPacket(), nil
 	}
 
-	return generic.ModifyPacket(entityVelocity.Marshal()), nil
+	return generic.PassPacket(), nil
 }
@@ -7,24 +7,24 @@ import (
 	"github.com/destructiqn/kogtevran/modules"
 )
 
-type Predicate func(entity minecraft.Entity) bool
-
 type GenericAura struct {
 	modules.SimpleTickingModule
 	MaxDistance  float64 `option:"maxDistance"`
 	HitAnimation bool    `option:"hitAnimation"`
-	Filter       Predicate
+	Filter       minecraft.EntityFilter
+}
+
+// SupportsVersion requires the entities and the player to be tracked, attacks are sent as 1.8 encodes them
+func (a *GenericAura) SupportsVersion(version *protocol.Version) bool {
+	return version.Supports(protocol.ConnC2S, protocol.ServerboundUseEntity) &&
+		version.Supports(protocol.ConnS2C, protocol.ClientboundJoinGame, protocol.ClientboundPlayerPositionAndLook,
+			protocol.ClientboundSpawnPlayer, protocol.ClientboundSpawnMob)
 }
 
 func (a *GenericAura) Tick() error {
-	a.Tunnel.GetEntityHandler().Lock()
 	location := a.Tunnel.GetPlayerHandler().GetLocation()
-	for id, entity := range a.Tunnel.GetEntityHandler().GetEntities() {
-		if !a.Filter(entity) || entity.GetLocation().Distance(location) > a.MaxDistance {
-			continue
-		}
-
-		err := a.Tunnel.GetPlayerHandler().Attack(id)
+	for _, entity := range a.Tunnel.GetEntityHandler().GetEntitiesWithin(location, a.MaxDistance, a.Filter) {
+		err := a.Tunnel.GetPlayerHandler().Attack(entity.GetEntityID())
 		if err != nil {
 			return err
 		}
@@ -34,6 +34,5 @@ func (a *GenericAura) Tick() error {
 		}
 	}
 
-	a.Tunnel.GetEntityHandler().Unlock()
 	return nil
 }
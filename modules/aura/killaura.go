@@ -21,15 +21,10 @@ func (k *KillAura) GetDescription() []string {
 }
 
 func (k *KillAura) Register(tunnel generic.Tunnel) {
-	k.GenericAura.Filter = k.Predicate
+	k.GenericAura.Filter = minecraft.IsPlayer
 	k.GenericAura.Register(tunnel)
 }
 
 func (k *KillAura) GetIdentifier() string {
 	return modules.ModuleKillAura
 }
-
-func (k *KillAura) Predicate(entity minecraft.Entity) bool {
-	_, ok := entity.(*minecraft.Player)
-	return ok
-}
@@ -21,15 +21,10 @@ func (m *MobAura) GetDescription() []string {
 }
 
 func (m *MobAura) Register(tunnel generic.Tunnel) {
-	m.GenericAura.Filter = m.Predicate
+	m.GenericAura.Filter = minecraft.IsMob
 	m.GenericAura.Register(tunnel)
 }
 
 func (m *MobAura) GetIdentifier() string {
 	return modules.ModuleMobAura
 }
-
-func (m *MobAura) Predicate(entity minecraft.Entity) bool {
-	_, ok := entity.(*minecraft.Player)
-	return !ok
-}
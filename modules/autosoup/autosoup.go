@@ -3,16 +3,14 @@ package autosoup
 import (
 	"errors"
 	"github.com/destructiqn/kogtevran/generic"
+	"github.com/destructiqn/kogtevran/minecraft"
+	"github.com/destructiqn/kogtevran/minecraft/items"
 	"github.com/destructiqn/kogtevran/minecraft/protocol"
 	"github.com/destructiqn/kogtevran/modules"
 )
 
-var HotbarSlots = []int{36, 37, 38, 39, 40, 41, 42, 43, 44}
-
-const (
-	SoupID   = 282
-	SoupSlot = 44
-)
+// SoupHotbarIndex is where soups are put when there are none in the hotbar
+const SoupHotbarIndex = 8
 
 type AutoSoup struct {
 	modules.SimpleModule
@@ -32,78 +30,32 @@ func (a *AutoSoup) GetDescription() []string {
 	}
 }
 
-func (a *AutoSoup) GetSlotWithSoup() int {
-	inventory, ok := a.Tunnel.GetInventoryHandler().GetWindow(0)
-	if !ok {
-		return 0
-	}
-
-	for _, slot := range HotbarSlots {
-		inventory.Lock()
-		if inventory.GetItem(slot).BlockID == SoupID {
-			inventory.Unlock()
-			return slot
-		} else {
-			inventory.Unlock()
-		}
-	}
-
-	// If there was no slot with soup
-	return a.PrepareSoup()
+func (a *AutoSoup) Register(tunnel generic.Tunnel) {
+	a.SimpleModule.Register(tunnel)
+	a.RegisterHandler(protocol.ConnS2C, protocol.ClientboundUpdateHealth, &protocol.UpdateHealth{}, HandleUpdateHealth)
 }
 
-func (a *AutoSoup) PrepareSoup() int {
-	inventory, ok := a.Tunnel.GetInventoryHandler().GetWindow(0)
-	if !ok {
-		return 0
-	}
-
-	soupSlot := a.FindSoup()
-	if soupSlot == 0 {
-		return 0
-	}
-
-	err := inventory.Move(soupSlot, SoupSlot)
-	if err != nil {
-		return 0
-	}
-
-	return SoupSlot
+// SupportsVersion requires the inventory to be tracked, soups are used with the block placement of 1.8
+func (a *AutoSoup) SupportsVersion(version *protocol.Version) bool {
+	return version.Supports(protocol.ConnC2S, protocol.ServerboundPlayerBlockPlacement) &&
+		version.Supports(protocol.ConnS2C, protocol.ClientboundWindowItems, protocol.ClientboundUpdateHealth)
 }
 
-func (a *AutoSoup) FindSoup() int {
+func (a *AutoSoup) UseSoup() error {
+	playerHandler := a.Tunnel.GetPlayerHandler()
 	inventory, ok := a.Tunnel.GetInventoryHandler().GetWindow(0)
 	if !ok {
-		return 0
-	}
-
-	inventory.Lock()
-	defer inventory.Unlock()
-
-	for slot, item := range inventory.GetContents() {
-		if item.BlockID == SoupID {
-			return slot
-		}
+		return errors.New("inventory is not available")
 	}
 
-	return 0
-}
-
-func (a *AutoSoup) UseSoup(slot int) error {
-	playerHandler := a.Tunnel.GetPlayerHandler()
-	err := playerHandler.ChangeSlot(slot - 36)
+	index, err := a.Tunnel.GetInventoryHandler().SelectItem(minecraft.IsItem(int16(items.MushroomStew.ID)), SoupHotbarIndex)
 	if err != nil {
 		return err
 	}
 
-	inventory, ok := a.Tunnel.GetInventoryHandler().GetWindow(0)
-	if !ok {
-		return errors.New("inventory is not available")
-	}
-
 	use := protocol.PlayerBlockPlacement{
 		Location:        playerHandler.GetLocation().ToPosition(),
-		HeldItem:        inventory.GetItem(slot),
+		HeldItem:        inventory.GetItem(inventory.GetLayout().HotbarSlot(index)),
 		Face:            -1,
 		CursorPositionX: -1,
 		CursorPositionY: -1,
@@ -123,12 +75,11 @@ func HandleUpdateHealth(_ protocol.Packet, tunnel generic.Tunnel) (result *gener
 		module, _ := tunnel.GetModuleHandler().GetModule(modules.ModuleAutoSoup)
 		autoSoup := module.(*AutoSoup)
 		if tunnel.GetPlayerHandler().GetHealth() < autoSoup.MinHealth {
-			slot := autoSoup.GetSlotWithSoup()
-			if slot == 0 {
+			err = autoSoup.UseSoup()
+			if err == generic.ErrItemNotFound {
 				return generic.PassPacket(), nil
 			}
 
-			err = autoSoup.UseSoup(slot)
 			if err != nil {
 				return nil, err
 			}
@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/destructiqn/kogtevran/generic"
-	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
 	"github.com/destructiqn/kogtevran/minecraft/protocol"
 	"github.com/destructiqn/kogtevran/modules"
 )
@@ -21,37 +20,44 @@ func (c *ChestStealer) GetIdentifier() string {
 	return modules.ModuleChestStealer
 }
 
-func HandleOpenWindow(packet protocol.Packet, tunnel generic.Tunnel) (result pk.Packet, next bool, err error) {
+func (c *ChestStealer) Register(tunnel generic.Tunnel) {
+	c.SimpleModule.Register(tunnel)
+	c.RegisterHandler(protocol.ConnS2C, protocol.ClientboundOpenWindow, &protocol.OpenWindow{}, HandleOpenWindow)
+	c.RegisterHandler(protocol.ConnS2C, protocol.ClientboundSetSlot, &protocol.SetSlot{}, HandleSetSlot)
+	c.RegisterHandler(protocol.ConnS2C, protocol.ClientboundWindowItems, &protocol.WindowItems{}, HandleWindowItems)
+}
+
+func HandleOpenWindow(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	openWindow := packet.(*protocol.OpenWindow)
 
 	if tunnel.GetModuleHandler().IsModuleEnabled(modules.ModuleChestStealer) {
 		window, ok := tunnel.GetInventoryHandler().GetWindow(int(openWindow.WindowID))
 		if ok && IsSuitable(window) {
-			return pk.Packet{}, false, nil
+			return generic.RejectPacket(), nil
 		}
 	}
 
-	return packet.Marshal(), true, nil
+	return generic.PassPacket(), nil
 }
 
-func HandleSetSlot(packet protocol.Packet, tunnel generic.Tunnel) (result pk.Packet, next bool, err error) {
+func HandleSetSlot(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	setSlot := packet.(*protocol.SetSlot)
 	if tunnel.GetModuleHandler().IsModuleEnabled(modules.ModuleChestStealer) {
 		window, ok := tunnel.GetInventoryHandler().GetWindow(int(setSlot.WindowID))
 		if ok && IsSuitable(window) {
-			err = tunnel.WriteServer(TakeItem(int(setSlot.WindowID), int(setSlot.Slot), setSlot.SlotData).Marshal())
+			_, err = window.Click(int(setSlot.Slot), 1, 0)
 			if err != nil {
 				return
 			}
 
-			return pk.Packet{}, false, nil
+			return generic.RejectPacket(), nil
 		}
 	}
 
-	return setSlot.Marshal(), true, nil
+	return generic.PassPacket(), nil
 }
 
-func HandleWindowItems(packet protocol.Packet, tunnel generic.Tunnel) (result pk.Packet, next bool, err error) {
+func HandleWindowItems(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	windowItems := packet.(*protocol.WindowItems)
 	if tunnel.GetModuleHandler().IsModuleEnabled(modules.ModuleChestStealer) {
 		window, ok := tunnel.GetInventoryHandler().GetWindow(int(windowItems.WindowID))
@@ -67,27 +73,17 @@ func HandleWindowItems(packet protocol.Packet, tunnel generic.Tunnel) (result pk
 					continue
 				}
 
-				err = tunnel.WriteServer(TakeItem(int(windowItems.WindowID), slot, item).Marshal())
+				_, err = window.Click(slot, 1, 0)
 				if err != nil {
 					return
 				}
 			}
 
-			return pk.Packet{}, false, nil
+			return generic.RejectPacket(), nil
 		}
 	}
 
-	return windowItems.Marshal(), true, nil
-}
-
-func TakeItem(windowID, slot int, item pk.Slot) protocol.Packet {
-	return &protocol.ClickWindow{
-		WindowID:     pk.UnsignedByte(windowID),
-		Slot:         pk.Short(slot),
-		ActionNumber: pk.Short(slot),
-		Mode:         1,
-		ClickedItem:  item,
-	}
+	return generic.PassPacket(), nil
 }
 
 func IsSuitable(window generic.Window) bool {
@@ -18,6 +18,11 @@ func (f *FastBreak) GetIdentifier() string {
 	return modules.ModuleFastBreak
 }
 
+func (f *FastBreak) Register(tunnel generic.Tunnel) {
+	f.SimpleModule.Register(tunnel)
+	f.RegisterHandler(protocol.ConnC2S, protocol.ServerboundPlayerDigging, &protocol.PlayerDigging{}, HandlePlayerDigging)
+}
+
 func HandlePlayerDigging(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	playerDigging := packet.(*protocol.PlayerDigging)
 	if tunnel.GetModuleHandler().IsModuleEnabled(modules.ModuleFastBreak) {
@@ -28,7 +33,7 @@ func HandlePlayerDigging(packet protocol.Packet, tunnel generic.Tunnel) (result
 			}
 
 			playerDigging.Status = 2
-			return generic.ModifyPacket(playerDigging.Marshal()), nil
+			return generic.ModifyPacket(), nil
 		}
 	}
 
@@ -28,6 +28,11 @@ func (f *Flight) GetIdentifier() string {
 	return modules.ModuleFlight
 }
 
+func (f *Flight) Register(tunnel generic.Tunnel) {
+	f.DefaultModule.Register(tunnel)
+	f.RegisterHandler(protocol.ConnS2C, protocol.ClientboundPlayerAbilities, &protocol.PlayerAbilities{}, HandlePlayerAbilities)
+}
+
 func (f *Flight) Toggle() (bool, error) {
 	f.Enabled = !f.Enabled
 	err := f.Update()
@@ -53,7 +58,12 @@ func (f *Flight) Update() error {
 func HandlePlayerAbilities(_ protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	if tunnel.GetModuleHandler().IsModuleEnabled(modules.ModuleFlight) {
 		go func(conn generic.Tunnel) {
-			time.Sleep(100 * time.Millisecond)
+			select {
+			case <-time.After(100 * time.Millisecond):
+			case <-conn.GetContext().Done():
+				return
+			}
+
 			module, _ := conn.GetModuleHandler().GetModule(modules.ModuleFlight)
 			err = module.(*Flight).Update()
 		}(tunnel)
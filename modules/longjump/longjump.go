@@ -29,6 +29,14 @@ func (l *LongJump) GetIdentifier() string {
 	return modules.ModuleLongJump
 }
 
+func (l *LongJump) Register(tunnel generic.Tunnel) {
+	l.SimpleModule.Register(tunnel)
+
+	// Jump detection compares new position against the previous one, so it has to run before it gets updated
+	l.RegisterPriorityHandler(generic.PriorityEarly, protocol.ConnC2S, protocol.ServerboundPlayerPosition, &protocol.PlayerPosition{}, HandlePlayerPosition)
+	l.RegisterPriorityHandler(generic.PriorityEarly, protocol.ConnC2S, protocol.ServerboundPlayerPositionAndLook, &protocol.ServerPlayerPositionAndLook{}, HandleServerPlayerPositionAndLook)
+}
+
 func (l *LongJump) Boost(yaw float64) (x int16, y int16, z int16) {
 	x = int16(-math.Sin(float64(yaw)*(math.Pi/180.0)) * l.Power * 5000)
 	y = int16(2500 * l.Height)
@@ -1,9 +1,11 @@
 package modules
 
 import (
+	"context"
 	"time"
 
 	"github.com/destructiqn/kogtevran/generic"
+	"github.com/destructiqn/kogtevran/minecraft/protocol"
 )
 
 const (
@@ -46,7 +48,18 @@ func (m *DefaultModule) Update() error {
 }
 
 func (m *DefaultModule) Close() {
-	// Do nothing by default
+	if m.Tunnel != nil {
+		m.Tunnel.GetHandlerRegistry().UnregisterHandlers(m)
+	}
+}
+
+// RegisterHandler subscribes the module to a play state packet, the handler is removed when the module is closed
+func (m *DefaultModule) RegisterHandler(direction int, id int32, packet protocol.Packet, handler generic.PacketHandler) {
+	m.RegisterPriorityHandler(generic.PriorityNormal, direction, id, packet, handler)
+}
+
+func (m *DefaultModule) RegisterPriorityHandler(priority generic.HandlerPriority, direction int, id int32, packet protocol.Packet, handler generic.PacketHandler) {
+	m.Tunnel.GetHandlerRegistry().RegisterHandler(m, protocol.ConnStatePlay, direction, id, packet, priority, handler)
 }
 
 func (m *DefaultModule) SetEnabled(enabled bool) {
@@ -64,21 +77,23 @@ func (s *SimpleModule) Toggle() (bool, error) {
 
 type SimpleTickingModule struct {
 	SimpleModule
-	Interval        time.Duration `option:"interval"`
-	InterruptTicker chan bool
+	Interval time.Duration `option:"interval"`
+
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func (m *SimpleTickingModule) GetInterval() time.Duration {
 	return m.Interval
 }
 
-func (m *SimpleTickingModule) GetInterruptChannel() chan bool {
-	return m.InterruptTicker
+func (m *SimpleTickingModule) GetContext() context.Context {
+	return m.ctx
 }
 
 func (m *SimpleTickingModule) Register(tunnel generic.Tunnel) {
 	m.SimpleModule.Register(tunnel)
-	m.InterruptTicker = make(chan bool)
+	m.ctx, m.cancel = context.WithCancel(tunnel.GetContext())
 }
 
 func (m *SimpleTickingModule) Close() {
@@ -87,7 +102,9 @@ func (m *SimpleTickingModule) Close() {
 }
 
 func (m *SimpleTickingModule) StopTicker() {
-	m.InterruptTicker <- true
+	if m.cancel != nil {
+		m.cancel()
+	}
 }
 
 type ClientModule struct {
@@ -2,23 +2,16 @@ package nobadeffects
 
 import (
 	"github.com/destructiqn/kogtevran/generic"
+	"github.com/destructiqn/kogtevran/minecraft/effects"
 	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
 	"github.com/destructiqn/kogtevran/minecraft/protocol"
 	"github.com/destructiqn/kogtevran/modules"
 )
 
-var BadEffects = map[pk.Byte]bool{
-	2:  true,
-	4:  true,
-	7:  true,
-	9:  true,
-	15: true,
-	17: true,
-	18: true,
-	19: true,
-	20: true,
-	25: true,
-	27: true,
+// IsBad reports whether the effect is a negative one, unknown effects are kept
+func IsBad(effectID pk.Byte) bool {
+	effect, ok := effects.ByID[effects.ID(effectID)]
+	return ok && effect.Type == "bad"
 }
 
 type NoBadEffects struct {
@@ -33,6 +26,11 @@ func (n *NoBadEffects) GetIdentifier() string {
 	return modules.ModuleNoBadEffects
 }
 
+func (n *NoBadEffects) Register(tunnel generic.Tunnel) {
+	n.SimpleModule.Register(tunnel)
+	n.RegisterHandler(protocol.ConnS2C, protocol.ClientboundEntityEffect, &protocol.EntityEffect{}, HandleEntityEffect)
+}
+
 func (n *NoBadEffects) Toggle() (bool, error) {
 	value, err := n.SimpleModule.Toggle()
 	if err != nil {
@@ -42,10 +40,14 @@ func (n *NoBadEffects) Toggle() (bool, error) {
 	if value {
 		entityID := n.Tunnel.GetPlayerHandler().GetEntityID()
 
-		for id := range BadEffects {
+		for id, effect := range effects.ByID {
+			if effect.Type != "bad" {
+				continue
+			}
+
 			packet := &protocol.RemoveEntityEffect{
 				EntityID: pk.VarInt(entityID),
-				EffectID: id,
+				EffectID: pk.Byte(id),
 			}
 
 			err := n.Tunnel.WriteClient(packet.Marshal())
@@ -62,7 +64,7 @@ func HandleEntityEffect(packet protocol.Packet, tunnel generic.Tunnel) (result *
 	entityEffect := packet.(*protocol.EntityEffect)
 
 	if tunnel.GetModuleHandler().IsModuleEnabled(modules.ModuleNoBadEffects) {
-		if v, ok := BadEffects[entityEffect.EffectID]; ok && v {
+		if IsBad(entityEffect.EffectID) {
 			return generic.RejectPacket(), nil
 		}
 	}
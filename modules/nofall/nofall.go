@@ -18,11 +18,19 @@ func (n *NoFall) GetIdentifier() string {
 	return modules.ModuleNoFall
 }
 
+func (n *NoFall) Register(tunnel generic.Tunnel) {
+	n.SimpleModule.Register(tunnel)
+	n.RegisterHandler(protocol.ConnC2S, protocol.ServerboundPlayer, &protocol.Player{}, HandlePlayer)
+	n.RegisterHandler(protocol.ConnC2S, protocol.ServerboundPlayerPosition, &protocol.PlayerPosition{}, HandlePlayerPosition)
+	n.RegisterHandler(protocol.ConnC2S, protocol.ServerboundPlayerLook, &protocol.PlayerLook{}, HandlePlayerLook)
+	n.RegisterHandler(protocol.ConnC2S, protocol.ServerboundPlayerPositionAndLook, &protocol.ServerPlayerPositionAndLook{}, HandleServerPlayerPositionAndLook)
+}
+
 func HandlePlayer(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	player := packet.(*protocol.Player)
 	if tunnel.GetModuleHandler().IsModuleEnabled(modules.ModuleNoFall) {
 		player.OnGround = true
-		return generic.ModifyPacket(player.Marshal()), nil
+		return generic.ModifyPacket(), nil
 	}
 	return generic.PassPacket(), nil
 }
@@ -32,7 +40,7 @@ func HandlePlayerPosition(packet protocol.Packet, tunnel generic.Tunnel) (result
 
 	if tunnel.GetModuleHandler().IsModuleEnabled(modules.ModuleNoFall) {
 		playerPosition.OnGround = true
-		return generic.ModifyPacket(playerPosition.Marshal()), nil
+		return generic.ModifyPacket(), nil
 	}
 
 	return generic.PassPacket(), nil
@@ -43,7 +51,7 @@ func HandlePlayerLook(packet protocol.Packet, tunnel generic.Tunnel) (result *ge
 
 	if tunnel.GetModuleHandler().IsModuleEnabled(modules.ModuleNoFall) {
 		playerLook.OnGround = true
-		return generic.ModifyPacket(playerLook.Marshal()), nil
+		return generic.ModifyPacket(), nil
 	}
 
 	return generic.PassPacket(), nil
@@ -54,7 +62,7 @@ func HandleServerPlayerPositionAndLook(packet protocol.Packet, tunnel generic.Tu
 
 	if tunnel.GetModuleHandler().IsModuleEnabled(modules.ModuleNoFall) {
 		playerPosition.OnGround = true
-		return generic.ModifyPacket(playerPosition.Marshal()), nil
+		return generic.ModifyPacket(), nil
 	}
 
 	return generic.PassPacket(), nil
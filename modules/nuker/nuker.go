@@ -38,28 +38,47 @@ func (n *Nuker) GetIdentifier() string {
 	return modules.ModuleNuker
 }
 
+func (n *Nuker) Register(tunnel generic.Tunnel) {
+	n.SimpleTickingModule.Register(tunnel)
+	n.RegisterHandler(protocol.ConnS2C, protocol.ClientboundBlockChange, &protocol.BlockChange{}, HandleBlockChange)
+}
+
 func (n *Nuker) Toggle() (bool, error) {
-    v, err := n.SimpleTickingModule.Toggle()
-    if n.toggleQueue != nil {
-        n.toggleQueue <- v
-    }
-    return v, err
+	v, err := n.SimpleTickingModule.Toggle()
+	n.queueLock.Lock()
+	toggleQueue := n.toggleQueue
+	n.queueLock.Unlock()
+
+	if toggleQueue != nil {
+		select {
+		case toggleQueue <- v:
+		case <-n.GetContext().Done():
+		}
+	}
+	return v, err
 }
 
 func (n *Nuker) Tick() error {
 	center := n.Tunnel.GetPlayerHandler().GetLocation()
+	world := n.Tunnel.GetWorldHandler()
 
+	n.queueLock.Lock()
 	if n.breakQueue == nil {
 		n.breakQueue = make(chan *Task)
 		n.toggleQueue = make(chan bool)
 		n.backlog = make(map[pk.Position]bool)
 		go n.handleQueue()
 	}
+	n.queueLock.Unlock()
 
 	for x := int(center.X) - n.Radius; x <= int(center.X)+n.Radius; x++ {
 		for y := int(center.Y) - n.Radius; y <= int(center.Y)+n.Radius; y++ {
 			for z := int(center.Z) - n.Radius; z <= int(center.Z)+n.Radius; z++ {
 				position := pk.Position{X: x, Y: y, Z: z}
+				if !world.GetBlock(position).Diggable {
+					continue
+				}
+
 				n.queueLock.Lock()
 				
 				if _, ok := n.backlog[position]; !ok {
@@ -117,6 +136,11 @@ func BreakBlock(location pk.Position, delay time.Duration, tunnel generic.Tunnel
 	}
 
 	_ = tunnel.WriteServer(start.Marshal())
-	time.Sleep(delay)
+	select {
+	case <-time.After(delay):
+	case <-tunnel.GetContext().Done():
+		return
+	}
+
 	_ = tunnel.WriteServer(finish.Marshal())
 }
@@ -12,6 +12,7 @@ type Task struct {
 }
 
 func (n *Nuker) handleQueue() {
+	done := n.GetContext().Done()
 	for {
 		select {
 		case task := <-n.breakQueue:
@@ -23,20 +24,29 @@ func (n *Nuker) handleQueue() {
 		case status := <-n.toggleQueue:
 			if !status {
 				// If disabled, wait for enable
-				<-n.toggleQueue
+				select {
+				case <-n.toggleQueue:
+				case <-done:
+					return
+				}
 			}
+		case <-done:
+			return
 		}
 	}
 }
 
 func (n *Nuker) enqueue(task *Task) {
-    n.queueLock.Lock()
-    if _, ok := n.backlog[task.Location]; !ok && n.backlog != nil {
-        n.backlog[task.Location] = true
-        n.queueLock.Unlock()
+	n.queueLock.Lock()
+	if _, ok := n.backlog[task.Location]; !ok && n.backlog != nil {
+		n.backlog[task.Location] = true
+		n.queueLock.Unlock()
 
-        n.breakQueue <- task
-    } else {
-        n.queueLock.Unlock()
-    }
+		select {
+		case n.breakQueue <- task:
+		case <-n.GetContext().Done():
+		}
+	} else {
+		n.queueLock.Unlock()
+	}
 }
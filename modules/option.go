@@ -2,7 +2,9 @@ package modules
 
 import (
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/destructiqn/kogtevran/generic"
 )
@@ -28,6 +30,22 @@ func SetOptionValue(module generic.Module, name string, newValue interface{}) bo
 	return true
 }
 
+// ParseOptionValue parses the text as a value of the type the option currently has
+func ParseOptionValue(current interface{}, raw string) (interface{}, error) {
+	switch current.(type) {
+	case string:
+		return raw, nil
+	case bool:
+		return strings.ToLower(raw) == "true" || raw == "1", nil
+	case float64:
+		return strconv.ParseFloat(raw, 64)
+	case time.Duration:
+		return time.ParseDuration(raw)
+	default:
+		return strconv.Atoi(raw)
+	}
+}
+
 func getField(value reflect.Value, name string) (reflect.StructField, reflect.Value, bool) {
 	defer func() {
 		recover()
@@ -16,6 +16,11 @@ func (s *SpeedHack) GetIdentifier() string {
 	return modules.ModuleSpeedHack
 }
 
+func (s *SpeedHack) Register(tunnel generic.Tunnel) {
+	s.SimpleModule.Register(tunnel)
+	s.RegisterHandler(protocol.ConnS2C, protocol.ClientboundEntityProperties, &protocol.EntityProperties{}, HandleEntityProperties)
+}
+
 func (s *SpeedHack) GetDescription() []string {
 	return []string{
 		"Увеличивает скорость передвижения",
@@ -49,7 +54,7 @@ func HandleEntityProperties(packet protocol.Packet, tunnel generic.Tunnel) (resu
 			entityProperties.Properties = append(entityProperties.Properties, fakeSpeed)
 		}
 
-		return generic.ModifyPacket(entityProperties.Marshal()), nil
+		return generic.ModifyPacket(), nil
 	}
 
 	return generic.PassPacket(), nil
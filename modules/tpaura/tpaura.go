@@ -31,9 +31,21 @@ func (t *TPAura) GetDescription() []string {
 	}
 }
 
+// SupportsVersion requires the entities and the player to be tracked, the teleport is sent to the client as well
+func (t *TPAura) SupportsVersion(version *protocol.Version) bool {
+	return version.Supports(protocol.ConnC2S, protocol.ServerboundPlayerPosition) &&
+		version.Supports(protocol.ConnS2C, protocol.ClientboundJoinGame, protocol.ClientboundPlayerPositionAndLook,
+			protocol.ClientboundSpawnPlayer, protocol.ClientboundEntityTeleport)
+}
+
 func (t *TPAura) Tick() error {
 	playerHandler := t.Tunnel.GetPlayerHandler()
 	playerLocation := playerHandler.GetLocation()
+	if t.CurrentTarget != nil {
+		// Target is a snapshot, so the current state is taken on every tick
+		t.CurrentTarget, _ = t.Tunnel.GetEntityHandler().GetEntity(t.CurrentTarget.GetEntityID())
+	}
+
 	if t.CurrentTarget == nil || t.CurrentTarget.GetLocation().Distance(playerLocation) > t.SearchRadius {
 		t.CurrentTarget = t.PickEntity()
 	}
@@ -86,15 +98,17 @@ func (t *TPAura) PickLocation(center *minecraft.Location) *minecraft.Location {
 
 func (t *TPAura) PickEntity() minecraft.Entity {
 	location := t.Tunnel.GetPlayerHandler().GetLocation()
-	for _, entity := range t.Tunnel.GetEntityHandler().GetEntities() {
-		if entity.GetLocation().Distance(location) > t.SearchRadius {
-			continue
-		}
-
-		return entity
+	entities := t.Tunnel.GetEntityHandler().GetEntitiesWithin(location, t.SearchRadius, isTarget)
+	if len(entities) == 0 {
+		return nil
 	}
 
-	return nil
+	return entities[0]
+}
+
+// isTarget leaves out objects such as dropped items and arrows, only players and mobs are attacked
+func isTarget(entity minecraft.Entity) bool {
+	return minecraft.IsPlayer(entity) || minecraft.IsMob(entity)
 }
 
 func (t *TPAura) GetIdentifier() string {
@@ -20,6 +20,11 @@ func (u *UnlimitedCPS) GetIdentifier() string {
 	return modules.ModuleUnlimitedCPS
 }
 
+func (u *UnlimitedCPS) Register(tunnel generic.Tunnel) {
+	u.DefaultModule.Register(tunnel)
+	u.RegisterHandler(protocol.ConnS2C, protocol.ClientboundJoinGame, &protocol.JoinGame{}, HandleJoinGame)
+}
+
 func (u *UnlimitedCPS) Toggle() (bool, error) {
 	u.Enabled = !u.Enabled
 	return u.Enabled, u.Update()
@@ -36,7 +41,12 @@ func (u *UnlimitedCPS) Update() error {
 func HandleJoinGame(_ protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	if tunnel.GetModuleHandler().IsModuleEnabled(modules.ModuleUnlimitedCPS) {
 		go func(conn generic.Tunnel) {
-			time.Sleep(100 * time.Millisecond)
+			select {
+			case <-time.After(100 * time.Millisecond):
+			case <-conn.GetContext().Done():
+				return
+			}
+
 			module, ok := conn.GetModuleHandler().GetModule(modules.ModuleUnlimitedCPS)
 			if ok {
 				err = module.(*UnlimitedCPS).Update()
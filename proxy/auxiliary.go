@@ -1,11 +1,13 @@
 package proxy
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/destructiqn/kogtevran/license"
@@ -34,30 +36,35 @@ const (
 	ModuleToggleAck
 )
 
-const KeepAliveInterval = 20 * time.Second
-
 type AuxiliaryChannel struct {
 	PairID     TunnelPairID
 	TunnelPair *TunnelPair
 
 	Conn          *websocket.Conn
 	lastKeepAlive *time.Time
-	close         chan bool
-	closed        bool
+
+	// ctx is cancelled once the channel is closed, which happens along with the minecraft tunnel of the pair
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
 }
 
-func (c *AuxiliaryChannel) Close() error {
-	if c.closed {
-		return nil
-	}
+func NewAuxiliaryChannel(conn *websocket.Conn) *AuxiliaryChannel {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &AuxiliaryChannel{Conn: conn, ctx: ctx, cancel: cancel}
+}
 
-	go func() {
-		c.close <- true
-	}()
+// Close cancels the context of the channel and unregisters its pair, only the first call closes the connection.
+// The pair is unregistered afterwards, it closes the channel again
+func (c *AuxiliaryChannel) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		c.cancel()
+		err = c.Conn.Close()
+	})
 
-	c.closed = true
 	CurrentTunnelPool.UnregisterPair(c.PairID)
-	return c.Conn.Close()
+	return err
 }
 
 func (c *AuxiliaryChannel) Handle() {
@@ -78,11 +85,14 @@ func (c *AuxiliaryChannel) Handle() {
 }
 
 func (c *AuxiliaryChannel) HandleKeepAlive() {
-	ticker := time.NewTicker(KeepAliveInterval)
+	interval := GetSettings().KeepAliveInterval
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case <-ticker.C:
-			if c.lastKeepAlive != nil && time.Now().Sub(*c.lastKeepAlive) > KeepAliveInterval*2 {
+			if c.lastKeepAlive != nil && time.Now().Sub(*c.lastKeepAlive) > interval*2 {
 				log.Println("dropping connection from", c.Conn.RemoteAddr(), "due to keep alive timeout")
 				if c.TunnelPair.Primary != nil {
 					c.TunnelPair.Primary.Close()
@@ -98,7 +108,7 @@ func (c *AuxiliaryChannel) HandleKeepAlive() {
 				}
 				return
 			}
-		case <-c.close:
+		case <-c.ctx.Done():
 			return
 		}
 	}
@@ -170,7 +180,12 @@ func (c *AuxiliaryChannel) HandleMessage(message *WebsocketMessage) error {
 			return errors.New("cannot find corresponding minecraft tunnel")
 		}
 
-		c.TunnelPair.Primary.EnableEncryptionC2S <- encryptionData.SharedSecret
+		primary := c.TunnelPair.Primary
+		select {
+		case primary.EnableEncryptionC2S <- encryptionData.SharedSecret:
+		case <-primary.GetContext().Done():
+			return ErrTunnelClosed
+		}
 	case ModuleToggleAck:
 		var moduleData AuxiliaryToggleModuleAck
 		err := mapstructure.Decode(message.Payload, &moduleData)
@@ -244,7 +259,7 @@ func WebsocketHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("accepted auxiliary connection from", r.RemoteAddr)
 
-	channel := AuxiliaryChannel{Conn: conn, close: make(chan bool)}
+	channel := NewAuxiliaryChannel(conn)
 	go channel.HandleKeepAlive()
 	channel.Handle()
 
@@ -8,11 +8,11 @@ import (
 	"strconv"
 	"strings"
 	"text/template"
-	"time"
 
 	"github.com/Tnze/go-mc/chat"
 	"github.com/destructiqn/kogtevran/generic"
 	"github.com/destructiqn/kogtevran/minecraft"
+	"github.com/destructiqn/kogtevran/minecraft/effects"
 	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
 	"github.com/destructiqn/kogtevran/minecraft/protocol"
 	"github.com/destructiqn/kogtevran/modules"
@@ -50,24 +50,13 @@ var Commands = map[string]CommandHandler{
 			return errors.New("unknown option")
 		}
 
-		var (
-			newValue interface{}
-			err      error
-		)
-
-		switch value.(type) {
-		case string:
-			newValue = strings.Join(args[2:], " ")
-		case bool:
-			newValue = strings.ToLower(args[2]) == "true" || args[2] == "1"
-		case float64:
-			newValue, err = strconv.ParseFloat(args[2], 64)
-		case time.Duration:
-			newValue, err = time.ParseDuration(args[2])
-		default:
-			newValue, err = strconv.Atoi(args[2])
+		// Only strings can contain spaces
+		raw := args[2]
+		if _, ok := value.(string); ok {
+			raw = strings.Join(args[2:], " ")
 		}
 
+		newValue, err := modules.ParseOptionValue(value, raw)
 		if err != nil {
 			return err
 		}
@@ -92,7 +81,7 @@ var Commands = map[string]CommandHandler{
 			return errors.New("not enough args")
 		}
 
-		id, err := strconv.Atoi(args[0])
+		effect, err := parseEffect(args[0])
 		if err != nil {
 			return err
 		}
@@ -102,7 +91,15 @@ var Commands = map[string]CommandHandler{
 			return err
 		}
 
-		return tunnel.WriteClient(pk.Marshal(0x1D, pk.VarInt(tunnel.GetPlayerHandler().GetEntityID()), pk.Byte(id), pk.Byte(amplifier), pk.VarInt(100000), pk.Boolean(true)))
+		packet := &protocol.EntityEffect{
+			EntityID:      pk.VarInt(tunnel.GetPlayerHandler().GetEntityID()),
+			EffectID:      pk.Byte(effect.ID),
+			Amplifier:     pk.Byte(amplifier),
+			Duration:      minecraft.InfiniteEffectDuration,
+			HideParticles: true,
+		}
+
+		return tunnel.WriteClient(packet.Marshal())
 	},
 
 	"inventory": func(args []string, tunnel generic.Tunnel) error {
@@ -251,7 +248,7 @@ var Commands = map[string]CommandHandler{
 		}
 
 		packet := &protocol.ChangeGameState{
-			Reason: 3,
+			Reason: pk.UnsignedByte(protocol.GameStateChangeGameMode),
 			Value:  gameMode,
 		}
 
@@ -305,6 +302,27 @@ var Commands = map[string]CommandHandler{
 	},
 }
 
+// parseEffect looks an effect up by its name, such as nightvision, or by its ID
+func parseEffect(value string) (*effects.Effect, error) {
+	for name, effect := range effects.ByName {
+		if strings.EqualFold(name, value) {
+			return effect, nil
+		}
+	}
+
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, errors.New("unknown effect")
+	}
+
+	effect, ok := effects.ByID[effects.ID(id)]
+	if !ok {
+		return nil, errors.New("unknown effect")
+	}
+
+	return effect, nil
+}
+
 func HandleCommand(message string, tunnel generic.Tunnel) bool {
 	if !strings.HasPrefix(message, "/") {
 		return false
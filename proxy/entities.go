@@ -1,30 +1,57 @@
 package proxy
 
 import (
+	"sort"
+	"strings"
 	"sync"
 
+	"github.com/Tnze/go-mc/chat"
 	"github.com/destructiqn/kogtevran/generic"
 	"github.com/destructiqn/kogtevran/minecraft"
+	"github.com/destructiqn/kogtevran/minecraft/entities"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
 	"github.com/destructiqn/kogtevran/minecraft/protocol"
+	"github.com/google/uuid"
 )
 
+// EntityHandler owns the tracked entities, they are changed only under the lock and handed out as snapshots
 type EntityHandler struct {
-	tunnel   *MinecraftTunnel
-	Entities map[int]minecraft.Entity
-	sync.Mutex
+	tunnel     *MinecraftTunnel
+	entities   map[int]minecraft.Entity
+	playerList map[uuid.UUID]*minecraft.PlayerListEntry
+	sync.RWMutex
 }
 
 func NewEntityHandler(tunnel *MinecraftTunnel) *EntityHandler {
-	return &EntityHandler{tunnel: tunnel, Entities: make(map[int]minecraft.Entity)}
+	return &EntityHandler{
+		tunnel:     tunnel,
+		entities:   make(map[int]minecraft.Entity),
+		playerList: make(map[uuid.UUID]*minecraft.PlayerListEntry),
+	}
 }
 
 func (h *EntityHandler) GetEntities() map[int]minecraft.Entity {
-	return h.Entities
+	h.RLock()
+	defer h.RUnlock()
+
+	entities := make(map[int]minecraft.Entity, len(h.entities))
+	for id, entity := range h.entities {
+		entities[id] = entity.Copy()
+	}
+
+	return entities
 }
 
 func (h *EntityHandler) GetEntity(entityID int) (minecraft.Entity, bool) {
-	entity, ok := h.Entities[entityID]
-	return entity, ok
+	h.RLock()
+	defer h.RUnlock()
+
+	entity, ok := h.entities[entityID]
+	if !ok {
+		return nil, false
+	}
+
+	return entity.Copy(), true
 }
 
 func (h *EntityHandler) InitPlayer(entityID int, player *minecraft.Player) {
@@ -33,18 +60,31 @@ func (h *EntityHandler) InitPlayer(entityID int, player *minecraft.Player) {
 	}
 
 	h.Lock()
-	h.Entities[entityID] = player
+	if entry, ok := h.playerList[player.UUID]; ok {
+		player.Name, player.DisplayName = entry.Name, entry.DisplayName
+	}
+
+	h.entities[entityID] = player
 	h.Unlock()
 }
 
 func (h *EntityHandler) InitMob(entityID int, mob *minecraft.Mob) {
 	h.Lock()
-	h.Entities[entityID] = mob
+	h.entities[entityID] = mob
+	h.Unlock()
+}
+
+func (h *EntityHandler) InitObject(entityID int, object *minecraft.Object) {
+	h.Lock()
+	h.entities[entityID] = object
 	h.Unlock()
 }
 
 func (h *EntityHandler) EntityRelativeMove(entityID int, dx, dy, dz float64) {
-	entity, ok := h.Entities[entityID]
+	h.Lock()
+	defer h.Unlock()
+
+	entity, ok := h.entities[entityID]
 	if !ok {
 		return
 	}
@@ -55,7 +95,10 @@ func (h *EntityHandler) EntityRelativeMove(entityID int, dx, dy, dz float64) {
 }
 
 func (h *EntityHandler) EntityTeleport(entityID int, x, y, z, yaw, pitch float64) {
-	entity, ok := h.Entities[entityID]
+	h.Lock()
+	defer h.Unlock()
+
+	entity, ok := h.entities[entityID]
 	if !ok {
 		return
 	}
@@ -64,10 +107,65 @@ func (h *EntityHandler) EntityTeleport(entityID int, x, y, z, yaw, pitch float64
 	entity.GetLocation().Yaw, entity.GetLocation().Pitch = yaw, pitch
 }
 
+func (h *EntityHandler) SetVelocity(entityID int, velocity minecraft.Velocity) {
+	h.Lock()
+	defer h.Unlock()
+
+	if entity, ok := h.entities[entityID]; ok {
+		*entity.GetVelocity() = velocity
+	}
+}
+
+func (h *EntityHandler) SetHeadYaw(entityID int, yaw float64) {
+	h.Lock()
+	defer h.Unlock()
+
+	if entity, ok := h.entities[entityID]; ok {
+		entity.SetHeadYaw(yaw)
+	}
+}
+
+func (h *EntityHandler) SetEquipment(entityID int, slot minecraft.EquipmentSlot, item pk.Slot) {
+	if slot < 0 || slot >= minecraft.EquipmentSlotCount {
+		return
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	if entity, ok := h.entities[entityID]; ok {
+		entity.GetEquipment()[slot] = item
+	}
+}
+
+// UpdateMetadata merges the fields into the entity metadata, the server sends only the changed ones
+func (h *EntityHandler) UpdateMetadata(entityID int, metadata pk.EntityMetadata) {
+	h.Lock()
+	defer h.Unlock()
+
+	entity, ok := h.entities[entityID]
+	if !ok {
+		return
+	}
+
+	for index, value := range metadata {
+		entity.GetMetadata()[index] = value
+	}
+}
+
+func (h *EntityHandler) AttachEntity(entityID, vehicleID int, leash bool) {
+	h.Lock()
+	defer h.Unlock()
+
+	if entity, ok := h.entities[entityID]; ok {
+		entity.Attach(vehicleID, leash)
+	}
+}
+
 func (h *EntityHandler) ResetEntities() {
 	h.Lock()
-	for id := range h.Entities {
-		delete(h.Entities, id)
+	for id := range h.entities {
+		delete(h.entities, id)
 	}
 	h.Unlock()
 }
@@ -75,22 +173,129 @@ func (h *EntityHandler) ResetEntities() {
 func (h *EntityHandler) DestroyEntities(entityIDs []int) {
 	h.Lock()
 	for _, id := range entityIDs {
-		delete(h.Entities, id)
+		delete(h.entities, id)
+	}
+	h.Unlock()
+}
+
+func (h *EntityHandler) AddPlayerListEntry(entry *minecraft.PlayerListEntry) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.playerList[entry.UUID] = entry
+	for _, entity := range h.entities {
+		if player, ok := entity.(*minecraft.Player); ok && player.UUID == entry.UUID {
+			player.Name, player.DisplayName = entry.Name, entry.DisplayName
+		}
+	}
+}
+
+func (h *EntityHandler) UpdatePlayerListEntry(id uuid.UUID, update func(entry *minecraft.PlayerListEntry)) {
+	h.Lock()
+	defer h.Unlock()
+
+	entry, ok := h.playerList[id]
+	if !ok {
+		return
+	}
+
+	update(entry)
+	for _, entity := range h.entities {
+		if player, ok := entity.(*minecraft.Player); ok && player.UUID == id {
+			player.DisplayName = entry.DisplayName
+		}
 	}
+}
+
+func (h *EntityHandler) RemovePlayerListEntry(id uuid.UUID) {
+	h.Lock()
+	delete(h.playerList, id)
 	h.Unlock()
 }
 
+func (h *EntityHandler) GetPlayerListEntry(id uuid.UUID) (minecraft.PlayerListEntry, bool) {
+	h.RLock()
+	defer h.RUnlock()
+
+	entry, ok := h.playerList[id]
+	if !ok {
+		return minecraft.PlayerListEntry{}, false
+	}
+
+	return *entry, true
+}
+
+// FindEntities returns all entities matching the filter, nil filter matches any entity
+func (h *EntityHandler) FindEntities(filter minecraft.EntityFilter) []minecraft.Entity {
+	h.RLock()
+	defer h.RUnlock()
+
+	entities := make([]minecraft.Entity, 0)
+	for _, entity := range h.entities {
+		snapshot := entity.Copy()
+		if filter == nil || filter(snapshot) {
+			entities = append(entities, snapshot)
+		}
+	}
+
+	return entities
+}
+
+// GetEntitiesWithin returns entities matching the filter within the radius, the nearest ones go first
+func (h *EntityHandler) GetEntitiesWithin(location *minecraft.Location, radius float64, filter minecraft.EntityFilter) []minecraft.Entity {
+	entities := h.FindEntities(func(entity minecraft.Entity) bool {
+		return entity.GetLocation().Distance(location) <= radius && (filter == nil || filter(entity))
+	})
+
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].GetLocation().Distance(location) < entities[j].GetLocation().Distance(location)
+	})
+
+	return entities
+}
+
+func (h *EntityHandler) GetNearestEntity(location *minecraft.Location, filter minecraft.EntityFilter) (minecraft.Entity, bool) {
+	var nearest minecraft.Entity
+	distance := 0.0
+
+	for _, entity := range h.FindEntities(filter) {
+		if d := entity.GetLocation().Distance(location); nearest == nil || d < distance {
+			nearest, distance = entity, d
+		}
+	}
+
+	return nearest, nearest != nil
+}
+
+// GetPlayerByName looks up a spawned player, names are case-insensitive as in the game
+func (h *EntityHandler) GetPlayerByName(name string) (*minecraft.Player, bool) {
+	players := h.FindEntities(func(entity minecraft.Entity) bool {
+		return minecraft.IsPlayer(entity) && strings.EqualFold(entity.(*minecraft.Player).GetName(), name)
+	})
+
+	if len(players) == 0 {
+		return nil, false
+	}
+
+	return players[0].(*minecraft.Player), true
+}
+
 func HandleSpawnPlayer(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	spawnPlayer := packet.(*protocol.SpawnPlayer)
 
 	player := &minecraft.Player{
-		DefaultEntity: minecraft.DefaultEntity{Location: &minecraft.Location{
+		DefaultEntity: minecraft.NewDefaultEntity(int(spawnPlayer.EntityID), &minecraft.Location{
 			X:     float64(spawnPlayer.X) / 32,
 			Y:     float64(spawnPlayer.Y) / 32,
 			Z:     float64(spawnPlayer.Z) / 32,
 			Yaw:   float64(spawnPlayer.Yaw),
 			Pitch: float64(spawnPlayer.Pitch),
-		}},
+		}, spawnPlayer.Metadata),
+		UUID: uuid.UUID(spawnPlayer.PlayerUUID),
+	}
+
+	if spawnPlayer.CurrentItem != 0 {
+		player.Equipment[minecraft.EquipmentHeld] = pk.Slot{BlockID: int16(spawnPlayer.CurrentItem), ItemCount: 1}
 	}
 
 	tunnel.GetEntityHandler().InitPlayer(int(spawnPlayer.EntityID), player)
@@ -100,20 +305,40 @@ func HandleSpawnPlayer(packet protocol.Packet, tunnel generic.Tunnel) (result *g
 func HandleSpawnMob(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	spawnMob := packet.(*protocol.SpawnMob)
 	mob := &minecraft.Mob{
-		DefaultEntity: minecraft.DefaultEntity{Location: &minecraft.Location{
+		DefaultEntity: minecraft.NewDefaultEntity(int(spawnMob.EntityID), &minecraft.Location{
 			X:     float64(spawnMob.X) / 32,
 			Y:     float64(spawnMob.Y) / 32,
 			Z:     float64(spawnMob.Z) / 32,
 			Yaw:   float64(spawnMob.Yaw),
 			Pitch: float64(spawnMob.Pitch),
-		}},
-		Type: minecraft.MobType(spawnMob.Type),
+		}, spawnMob.Metadata),
+		Type: entities.ID(spawnMob.Type),
 	}
 
+	mob.Velocity = velocity(spawnMob.VX, spawnMob.VY, spawnMob.VZ)
 	tunnel.GetEntityHandler().InitMob(int(spawnMob.EntityID), mob)
 	return generic.PassPacket(), nil
 }
 
+func HandleSpawnObject(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	spawnObject := packet.(*protocol.SpawnObject)
+	object := &minecraft.Object{
+		DefaultEntity: minecraft.NewDefaultEntity(int(spawnObject.EntityID), &minecraft.Location{
+			X:     float64(spawnObject.X) / 32,
+			Y:     float64(spawnObject.Y) / 32,
+			Z:     float64(spawnObject.Z) / 32,
+			Yaw:   float64(spawnObject.Yaw),
+			Pitch: float64(spawnObject.Pitch),
+		}, nil),
+		Type: entities.ID(spawnObject.Type),
+		Data: int(spawnObject.Data),
+	}
+
+	object.Velocity = velocity(spawnObject.VX, spawnObject.VY, spawnObject.VZ)
+	tunnel.GetEntityHandler().InitObject(int(spawnObject.EntityID), object)
+	return generic.PassPacket(), nil
+}
+
 func HandleDestroyEntities(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	destroyEntities := packet.(*protocol.DestroyEntities)
 	entityIDs := make([]int, 0)
@@ -146,3 +371,84 @@ func HandleEntityTeleport(packet protocol.Packet, tunnel generic.Tunnel) (result
 	tunnel.GetEntityHandler().EntityTeleport(int(entityTeleport.EntityID), float64(x)/32, float64(y)/32, float64(z)/32, float64(yaw), float64(pitch))
 	return generic.PassPacket(), nil
 }
+
+// velocity converts the protocol units of 1/8000 block per tick
+func velocity(vx, vy, vz pk.Short) minecraft.Velocity {
+	return minecraft.Velocity{X: float64(vx) / 8000, Y: float64(vy) / 8000, Z: float64(vz) / 8000}
+}
+
+func HandleEntityVelocity(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	entityVelocity := packet.(*protocol.EntityVelocity)
+	tunnel.GetEntityHandler().SetVelocity(int(entityVelocity.EntityID), velocity(entityVelocity.VX, entityVelocity.VY, entityVelocity.VZ))
+	return generic.PassPacket(), nil
+}
+
+func HandleEntityHeadLook(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	entityHeadLook := packet.(*protocol.EntityHeadLook)
+	tunnel.GetEntityHandler().SetHeadYaw(int(entityHeadLook.EntityID), float64(entityHeadLook.HeadYaw))
+	return generic.PassPacket(), nil
+}
+
+func HandleEntityEquipment(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	entityEquipment := packet.(*protocol.EntityEquipment)
+	tunnel.GetEntityHandler().SetEquipment(int(entityEquipment.EntityID), minecraft.EquipmentSlot(entityEquipment.Slot), entityEquipment.Item)
+	return generic.PassPacket(), nil
+}
+
+func HandleEntityMetadata(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	entityMetadata := packet.(*protocol.EntityMetadata)
+	tunnel.GetEntityHandler().UpdateMetadata(int(entityMetadata.EntityID), entityMetadata.Metadata)
+	return generic.PassPacket(), nil
+}
+
+func HandleAttachEntity(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	attachEntity := packet.(*protocol.AttachEntity)
+	tunnel.GetEntityHandler().AttachEntity(int(attachEntity.EntityID), int(attachEntity.VehicleID), bool(attachEntity.Leash))
+	return generic.PassPacket(), nil
+}
+
+func displayName(entry *protocol.PlayerListItemEntry) *chat.Message {
+	if !entry.HasDisplayName {
+		return nil
+	}
+
+	name := entry.DisplayName
+	return &name
+}
+
+func HandlePlayerListItem(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	playerListItem := packet.(*protocol.PlayerListItem)
+	entityHandler := tunnel.GetEntityHandler().(*EntityHandler)
+
+	for i := range playerListItem.Players {
+		entry := &playerListItem.Players[i]
+		id := uuid.UUID(entry.UUID)
+
+		switch protocol.PlayerListAction(playerListItem.Action) {
+		case protocol.PlayerListAddPlayer:
+			entityHandler.AddPlayerListEntry(&minecraft.PlayerListEntry{
+				UUID:        id,
+				Name:        string(entry.Name),
+				GameMode:    int(entry.GameMode),
+				Ping:        int(entry.Ping),
+				DisplayName: displayName(entry),
+			})
+		case protocol.PlayerListUpdateGameMode:
+			entityHandler.UpdatePlayerListEntry(id, func(listEntry *minecraft.PlayerListEntry) {
+				listEntry.GameMode = int(entry.GameMode)
+			})
+		case protocol.PlayerListUpdateLatency:
+			entityHandler.UpdatePlayerListEntry(id, func(listEntry *minecraft.PlayerListEntry) {
+				listEntry.Ping = int(entry.Ping)
+			})
+		case protocol.PlayerListUpdateDisplayName:
+			entityHandler.UpdatePlayerListEntry(id, func(listEntry *minecraft.PlayerListEntry) {
+				listEntry.DisplayName = displayName(entry)
+			})
+		case protocol.PlayerListRemovePlayer:
+			entityHandler.RemovePlayerListEntry(id)
+		}
+	}
+
+	return generic.PassPacket(), nil
+}
@@ -0,0 +1,197 @@
+package proxy
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/destructiqn/kogtevran/generic"
+	"github.com/destructiqn/kogtevran/minecraft"
+	"github.com/destructiqn/kogtevran/minecraft/entities"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/destructiqn/kogtevran/minecraft/protocol"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newEntityTunnel() *MinecraftTunnel {
+	tunnel := &MinecraftTunnel{State: protocol.ConnStatePlay}
+	tunnel.PlayerHandler = NewPlayerHandler(tunnel)
+	tunnel.EntityHandler = NewEntityHandler(tunnel)
+	return tunnel
+}
+
+// dispatch encodes the packet and passes the decoded copy to the handler, as the pipeline does
+func dispatch(t *testing.T, tunnel generic.Tunnel, packet protocol.Packet, handler generic.PacketHandler) {
+	decoded := reflect.New(reflect.TypeOf(packet).Elem()).Interface().(protocol.Packet)
+	assert.NoError(t, decoded.Read(packet.Marshal()))
+
+	result, err := handler(decoded, tunnel)
+	assert.NoError(t, err)
+	assert.True(t, result.ShouldPass)
+}
+
+func TestEntityHandler_Players(t *testing.T) {
+	tunnel := newEntityTunnel()
+	handler := tunnel.EntityHandler
+
+	alice, bob := uuid.New(), uuid.New()
+	dispatch(t, tunnel, &protocol.PlayerListItem{
+		Action: pk.VarInt(protocol.PlayerListAddPlayer),
+		Players: []protocol.PlayerListItemEntry{
+			{UUID: pk.UUID(alice), Name: "Alice", GameMode: 1, Ping: 30, Properties: []pk.PlayerProperty{
+				{Name: "textures", Value: "e30=", IsSigned: true, Signature: "c2lnbg=="},
+			}},
+		},
+	}, HandlePlayerListItem)
+
+	dispatch(t, tunnel, &protocol.SpawnPlayer{
+		EntityID:    10,
+		PlayerUUID:  pk.UUID(alice),
+		X:           32 * 10,
+		Y:           32 * 64,
+		Z:           32 * -5,
+		CurrentItem: 276,
+		Metadata: pk.EntityMetadata{
+			minecraft.MetadataFlags:  pk.MetadataByte(minecraft.EntityCrouched | minecraft.EntitySprinting),
+			minecraft.MetadataHealth: pk.MetadataFloat(15),
+		},
+	}, HandleSpawnPlayer)
+
+	dispatch(t, tunnel, &protocol.SpawnPlayer{EntityID: 11, PlayerUUID: pk.UUID(bob), Y: 32 * 64}, HandleSpawnPlayer)
+
+	player, ok := handler.GetPlayerByName("alice")
+	assert.True(t, ok)
+	assert.Equal(t, 10, player.GetEntityID())
+	assert.Equal(t, alice, player.UUID)
+	assert.Equal(t, &minecraft.Location{X: 10, Y: 64, Z: -5}, player.GetLocation())
+	assert.Equal(t, int16(276), player.GetEquipment()[minecraft.EquipmentHeld].BlockID)
+	assert.True(t, player.GetEquipment()[minecraft.EquipmentHelmet].IsEmpty())
+	assert.True(t, player.GetFlags().Has(minecraft.EntityCrouched))
+	assert.True(t, player.GetFlags().Has(minecraft.EntitySprinting))
+	assert.False(t, player.GetFlags().Has(minecraft.EntityInvisible))
+
+	health, ok := player.GetHealth()
+	assert.True(t, ok)
+	assert.Equal(t, 15.0, health)
+
+	entry, ok := handler.GetPlayerListEntry(alice)
+	assert.True(t, ok)
+	assert.Equal(t, 1, entry.GameMode)
+	assert.Equal(t, 30, entry.Ping)
+
+	// Tab list entry may come after the player is spawned
+	_, ok = handler.GetPlayerByName("Bob")
+	assert.False(t, ok)
+
+	dispatch(t, tunnel, &protocol.PlayerListItem{
+		Action:  pk.VarInt(protocol.PlayerListAddPlayer),
+		Players: []protocol.PlayerListItemEntry{{UUID: pk.UUID(bob), Name: "Bob"}},
+	}, HandlePlayerListItem)
+
+	player, ok = handler.GetPlayerByName("Bob")
+	assert.True(t, ok)
+	assert.Equal(t, 11, player.GetEntityID())
+
+	dispatch(t, tunnel, &protocol.PlayerListItem{
+		Action:  pk.VarInt(protocol.PlayerListUpdateLatency),
+		Players: []protocol.PlayerListItemEntry{{UUID: pk.UUID(bob), Ping: 150}},
+	}, HandlePlayerListItem)
+
+	entry, _ = handler.GetPlayerListEntry(bob)
+	assert.Equal(t, 150, entry.Ping)
+
+	dispatch(t, tunnel, &protocol.PlayerListItem{
+		Action:  pk.VarInt(protocol.PlayerListRemovePlayer),
+		Players: []protocol.PlayerListItemEntry{{UUID: pk.UUID(bob)}},
+	}, HandlePlayerListItem)
+
+	_, ok = handler.GetPlayerListEntry(bob)
+	assert.False(t, ok)
+}
+
+func TestEntityHandler_State(t *testing.T) {
+	tunnel := newEntityTunnel()
+	handler := tunnel.EntityHandler
+
+	dispatch(t, tunnel, &protocol.SpawnMob{EntityID: 1, Type: pk.UnsignedByte(entities.Pig.ID), Y: 32 * 64, VY: -8000}, HandleSpawnMob)
+	dispatch(t, tunnel, &protocol.SpawnObject{EntityID: 2, Type: pk.Byte(entities.Arrow.ID), Y: 32 * 70, Data: 1, VX: 4000}, HandleSpawnObject)
+	dispatch(t, tunnel, &protocol.SpawnObject{EntityID: 3, Type: pk.Byte(entities.MinecartRideable.ID), Y: 32 * 64}, HandleSpawnObject)
+
+	get := func(entityID int) minecraft.Entity {
+		entity, ok := handler.GetEntity(entityID)
+		assert.True(t, ok)
+		return entity
+	}
+
+	assert.Equal(t, minecraft.Velocity{Y: -1}, *get(1).GetVelocity())
+	assert.Equal(t, 1, get(2).(*minecraft.Object).Data)
+	assert.Equal(t, minecraft.Velocity{X: 0.5}, *get(2).GetVelocity())
+
+	dispatch(t, tunnel, &protocol.EntityVelocity{EntityID: 2, VZ: 800}, HandleEntityVelocity)
+	assert.Equal(t, minecraft.Velocity{Z: 0.1}, *get(2).GetVelocity())
+
+	dispatch(t, tunnel, &protocol.EntityMetadata{EntityID: 1, Metadata: pk.EntityMetadata{
+		minecraft.MetadataHealth:     pk.MetadataFloat(4),
+		minecraft.MetadataCustomName: pk.MetadataString("Piggy"),
+	}}, HandleEntityMetadata)
+	dispatch(t, tunnel, &protocol.EntityMetadata{EntityID: 1, Metadata: pk.EntityMetadata{
+		minecraft.MetadataFlags: pk.MetadataByte(minecraft.EntityOnFire),
+	}}, HandleEntityMetadata)
+
+	pig := get(1)
+	health, _ := pig.GetHealth()
+	assert.Equal(t, 4.0, health)
+	assert.Equal(t, "Piggy", pig.GetCustomName())
+	assert.True(t, pig.GetFlags().Has(minecraft.EntityOnFire))
+
+	dispatch(t, tunnel, &protocol.EntityEquipment{EntityID: 1, Slot: pk.Short(minecraft.EquipmentBoots), Item: pk.Slot{BlockID: 301, ItemCount: 1}}, HandleEntityEquipment)
+	assert.Equal(t, int16(301), get(1).GetEquipment()[minecraft.EquipmentBoots].BlockID)
+
+	dispatch(t, tunnel, &protocol.EntityHeadLook{EntityID: 1, HeadYaw: 64}, HandleEntityHeadLook)
+	assert.Equal(t, 64.0, get(1).GetHeadYaw())
+
+	assert.Equal(t, minecraft.NoEntity, get(1).GetVehicleID())
+	dispatch(t, tunnel, &protocol.AttachEntity{EntityID: 1, VehicleID: 3}, HandleAttachEntity)
+	dispatch(t, tunnel, &protocol.AttachEntity{EntityID: 1, VehicleID: 2, Leash: true}, HandleAttachEntity)
+	assert.Equal(t, 3, get(1).GetVehicleID())
+	assert.Equal(t, 2, get(1).GetLeashHolderID())
+
+	dispatch(t, tunnel, &protocol.AttachEntity{EntityID: 1, VehicleID: minecraft.NoEntity}, HandleAttachEntity)
+	assert.Equal(t, minecraft.NoEntity, get(1).GetVehicleID())
+}
+
+func TestEntityHandler_Queries(t *testing.T) {
+	tunnel := newEntityTunnel()
+	handler := tunnel.EntityHandler
+
+	dispatch(t, tunnel, &protocol.SpawnMob{EntityID: 1, Type: pk.UnsignedByte(entities.Zombie.ID), X: 32 * 5}, HandleSpawnMob)
+	dispatch(t, tunnel, &protocol.SpawnMob{EntityID: 2, Type: pk.UnsignedByte(entities.Cow.ID), X: 32 * 2}, HandleSpawnMob)
+	dispatch(t, tunnel, &protocol.SpawnMob{EntityID: 3, Type: pk.UnsignedByte(entities.Zombie.ID), X: 32 * 20}, HandleSpawnMob)
+	dispatch(t, tunnel, &protocol.SpawnPlayer{EntityID: 4, X: 32 * 1}, HandleSpawnPlayer)
+	dispatch(t, tunnel, &protocol.SpawnObject{EntityID: 5, Type: pk.Byte(entities.Item.ID), X: 32 * 3}, HandleSpawnObject)
+
+	ids := func(list []minecraft.Entity) []int {
+		result := make([]int, 0)
+		for _, entity := range list {
+			result = append(result, entity.GetEntityID())
+		}
+		return result
+	}
+
+	center := &minecraft.Location{}
+	assert.Equal(t, []int{4, 2, 5, 1}, ids(handler.GetEntitiesWithin(center, 10, nil)))
+	assert.Equal(t, []int{2, 1}, ids(handler.GetEntitiesWithin(center, 10, minecraft.IsMob)))
+	assert.Equal(t, []int{1}, ids(handler.GetEntitiesWithin(center, 10, minecraft.IsMobOfType(entities.Zombie))))
+	assert.ElementsMatch(t, []int{1, 3}, ids(handler.FindEntities(minecraft.IsMobOfType(entities.Zombie))))
+	assert.Equal(t, []int{5}, ids(handler.FindEntities(minecraft.IsObjectOfType(entities.Item))))
+
+	nearest, ok := handler.GetNearestEntity(&minecraft.Location{X: 18}, minecraft.IsMob)
+	assert.True(t, ok)
+	assert.Equal(t, 3, nearest.GetEntityID())
+
+	_, ok = handler.GetNearestEntity(center, minecraft.IsMobOfType(entities.Creeper))
+	assert.False(t, ok)
+
+	dispatch(t, tunnel, &protocol.DestroyEntities{EntityIDs: []pk.VarInt{1, 2}}, HandleDestroyEntities)
+	assert.Equal(t, []int{4, 5}, ids(handler.GetEntitiesWithin(center, 10, nil)))
+}
@@ -1,12 +1,12 @@
 package proxy
 
 import (
-	"math/rand"
+	"sort"
 	"sync"
-	"time"
 
 	"github.com/Tnze/go-mc/chat"
 	"github.com/destructiqn/kogtevran/generic"
+	"github.com/destructiqn/kogtevran/minecraft"
 	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
 	"github.com/destructiqn/kogtevran/minecraft/protocol"
 )
@@ -14,13 +14,15 @@ import (
 type InventoryHandler struct {
 	tunnel  *MinecraftTunnel
 	windows map[int]generic.Window
+	// openWindow is the ID of the window opened last, the client has only one open at a time
+	openWindow int
 	sync.Mutex
 }
 
 func NewInventoryHandler(tunnel *MinecraftTunnel) *InventoryHandler {
 	handler := &InventoryHandler{tunnel: tunnel}
 	handler.windows = map[int]generic.Window{
-		0: NewWindow(handler, 0, 44, "", chat.Text("Player Inventory")),
+		0: NewWindow(handler, 0, minecraft.PlayerInventorySize, "", chat.Text("Player Inventory")),
 	}
 	return handler
 }
@@ -38,34 +40,113 @@ func (i *InventoryHandler) GetWindows() []generic.Window {
 }
 
 func (i *InventoryHandler) GetWindow(id int) (generic.Window, bool) {
+	i.Lock()
+	defer i.Unlock()
 	window, ok := i.windows[id]
 	return window, ok
 }
 
+// GetOpenWindow returns the window the client has open, which is the player inventory if there is no other one
+func (i *InventoryHandler) GetOpenWindow() generic.Window {
+	i.Lock()
+	defer i.Unlock()
+
+	if window, ok := i.windows[i.openWindow]; ok {
+		return window
+	}
+
+	return i.windows[0]
+}
+
+// SelectItem makes the player hold the item from the hotbar, or from the main inventory
+// after swapping it with the hotbar slot with the fallback index. The held slot is not tracked as changed
+func (i *InventoryHandler) SelectItem(filter minecraft.ItemFilter, fallback int) (index int, err error) {
+	window, _ := i.GetWindow(0)
+	layout, playerHandler := window.GetLayout(), i.tunnel.GetPlayerHandler()
+
+	current := playerHandler.GetCurrentSlot()
+	if item := window.GetItem(layout.HotbarSlot(current)); !item.IsEmpty() && filter(item) {
+		return current, nil
+	}
+
+	if slot, ok := window.FindItem(filter, minecraft.RegionHotbar); ok {
+		index, _ = layout.HotbarIndex(slot)
+		return index, playerHandler.ChangeSlot(index)
+	}
+
+	slot, ok := window.FindItem(filter, minecraft.RegionMain)
+	if !ok {
+		return 0, generic.ErrItemNotFound
+	}
+
+	_, err = window.SwapHotbar(slot, fallback)
+	if err != nil {
+		return 0, err
+	}
+
+	return fallback, playerHandler.ChangeSlot(fallback)
+}
+
 func (i *InventoryHandler) OpenWindow(id int, window generic.Window) {
 	i.Lock()
 	defer i.Unlock()
+	abortTransactions(i.windows[id])
 	i.windows[id] = window
+	i.openWindow = id
 }
 
 func (i *InventoryHandler) CloseWindow(id int) {
 	if id != 0 {
 		i.Lock()
 		defer i.Unlock()
+		abortTransactions(i.windows[id])
 		delete(i.windows, id)
+		if i.openWindow == id {
+			i.openWindow = 0
+		}
 	}
 }
 
 func (i *InventoryHandler) Reset() {
 	i.Lock()
 	defer i.Unlock()
-	for id := range i.windows {
+	for id, window := range i.windows {
+		abortTransactions(window)
 		if id == 0 {
 			continue
 		}
 
 		delete(i.windows, id)
 	}
+
+	i.openWindow = 0
+}
+
+// abortTransactions fails pending clicks of the window, the server never confirms them once the window is gone
+func abortTransactions(window generic.Window) {
+	if window, ok := window.(*Window); ok {
+		window.state.Lock()
+		for _, transaction := range window.transactions {
+			transaction.result.Resolve(false)
+		}
+
+		window.transactions = nil
+		window.state.Unlock()
+	}
+}
+
+// transaction is a click waiting for the confirmation from the server
+type transaction struct {
+	action int16
+	result *generic.ClickResult
+
+	// Clicks made by the client are sent with our action numbers, the client gets its own ones back
+	fromClient   bool
+	clientAction int16
+
+	// rollback holds contents of the slots changed locally in advance of the confirmation
+	rollback map[int]pk.Slot
+	resync   bool
 }
 
 type Window struct {
@@ -74,12 +155,36 @@ type Window struct {
 	size    int
 	wType   string
 	title   chat.Message
-	items   map[int]pk.Slot
+	layout  minecraft.WindowLayout
+
+	// state guards the contents and the transactions, the embedded mutex is held by callers through click sequences
+	state        sync.Mutex
+	items        map[int]pk.Slot
+	lastAction   int16
+	transactions []*transaction
+
+	// acknowledgements map action numbers of rejected client clicks to the ones sent to the server
+	acknowledgements map[int16]int16
 	sync.Mutex
 }
 
+// NewWindow creates a window with the size of its container, the player inventory slots are added to it by the client
 func NewWindow(handler *InventoryHandler, id byte, size int, wType string, title chat.Message) *Window {
-	return &Window{handler: handler, id: id, size: size, wType: wType, title: title, items: make(map[int]pk.Slot)}
+	layout := minecraft.WindowLayout{ContainerSize: size}
+	if id == 0 {
+		layout = minecraft.PlayerInventoryLayout
+	}
+
+	return &Window{
+		handler:          handler,
+		id:               id,
+		size:             size,
+		wType:            wType,
+		title:            title,
+		layout:           layout,
+		items:            make(map[int]pk.Slot),
+		acknowledgements: make(map[int16]int16),
+	}
 }
 
 func (w *Window) GetType() string {
@@ -94,87 +199,262 @@ func (w *Window) GetTitle() chat.Message {
 	return w.title
 }
 
+// GetContents returns a copy of the window contents
 func (w *Window) GetContents() map[int]pk.Slot {
-	return w.items
+	w.state.Lock()
+	defer w.state.Unlock()
+
+	items := make(map[int]pk.Slot, len(w.items))
+	for slot, item := range w.items {
+		items[slot] = item
+	}
+
+	return items
 }
 
 func (w *Window) PutItem(slot int, item pk.Slot) {
-	w.Lock()
-	defer w.Unlock()
+	w.state.Lock()
+	defer w.state.Unlock()
 	w.items[slot] = item
 }
 
 func (w *Window) GetItem(slot int) pk.Slot {
-	return w.items[slot]
+	w.state.Lock()
+	defer w.state.Unlock()
+	return w.item(slot)
 }
 
-func (w *Window) Click(slot int, mode, button byte) error {
-	tunnel := w.handler.tunnel
-	rand.Seed(time.Now().UnixNano())
+// item returns contents of the slot, slots never set are empty. Must be called under the state lock
+func (w *Window) item(slot int) pk.Slot {
+	item, ok := w.items[slot]
+	if !ok {
+		return emptySlot
+	}
 
-	packet := protocol.ClickWindow{
-		WindowID:     pk.UnsignedByte(w.id),
-		Slot:         pk.Short(slot),
-		Button:       pk.Byte(button),
-		ActionNumber: pk.Short(rand.Intn(100000)),
-		Mode:         pk.Byte(mode),
-		ClickedItem:  w.GetItem(slot),
+	return item
+}
+
+// begin registers a transaction with the next action number, changes are applied locally right away.
+// Must be called under the state lock
+func (w *Window) begin(transaction *transaction, changes map[int]pk.Slot) {
+	w.lastAction++
+	transaction.action = w.lastAction
+	transaction.result = generic.NewClickResult(transaction.action)
+
+	if len(changes) > 0 {
+		transaction.rollback = make(map[int]pk.Slot, len(changes))
+		for slot, item := range changes {
+			transaction.rollback[slot] = w.item(slot)
+			w.items[slot] = item
+		}
 	}
 
-	return tunnel.WriteServer(packet.Marshal())
+	w.transactions = append(w.transactions, transaction)
 }
 
-func (w *Window) Move(from, to int) error {
-	w.Lock()
-	defer w.Unlock()
+// confirm resolves the transaction and returns it with the slots restored by the rollback, transaction is nil if unknown.
+// Server ignores clicks after a rejected one until it is acknowledged, so the later transactions are rejected too
+func (w *Window) confirm(action int16, accepted bool) (*transaction, map[int]pk.Slot) {
+	w.state.Lock()
+	defer w.state.Unlock()
+
+	index := -1
+	for i, transaction := range w.transactions {
+		if transaction.action == action {
+			index = i
+			break
+		}
+	}
 
-	if w.GetItem(from).BlockID == -1 {
-		return nil
+	if index == -1 {
+		return nil, nil
 	}
 
-	err := w.Click(from, 0, 0)
-	if err != nil {
-		return err
+	confirmed := w.transactions[index]
+	if accepted {
+		w.transactions = append(w.transactions[:index], w.transactions[index+1:]...)
+		confirmed.result.Resolve(true)
+		return confirmed, nil
 	}
 
-	err = w.Click(to, 0, 0)
+	restored := make(map[int]pk.Slot)
+	for i := len(w.transactions) - 1; i >= index; i-- {
+		transaction := w.transactions[i]
+		for slot, item := range transaction.rollback {
+			w.items[slot] = item
+			restored[slot] = item
+		}
+
+		if i > index {
+			transaction.result.Resolve(false)
+		}
+	}
+
+	// Rejection of a resync click means it was performed, and the server sends the contents right after
+	confirmed.result.Resolve(confirmed.resync)
+
+	w.transactions = w.transactions[:index]
+	if confirmed.fromClient {
+		w.acknowledgements[confirmed.clientAction] = confirmed.action
+	}
+
+	return confirmed, restored
+}
+
+// trackClientClick returns the action number the click of the client is sent to the server with
+func (w *Window) trackClientClick(clientAction int16) int16 {
+	w.state.Lock()
+	defer w.state.Unlock()
+
+	transaction := &transaction{fromClient: true, clientAction: clientAction}
+	w.begin(transaction, nil)
+	return transaction.action
+}
+
+// acknowledge returns the action number of the rejected client click the client acknowledges
+func (w *Window) acknowledge(clientAction int16) (int16, bool) {
+	w.state.Lock()
+	defer w.state.Unlock()
+
+	action, ok := w.acknowledgements[clientAction]
+	delete(w.acknowledgements, clientAction)
+	return action, ok
+}
+
+// prediction is the outcome of a click expected by the server and by us
+type prediction struct {
+	// clickedItem is compared by the server with the result of the click, a mismatch makes it reject the click
+	clickedItem pk.Slot
+	changes     map[int]pk.Slot
+
+	// resync clicks are sent with a clicked item the server does not agree with whenever something changes,
+	// so it answers with the whole window contents. Used when the outcome can not be predicted
+	resync bool
+}
+
+// predictNothing expects the click to change nothing, the server returns the clicked item only for pickups and shift clicks
+func (w *Window) predictNothing(slot int, mode byte) func() prediction {
+	return func() prediction {
+		if mode == clickModeHotbar || mode == clickModeDrop {
+			return prediction{clickedItem: emptySlot}
+		}
+
+		return prediction{clickedItem: w.item(slot)}
+	}
+}
+
+// click sends the click to the server, predict is called under the state lock right before the click
+func (w *Window) click(slot int, mode, button byte, predict func() prediction) (*transaction, map[int]pk.Slot, error) {
+	w.state.Lock()
+	expected := predict()
+	transaction := &transaction{resync: expected.resync}
+	w.begin(transaction, expected.changes)
+	w.state.Unlock()
+
+	packet := protocol.ClickWindow{
+		WindowID:     pk.UnsignedByte(w.id),
+		Slot:         pk.Short(slot),
+		Button:       pk.Byte(button),
+		ActionNumber: pk.Short(transaction.action),
+		Mode:         pk.Byte(mode),
+		ClickedItem:  expected.clickedItem,
+	}
+
+	err := w.handler.tunnel.WriteServer(packet.Marshal())
 	if err != nil {
-		return err
+		w.confirm(transaction.action, false)
+		return nil, nil, err
 	}
 
-	if w.GetItem(to).BlockID != -1 {
-		err = w.Click(from, 0, 0)
+	return transaction, expected.changes, nil
+}
+
+// updateClient shows the predicted contents to the client, as the server does not send them after accepted clicks
+func (w *Window) updateClient(changes map[int]pk.Slot) error {
+	slots := make([]int, 0, len(changes))
+	for slot := range changes {
+		slots = append(slots, slot)
+	}
+
+	sort.Ints(slots)
+	for _, slot := range slots {
+		setSlot := protocol.SetSlot{
+			WindowID: pk.Byte(w.id),
+			Slot:     pk.Short(slot),
+			SlotData: changes[slot],
+		}
+
+		err := w.handler.tunnel.WriteClient(setSlot.Marshal())
 		if err != nil {
 			return err
 		}
 	}
 
-	w.items[from], w.items[to] = w.items[to], w.items[from]
+	return nil
+}
+
+// sequence sends the clicks one by one, only the last of them changes the contents.
+// The result is accepted only if all of the clicks are accepted, as rejection fails the clicks sent after it
+func (w *Window) sequence(slots []int, button byte, predict func() prediction) (*generic.ClickResult, error) {
+	var (
+		last    *transaction
+		changes map[int]pk.Slot
+		err     error
+	)
 
-	tunnel := w.handler.tunnel
-	updateSource := protocol.SetSlot{
-		WindowID: pk.Byte(w.id),
-		Slot:     pk.Short(from),
-		SlotData: w.GetItem(from),
+	for i, slot := range slots {
+		expected := w.predictNothing(slot, clickModePickup)
+		if i == len(slots)-1 {
+			expected = predict
+		}
+
+		last, changes, err = w.click(slot, clickModePickup, button, expected)
+		if err != nil {
+			return nil, err
+		}
+
+		button = 0
 	}
 
-	err = tunnel.WriteClient(updateSource.Marshal())
+	err = w.updateClient(changes)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	updateDestination := protocol.SetSlot{
-		WindowID: pk.Byte(w.id),
-		Slot:     pk.Short(to),
-		SlotData: w.GetItem(to),
-	}
+	return last.result, nil
+}
 
-	err = tunnel.WriteClient(updateDestination.Marshal())
+func (w *Window) Click(slot int, mode, button byte) (*generic.ClickResult, error) {
+	transaction, _, err := w.click(slot, mode, button, w.predictNothing(slot, mode))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return transaction.result, nil
+}
+
+// Move swaps contents of the slots
+func (w *Window) Move(from, to int) (*generic.ClickResult, error) {
+	w.Lock()
+	defer w.Unlock()
+
+	source, destination := w.GetItem(from), w.GetItem(to)
+	if source.IsEmpty() {
+		return nil, generic.ErrEmptySlot
+	}
+
+	slots := []int{from, to}
+	if !destination.IsEmpty() {
+		slots = append(slots, from)
+	}
+
+	return w.sequence(slots, 0, func() prediction {
+		last := slots[len(slots)-1]
+		return prediction{
+			clickedItem: w.item(last),
+			changes:     map[int]pk.Slot{from: destination, to: source},
+		}
+	})
 }
 
 func HandleOpenWindow(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
@@ -223,3 +503,72 @@ func HandleWindowItems(packet protocol.Packet, tunnel generic.Tunnel) (result *g
 
 	return generic.PassPacket(), nil
 }
+
+func HandleClickWindow(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	clickWindow := packet.(*protocol.ClickWindow)
+	window, ok := tunnel.GetInventoryHandler().GetWindow(int(clickWindow.WindowID))
+	if !ok {
+		return generic.PassPacket(), nil
+	}
+
+	clickWindow.ActionNumber = pk.Short(window.(*Window).trackClientClick(int16(clickWindow.ActionNumber)))
+	return generic.ModifyPacket(), nil
+}
+
+func HandleConfirmTransaction(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	confirmTransaction := packet.(*protocol.ConfirmTransaction)
+	window, ok := tunnel.GetInventoryHandler().GetWindow(int(confirmTransaction.WindowID))
+	if !ok {
+		return generic.PassPacket(), nil
+	}
+
+	transaction, restored := window.(*Window).confirm(int16(confirmTransaction.ActionNumber), bool(confirmTransaction.Accepted))
+	if transaction == nil {
+		return generic.PassPacket(), nil
+	}
+
+	if transaction.fromClient {
+		confirmTransaction.ActionNumber = pk.Short(transaction.clientAction)
+		return generic.ModifyPacket(), nil
+	}
+
+	// Client knows nothing about our clicks, so we acknowledge the rejection and resync the slots ourselves
+	if !confirmTransaction.Accepted {
+		acknowledgement := protocol.ServerConfirmTransaction{ConfirmTransaction: protocol.ConfirmTransaction{
+			WindowID:     confirmTransaction.WindowID,
+			ActionNumber: confirmTransaction.ActionNumber,
+			Accepted:     true,
+		}}
+
+		err = tunnel.WriteServer(acknowledgement.Marshal())
+		if err != nil {
+			return
+		}
+
+		for slot, item := range restored {
+			setSlot := protocol.SetSlot{WindowID: confirmTransaction.WindowID, Slot: pk.Short(slot), SlotData: item}
+			err = tunnel.WriteClient(setSlot.Marshal())
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	return generic.RejectPacket(), nil
+}
+
+func HandleServerConfirmTransaction(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	confirmTransaction := packet.(*protocol.ServerConfirmTransaction)
+	window, ok := tunnel.GetInventoryHandler().GetWindow(int(confirmTransaction.WindowID))
+	if !ok {
+		return generic.PassPacket(), nil
+	}
+
+	action, ok := window.(*Window).acknowledge(int16(confirmTransaction.ActionNumber))
+	if !ok {
+		return generic.PassPacket(), nil
+	}
+
+	confirmTransaction.ActionNumber = pk.Short(action)
+	return generic.ModifyPacket(), nil
+}
@@ -0,0 +1,371 @@
+package proxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tnze/go-mc/chat"
+	"github.com/destructiqn/kogtevran/generic"
+	"github.com/destructiqn/kogtevran/minecraft"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/destructiqn/kogtevran/minecraft/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+// receive decodes the next packet written to the connection end
+func receive(t *testing.T, packets <-chan pk.Packet, packet protocol.Packet) {
+	select {
+	case received := <-packets:
+		assert.NoError(t, packet.Read(received))
+	case <-time.After(time.Second):
+		t.Fatal("packet was not written")
+	}
+}
+
+func handleResult(t *testing.T, tunnel *MinecraftTunnel, packet protocol.Packet, direction int) *generic.HandlerResult {
+	result, err := tunnel.HandlerRegistry.Handle(packet.Marshal(), direction)
+	assert.NoError(t, err)
+	assert.NoError(t, tunnel.Flush())
+	return result
+}
+
+func confirm(windowID, action int, accepted bool) *protocol.ConfirmTransaction {
+	return &protocol.ConfirmTransaction{WindowID: pk.Byte(windowID), ActionNumber: pk.Short(action), Accepted: pk.Boolean(accepted)}
+}
+
+func TestWindow_Move(t *testing.T) {
+	tunnel, toServer, toClient := newRecordingTunnel(t)
+	inventory, _ := tunnel.InventoryHandler.GetWindow(0)
+
+	stone, sword := pk.Slot{BlockID: 1, ItemCount: 64}, pk.Slot{BlockID: 276, ItemCount: 1}
+	inventory.PutItem(9, stone)
+	inventory.PutItem(36, sword)
+
+	// Moving to an occupied slot takes three clicks, all of them get monotonic action numbers
+	move, err := inventory.Move(9, 36)
+	assert.NoError(t, err)
+
+	for i, slot := range []int{9, 36, 9} {
+		var click protocol.ClickWindow
+		receive(t, toServer, &click)
+		assert.Equal(t, pk.Short(slot), click.Slot)
+		assert.Equal(t, pk.Short(i+1), click.ActionNumber)
+	}
+
+	var setSlot protocol.SetSlot
+	receive(t, toClient, &setSlot)
+	assert.Equal(t, sword, setSlot.SlotData)
+	receive(t, toClient, &setSlot)
+	assert.Equal(t, stone, setSlot.SlotData)
+	assert.Equal(t, sword, inventory.GetItem(9))
+	assert.Equal(t, stone, inventory.GetItem(36))
+
+	// Confirmations of our clicks are not passed to the client
+	assert.False(t, handleResult(t, tunnel, confirm(0, 1, true), protocol.ConnS2C).ShouldPass)
+	assert.False(t, move.IsAccepted())
+
+	// Rejection is acknowledged, later clicks are failed and the swap is rolled back
+	assert.False(t, handleResult(t, tunnel, confirm(0, 2, false), protocol.ConnS2C).ShouldPass)
+
+	var acknowledgement protocol.ServerConfirmTransaction
+	receive(t, toServer, &acknowledgement)
+	assert.Equal(t, pk.Short(2), acknowledgement.ActionNumber)
+	assert.True(t, bool(acknowledgement.Accepted))
+
+	accepted, err := move.Wait(time.Second)
+	assert.NoError(t, err)
+	assert.False(t, accepted)
+	assert.Equal(t, stone, inventory.GetItem(9))
+	assert.Equal(t, sword, inventory.GetItem(36))
+
+	restored := make(map[pk.Short]pk.Slot)
+	for i := 0; i < 2; i++ {
+		receive(t, toClient, &setSlot)
+		restored[setSlot.Slot] = setSlot.SlotData
+	}
+	assert.Equal(t, map[pk.Short]pk.Slot{9: stone, 36: sword}, restored)
+
+	// Late confirmation of a failed click is unknown, so it passes to the client
+	assert.True(t, handleResult(t, tunnel, confirm(0, 3, true), protocol.ConnS2C).ShouldPass)
+
+	move, err = inventory.Move(9, 10)
+	assert.NoError(t, err)
+	handleResult(t, tunnel, confirm(0, 4, true), protocol.ConnS2C)
+	handleResult(t, tunnel, confirm(0, 5, true), protocol.ConnS2C)
+	assert.True(t, move.IsAccepted())
+	assert.Equal(t, stone, inventory.GetItem(10))
+}
+
+func TestWindow_ClientClicks(t *testing.T) {
+	tunnel, _, _ := newRecordingTunnel(t)
+	inventory, _ := tunnel.InventoryHandler.GetWindow(0)
+
+	click, err := inventory.Click(5, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int16(1), click.ActionNumber)
+
+	// Client has its own counter, its clicks are renumbered on the way to the server and back
+	result := handleResult(t, tunnel, &protocol.ClickWindow{Slot: 6, ActionNumber: 1}, protocol.ConnC2S)
+	var clientClick protocol.ClickWindow
+	assert.NoError(t, clientClick.Read(result.Packet))
+	assert.Equal(t, pk.Short(2), clientClick.ActionNumber)
+
+	result = handleResult(t, tunnel, confirm(0, 2, false), protocol.ConnS2C)
+	assert.True(t, result.ShouldPass)
+	var confirmation protocol.ConfirmTransaction
+	assert.NoError(t, confirmation.Read(result.Packet))
+	assert.Equal(t, pk.Short(1), confirmation.ActionNumber)
+
+	result = handleResult(t, tunnel, &protocol.ServerConfirmTransaction{ConfirmTransaction: *confirm(0, 1, true)}, protocol.ConnC2S)
+	var acknowledgement protocol.ServerConfirmTransaction
+	assert.NoError(t, acknowledgement.Read(result.Packet))
+	assert.Equal(t, pk.Short(2), acknowledgement.ActionNumber)
+
+	// Our click was sent before the rejected one
+	assert.False(t, handleResult(t, tunnel, confirm(0, 1, true), protocol.ConnS2C).ShouldPass)
+	assert.True(t, click.IsAccepted())
+}
+
+func TestWindow_Close(t *testing.T) {
+	tunnel, _, _ := newRecordingTunnel(t)
+	tunnel.InventoryHandler.OpenWindow(1, NewWindow(tunnel.InventoryHandler, 1, 27, "minecraft:chest", chat.Text("Chest")))
+	chest, _ := tunnel.InventoryHandler.GetWindow(1)
+
+	click, err := chest.Click(0, 1, 0)
+	assert.NoError(t, err)
+
+	tunnel.InventoryHandler.CloseWindow(1)
+	accepted, err := click.Wait(time.Second)
+	assert.NoError(t, err)
+	assert.False(t, accepted)
+}
+
+// receiveClick decodes the next click sent to the server
+func receiveClick(t *testing.T, toServer <-chan pk.Packet) protocol.ClickWindow {
+	var click protocol.ClickWindow
+	receive(t, toServer, &click)
+	return click
+}
+
+func TestWindow_FindItems(t *testing.T) {
+	tunnel := newPipeTunnel(t)
+	tunnel.InventoryHandler.OpenWindow(1, NewWindow(tunnel.InventoryHandler, 1, 27, "minecraft:chest", chat.Text("Chest")))
+	chest, _ := tunnel.InventoryHandler.GetWindow(1)
+
+	stone := pk.Slot{BlockID: 1, ItemCount: 1}
+	for _, slot := range []int{62, 3, 40} {
+		chest.PutItem(slot, stone)
+	}
+
+	assert.Equal(t, []int{3, 40, 62}, chest.FindItems(minecraft.IsItem(1), minecraft.RegionAll))
+	assert.Equal(t, []int{40}, chest.FindItems(minecraft.IsItem(1), minecraft.RegionMain))
+	assert.Empty(t, chest.FindItems(minecraft.IsItemWithDamage(1, 1), minecraft.RegionAll))
+
+	slot, ok := chest.FindItem(minecraft.IsItem(1), minecraft.RegionHotbar)
+	assert.True(t, ok)
+	assert.Equal(t, 62, slot)
+	index, _ := chest.GetLayout().HotbarIndex(slot)
+	assert.Equal(t, 8, index)
+	assert.Equal(t, chest, tunnel.InventoryHandler.GetOpenWindow())
+}
+
+func TestInventoryHandler_GetOpenWindow(t *testing.T) {
+	tunnel := newPipeTunnel(t)
+	inventory := tunnel.InventoryHandler
+	player, _ := inventory.GetWindow(0)
+	assert.Equal(t, player, inventory.GetOpenWindow())
+
+	// A window which has not been closed is still tracked, the one opened last is open
+	for id := 1; id <= 5; id++ {
+		inventory.OpenWindow(id, NewWindow(inventory, byte(id), 27, "minecraft:chest", chat.Text("Chest")))
+	}
+	chest, _ := inventory.GetWindow(5)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, chest, inventory.GetOpenWindow())
+	}
+
+	inventory.CloseWindow(5)
+	assert.Equal(t, player, inventory.GetOpenWindow())
+
+	inventory.OpenWindow(3, NewWindow(inventory, 3, 27, "minecraft:chest", chat.Text("Chest")))
+	inventory.Reset()
+	assert.Equal(t, player, inventory.GetOpenWindow())
+}
+
+func TestWindow_ShiftClick(t *testing.T) {
+	tunnel, toServer, toClient := newRecordingTunnel(t)
+	inventory, _ := tunnel.InventoryHandler.GetWindow(0)
+
+	stone, helmet := pk.Slot{BlockID: 1, ItemCount: 30}, pk.Slot{BlockID: 310, ItemCount: 1}
+	inventory.PutItem(9, stone)
+	inventory.PutItem(36, pk.Slot{BlockID: 1, ItemCount: 60})
+	inventory.PutItem(37, helmet)
+
+	// Main inventory fills existing stacks of the hotbar first
+	_, err := inventory.ShiftClick(9)
+	assert.NoError(t, err)
+	click := receiveClick(t, toServer)
+	assert.Equal(t, pk.Byte(clickModeShift), click.Mode)
+	assert.Equal(t, stone, click.ClickedItem)
+	assert.True(t, inventory.GetItem(9).IsEmpty())
+	assert.Equal(t, int8(64), inventory.GetItem(36).ItemCount)
+	assert.Equal(t, int8(26), inventory.GetItem(38).ItemCount)
+
+	var setSlot protocol.SetSlot
+	for _, slot := range []int{9, 36, 38} {
+		receive(t, toClient, &setSlot)
+		assert.Equal(t, pk.Short(slot), setSlot.Slot)
+	}
+
+	// Armor goes to its slot
+	_, err = inventory.ShiftClick(37)
+	assert.NoError(t, err)
+	receiveClick(t, toServer)
+	assert.Equal(t, helmet, inventory.GetItem(5))
+
+	_, err = inventory.ShiftClick(37)
+	assert.Equal(t, generic.ErrEmptySlot, err)
+
+	// Chest sends its items to the end of the hotbar
+	tunnel.InventoryHandler.OpenWindow(1, NewWindow(tunnel.InventoryHandler, 1, 27, "minecraft:chest", chat.Text("Chest")))
+	chest, _ := tunnel.InventoryHandler.GetWindow(1)
+	chest.PutItem(0, stone)
+
+	_, err = chest.ShiftClick(0)
+	assert.NoError(t, err)
+	receiveClick(t, toServer)
+	assert.Equal(t, stone, chest.GetItem(62))
+
+	_, err = chest.ShiftClick(62)
+	assert.NoError(t, err)
+	receiveClick(t, toServer)
+	assert.Equal(t, stone, chest.GetItem(0))
+}
+
+func TestWindow_ShiftClickFurnace(t *testing.T) {
+	tunnel, toServer, _ := newRecordingTunnel(t)
+	tunnel.InventoryHandler.OpenWindow(1, NewWindow(tunnel.InventoryHandler, 1, 3, "minecraft:furnace", chat.Text("Furnace")))
+	furnace, _ := tunnel.InventoryHandler.GetWindow(1)
+
+	iron := pk.Slot{BlockID: 265, ItemCount: 5}
+	furnace.PutItem(2, iron)
+	furnace.PutItem(3, pk.Slot{BlockID: 263, ItemCount: 1})
+
+	_, err := furnace.ShiftClick(2)
+	assert.NoError(t, err)
+	receiveClick(t, toServer)
+	assert.Equal(t, iron, furnace.GetItem(38))
+
+	// Whether the item is smeltable is up to the server, it sends the contents after the rejection
+	click, err := furnace.ShiftClick(3)
+	assert.NoError(t, err)
+	assert.True(t, receiveClick(t, toServer).ClickedItem.IsEmpty())
+	assert.Equal(t, int8(1), furnace.GetItem(3).ItemCount)
+
+	handleResult(t, tunnel, confirm(1, 2, false), protocol.ConnS2C)
+	accepted, err := click.Wait(time.Second)
+	assert.NoError(t, err)
+	assert.True(t, accepted)
+}
+
+func TestWindow_SwapHotbarAndDrop(t *testing.T) {
+	tunnel, toServer, _ := newRecordingTunnel(t)
+	inventory, _ := tunnel.InventoryHandler.GetWindow(0)
+
+	stone, sword := pk.Slot{BlockID: 1, ItemCount: 3}, pk.Slot{BlockID: 276, ItemCount: 1}
+	inventory.PutItem(9, stone)
+	inventory.PutItem(38, sword)
+
+	_, err := inventory.SwapHotbar(9, 2)
+	assert.NoError(t, err)
+	click := receiveClick(t, toServer)
+	assert.Equal(t, pk.Byte(clickModeHotbar), click.Mode)
+	assert.Equal(t, pk.Byte(2), click.Button)
+	assert.Equal(t, sword, inventory.GetItem(9))
+	assert.Equal(t, stone, inventory.GetItem(38))
+
+	_, err = inventory.SwapHotbar(9, 9)
+	assert.Equal(t, generic.ErrInvalidSlot, err)
+
+	_, err = inventory.Drop(38, false)
+	assert.NoError(t, err)
+	click = receiveClick(t, toServer)
+	assert.Equal(t, pk.Byte(clickModeDrop), click.Mode)
+	assert.Equal(t, pk.Byte(0), click.Button)
+	assert.Equal(t, int8(2), inventory.GetItem(38).ItemCount)
+
+	_, err = inventory.Drop(38, true)
+	assert.NoError(t, err)
+	assert.Equal(t, pk.Byte(1), receiveClick(t, toServer).Button)
+	assert.True(t, inventory.GetItem(38).IsEmpty())
+}
+
+func TestWindow_SplitAndMerge(t *testing.T) {
+	tunnel, toServer, _ := newRecordingTunnel(t)
+	inventory, _ := tunnel.InventoryHandler.GetWindow(0)
+
+	inventory.PutItem(9, pk.Slot{BlockID: 1, ItemCount: 5})
+	inventory.PutItem(10, pk.Slot{BlockID: 1, ItemCount: 62})
+	inventory.PutItem(11, pk.Slot{BlockID: 276, ItemCount: 1})
+
+	// Right click picks up the bigger half, the left one puts it down
+	_, err := inventory.Split(9, 12)
+	assert.NoError(t, err)
+	first, second := receiveClick(t, toServer), receiveClick(t, toServer)
+	assert.Equal(t, pk.Byte(1), first.Button)
+	assert.Equal(t, pk.Byte(0), second.Button)
+	assert.Equal(t, int8(2), inventory.GetItem(9).ItemCount)
+	assert.Equal(t, int8(3), inventory.GetItem(12).ItemCount)
+
+	_, err = inventory.Split(9, 10)
+	assert.Equal(t, generic.ErrSlotOccupied, err)
+	_, err = inventory.Split(11, 13)
+	assert.Equal(t, generic.ErrNotStackable, err)
+
+	// Remainder that does not fit is put back
+	_, err = inventory.Merge(12, 10)
+	assert.NoError(t, err)
+	for _, slot := range []int{12, 10, 12} {
+		assert.Equal(t, pk.Short(slot), receiveClick(t, toServer).Slot)
+	}
+	assert.Equal(t, int8(64), inventory.GetItem(10).ItemCount)
+	assert.Equal(t, int8(1), inventory.GetItem(12).ItemCount)
+
+	_, err = inventory.Merge(12, 9)
+	assert.NoError(t, err)
+	receiveClick(t, toServer)
+	receiveClick(t, toServer)
+	assert.True(t, inventory.GetItem(12).IsEmpty())
+	assert.Equal(t, int8(3), inventory.GetItem(9).ItemCount)
+
+	_, err = inventory.Merge(9, 10)
+	assert.Equal(t, generic.ErrNotStackable, err)
+	_, err = inventory.Merge(9, 11)
+	assert.Equal(t, generic.ErrNotStackable, err)
+}
+
+func TestInventoryHandler_SelectItem(t *testing.T) {
+	tunnel, toServer, _ := newRecordingTunnel(t)
+	inventory, _ := tunnel.InventoryHandler.GetWindow(0)
+	soup := minecraft.IsItem(282)
+
+	_, err := tunnel.InventoryHandler.SelectItem(soup, 8)
+	assert.Equal(t, generic.ErrItemNotFound, err)
+
+	// Soup from the main inventory is swapped into the fallback slot
+	inventory.PutItem(20, pk.Slot{BlockID: 282, ItemCount: 1})
+	index, err := tunnel.InventoryHandler.SelectItem(soup, 8)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, index)
+	assert.Equal(t, pk.Byte(clickModeHotbar), receiveClick(t, toServer).Mode)
+
+	var heldItemChange protocol.ServerHeldItemChange
+	receive(t, toServer, &heldItemChange)
+	assert.Equal(t, pk.Short(8), heldItemChange.Slot)
+
+	// Held item is used as is
+	handle(t, tunnel, &protocol.ServerHeldItemChange{Slot: 8}, protocol.ConnC2S)
+	index, err = tunnel.InventoryHandler.SelectItem(soup, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, index)
+}
@@ -29,11 +29,12 @@ import (
 	"github.com/destructiqn/kogtevran/modules/unlimitedcps"
 )
 
+// ModuleHandler keeps the modules of a tunnel, they are looked up by handlers while more are registered
 type ModuleHandler struct {
 	tunnel                *MinecraftTunnel
 	modules               map[string]generic.Module
 	initializedCategories map[string]bool
-	sync.Mutex
+	sync.RWMutex
 }
 
 func NewModuleHandler(tunnel *MinecraftTunnel) *ModuleHandler {
@@ -49,23 +50,47 @@ func (m *ModuleHandler) RegisterModule(module generic.Module) {
 
 	tickingModule, isTicking := module.(generic.TickingModule)
 	if isTicking {
-		go func(module generic.TickingModule) {
-			for {
-				select {
-				case <-time.NewTimer(tickingModule.GetInterval()).C:
-					if !module.IsEnabled() {
-						continue
-					}
-
-					err := module.Tick()
-					if err != nil {
-						log.Println("error ticking", module.GetIdentifier(), err)
-					}
-				case <-tickingModule.GetInterruptChannel():
-					return
-				}
+		go tick(tickingModule)
+	}
+}
+
+// minTickInterval keeps modules with the interval option set to zero from spinning
+const minTickInterval = time.Millisecond
+
+func tickInterval(module generic.TickingModule) time.Duration {
+	if interval := module.GetInterval(); interval > minTickInterval {
+		return interval
+	}
+
+	return minTickInterval
+}
+
+// tick runs the module on its interval until its ticker is stopped or the tunnel is closed
+func tick(module generic.TickingModule) {
+	interval := tickInterval(module)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// The interval is an option, it can be changed while the module is running
+			if current := tickInterval(module); current != interval {
+				interval = current
+				ticker.Reset(interval)
+			}
+
+			if !module.IsEnabled() {
+				continue
 			}
-		}(tickingModule)
+
+			err := module.Tick()
+			if err != nil {
+				log.Println("error ticking", module.GetIdentifier(), err)
+			}
+		case <-module.GetContext().Done():
+			return
+		}
 	}
 }
 
@@ -84,8 +109,8 @@ func (m ModuleList) Swap(i, j int) {
 }
 
 func (m *ModuleHandler) GetModules() []generic.Module {
-	m.Lock()
-	defer m.Unlock()
+	m.RLock()
+	defer m.RUnlock()
 
 	aModules := make([]generic.Module, 0)
 	for _, module := range m.modules {
@@ -95,12 +120,15 @@ func (m *ModuleHandler) GetModules() []generic.Module {
 }
 
 func (m *ModuleHandler) GetModule(identifier string) (generic.Module, bool) {
+	m.RLock()
+	defer m.RUnlock()
+
 	module, ok := m.modules[identifier]
 	return module, ok
 }
 
 func (m *ModuleHandler) IsModuleEnabled(moduleID string) bool {
-	module, ok := m.modules[moduleID]
+	module, ok := m.GetModule(moduleID)
 	if !ok {
 		return false
 	}
@@ -184,7 +212,7 @@ func (m *ModuleHandler) GetModulesDetails() []map[string]interface{} {
 		modulesList := make(ModuleList, 0)
 
 		for _, moduleID := range category.ModuleIDs {
-			if module, ok := m.GetModule(moduleID); ok {
+			if module, ok := m.modules[moduleID]; ok {
 				modulesList = append(modulesList, module)
 			}
 		}
@@ -320,78 +348,146 @@ func (m *ModuleHandler) GetModulesDetails() []map[string]interface{} {
 
 func RegisterDefaultModules(tunnel *MinecraftTunnel) {
 	moduleHandler := tunnel.GetModuleHandler()
+	options := tunnel.ModuleProfile
+	if options == nil {
+		options = GetSettings().ModuleOptions
+	}
+
+	version := tunnel.GetVersion()
+	for _, module := range defaultModules(tunnel.HasFeature) {
+		// Modules relying on packets the version encodes differently would only fail on every tick
+		if versioned, ok := module.(generic.VersionedModule); ok && !versioned.SupportsVersion(version) {
+			continue
+		}
+
+		// Options have been validated along with the settings
+		if err := ApplyModuleOptions(module, options[module.GetIdentifier()]); err != nil {
+			log.Println("error applying options of", module.GetIdentifier()+":", err)
+		}
+
+		moduleHandler.RegisterModule(module)
+	}
+}
+
+// defaultModules returns new instances of the modules the license has features for
+func defaultModules(hasFeature func(feature license.Feature) bool) []generic.Module {
 	tpAuraTicking := modules.SimpleTickingModule{Interval: 250 * time.Millisecond}
+	list := make([]generic.Module, 0)
 
-	if tunnel.HasFeature(license.FeatureFlight) {
-		moduleHandler.RegisterModule(&flight.Flight{Speed: 3})
+	if hasFeature(license.FeatureFlight) {
+		list = append(list, &flight.Flight{Speed: 3})
 	}
 
-	if tunnel.HasFeature(license.FeatureAntiKnockback) {
-		moduleHandler.RegisterModule(&antiknockback.AntiKnockback{})
+	if hasFeature(license.FeatureAntiKnockback) {
+		list = append(list, &antiknockback.AntiKnockback{})
 	}
 
-	if tunnel.HasFeature(license.FeatureNoFall) {
-		moduleHandler.RegisterModule(&nofall.NoFall{})
+	if hasFeature(license.FeatureNoFall) {
+		list = append(list, &nofall.NoFall{})
 	}
 
-	if tunnel.HasFeature(license.FeatureKillAura) {
+	if hasFeature(license.FeatureKillAura) {
 		genericAura := aura.GenericAura{
 			MaxDistance: 7, HitAnimation: false,
 			SimpleTickingModule: modules.SimpleTickingModule{Interval: 35 * time.Millisecond},
 		}
 
-		moduleHandler.RegisterModule(&aura.KillAura{GenericAura: genericAura})
-		moduleHandler.RegisterModule(&aura.MobAura{GenericAura: genericAura})
+		list = append(list, &aura.KillAura{GenericAura: genericAura}, &aura.MobAura{GenericAura: genericAura})
 	}
 
-	if tunnel.HasFeature(license.FeatureLongJump) {
-		moduleHandler.RegisterModule(&longjump.LongJump{Power: 2, Height: 1})
+	if hasFeature(license.FeatureLongJump) {
+		list = append(list, &longjump.LongJump{Power: 2, Height: 1})
 	}
 
-	if tunnel.HasFeature(license.FeatureUnlimitedCPS) {
-		moduleHandler.RegisterModule(&unlimitedcps.UnlimitedCPS{})
+	if hasFeature(license.FeatureUnlimitedCPS) {
+		list = append(list, &unlimitedcps.UnlimitedCPS{})
 	}
 
-	if tunnel.HasFeature(license.FeatureTPAura) {
-		moduleHandler.RegisterModule(&tpaura.TPAura{SearchRadius: 20, TeleportRadius: 4, SimpleTickingModule: tpAuraTicking})
+	if hasFeature(license.FeatureTPAura) {
+		list = append(list, &tpaura.TPAura{SearchRadius: 20, TeleportRadius: 4, SimpleTickingModule: tpAuraTicking})
 	}
 
-	if tunnel.HasFeature(license.FeatureESP) {
-		moduleHandler.RegisterModule(&modules.ClientModule{
+	if hasFeature(license.FeatureESP) {
+		list = append(list, &modules.ClientModule{
 			Identifier:  modules.ModulePlayerESP,
 			Description: []string{"Отныне ты можешь видеть игроков через стены"},
-		})
-
-		moduleHandler.RegisterModule(&modules.ClientModule{
+		}, &modules.ClientModule{
 			Identifier:  modules.ModuleChestESP,
 			Description: []string{"Отныне ты можешь видеть сундуки через стены"},
 		})
 	}
 
-	if tunnel.HasFeature(license.FeatureNuker) {
-		moduleHandler.RegisterModule(&nuker.Nuker{Radius: 2, Delay: 1, SimpleTickingModule: modules.SimpleTickingModule{Interval: 5 * time.Second}})
+	if hasFeature(license.FeatureNuker) {
+		list = append(list, &nuker.Nuker{Radius: 2, Delay: 1, SimpleTickingModule: modules.SimpleTickingModule{Interval: 5 * time.Second}})
 	}
 
-	if tunnel.HasFeature(license.FeatureFastBreak) {
-		moduleHandler.RegisterModule(&fastbreak.FastBreak{})
+	if hasFeature(license.FeatureFastBreak) {
+		list = append(list, &fastbreak.FastBreak{})
 	}
 
-	if tunnel.HasFeature(license.FeatureNoBadEffects) {
-		moduleHandler.RegisterModule(&nobadeffects.NoBadEffects{})
+	if hasFeature(license.FeatureNoBadEffects) {
+		list = append(list, &nobadeffects.NoBadEffects{})
 	}
 
-	if tunnel.HasFeature(license.FeatureSpeedHack) {
-		moduleHandler.RegisterModule(&speedhack.SpeedHack{Speed: 2})
+	if hasFeature(license.FeatureSpeedHack) {
+		list = append(list, &speedhack.SpeedHack{Speed: 2})
 	}
 
-	if tunnel.HasFeature(license.FeatureAutoSoup) {
-		moduleHandler.RegisterModule(&autosoup.AutoSoup{MinHealth: 10})
+	if hasFeature(license.FeatureAutoSoup) {
+		list = append(list, &autosoup.AutoSoup{MinHealth: 10})
+	}
+
+	return append(list,
+		&spammer.Spammer{SimpleTickingModule: modules.SimpleTickingModule{Interval: 20 * time.Second}},
+		&cmdcam.CMDCam{},
+	)
+}
+
+// ApplyModuleOptions sets the options of the module, values are parsed like the set command does
+func ApplyModuleOptions(module generic.Module, options map[string]string) error {
+	for name, raw := range options {
+		value, ok := modules.GetOptionValue(module, name)
+		if !ok {
+			return fmt.Errorf("unknown option %s", name)
+		}
+
+		newValue, err := modules.ParseOptionValue(value, raw)
+		if err != nil {
+			return fmt.Errorf("option %s: %w", name, err)
+		}
+
+		if !modules.SetOptionValue(module, name, newValue) {
+			return fmt.Errorf("unable to change option %s", name)
+		}
 	}
 
-	moduleHandler.RegisterModule(&spammer.Spammer{SimpleTickingModule: modules.SimpleTickingModule{Interval: 20 * time.Second}})
-	moduleHandler.RegisterModule(&cmdcam.CMDCam{})
+	return nil
+}
+
+// ValidateModuleOptions checks options keyed by module identifier against the default modules
+func ValidateModuleOptions(options map[string]map[string]string) error {
+	known := make(map[string]generic.Module)
+	for _, module := range defaultModules(func(license.Feature) bool { return true }) {
+		known[module.GetIdentifier()] = module
+	}
+
+	for identifier, moduleOptions := range options {
+		module, ok := known[identifier]
+		if !ok {
+			return fmt.Errorf("unknown module %s", identifier)
+		}
+
+		if err := ApplyModuleOptions(module, moduleOptions); err != nil {
+			return fmt.Errorf("module %s: %w", identifier, err)
+		}
+	}
+
+	return nil
 }
 
 func (m *ModuleHandler) Reset() {
+	m.Lock()
+	defer m.Unlock()
+
 	m.initializedCategories = make(map[string]bool)
 }
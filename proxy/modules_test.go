@@ -0,0 +1,67 @@
+package proxy
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/destructiqn/kogtevran/license"
+	"github.com/destructiqn/kogtevran/minecraft/protocol"
+	"github.com/destructiqn/kogtevran/modules"
+	"github.com/destructiqn/kogtevran/modules/aura"
+	"github.com/destructiqn/kogtevran/modules/nuker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyModuleOptions(t *testing.T) {
+	module := &nuker.Nuker{Radius: 2, SimpleTickingModule: modules.SimpleTickingModule{Interval: 5 * time.Second}}
+	assert.NoError(t, ApplyModuleOptions(module, map[string]string{"interval": "1s", "radius": "4", "delay": "0.5"}))
+	assert.Equal(t, time.Second, module.Interval)
+	assert.Equal(t, 4, module.Radius)
+	assert.Equal(t, 0.5, module.Delay)
+
+	assert.Error(t, ApplyModuleOptions(module, map[string]string{"radius": "far"}))
+	assert.Error(t, ApplyModuleOptions(module, map[string]string{"speed": "2"}))
+}
+
+func TestValidateModuleOptions(t *testing.T) {
+	assert.NoError(t, ValidateModuleOptions(map[string]map[string]string{
+		modules.ModuleKillAura: {"interval": "50ms", "hitAnimation": "true"},
+		modules.ModuleFlight:   {"speed": "2.5"},
+	}))
+
+	assert.Error(t, ValidateModuleOptions(map[string]map[string]string{"Blink": {"interval": "1s"}}))
+	assert.Error(t, ValidateModuleOptions(map[string]map[string]string{modules.ModuleSpammer: {"interval": "often"}}))
+}
+
+func TestKillAura_Version(t *testing.T) {
+	tunnel, toServer, _ := newRecordingTunnel(t)
+	handle(t, tunnel, &protocol.SpawnPlayer{EntityID: 1, X: 32}, protocol.ConnS2C)
+
+	killAura := &aura.KillAura{}
+	killAura.MaxDistance = 7
+	killAura.Register(tunnel)
+
+	// Attacks are written with the logical ID, which is the one of 1.8
+	assert.NoError(t, killAura.Tick())
+	assert.NoError(t, tunnel.Flush())
+	select {
+	case packet := <-toServer:
+		assert.Equal(t, int32(protocol.ServerboundUseEntity), packet.ID)
+	case <-time.After(time.Second):
+		t.Fatal("attack has not been written")
+	}
+
+	// 1.12.2 encodes attacks differently, they are not written as anything else
+	tunnel.SetVersion(protocol.V1_12_2)
+	assert.False(t, killAura.SupportsVersion(protocol.V1_12_2))
+	assert.True(t, errors.Is(killAura.Tick(), protocol.ErrUnsupportedPacket))
+
+	// Neither is the module registered for sessions of that version
+	tunnel.TunnelPair = &TunnelPair{License: &license.DevelopmentLicense{}}
+	RegisterDefaultModules(tunnel)
+	_, ok := tunnel.GetModuleHandler().GetModule(modules.ModuleKillAura)
+	assert.False(t, ok)
+	_, ok = tunnel.GetModuleHandler().GetModule(modules.ModuleNuker)
+	assert.True(t, ok)
+}
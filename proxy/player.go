@@ -1,6 +1,8 @@
 package proxy
 
 import (
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/destructiqn/kogtevran/generic"
@@ -9,58 +11,206 @@ import (
 	"github.com/destructiqn/kogtevran/minecraft/protocol"
 )
 
+// PlayerHandler state is written by the pipe goroutines and read by modules, so it is accessed only under the lock
 type PlayerHandler struct {
-	tunnel      *MinecraftTunnel
-	entityID    int32
-	isFlying    bool
-	onGround    bool
-	location    *minecraft.Location
-	health      float64
-	currentSlot int
-	PlayerName  string
+	tunnel        *MinecraftTunnel
+	entityID      int32
+	isFlying      bool
+	onGround      bool
+	location      minecraft.Location
+	health        float64
+	food          int
+	saturation    float64
+	experience    minecraft.Experience
+	gameMode      minecraft.GameMode
+	hardcore      bool
+	abilities     minecraft.Abilities
+	effects       map[int]minecraft.PotionEffect
+	attributes    map[string]minecraft.Attribute
+	spawnPosition pk.Position
+	dimension     int
+	currentSlot   int
+	playerName    string
+	sync.RWMutex
 }
 
 func NewPlayerHandler(tunnel *MinecraftTunnel) *PlayerHandler {
-	return &PlayerHandler{
-		tunnel:   tunnel,
-		location: &minecraft.Location{},
-	}
+	handler := &PlayerHandler{tunnel: tunnel}
+	handler.reset()
+	return handler
+}
+
+// reset brings the state of the player entity to the one it has after spawn, must be called under the lock
+func (p *PlayerHandler) reset() {
+	p.health, p.food, p.saturation = 20, 20, 5
+	p.effects = make(map[int]minecraft.PotionEffect)
+	p.attributes = make(map[string]minecraft.Attribute)
+}
+
+// update changes the state under the lock
+func (p *PlayerHandler) update(change func()) {
+	p.Lock()
+	change()
+	p.Unlock()
 }
 
 func (p *PlayerHandler) GetEntityID() int32 {
+	p.RLock()
+	defer p.RUnlock()
 	return p.entityID
 }
 
 func (p *PlayerHandler) IsFlying() bool {
+	p.RLock()
+	defer p.RUnlock()
 	return p.isFlying
 }
 
 func (p *PlayerHandler) IsOnGround() bool {
+	p.RLock()
+	defer p.RUnlock()
 	return p.onGround
 }
 
 func (p *PlayerHandler) SetFlying(isFlying bool) {
-	p.isFlying = isFlying
+	p.update(func() {
+		p.isFlying = isFlying
+	})
 }
 
+// GetLocation returns a copy of the current location
 func (p *PlayerHandler) GetLocation() *minecraft.Location {
-	return p.location
+	p.RLock()
+	defer p.RUnlock()
+	location := p.location
+	return &location
 }
 
 func (p *PlayerHandler) GetPlayerName() string {
-	return p.PlayerName
+	p.RLock()
+	defer p.RUnlock()
+	return p.playerName
+}
+
+func (p *PlayerHandler) SetPlayerName(name string) {
+	p.update(func() {
+		p.playerName = name
+	})
 }
 
 func (p *PlayerHandler) GetHealth() float64 {
+	p.RLock()
+	defer p.RUnlock()
 	return p.health
 }
 
+func (p *PlayerHandler) GetFood() int {
+	p.RLock()
+	defer p.RUnlock()
+	return p.food
+}
+
+func (p *PlayerHandler) GetSaturation() float64 {
+	p.RLock()
+	defer p.RUnlock()
+	return p.saturation
+}
+
+func (p *PlayerHandler) GetExperience() minecraft.Experience {
+	p.RLock()
+	defer p.RUnlock()
+	return p.experience
+}
+
+func (p *PlayerHandler) GetGameMode() minecraft.GameMode {
+	p.RLock()
+	defer p.RUnlock()
+	return p.gameMode
+}
+
+func (p *PlayerHandler) IsHardcore() bool {
+	p.RLock()
+	defer p.RUnlock()
+	return p.hardcore
+}
+
+func (p *PlayerHandler) GetAbilities() minecraft.Abilities {
+	p.RLock()
+	defer p.RUnlock()
+	return p.abilities
+}
+
+// GetEffects returns active potion effects ordered by effect ID
+func (p *PlayerHandler) GetEffects() []minecraft.PotionEffect {
+	p.RLock()
+	defer p.RUnlock()
+
+	effects := make([]minecraft.PotionEffect, 0, len(p.effects))
+	for _, effect := range p.effects {
+		if !effect.IsExpired() {
+			effects = append(effects, effect)
+		}
+	}
+
+	sort.Slice(effects, func(i, j int) bool {
+		return effects[i].EffectID < effects[j].EffectID
+	})
+
+	return effects
+}
+
+func (p *PlayerHandler) GetEffect(effectID int) (minecraft.PotionEffect, bool) {
+	p.RLock()
+	defer p.RUnlock()
+
+	effect, ok := p.effects[effectID]
+	if !ok || effect.IsExpired() {
+		return minecraft.PotionEffect{}, false
+	}
+
+	return effect, true
+}
+
+func (p *PlayerHandler) GetAttribute(key string) (minecraft.Attribute, bool) {
+	p.RLock()
+	defer p.RUnlock()
+
+	attribute, ok := p.attributes[key]
+	return attribute.Copy(), ok
+}
+
+func (p *PlayerHandler) GetAttributes() map[string]minecraft.Attribute {
+	p.RLock()
+	defer p.RUnlock()
+
+	attributes := make(map[string]minecraft.Attribute, len(p.attributes))
+	for key, attribute := range p.attributes {
+		attributes[key] = attribute.Copy()
+	}
+
+	return attributes
+}
+
+func (p *PlayerHandler) GetSpawnPosition() pk.Position {
+	p.RLock()
+	defer p.RUnlock()
+	return p.spawnPosition
+}
+
+func (p *PlayerHandler) GetDimension() int {
+	p.RLock()
+	defer p.RUnlock()
+	return p.dimension
+}
+
 func (p *PlayerHandler) GetCurrentSlot() int {
+	p.RLock()
+	defer p.RUnlock()
 	return p.currentSlot
 }
 
 func (p *PlayerHandler) Attack(target int) error {
-	return p.tunnel.WriteServer(pk.Marshal(0x02, pk.VarInt(target), pk.VarInt(1)))
+	return p.tunnel.WriteServer(pk.Marshal(protocol.ServerboundUseEntity, pk.VarInt(target), pk.VarInt(1)))
 }
 
 func (p *PlayerHandler) ChangeSlot(slot int) error {
@@ -80,23 +230,159 @@ func (p *PlayerHandler) ChangeSlot(slot int) error {
 
 func HandleJoinGame(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	joinGame := packet.(*protocol.JoinGame)
-	tunnel.GetPlayerHandler().(*PlayerHandler).entityID = int32(joinGame.EntityID)
-	tunnel.GetPlayerHandler().(*PlayerHandler).health = 20
+	playerHandler := tunnel.GetPlayerHandler().(*PlayerHandler)
+	playerHandler.update(func() {
+		playerHandler.reset()
+		playerHandler.entityID = int32(joinGame.EntityID)
+		playerHandler.gameMode = minecraft.GameMode(joinGame.GameMode &^ minecraft.GameModeHardcore)
+		playerHandler.hardcore = joinGame.GameMode&minecraft.GameModeHardcore != 0
+		playerHandler.dimension = int(joinGame.Dimension)
+	})
+
 	tunnel.GetEntityHandler().(*EntityHandler).ResetEntities()
+	tunnel.GetWorldHandler().(*WorldHandler).Reset(int(joinGame.Dimension))
 	tunnel.GetInventoryHandler().Reset()
 	tunnel.GetModuleHandler().Reset()
 
 	go func() {
-		time.Sleep(time.Second)
+		select {
+		case <-time.After(time.Second):
+		case <-tunnel.GetContext().Done():
+			return
+		}
+
 		_ = tunnel.GetTexteriaHandler().UpdateInterface()
 	}()
 
 	return generic.PassPacket(), nil
 }
 
+// HandleRespawn replaces the player entity, so everything the client knew about the old one is dropped.
+// The server sends tracked entities and the inventory again, chunks are kept unless the dimension changes
+func HandleRespawn(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	respawn := packet.(*protocol.Respawn)
+	playerHandler := tunnel.GetPlayerHandler().(*PlayerHandler)
+	playerHandler.update(func() {
+		playerHandler.reset()
+		playerHandler.gameMode = minecraft.GameMode(respawn.GameMode &^ minecraft.GameModeHardcore)
+		playerHandler.dimension = int(respawn.Dimension)
+	})
+
+	world := tunnel.GetWorldHandler().(*WorldHandler)
+	if dimension := int(respawn.Dimension); dimension != world.GetDimension() {
+		world.Reset(dimension)
+	}
+
+	tunnel.GetEntityHandler().(*EntityHandler).ResetEntities()
+	tunnel.GetInventoryHandler().Reset()
+	return generic.PassPacket(), nil
+}
+
+func HandleSpawnPosition(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	spawnPosition := packet.(*protocol.SpawnPosition)
+	playerHandler := tunnel.GetPlayerHandler().(*PlayerHandler)
+	playerHandler.update(func() {
+		playerHandler.spawnPosition = spawnPosition.Location
+	})
+
+	return generic.PassPacket(), nil
+}
+
+func HandleChangeGameState(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	changeGameState := packet.(*protocol.ChangeGameState)
+	if protocol.GameStateReason(changeGameState.Reason) != protocol.GameStateChangeGameMode {
+		return generic.PassPacket(), nil
+	}
+
+	playerHandler := tunnel.GetPlayerHandler().(*PlayerHandler)
+	playerHandler.update(func() {
+		playerHandler.gameMode = minecraft.GameMode(changeGameState.Value)
+	})
+
+	return generic.PassPacket(), nil
+}
+
+func HandlePlayerAbilities(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	playerAbilities := packet.(*protocol.PlayerAbilities)
+	playerHandler := tunnel.GetPlayerHandler().(*PlayerHandler)
+	playerHandler.update(func() {
+		playerHandler.abilities = minecraft.Abilities{
+			Flags:               minecraft.AbilityFlags(playerAbilities.Flags),
+			FlyingSpeed:         float64(playerAbilities.FlyingSpeed),
+			FieldOfViewModifier: float64(playerAbilities.FieldOfViewModifier),
+		}
+		playerHandler.isFlying = playerHandler.abilities.Flags.Has(minecraft.AbilityFlying)
+	})
+
+	return generic.PassPacket(), nil
+}
+
+func HandleSetExperience(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	setExperience := packet.(*protocol.SetExperience)
+	playerHandler := tunnel.GetPlayerHandler().(*PlayerHandler)
+	playerHandler.update(func() {
+		playerHandler.experience = minecraft.Experience{
+			Bar:   float64(setExperience.ExperienceBar),
+			Level: int(setExperience.Level),
+			Total: int(setExperience.TotalExperience),
+		}
+	})
+
+	return generic.PassPacket(), nil
+}
+
+// HandleEntityEffect tracks effects of the player only, effects of other entities are ignored.
+// It runs late, so that effects dropped by modules such as NoBadEffects are not tracked
+func HandleEntityEffect(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	entityEffect := packet.(*protocol.EntityEffect)
+	playerHandler := tunnel.GetPlayerHandler().(*PlayerHandler)
+	playerHandler.Lock()
+	defer playerHandler.Unlock()
+
+	if int32(entityEffect.EntityID) == playerHandler.entityID {
+		effect := minecraft.NewPotionEffect(int(entityEffect.EffectID), int(entityEffect.Amplifier), int(entityEffect.Duration), bool(entityEffect.HideParticles))
+		playerHandler.effects[effect.EffectID] = effect
+	}
+
+	return generic.PassPacket(), nil
+}
+
+func HandleRemoveEntityEffect(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	removeEntityEffect := packet.(*protocol.RemoveEntityEffect)
+	playerHandler := tunnel.GetPlayerHandler().(*PlayerHandler)
+	playerHandler.Lock()
+	defer playerHandler.Unlock()
+
+	if int32(removeEntityEffect.EntityID) == playerHandler.entityID {
+		delete(playerHandler.effects, int(removeEntityEffect.EffectID))
+	}
+
+	return generic.PassPacket(), nil
+}
+
+func HandleEntityProperties(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	entityProperties := packet.(*protocol.EntityProperties)
+	playerHandler := tunnel.GetPlayerHandler().(*PlayerHandler)
+	playerHandler.Lock()
+	defer playerHandler.Unlock()
+
+	if int32(entityProperties.EntityID) == playerHandler.entityID {
+		for _, property := range entityProperties.Properties {
+			attribute := minecraft.NewAttribute(property)
+			playerHandler.attributes[attribute.Key] = attribute
+		}
+	}
+
+	return generic.PassPacket(), nil
+}
+
 func HandlePlayer(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	player := packet.(*protocol.Player)
-	tunnel.GetPlayerHandler().(*PlayerHandler).onGround = bool(player.OnGround)
+	playerHandler := tunnel.GetPlayerHandler().(*PlayerHandler)
+	playerHandler.update(func() {
+		playerHandler.onGround = bool(player.OnGround)
+	})
+
 	return generic.PassPacket(), nil
 }
 
@@ -106,6 +392,8 @@ func HandlePlayerPositionAndLook(packet protocol.Packet, tunnel generic.Tunnel)
 	x, y, z := playerPositionAndLook.X, playerPositionAndLook.Y, playerPositionAndLook.Z
 	yaw, pitch := playerPositionAndLook.Yaw, playerPositionAndLook.Pitch
 	playerHandler := tunnel.GetPlayerHandler().(*PlayerHandler)
+	playerHandler.Lock()
+	defer playerHandler.Unlock()
 
 	if flags&0x01 > 0 {
 		playerHandler.location.X += float64(x)
@@ -131,26 +419,37 @@ func HandlePlayerPositionAndLook(packet protocol.Packet, tunnel generic.Tunnel)
 
 func HandlePlayerLook(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	playerLook := packet.(*protocol.PlayerLook)
-	location := tunnel.GetPlayerHandler().GetLocation()
-	location.Yaw, location.Pitch = float64(playerLook.Yaw), float64(playerLook.Pitch)
-	tunnel.GetPlayerHandler().(*PlayerHandler).onGround = bool(playerLook.OnGround)
+	playerHandler := tunnel.GetPlayerHandler().(*PlayerHandler)
+	playerHandler.update(func() {
+		playerHandler.location.Yaw, playerHandler.location.Pitch = float64(playerLook.Yaw), float64(playerLook.Pitch)
+		playerHandler.onGround = bool(playerLook.OnGround)
+	})
+
 	return generic.PassPacket(), nil
 }
 
 func HandlePlayerPosition(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	playerPosition := packet.(*protocol.PlayerPosition)
-	location := tunnel.GetPlayerHandler().GetLocation()
-	location.X, location.Y, location.Z = float64(playerPosition.X), float64(playerPosition.Y), float64(playerPosition.Z)
-	tunnel.GetPlayerHandler().(*PlayerHandler).onGround = bool(playerPosition.OnGround)
+	playerHandler := tunnel.GetPlayerHandler().(*PlayerHandler)
+	playerHandler.update(func() {
+		location := &playerHandler.location
+		location.X, location.Y, location.Z = float64(playerPosition.X), float64(playerPosition.Y), float64(playerPosition.Z)
+		playerHandler.onGround = bool(playerPosition.OnGround)
+	})
+
 	return generic.PassPacket(), nil
 }
 
 func HandleServerPlayerPositionAndLook(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	playerPosition := packet.(*protocol.ServerPlayerPositionAndLook)
-	location := tunnel.GetPlayerHandler().GetLocation()
-	location.X, location.Y, location.Z = float64(playerPosition.X), float64(playerPosition.Y), float64(playerPosition.Z)
-	location.Yaw, location.Pitch = float64(playerPosition.Yaw), float64(playerPosition.Pitch)
-	tunnel.GetPlayerHandler().(*PlayerHandler).onGround = bool(playerPosition.OnGround)
+	playerHandler := tunnel.GetPlayerHandler().(*PlayerHandler)
+	playerHandler.update(func() {
+		location := &playerHandler.location
+		location.X, location.Y, location.Z = float64(playerPosition.X), float64(playerPosition.Y), float64(playerPosition.Z)
+		location.Yaw, location.Pitch = float64(playerPosition.Yaw), float64(playerPosition.Pitch)
+		playerHandler.onGround = bool(playerPosition.OnGround)
+	})
+
 	return generic.PassPacket(), nil
 }
 
@@ -162,12 +461,22 @@ func HandleServerPlayerAbilities(packet protocol.Packet, tunnel generic.Tunnel)
 
 func HandleUpdateHealth(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	updateHealth := packet.(*protocol.UpdateHealth)
-	tunnel.GetPlayerHandler().(*PlayerHandler).health = float64(updateHealth.Health)
+	playerHandler := tunnel.GetPlayerHandler().(*PlayerHandler)
+	playerHandler.update(func() {
+		playerHandler.health = float64(updateHealth.Health)
+		playerHandler.food = int(updateHealth.Food)
+		playerHandler.saturation = float64(updateHealth.FoodSaturation)
+	})
+
 	return generic.PassPacket(), nil
 }
 
 func HandleHeldItemChange(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
 	heldItemChange := packet.(*protocol.ServerHeldItemChange)
-	tunnel.GetPlayerHandler().(*PlayerHandler).currentSlot = int(heldItemChange.Slot)
+	playerHandler := tunnel.GetPlayerHandler().(*PlayerHandler)
+	playerHandler.update(func() {
+		playerHandler.currentSlot = int(heldItemChange.Slot)
+	})
+
 	return generic.PassPacket(), nil
 }
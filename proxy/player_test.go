@@ -0,0 +1,171 @@
+package proxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tnze/go-mc/chat"
+	"github.com/destructiqn/kogtevran/generic"
+	"github.com/destructiqn/kogtevran/minecraft"
+	"github.com/destructiqn/kogtevran/minecraft/entities"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/destructiqn/kogtevran/minecraft/protocol"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlayerHandler_State(t *testing.T) {
+	tunnel := newPipeTunnel(t)
+	player := tunnel.PlayerHandler
+
+	dispatch(t, tunnel, &protocol.JoinGame{EntityID: 7, GameMode: pk.UnsignedByte(minecraft.Survival) | minecraft.GameModeHardcore, Dimension: -1}, HandleJoinGame)
+	assert.Equal(t, minecraft.Survival, player.GetGameMode())
+	assert.True(t, player.IsHardcore())
+	assert.Equal(t, -1, player.GetDimension())
+	assert.Equal(t, 20, player.GetFood())
+
+	dispatch(t, tunnel, &protocol.ChangeGameState{Reason: pk.UnsignedByte(protocol.GameStateBeginRaining)}, HandleChangeGameState)
+	dispatch(t, tunnel, &protocol.ChangeGameState{Reason: pk.UnsignedByte(protocol.GameStateChangeGameMode), Value: 1}, HandleChangeGameState)
+	assert.Equal(t, minecraft.Creative, player.GetGameMode())
+
+	dispatch(t, tunnel, &protocol.UpdateHealth{Health: 12, Food: 8, FoodSaturation: 1.5}, HandleUpdateHealth)
+	assert.Equal(t, 12.0, player.GetHealth())
+	assert.Equal(t, 8, player.GetFood())
+	assert.Equal(t, 1.5, player.GetSaturation())
+
+	dispatch(t, tunnel, &protocol.SetExperience{ExperienceBar: 0.5, Level: 3, TotalExperience: 25}, HandleSetExperience)
+	assert.Equal(t, minecraft.Experience{Bar: 0.5, Level: 3, Total: 25}, player.GetExperience())
+
+	dispatch(t, tunnel, &protocol.PlayerAbilities{
+		Flags:               pk.Byte(minecraft.AbilityFlying | minecraft.AbilityAllowFlying),
+		FlyingSpeed:         0.25,
+		FieldOfViewModifier: 0.5,
+	}, HandlePlayerAbilities)
+	abilities := player.GetAbilities()
+	assert.True(t, abilities.Flags.Has(minecraft.AbilityAllowFlying))
+	assert.False(t, abilities.Flags.Has(minecraft.AbilityInvulnerable))
+	assert.Equal(t, 0.25, abilities.FlyingSpeed)
+	assert.True(t, player.IsFlying())
+
+	dispatch(t, tunnel, &protocol.SpawnPosition{Location: pk.Position{X: 10, Y: 64, Z: -20}}, HandleSpawnPosition)
+	assert.Equal(t, pk.Position{X: 10, Y: 64, Z: -20}, player.GetSpawnPosition())
+}
+
+func TestPlayerHandler_Effects(t *testing.T) {
+	tunnel := newPipeTunnel(t)
+	player := tunnel.PlayerHandler
+	dispatch(t, tunnel, &protocol.JoinGame{EntityID: 7}, HandleJoinGame)
+
+	dispatch(t, tunnel, &protocol.EntityEffect{EntityID: 7, EffectID: 1, Amplifier: 1, Duration: 600}, HandleEntityEffect)
+	dispatch(t, tunnel, &protocol.EntityEffect{EntityID: 7, EffectID: 16, Duration: minecraft.InfiniteEffectDuration, HideParticles: true}, HandleEntityEffect)
+	dispatch(t, tunnel, &protocol.EntityEffect{EntityID: 7, EffectID: 9, Duration: 0}, HandleEntityEffect)
+	dispatch(t, tunnel, &protocol.EntityEffect{EntityID: 8, EffectID: 2, Duration: 600}, HandleEntityEffect)
+
+	effects := player.GetEffects()
+	if assert.Len(t, effects, 2) {
+		assert.Equal(t, 1, effects[0].EffectID)
+		assert.Equal(t, 1, effects[0].Amplifier)
+		assert.InDelta(t, float64(30*time.Second), float64(effects[0].Remaining()), float64(time.Second))
+		assert.Equal(t, 16, effects[1].EffectID)
+		assert.True(t, effects[1].IsInfinite())
+		assert.True(t, effects[1].HideParticles)
+	}
+
+	_, ok := player.GetEffect(9)
+	assert.False(t, ok)
+
+	dispatch(t, tunnel, &protocol.RemoveEntityEffect{EntityID: 7, EffectID: 1}, HandleRemoveEntityEffect)
+	_, ok = player.GetEffect(1)
+	assert.False(t, ok)
+	_, ok = player.GetEffect(16)
+	assert.True(t, ok)
+}
+
+func TestPlayerHandler_RejectedEffect(t *testing.T) {
+	tunnel := newPipeTunnel(t)
+	dispatch(t, tunnel, &protocol.JoinGame{EntityID: 7}, HandleJoinGame)
+
+	// Effects a module drops before the late tracker never reach the client, they are not tracked either
+	registry := tunnel.HandlerRegistry
+	registry.RegisterHandler(nil, protocol.ConnStatePlay, protocol.ConnS2C, protocol.ClientboundEntityEffect, &protocol.EntityEffect{}, generic.PriorityLate, HandleEntityEffect)
+	registry.RegisterHandler(nil, protocol.ConnStatePlay, protocol.ConnS2C, protocol.ClientboundEntityEffect, &protocol.EntityEffect{}, generic.PriorityNormal,
+		func(packet protocol.Packet, tunnel generic.Tunnel) (*generic.HandlerResult, error) {
+			if packet.(*protocol.EntityEffect).EffectID == 2 {
+				return generic.RejectPacket(), nil
+			}
+
+			return generic.PassPacket(), nil
+		})
+
+	tunnel.SetState(protocol.ConnStatePlay)
+	for _, effectID := range []pk.Byte{1, 2} {
+		_, err := registry.Handle((&protocol.EntityEffect{EntityID: 7, EffectID: effectID, Duration: 600}).Marshal(), protocol.ConnS2C)
+		assert.NoError(t, err)
+	}
+
+	_, ok := tunnel.PlayerHandler.GetEffect(1)
+	assert.True(t, ok)
+	_, ok = tunnel.PlayerHandler.GetEffect(2)
+	assert.False(t, ok)
+}
+
+func TestPlayerHandler_Attributes(t *testing.T) {
+	tunnel := newPipeTunnel(t)
+	player := tunnel.PlayerHandler
+	dispatch(t, tunnel, &protocol.JoinGame{EntityID: 7}, HandleJoinGame)
+
+	sprinting := uuid.New()
+	dispatch(t, tunnel, &protocol.EntityProperties{EntityID: 7, Properties: []pk.Property{
+		{Key: "generic.maxHealth", Value: 20},
+		{Key: "generic.movementSpeed", Value: 0.1, Modifiers: []pk.AttributeModifier{
+			{UUID: pk.UUID(sprinting), Amount: 0.3, Operation: pk.Byte(minecraft.AttributeMultiply)},
+			{UUID: pk.UUID(uuid.New()), Amount: 0.1, Operation: pk.Byte(minecraft.AttributeAdd)},
+			{UUID: pk.UUID(uuid.New()), Amount: 0.5, Operation: pk.Byte(minecraft.AttributeAddMultiplied)},
+		}},
+	}}, HandleEntityProperties)
+	dispatch(t, tunnel, &protocol.EntityProperties{EntityID: 8, Properties: []pk.Property{{Key: "generic.maxHealth", Value: 40}}}, HandleEntityProperties)
+
+	maxHealth, ok := player.GetAttribute("generic.maxHealth")
+	assert.True(t, ok)
+	assert.Equal(t, 20.0, maxHealth.Value())
+
+	speed, ok := player.GetAttribute("generic.movementSpeed")
+	assert.True(t, ok)
+	assert.Equal(t, sprinting, speed.Modifiers[0].UUID)
+	assert.InDelta(t, (0.1+0.1)*1.5*1.3, speed.Value(), 1e-9)
+
+	speed.Modifiers[0].Amount = 100
+	speed, _ = player.GetAttribute("generic.movementSpeed")
+	assert.Equal(t, 0.3, speed.Modifiers[0].Amount)
+	assert.Len(t, player.GetAttributes(), 2)
+}
+
+func TestPlayerHandler_Respawn(t *testing.T) {
+	tunnel := newPipeTunnel(t)
+	player := tunnel.PlayerHandler
+	dispatch(t, tunnel, &protocol.JoinGame{EntityID: 7}, HandleJoinGame)
+
+	dispatch(t, tunnel, &protocol.SpawnMob{EntityID: 1, Type: pk.UnsignedByte(entities.Zombie.ID)}, HandleSpawnMob)
+	dispatch(t, tunnel, &protocol.EntityEffect{EntityID: 7, EffectID: 1, Duration: 600}, HandleEntityEffect)
+	dispatch(t, tunnel, &protocol.EntityProperties{EntityID: 7, Properties: []pk.Property{{Key: "generic.maxHealth", Value: 20}}}, HandleEntityProperties)
+	dispatch(t, tunnel, &protocol.UpdateHealth{Health: 0, Food: 3}, HandleUpdateHealth)
+	tunnel.InventoryHandler.OpenWindow(1, NewWindow(tunnel.InventoryHandler, 1, 27, "minecraft:chest", chat.Text("Chest")))
+	_, err := tunnel.WorldHandler.LoadChunk(0, 0, chunkData(1, true, nil), 1, true, true)
+	assert.NoError(t, err)
+
+	dispatch(t, tunnel, &protocol.Respawn{Dimension: 0, GameMode: pk.UnsignedByte(minecraft.Adventure)}, HandleRespawn)
+
+	assert.Equal(t, minecraft.Adventure, player.GetGameMode())
+	assert.Equal(t, 20.0, player.GetHealth())
+	assert.Equal(t, 20, player.GetFood())
+	assert.Empty(t, player.GetEffects())
+	assert.Empty(t, player.GetAttributes())
+	assert.Empty(t, tunnel.EntityHandler.GetEntities())
+	assert.Len(t, tunnel.InventoryHandler.GetWindows(), 1)
+
+	// Chunks are dropped only when the dimension changes
+	assert.True(t, tunnel.WorldHandler.IsLoaded(pk.Position{}))
+	dispatch(t, tunnel, &protocol.Respawn{Dimension: 1}, HandleRespawn)
+	assert.Equal(t, 1, player.GetDimension())
+	assert.False(t, tunnel.WorldHandler.IsLoaded(pk.Position{}))
+}
@@ -0,0 +1,225 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/destructiqn/kogtevran/metrics"
+	mcnet "github.com/destructiqn/kogtevran/minecraft/net"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/destructiqn/kogtevran/minecraft/protocol"
+)
+
+var (
+	ErrQueueFull    = errors.New("write queue is full")
+	ErrTunnelClosed = errors.New("tunnel is closed")
+)
+
+// OverflowPolicy decides what happens to a packet written when the queue is full
+type OverflowPolicy int
+
+const (
+	// OverflowDrop drops the packet, the write returns ErrQueueFull
+	OverflowDrop OverflowPolicy = iota
+	// OverflowDisconnect closes the tunnel, the peer is considered to be stuck
+	OverflowDisconnect
+)
+
+func (p OverflowPolicy) String() string {
+	if p == OverflowDisconnect {
+		return "disconnect"
+	}
+
+	return "drop"
+}
+
+// ParseOverflowPolicy returns the policy with the name String returns for it
+func ParseOverflowPolicy(name string) (OverflowPolicy, error) {
+	switch name {
+	case "drop":
+		return OverflowDrop, nil
+	case "disconnect":
+		return OverflowDisconnect, nil
+	}
+
+	return OverflowDrop, fmt.Errorf("unknown overflow policy %q", name)
+}
+
+// priorityCapacity is the capacity of the queue of keep-alives, there is a single one in flight normally
+const priorityCapacity = 16
+
+type queuedWrite struct {
+	packet pk.Packet
+	// exec is run by the writer instead of writing a packet, after everything queued before it is written
+	exec   func(conn *mcnet.Conn) error
+	result chan error
+}
+
+// writeQueue owns the writes to one side of the tunnel. Packets are written by a single goroutine,
+// which flushes the connection whenever it has nothing more to write
+type writeQueue struct {
+	conn      *mcnet.Conn
+	direction string
+	policy    OverflowPolicy
+
+	packets  chan queuedWrite
+	priority chan queuedWrite
+
+	// done is closed along with the tunnel, fail reports an error which the tunnel cannot recover from
+	done <-chan struct{}
+	fail func(err error)
+}
+
+func newWriteQueue(conn *mcnet.Conn, direction int, settings Settings, done <-chan struct{}, fail func(err error)) *writeQueue {
+	name := "client"
+	if direction == protocol.ConnC2S {
+		name = "server"
+	}
+
+	return &writeQueue{
+		conn:      conn,
+		direction: name,
+		policy:    settings.QueueOverflowPolicy,
+		packets:   make(chan queuedWrite, settings.QueueCapacity),
+		priority:  make(chan queuedWrite, priorityCapacity),
+		done:      done,
+		fail:      fail,
+	}
+}
+
+// push queues the write. With wait it blocks until there is room in the queue, otherwise the overflow policy applies
+func (q *writeQueue) push(write queuedWrite, priority, wait bool) error {
+	queue := q.packets
+	if priority {
+		queue = q.priority
+	}
+
+	depth := metrics.WriteQueueDepth.WithLabelValues(q.direction)
+	depth.Inc()
+	if wait {
+		select {
+		case queue <- write:
+			return q.queued()
+		case <-q.done:
+			depth.Dec()
+			return ErrTunnelClosed
+		}
+	}
+
+	select {
+	case queue <- write:
+		return q.queued()
+	case <-q.done:
+		depth.Dec()
+		return ErrTunnelClosed
+	default:
+	}
+
+	depth.Dec()
+	metrics.WriteQueueOverflows.WithLabelValues(q.direction, q.policy.String()).Inc()
+	err := fmt.Errorf("%w: %d packets to %s", ErrQueueFull, cap(queue), q.direction)
+	if q.policy == OverflowDisconnect {
+		q.fail(err)
+	}
+
+	return err
+}
+
+// queued checks the tunnel once the write is queued, the writer is gone when it is closed and the write is not taken
+// off the queue by it
+func (q *writeQueue) queued() error {
+	select {
+	case <-q.done:
+		q.discard()
+		return ErrTunnelClosed
+	default:
+		return nil
+	}
+}
+
+// discard takes whatever is left off the queue, every write is received once, so it is not counted off twice
+func (q *writeQueue) discard() {
+	depth := metrics.WriteQueueDepth.WithLabelValues(q.direction)
+	for {
+		select {
+		case <-q.priority:
+		case <-q.packets:
+		default:
+			return
+		}
+
+		depth.Dec()
+	}
+}
+
+// exec runs the function on the writer and waits for it, so that it does not race with the writes
+func (q *writeQueue) exec(exec func(conn *mcnet.Conn) error) error {
+	result := make(chan error, 1)
+	err := q.push(queuedWrite{exec: exec, result: result}, false, true)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case err = <-result:
+		return err
+	case <-q.done:
+		return ErrTunnelClosed
+	}
+}
+
+// next returns the next write, keep-alives first. The connection is flushed before the writer waits for more
+func (q *writeQueue) next() (queuedWrite, bool) {
+	select {
+	case write := <-q.priority:
+		return write, true
+	default:
+	}
+
+	select {
+	case write := <-q.priority:
+		return write, true
+	case write := <-q.packets:
+		return write, true
+	default:
+	}
+
+	if err := q.conn.Flush(); err != nil {
+		q.fail(err)
+		return queuedWrite{}, false
+	}
+
+	select {
+	case write := <-q.priority:
+		return write, true
+	case write := <-q.packets:
+		return write, true
+	case <-q.done:
+		return queuedWrite{}, false
+	}
+}
+
+// run writes queued packets until the tunnel is closed or a write fails
+func (q *writeQueue) run() {
+	// Whatever is left is never written
+	defer q.discard()
+
+	depth := metrics.WriteQueueDepth.WithLabelValues(q.direction)
+	for {
+		write, ok := q.next()
+		if !ok {
+			return
+		}
+
+		depth.Dec()
+		if write.exec != nil {
+			write.result <- write.exec(q.conn)
+			continue
+		}
+
+		if err := q.conn.WritePacket(write.packet); err != nil {
+			q.fail(fmt.Errorf("writing to %s: %w", q.direction, err))
+			return
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package proxy
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/destructiqn/kogtevran/metrics"
+	mcnet "github.com/destructiqn/kogtevran/minecraft/net"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/destructiqn/kogtevran/minecraft/protocol"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// newStuckTunnel returns a tunnel in play state whose client never reads, its queue holds capacity packets
+func newStuckTunnel(t *testing.T, capacity int, policy OverflowPolicy) *MinecraftTunnel {
+	defer SetSettings(GetSettings())
+	settings := GetSettings()
+	settings.QueueCapacity, settings.QueueOverflowPolicy = capacity, policy
+	SetSettings(settings)
+
+	server, _ := net.Pipe()
+	client, _ := net.Pipe()
+	t.Cleanup(func() {
+		_ = server.Close()
+		_ = client.Close()
+	})
+
+	tunnel := WrapConn(mcnet.WrapConn(server), mcnet.WrapConn(client))
+	tunnel.State = protocol.ConnStatePlay
+	return tunnel
+}
+
+// fill writes to the client until the queue overflows, the writer holds at most one more packet
+func fill(t *testing.T, tunnel *MinecraftTunnel, capacity int) error {
+	var err error
+	for i := 0; i <= capacity+1 && err == nil; i++ {
+		err = tunnel.WriteClient(pk.Marshal(protocol.ClientboundChatMessage, pk.String("{}"), pk.Byte(0)))
+	}
+
+	if err == nil {
+		t.Fatal("queue has not overflowed")
+	}
+
+	return err
+}
+
+func closed(tunnel *MinecraftTunnel) bool {
+	select {
+	case <-tunnel.GetContext().Done():
+		return true
+	case <-time.After(time.Second):
+		return false
+	}
+}
+
+func TestWriteQueue_Drop(t *testing.T) {
+	tunnel := newStuckTunnel(t, 4, OverflowDrop)
+
+	assert.True(t, errors.Is(fill(t, tunnel, 4), ErrQueueFull))
+	assert.NoError(t, tunnel.Err())
+	assert.False(t, tunnel.IsClosed())
+}
+
+func TestWriteQueue_Disconnect(t *testing.T) {
+	tunnel := newStuckTunnel(t, 4, OverflowDisconnect)
+
+	assert.True(t, errors.Is(fill(t, tunnel, 4), ErrQueueFull))
+	assert.True(t, errors.Is(tunnel.Err(), ErrQueueFull))
+	assert.True(t, closed(tunnel))
+
+	// Writes after the tunnel is closed fail right away, even those waiting for room
+	assert.True(t, errors.Is(tunnel.WriteRaw(pk.Marshal(0x4D), protocol.ConnS2C), ErrTunnelClosed))
+}
+
+func TestWriteQueue_KeepAlivePriority(t *testing.T) {
+	tunnel, _, toClient := newRecordingTunnel(t)
+
+	// The writer is held by an exec while packets are queued behind it
+	started, release := make(chan struct{}), make(chan struct{})
+	go func() {
+		_ = tunnel.Exec(protocol.ConnS2C, func(*mcnet.Conn) {
+			close(started)
+			<-release
+		})
+	}()
+	<-started
+
+	chat := pk.Marshal(protocol.ClientboundChatMessage, pk.String("{}"), pk.Byte(0))
+	assert.NoError(t, tunnel.WriteClient(chat))
+	assert.NoError(t, tunnel.WriteClient(pk.Marshal(protocol.ClientboundKeepAlive, pk.VarInt(42))))
+	assert.NoError(t, tunnel.Forward(chat, protocol.ConnS2C, false))
+	close(release)
+
+	expected := []int32{protocol.ClientboundKeepAlive, protocol.ClientboundChatMessage, protocol.ClientboundChatMessage}
+	for _, id := range expected {
+		select {
+		case packet := <-toClient:
+			assert.Equal(t, id, packet.ID)
+		case <-time.After(time.Second):
+			t.Fatal("packet was not written")
+		}
+	}
+}
+
+func TestWriteQueue_WriteFailure(t *testing.T) {
+	server, _ := net.Pipe()
+	client, clientPeer := net.Pipe()
+	t.Cleanup(func() { _ = server.Close() })
+
+	tunnel := WrapConn(mcnet.WrapConn(server), mcnet.WrapConn(client))
+	_ = clientPeer.Close()
+
+	assert.NoError(t, tunnel.WriteRaw(pk.Marshal(0x4D), protocol.ConnS2C))
+	assert.True(t, closed(tunnel))
+	assert.Error(t, tunnel.Err())
+
+	// Waiting for a flush reports the tunnel as closed instead of hanging
+	assert.True(t, errors.Is(tunnel.Flush(), ErrTunnelClosed))
+}
+
+func TestWriteQueue_DepthAfterClose(t *testing.T) {
+	server, _ := net.Pipe()
+	t.Cleanup(func() { _ = server.Close() })
+
+	// The queue has a direction of its own, so that writes of other tests do not count
+	done := make(chan struct{})
+	queue := &writeQueue{
+		conn:      mcnet.WrapConn(server),
+		direction: "closed",
+		packets:   make(chan queuedWrite, 4),
+		priority:  make(chan queuedWrite, priorityCapacity),
+		done:      done,
+		fail:      func(err error) {},
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		queue.run()
+		close(stopped)
+	}()
+
+	close(done)
+	<-stopped
+
+	// There is room left after the writer is gone, writes getting into the queue are not counted as waiting
+	for i := 0; i < 8; i++ {
+		err := queue.push(queuedWrite{packet: pk.Marshal(protocol.ClientboundChatMessage)}, i%2 == 0, i%4 == 0)
+		assert.True(t, errors.Is(err, ErrTunnelClosed))
+	}
+
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.WriteQueueDepth.WithLabelValues(queue.direction)))
+}
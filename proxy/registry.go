@@ -0,0 +1,136 @@
+package proxy
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/destructiqn/kogtevran/generic"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/destructiqn/kogtevran/minecraft/protocol"
+)
+
+type hookKey struct {
+	state     protocol.ConnectionState
+	direction int
+	id        int32
+}
+
+type packetHook struct {
+	owner    interface{}
+	priority generic.HandlerPriority
+	packet   reflect.Type
+	handler  generic.PacketHandler
+}
+
+type HandlerRegistry struct {
+	tunnel *MinecraftTunnel
+	hooks  map[hookKey][]*packetHook
+	sync.RWMutex
+}
+
+func NewHandlerRegistry(tunnel *MinecraftTunnel) *HandlerRegistry {
+	return &HandlerRegistry{tunnel: tunnel, hooks: make(map[hookKey][]*packetHook)}
+}
+
+// RegisterHandler subscribes the handler to the packet. All handlers of the same packet share a single
+// decoded structure, so they must be registered with the same packet type
+func (r *HandlerRegistry) RegisterHandler(owner interface{}, state protocol.ConnectionState, direction int, id int32, packet protocol.Packet, priority generic.HandlerPriority, handler generic.PacketHandler) {
+	r.Lock()
+	defer r.Unlock()
+
+	key := hookKey{state: state, direction: direction, id: id}
+	packetType := reflect.TypeOf(packet).Elem()
+	if hooks := r.hooks[key]; len(hooks) > 0 && hooks[0].packet != packetType {
+		panic(fmt.Sprintf("packet %s is already handled as %s, not %s", protocol.FormatPacket(id, direction), hooks[0].packet, packetType))
+	}
+
+	hooks := append(r.hooks[key], &packetHook{
+		owner:    owner,
+		priority: priority,
+		packet:   packetType,
+		handler:  handler,
+	})
+
+	// Handlers with equal priority are called in order of registration
+	sort.SliceStable(hooks, func(i, j int) bool {
+		return hooks[i].priority < hooks[j].priority
+	})
+
+	r.hooks[key] = hooks
+}
+
+func (r *HandlerRegistry) UnregisterHandlers(owner interface{}) {
+	r.Lock()
+	defer r.Unlock()
+
+	for key, hooks := range r.hooks {
+		filtered := make([]*packetHook, 0, len(hooks))
+		for _, hook := range hooks {
+			if hook.owner != owner {
+				filtered = append(filtered, hook)
+			}
+		}
+
+		if len(filtered) == 0 {
+			delete(r.hooks, key)
+		} else {
+			r.hooks[key] = filtered
+		}
+	}
+}
+
+func (r *HandlerRegistry) getHooks(key hookKey) []*packetHook {
+	r.RLock()
+	defer r.RUnlock()
+
+	hooks := make([]*packetHook, len(r.hooks[key]))
+	copy(hooks, r.hooks[key])
+	return hooks
+}
+
+// Handle passes the packet through the pipeline of handlers subscribed to it
+func (r *HandlerRegistry) Handle(packet pk.Packet, direction int) (*generic.HandlerResult, error) {
+	hooks := r.getHooks(hookKey{state: r.tunnel.GetState(), direction: direction, id: packet.ID})
+	return runPipeline(hooks, packet, r.tunnel)
+}
+
+// runPipeline decodes the packet once and calls the handlers in order of their priority.
+// A rejection (or a nil result) stops the pipeline and drops the packet, as does an error.
+// Modifications are made on the shared structure, which is marshalled again only if any handler changed it
+func runPipeline(hooks []*packetHook, packet pk.Packet, tunnel generic.Tunnel) (*generic.HandlerResult, error) {
+	if len(hooks) == 0 {
+		return generic.PassPacket(), nil
+	}
+
+	decoded := reflect.New(hooks[0].packet).Interface().(protocol.Packet)
+	err := decoded.Read(packet)
+	if err != nil {
+		return nil, err
+	}
+
+	modified := false
+	for _, hook := range hooks {
+		result, err := hook.handler(decoded, tunnel)
+		if err != nil {
+			return nil, err
+		}
+
+		if result == nil || !result.ShouldPass {
+			return generic.RejectPacket(), nil
+		}
+
+		if result.IsModified {
+			modified = true
+		}
+	}
+
+	if !modified {
+		return generic.PassPacket(), nil
+	}
+
+	result := generic.ModifyPacket()
+	result.Packet = decoded.Marshal()
+	return result, nil
+}
@@ -0,0 +1,222 @@
+package proxy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/destructiqn/kogtevran/generic"
+	"github.com/destructiqn/kogtevran/minecraft/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+type pipelineStep struct {
+	priority generic.HandlerPriority
+	handler  func(position *protocol.PlayerPosition) (*generic.HandlerResult, error)
+}
+
+func pass(*protocol.PlayerPosition) (*generic.HandlerResult, error) {
+	return generic.PassPacket(), nil
+}
+
+func reject(*protocol.PlayerPosition) (*generic.HandlerResult, error) {
+	return generic.RejectPacket(), nil
+}
+
+func setOnGround(position *protocol.PlayerPosition) (*generic.HandlerResult, error) {
+	position.OnGround = true
+	return generic.ModifyPacket(), nil
+}
+
+func moveUp(position *protocol.PlayerPosition) (*generic.HandlerResult, error) {
+	position.Y++
+	return generic.ModifyPacket(), nil
+}
+
+func TestHandlerRegistry_Handle(t *testing.T) {
+	failure := errors.New("handler failure")
+
+	tests := []struct {
+		name     string
+		steps    []pipelineStep
+		pass     bool
+		modified bool
+		expected protocol.PlayerPosition
+		calls    []int
+		err      error
+	}{
+		{
+			name:     "no handlers",
+			pass:     true,
+			expected: protocol.PlayerPosition{Y: 64},
+		},
+		{
+			name:     "pass",
+			steps:    []pipelineStep{{handler: pass}, {handler: pass}},
+			pass:     true,
+			expected: protocol.PlayerPosition{Y: 64},
+			calls:    []int{0, 1},
+		},
+		{
+			name:     "modification is kept after later pass",
+			steps:    []pipelineStep{{handler: setOnGround}, {handler: pass}},
+			pass:     true,
+			modified: true,
+			expected: protocol.PlayerPosition{Y: 64, OnGround: true},
+			calls:    []int{0, 1},
+		},
+		{
+			name:     "modifications are accumulated",
+			steps:    []pipelineStep{{handler: moveUp}, {handler: setOnGround}, {handler: moveUp}},
+			pass:     true,
+			modified: true,
+			expected: protocol.PlayerPosition{Y: 66, OnGround: true},
+			calls:    []int{0, 1, 2},
+		},
+		{
+			name: "modification is visible to later handlers",
+			steps: []pipelineStep{{handler: setOnGround}, {handler: func(position *protocol.PlayerPosition) (*generic.HandlerResult, error) {
+				if !position.OnGround {
+					return nil, errors.New("modification is not visible")
+				}
+
+				return generic.PassPacket(), nil
+			}}},
+			pass:     true,
+			modified: true,
+			expected: protocol.PlayerPosition{Y: 64, OnGround: true},
+			calls:    []int{0, 1},
+		},
+		{
+			name:  "rejection short-circuits",
+			steps: []pipelineStep{{handler: pass}, {handler: reject}, {handler: pass}},
+			calls: []int{0, 1},
+		},
+		{
+			name:  "rejection overrides modification",
+			steps: []pipelineStep{{handler: setOnGround}, {handler: reject}},
+			calls: []int{0, 1},
+		},
+		{
+			name: "nil result rejects",
+			steps: []pipelineStep{{handler: func(*protocol.PlayerPosition) (*generic.HandlerResult, error) {
+				return nil, nil
+			}}, {handler: pass}},
+			calls: []int{0},
+		},
+		{
+			name: "error stops the pipeline",
+			steps: []pipelineStep{{handler: func(*protocol.PlayerPosition) (*generic.HandlerResult, error) {
+				return generic.PassPacket(), failure
+			}}, {handler: pass}},
+			calls: []int{0},
+			err:   failure,
+		},
+		{
+			name: "priorities",
+			steps: []pipelineStep{
+				{priority: generic.PriorityLate, handler: reject},
+				{priority: generic.PriorityNormal, handler: moveUp},
+				{priority: generic.PriorityEarly, handler: setOnGround},
+			},
+			calls: []int{2, 1, 0},
+		},
+		{
+			name: "equal priorities keep registration order",
+			steps: []pipelineStep{
+				{priority: generic.PriorityNormal, handler: moveUp},
+				{priority: generic.PriorityEarly, handler: pass},
+				{priority: generic.PriorityNormal, handler: setOnGround},
+				{priority: generic.PriorityNormal, handler: pass},
+			},
+			pass:     true,
+			modified: true,
+			expected: protocol.PlayerPosition{Y: 65, OnGround: true},
+			calls:    []int{1, 0, 2, 3},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tunnel := &MinecraftTunnel{State: protocol.ConnStatePlay}
+			registry := NewHandlerRegistry(tunnel)
+
+			calls := make([]int, 0)
+			for i, step := range test.steps {
+				i, step := i, step
+				registry.RegisterHandler(nil, protocol.ConnStatePlay, protocol.ConnC2S, protocol.ServerboundPlayerPosition, &protocol.PlayerPosition{}, step.priority,
+					func(packet protocol.Packet, _ generic.Tunnel) (*generic.HandlerResult, error) {
+						calls = append(calls, i)
+						return step.handler(packet.(*protocol.PlayerPosition))
+					},
+				)
+			}
+
+			original := (&protocol.PlayerPosition{Y: 64}).Marshal()
+			result, err := registry.Handle(original, protocol.ConnC2S)
+			if len(test.steps) > 0 {
+				assert.Equal(t, test.calls, calls)
+			}
+
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.pass, result.ShouldPass)
+			assert.Equal(t, test.modified, result.IsModified)
+			if !test.pass {
+				return
+			}
+
+			output := original
+			if result.IsModified {
+				output = result.Packet
+			}
+
+			var position protocol.PlayerPosition
+			assert.NoError(t, position.Read(output))
+			assert.Equal(t, test.expected, position)
+		})
+	}
+}
+
+func TestHandlerRegistry_UnregisterHandlers(t *testing.T) {
+	tunnel := &MinecraftTunnel{State: protocol.ConnStatePlay}
+	registry := NewHandlerRegistry(tunnel)
+	owner := &struct{ name string }{"module"}
+
+	registry.RegisterHandler(nil, protocol.ConnStatePlay, protocol.ConnC2S, protocol.ServerboundPlayerPosition, &protocol.PlayerPosition{}, generic.PriorityNormal,
+		func(protocol.Packet, generic.Tunnel) (*generic.HandlerResult, error) {
+			return generic.PassPacket(), nil
+		},
+	)
+
+	registry.RegisterHandler(owner, protocol.ConnStatePlay, protocol.ConnC2S, protocol.ServerboundPlayerPosition, &protocol.PlayerPosition{}, generic.PriorityNormal,
+		func(protocol.Packet, generic.Tunnel) (*generic.HandlerResult, error) {
+			return generic.RejectPacket(), nil
+		},
+	)
+
+	packet := (&protocol.PlayerPosition{}).Marshal()
+	result, err := registry.Handle(packet, protocol.ConnC2S)
+	assert.NoError(t, err)
+	assert.False(t, result.ShouldPass)
+
+	registry.UnregisterHandlers(owner)
+	result, err = registry.Handle(packet, protocol.ConnC2S)
+	assert.NoError(t, err)
+	assert.True(t, result.ShouldPass)
+}
+
+func TestHandlerRegistry_RegisterHandlerTypeMismatch(t *testing.T) {
+	registry := NewHandlerRegistry(&MinecraftTunnel{})
+	handler := func(protocol.Packet, generic.Tunnel) (*generic.HandlerResult, error) {
+		return generic.PassPacket(), nil
+	}
+
+	registry.RegisterHandler(nil, protocol.ConnStatePlay, protocol.ConnC2S, protocol.ServerboundPlayerPosition, &protocol.PlayerPosition{}, generic.PriorityNormal, handler)
+	assert.Panics(t, func() {
+		registry.RegisterHandler(nil, protocol.ConnStatePlay, protocol.ConnC2S, protocol.ServerboundPlayerPosition, &protocol.Player{}, generic.PriorityNormal, handler)
+	})
+}
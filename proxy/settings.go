@@ -0,0 +1,38 @@
+package proxy
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Settings are read by new sessions when they start, replacing them does not affect running ones
+type Settings struct {
+	// QueueCapacity is the number of packets waiting to be written to either side of a tunnel
+	QueueCapacity       int
+	QueueOverflowPolicy OverflowPolicy
+	// KeepAliveInterval is how often auxiliary clients are pinged
+	KeepAliveInterval time.Duration
+	// ModuleOptions override the defaults of module options, see ApplyModuleOptions
+	ModuleOptions map[string]map[string]string
+}
+
+var DefaultSettings = Settings{
+	QueueCapacity:       1024,
+	QueueOverflowPolicy: OverflowDrop,
+	KeepAliveInterval:   20 * time.Second,
+}
+
+var settings atomic.Value
+
+func init() {
+	settings.Store(DefaultSettings)
+}
+
+func GetSettings() Settings {
+	return settings.Load().(Settings)
+}
+
+// SetSettings replaces the settings, it is safe to call while the proxy is running
+func SetSettings(s Settings) {
+	settings.Store(s)
+}
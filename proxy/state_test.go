@@ -0,0 +1,230 @@
+package proxy
+
+import (
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/destructiqn/kogtevran/generic"
+	"github.com/destructiqn/kogtevran/minecraft"
+	"github.com/destructiqn/kogtevran/minecraft/entities"
+	mcnet "github.com/destructiqn/kogtevran/minecraft/net"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/destructiqn/kogtevran/minecraft/protocol"
+	"github.com/destructiqn/kogtevran/modules"
+	"github.com/destructiqn/kogtevran/modules/aura"
+	"github.com/destructiqn/kogtevran/modules/nofall"
+	"github.com/destructiqn/kogtevran/modules/tpaura"
+	"github.com/stretchr/testify/assert"
+)
+
+// newPipeTunnel returns a tunnel in play state, everything written to its ends is discarded
+func newPipeTunnel(t *testing.T) *MinecraftTunnel {
+	tunnel, _, _ := newRecordingTunnel(t)
+	return tunnel
+}
+
+// record reads packets written to the connection, they are dropped when nobody receives them
+func record(conn net.Conn) <-chan pk.Packet {
+	packets := make(chan pk.Packet, 64)
+	go func() {
+		wrapped := mcnet.WrapConn(conn)
+		for {
+			var packet pk.Packet
+			if err := wrapped.ReadPacket(&packet); err != nil {
+				return
+			}
+
+			select {
+			case packets <- packet:
+			default:
+			}
+		}
+	}()
+
+	return packets
+}
+
+// newRecordingTunnel returns a tunnel in play state and packets written to the server and to the client
+func newRecordingTunnel(t *testing.T) (tunnel *MinecraftTunnel, toServer, toClient <-chan pk.Packet) {
+	server, serverPeer := net.Pipe()
+	client, clientPeer := net.Pipe()
+	toServer, toClient = record(serverPeer), record(clientPeer)
+
+	tunnel = WrapConn(mcnet.WrapConn(server), mcnet.WrapConn(client))
+	tunnel.State = protocol.ConnStatePlay
+	t.Cleanup(tunnel.Close)
+
+	handlers := []struct {
+		direction int
+		id        int32
+		packet    protocol.Packet
+		handler   generic.PacketHandler
+	}{
+		{protocol.ConnS2C, protocol.ClientboundSpawnMob, &protocol.SpawnMob{}, HandleSpawnMob},
+		{protocol.ConnS2C, protocol.ClientboundSpawnPlayer, &protocol.SpawnPlayer{}, HandleSpawnPlayer},
+		{protocol.ConnS2C, protocol.ClientboundDestroyEntities, &protocol.DestroyEntities{}, HandleDestroyEntities},
+		{protocol.ConnS2C, protocol.ClientboundEntityRelativeMove, &protocol.EntityRelativeMove{}, HandleEntityRelativeMove},
+		{protocol.ConnS2C, protocol.ClientboundEntityTeleport, &protocol.EntityTeleport{}, HandleEntityTeleport},
+		{protocol.ConnS2C, protocol.ClientboundEntityVelocity, &protocol.EntityVelocity{}, HandleEntityVelocity},
+		{protocol.ConnS2C, protocol.ClientboundEntityMetadata, &protocol.EntityMetadata{}, HandleEntityMetadata},
+		{protocol.ConnS2C, protocol.ClientboundUpdateHealth, &protocol.UpdateHealth{}, HandleUpdateHealth},
+		{protocol.ConnS2C, protocol.ClientboundPlayerPositionAndLook, &protocol.PlayerPositionAndLook{}, HandlePlayerPositionAndLook},
+		{protocol.ConnC2S, protocol.ServerboundPlayer, &protocol.Player{}, HandlePlayer},
+		{protocol.ConnC2S, protocol.ServerboundPlayerPosition, &protocol.PlayerPosition{}, HandlePlayerPosition},
+		{protocol.ConnC2S, protocol.ServerboundPlayerLook, &protocol.PlayerLook{}, HandlePlayerLook},
+		{protocol.ConnC2S, protocol.ServerboundPlayerPositionAndLook, &protocol.ServerPlayerPositionAndLook{}, HandleServerPlayerPositionAndLook},
+		{protocol.ConnC2S, protocol.ServerboundHeldItemChange, &protocol.ServerHeldItemChange{}, HandleHeldItemChange},
+		{protocol.ConnS2C, protocol.ClientboundSetSlot, &protocol.SetSlot{}, HandleSetSlot},
+		{protocol.ConnS2C, protocol.ClientboundConfirmTransaction, &protocol.ConfirmTransaction{}, HandleConfirmTransaction},
+		{protocol.ConnC2S, protocol.ServerboundClickWindow, &protocol.ClickWindow{}, HandleClickWindow},
+		{protocol.ConnC2S, protocol.ServerboundConfirmTransaction, &protocol.ServerConfirmTransaction{}, HandleServerConfirmTransaction},
+	}
+
+	for _, h := range handlers {
+		tunnel.HandlerRegistry.RegisterHandler(nil, protocol.ConnStatePlay, h.direction, h.id, h.packet, generic.PriorityNormal, h.handler)
+	}
+
+	return tunnel, toServer, toClient
+}
+
+func handle(t *testing.T, tunnel *MinecraftTunnel, packet protocol.Packet, direction int) {
+	_, err := tunnel.HandlerRegistry.Handle(packet.Marshal(), direction)
+	if err != nil {
+		t.Error(err)
+	}
+
+	// Like the pipe, the packets written by the handlers are sent once the packet has been handled
+	if err = tunnel.Flush(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStateConcurrency(t *testing.T) {
+	const (
+		iterations  = 500
+		entityCount = 20
+	)
+
+	tunnel := newPipeTunnel(t)
+
+	killAura := &aura.KillAura{}
+	killAura.MaxDistance, killAura.HitAnimation = 1000, true
+	killAura.Register(tunnel)
+
+	mobAura := &aura.MobAura{}
+	mobAura.MaxDistance = 1000
+	mobAura.Register(tunnel)
+
+	tpAura := &tpaura.TPAura{SearchRadius: 1000, SendClient: true}
+	tpAura.Register(tunnel)
+
+	var wg sync.WaitGroup
+	run := func(routine func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				routine(i)
+			}
+		}()
+	}
+
+	// Server moves entities around, spawns and destroys them
+	run(func(i int) {
+		id := pk.VarInt(i % entityCount)
+		if i%2 == 0 {
+			handle(t, tunnel, &protocol.SpawnMob{EntityID: id, Type: pk.UnsignedByte(entities.Zombie.ID), X: pk.Int(i)}, protocol.ConnS2C)
+		} else {
+			handle(t, tunnel, &protocol.SpawnPlayer{EntityID: id, Y: pk.Int(i)}, protocol.ConnS2C)
+		}
+
+		handle(t, tunnel, &protocol.EntityRelativeMove{EntityID: id, DX: 32, DY: -32}, protocol.ConnS2C)
+		handle(t, tunnel, &protocol.EntityTeleport{EntityID: id, X: pk.Int(i * 32), Z: 64}, protocol.ConnS2C)
+		handle(t, tunnel, &protocol.EntityVelocity{EntityID: id, VX: 8000}, protocol.ConnS2C)
+		handle(t, tunnel, &protocol.EntityMetadata{EntityID: id, Metadata: pk.EntityMetadata{
+			minecraft.MetadataHealth: pk.MetadataFloat(i),
+		}}, protocol.ConnS2C)
+
+		if i%5 == 0 {
+			handle(t, tunnel, &protocol.DestroyEntities{EntityIDs: []pk.VarInt{id}}, protocol.ConnS2C)
+		}
+	})
+
+	// Server and client move the player
+	run(func(i int) {
+		handle(t, tunnel, &protocol.UpdateHealth{Health: pk.Float(i % 20)}, protocol.ConnS2C)
+		handle(t, tunnel, &protocol.PlayerPositionAndLook{X: 1, Y: 64, Flags: 0x01}, protocol.ConnS2C)
+	})
+
+	run(func(i int) {
+		handle(t, tunnel, &protocol.PlayerPosition{X: pk.Double(i), Y: 64, OnGround: i%2 == 0}, protocol.ConnC2S)
+		handle(t, tunnel, &protocol.PlayerLook{Yaw: pk.Float(i)}, protocol.ConnC2S)
+		handle(t, tunnel, &protocol.ServerPlayerPositionAndLook{Y: 64, Z: pk.Double(i)}, protocol.ConnC2S)
+		handle(t, tunnel, &protocol.Player{OnGround: true}, protocol.ConnC2S)
+	})
+
+	// Modules tick and read the state, their packets are waited for so that the queues do not overflow
+	run(func(int) {
+		assert.NoError(t, killAura.Tick())
+		assert.NoError(t, mobAura.Tick())
+		assert.NoError(t, tpAura.Tick())
+		assert.NoError(t, tunnel.Flush())
+	})
+
+	// Modules are registered while the handlers and the ticking modules look them up
+	run(func(i int) {
+		moduleHandler := tunnel.GetModuleHandler()
+		if i%50 == 0 {
+			moduleHandler.RegisterModule(&nofall.NoFall{})
+		}
+
+		moduleHandler.RegisterModule(&modules.ClientModule{Identifier: modules.ModulePlayerESP})
+		moduleHandler.Reset()
+	})
+
+	run(func(i int) {
+		_, _ = tunnel.GetModuleHandler().GetModule(modules.ModulePlayerESP)
+		_ = tunnel.GetModuleHandler().IsModuleEnabled(modules.ModuleNoFall)
+		for _, entity := range tunnel.GetEntityHandler().GetEntities() {
+			entity.GetLocation().X = -1
+			entity.GetMetadata()[minecraft.MetadataHealth] = pk.MetadataFloat(-1)
+		}
+
+		location := tunnel.GetPlayerHandler().GetLocation()
+		location.Y = -1
+
+		_ = tunnel.GetPlayerHandler().IsOnGround()
+		_ = tunnel.GetPlayerHandler().GetHealth()
+		_, _ = tunnel.GetEntityHandler().GetNearestEntity(location, minecraft.IsPlayer)
+	})
+
+	wg.Wait()
+
+	// Changes made to snapshots never get into the tracked state
+	assert.Equal(t, 64.0, tunnel.GetPlayerHandler().GetLocation().Y)
+	for _, entity := range tunnel.GetEntityHandler().GetEntities() {
+		health, _ := entity.GetHealth()
+		assert.NotEqual(t, -1.0, entity.GetLocation().X)
+		assert.NotEqual(t, -1.0, health)
+	}
+}
+
+func TestEntitySnapshot(t *testing.T) {
+	tunnel := newPipeTunnel(t)
+	handle(t, tunnel, &protocol.SpawnMob{EntityID: 1, Type: pk.UnsignedByte(entities.Cow.ID), X: 32}, protocol.ConnS2C)
+
+	snapshot, ok := tunnel.GetEntityHandler().GetEntity(1)
+	assert.True(t, ok)
+
+	handle(t, tunnel, &protocol.EntityRelativeMove{EntityID: 1, DX: 32}, protocol.ConnS2C)
+	assert.Equal(t, 1.0, snapshot.GetLocation().X)
+
+	snapshot.GetEquipment()[minecraft.EquipmentHelmet] = pk.Slot{BlockID: 1}
+	snapshot.Attach(5, false)
+
+	entity, _ := tunnel.GetEntityHandler().GetEntity(1)
+	assert.Equal(t, 2.0, entity.GetLocation().X)
+	assert.True(t, entity.GetEquipment()[minecraft.EquipmentHelmet].IsEmpty())
+	assert.Equal(t, minecraft.NoEntity, entity.GetVehicleID())
+}
@@ -3,100 +3,131 @@ package proxy
 import (
 	"bytes"
 	"fmt"
+	"log"
+	"strings"
 
 	"github.com/destructiqn/kogtevran/generic"
 	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
 	"github.com/destructiqn/kogtevran/minecraft/protocol"
 )
 
+const (
+	// ElementPrefix starts ids of the elements of our interface
+	ElementPrefix = "kv."
+
+	// ServerElementPrefix is prepended to ids of server elements colliding with ours
+	ServerElementPrefix = "srv."
+)
+
 type TexteriaHandler struct {
-	tunnel      *MinecraftTunnel
-	initialized bool
+	tunnel *MinecraftTunnel
 }
 
 func NewTexteriaHandler(tunnel *MinecraftTunnel) *TexteriaHandler {
 	return &TexteriaHandler{tunnel: tunnel}
 }
 
-// HandleClientboundTexteriaPacket - метод, обрабатывавший пакеты Texteria, но из-за проблем с парсингом ByteMap'ов
-// не используется
-//
-// Deprecated
-func HandleClientboundTexteriaPacket(data []byte, tunnel generic.Tunnel) (result []byte, next bool, err error) {
-	minecraftTunnel := tunnel.(*MinecraftTunnel)
-
+// ReadTexteriaActions splits the Texteria message into encoded actions
+func ReadTexteriaActions(data []byte) ([][]byte, error) {
 	var amount pk.VarInt
 	reader := bytes.NewReader(data)
-	_, err = amount.ReadFrom(reader)
+	_, err := amount.ReadFrom(reader)
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	buffer := &bytes.Buffer{}
-	for i := 0; i < int(amount); i++ {
+	if amount < 0 || int(amount) > reader.Len() {
+		return nil, pk.ErrInvalidLength
+	}
+
+	actions := make([][]byte, amount)
+	for i := range actions {
 		var actionData pk.ByteArray
 		_, err = actionData.ReadFrom(reader)
 		if err != nil {
-			return
+			return nil, err
 		}
 
+		actions[i] = actionData
+	}
+
+	return actions, nil
+}
+
+func EncodeTexteriaActions(actions [][]byte) []byte {
+	buffer := &bytes.Buffer{}
+	_, _ = pk.VarInt(len(actions)).WriteTo(buffer)
+	for _, action := range actions {
+		_, _ = pk.ByteArray(action).WriteTo(buffer)
+	}
+
+	return buffer.Bytes()
+}
+
+// HandleClientboundTexteriaPacket rewrites server elements colliding with ours, and injects our interface again
+// after the server resets its own one. Actions that are not changed are passed as they are, rewritten ones are
+// encoded with their keys sorted, which Texteria reads the same
+func HandleClientboundTexteriaPacket(data []byte, tunnel generic.Tunnel) (result []byte, next bool, err error) {
+	texteriaHandler := tunnel.GetTexteriaHandler().(*TexteriaHandler)
+
+	// Messages we fail to read are passed as they are, so that the server interface keeps working
+	actions, err := ReadTexteriaActions(data)
+	if err != nil {
+		log.Println("unable to read Texteria message:", err)
+		return nil, true, nil
+	}
+
+	// Plugin messages are sent in play, the actions are decoded with the limits of the packets carrying them
+	limits := protocol.GetLimits(protocol.ConnStatePlay)
+	modified, reset := false, false
+	for i, action := range actions {
 		var byteMap map[string]interface{}
-		byteMap, err = pk.ReadMap(actionData)
+		byteMap, err = pk.ReadMap(action, limits)
 		if err != nil {
-			return
+			log.Println("unable to read Texteria action:", err)
+			return nil, true, nil
+		}
+
+		if byteMap["%"] == "reset" {
+			reset = true
 		}
 
-		modified := minecraftTunnel.GetTexteriaHandler().(*TexteriaHandler).InterceptAction(byteMap)
-		if modified {
-			actionData, err = pk.EncodeMap(byteMap)
+		if texteriaHandler.InterceptAction(byteMap) {
+			actions[i], err = pk.EncodeMap(byteMap)
 			if err != nil {
-				return
+				return nil, true, err
 			}
-		}
 
-		_, err = actionData.WriteTo(buffer)
-		if err != nil {
-			return
+			modified = true
 		}
 	}
 
-	var modulesDetails []map[string]interface{}
-	if !minecraftTunnel.TexteriaHandler.initialized {
-		modulesDetails = minecraftTunnel.ModuleHandler.GetModulesDetails()
-		amount += pk.VarInt(len(modulesDetails))
-	}
+	if reset {
+		// Categories are only sent once, so they have to be sent again
+		tunnel.GetModuleHandler().(*ModuleHandler).Reset()
+		for _, fragment := range texteriaHandler.GetInterface() {
+			var action []byte
+			action, err = pk.EncodeMap(fragment)
+			if err != nil {
+				return nil, true, err
+			}
 
-	finalBuffer := &bytes.Buffer{}
-	_, err = (amount).WriteTo(finalBuffer)
-	if err != nil {
-		return
-	}
+			actions = append(actions, action)
+		}
 
-	_, err = buffer.WriteTo(finalBuffer)
-	if err != nil {
-		return
+		modified = true
 	}
 
-	for _, modulesDetailsFragment := range modulesDetails {
-		var encodedFragment []byte
-		encodedFragment, err = pk.EncodeMap(modulesDetailsFragment)
-		if err != nil {
-			return
-		}
-
-		_, err = pk.ByteArray(encodedFragment).WriteTo(finalBuffer)
-		if err != nil {
-			return
-		}
+	if !modified {
+		return nil, true, nil
 	}
 
-	minecraftTunnel.TexteriaHandler.initialized = true
-	return finalBuffer.Bytes(), true, err
+	return EncodeTexteriaActions(actions), true, nil
 }
 
 func HandleKeyboardPacketCandidate(data []byte, tunnel generic.Tunnel) (next bool, err error) {
 	var dataMap map[string]interface{}
-	dataMap, err = pk.ReadMap(data)
+	dataMap, err = pk.ReadMap(data, protocol.GetLimits(protocol.ConnStatePlay))
 	if err != nil {
 		return
 	}
@@ -138,12 +169,22 @@ func (t *TexteriaHandler) SendClient(data ...map[string]interface{}) error {
 	return t.tunnel.WriteClient(packet)
 }
 
+// InterceptAction moves server elements colliding with ours to other ids, it reports whether the action is changed
 func (t *TexteriaHandler) InterceptAction(data map[string]interface{}) bool {
-	if data["%"] == "reset" {
-		t.initialized = false
+	modified := false
+	if id, ok := data["id"].(string); ok && strings.HasPrefix(id, ElementPrefix) {
+		data["id"], modified = ServerElementPrefix+id, true
+	}
+
+	if ids, ok := data["ids"].([]string); ok {
+		for i, id := range ids {
+			if strings.HasPrefix(id, ElementPrefix) {
+				ids[i], modified = ServerElementPrefix+id, true
+			}
+		}
 	}
 
-	return false
+	return modified
 }
 
 func GetBranding() []map[string]interface{} {
@@ -263,8 +304,12 @@ func GetBranding() []map[string]interface{} {
 	}
 }
 
-func (t *TexteriaHandler) UpdateInterface() error {
+// GetInterface returns the actions adding our interface
+func (t *TexteriaHandler) GetInterface() []map[string]interface{} {
 	modulesDetails := t.tunnel.GetModuleHandler().(*ModuleHandler).GetModulesDetails()
-	branding := GetBranding()
-	return t.tunnel.TexteriaHandler.SendClient(append(modulesDetails, branding...)...)
+	return append(modulesDetails, GetBranding()...)
+}
+
+func (t *TexteriaHandler) UpdateInterface() error {
+	return t.SendClient(t.GetInterface()...)
 }
@@ -0,0 +1,80 @@
+package proxy
+
+import (
+	"testing"
+
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+func texteriaMessage(t *testing.T, actions ...map[string]interface{}) ([]byte, [][]byte) {
+	encoded := make([][]byte, 0, len(actions))
+	for _, action := range actions {
+		data, err := pk.EncodeMap(action)
+		assert.NoError(t, err)
+		encoded = append(encoded, data)
+	}
+
+	return EncodeTexteriaActions(encoded), encoded
+}
+
+func TestHandleClientboundTexteriaPacket(t *testing.T) {
+	tunnel := newPipeTunnel(t)
+
+	// Messages without collisions are passed as they are
+	message, _ := texteriaMessage(t, map[string]interface{}{"%": "add", "id": "hp", "x": 5})
+	result, next, err := HandleClientboundTexteriaPacket(message, tunnel)
+	assert.NoError(t, err)
+	assert.True(t, next)
+	assert.Nil(t, result)
+
+	message, actions := texteriaMessage(t,
+		map[string]interface{}{"%": "add", "id": "kv.mh", "x": 5},
+		map[string]interface{}{"%": "add", "id": "hp", "x": 5},
+		map[string]interface{}{"%": "remove", "ids": []string{"kv.mh", "hp"}},
+	)
+
+	result, next, err = HandleClientboundTexteriaPacket(message, tunnel)
+	assert.NoError(t, err)
+	assert.True(t, next)
+
+	rewritten, err := ReadTexteriaActions(result)
+	assert.NoError(t, err)
+	assert.Len(t, rewritten, 3)
+	assert.Equal(t, actions[1], rewritten[1])
+
+	add, _ := pk.ReadMap(rewritten[0], pk.DefaultLimits)
+	assert.Equal(t, "srv.kv.mh", add["id"])
+	remove, _ := pk.ReadMap(rewritten[2], pk.DefaultLimits)
+	assert.Equal(t, []string{"srv.kv.mh", "hp"}, remove["ids"])
+}
+
+func TestHandleClientboundTexteriaPacket_Reset(t *testing.T) {
+	tunnel := newPipeTunnel(t)
+	message, actions := texteriaMessage(t, map[string]interface{}{"%": "reset"})
+
+	// Our interface is added after the reset
+	result, _, err := HandleClientboundTexteriaPacket(message, tunnel)
+	assert.NoError(t, err)
+
+	injected, err := ReadTexteriaActions(result)
+	assert.NoError(t, err)
+	assert.Equal(t, actions[0], injected[0])
+
+	ids := make([]string, 0)
+	for _, action := range injected[1:] {
+		decoded, err := pk.ReadMap(action, pk.DefaultLimits)
+		assert.NoError(t, err)
+		ids = append(ids, decoded["id"].(string))
+	}
+
+	for _, element := range GetBranding() {
+		assert.Contains(t, ids, element["id"])
+	}
+
+	// Broken messages are not touched
+	result, next, err := HandleClientboundTexteriaPacket([]byte{0x02, 0x01}, tunnel)
+	assert.NoError(t, err)
+	assert.True(t, next)
+	assert.Nil(t, result)
+}
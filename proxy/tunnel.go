@@ -1,6 +1,8 @@
 package proxy
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net"
 	"sync"
@@ -19,15 +21,27 @@ type MinecraftTunnel struct {
 	PairID     TunnelPairID
 	TunnelPair *TunnelPair
 
-	Closed bool
-	Server *mcnet.Conn
-	Client *mcnet.Conn
+	// ctx is cancelled once the tunnel is closed, goroutines of the session are stopped with it
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+	// err is the failure the tunnel has been closed because of
+	err     error
+	errLock sync.Mutex
+	Server  *mcnet.Conn
+	Client  *mcnet.Conn
 
-	ServerWrite sync.Mutex
-	ClientWrite sync.Mutex
+	// Packets are written to each side by the goroutine of its queue
+	serverQueue *writeQueue
+	clientQueue *writeQueue
 
-	State               protocol.ConnectionState
-	TargetAddress       string
+	// State is changed by the pipes, other goroutines read it and the version with GetState and GetVersion
+	State         protocol.ConnectionState
+	stateLock     sync.RWMutex
+	Version       *protocol.Version
+	TargetAddress string
+	// ModuleProfile are the module options of the route of the session, the settings apply when it is nil
+	ModuleProfile       map[string]map[string]string
 	EnableEncryptionS2C chan []byte
 	EnableEncryptionC2S chan []byte
 
@@ -36,7 +50,9 @@ type MinecraftTunnel struct {
 	ModuleHandler    *ModuleHandler
 	PlayerHandler    *PlayerHandler
 	EntityHandler    *EntityHandler
+	WorldHandler     *WorldHandler
 	ChatHandler      *ChatHandler
+	HandlerRegistry  *HandlerRegistry
 }
 
 func (t *MinecraftTunnel) GetInventoryHandler() generic.InventoryHandler {
@@ -51,14 +67,48 @@ func (t *MinecraftTunnel) GetChatHandler() generic.ChatHandler {
 	return t.ChatHandler
 }
 
+func (t *MinecraftTunnel) GetHandlerRegistry() generic.HandlerRegistry {
+	return t.HandlerRegistry
+}
+
+// SetState switches the tunnel to the state, packets read afterwards are checked against its limits
 func (t *MinecraftTunnel) SetState(state protocol.ConnectionState) {
+	t.stateLock.Lock()
 	t.State = state
+	t.stateLock.Unlock()
+
+	limits := protocol.GetLimits(state)
+	t.Client.SetLimits(limits)
+	t.Server.SetLimits(limits)
+}
+
+func (t *MinecraftTunnel) GetState() protocol.ConnectionState {
+	t.stateLock.RLock()
+	defer t.stateLock.RUnlock()
+	return t.State
+}
+
+// SetVersion selects the packet IDs written packets are mapped to, it is known after the handshake
+func (t *MinecraftTunnel) SetVersion(version *protocol.Version) {
+	t.stateLock.Lock()
+	t.Version = version
+	t.stateLock.Unlock()
+}
+
+func (t *MinecraftTunnel) GetVersion() *protocol.Version {
+	t.stateLock.RLock()
+	defer t.stateLock.RUnlock()
+	return t.Version
 }
 
 func (t *MinecraftTunnel) GetEntityHandler() generic.EntityHandler {
 	return t.EntityHandler
 }
 
+func (t *MinecraftTunnel) GetWorldHandler() generic.WorldHandler {
+	return t.WorldHandler
+}
+
 func (t *MinecraftTunnel) GetPlayerHandler() generic.PlayerHandler {
 	return t.PlayerHandler
 }
@@ -69,22 +119,103 @@ func (t *MinecraftTunnel) GetModuleHandler() generic.ModuleHandler {
 
 func (t *MinecraftTunnel) Disconnect(reason chat.Message) {
 	metrics.Disconnects.With(prometheus.Labels{"reason": reason.String()}).Inc()
-	_ = t.WriteClient(pk.Marshal(0x00, reason))
+	id := int32(protocol.ClientboundDisconnect)
+	if t.GetState() != protocol.ConnStatePlay {
+		id = protocol.ClientboundLoginDisconnect
+	}
+
+	// The reason is waited for, unlike other packets, and is sent before the tunnel is closed
+	_ = t.write(pk.Marshal(id, reason), protocol.ConnS2C, true)
+	_ = t.clientQueue.exec((*mcnet.Conn).Flush)
 	t.Close()
 }
 
+// WriteClient queues the packet with a logical ID, see protocol.Version. It does not wait for the client,
+// when its queue is full the overflow policy applies
 func (t *MinecraftTunnel) WriteClient(packet pk.Packet) error {
-	t.ClientWrite.Lock()
-	err := t.Client.WritePacket(packet)
-	t.ClientWrite.Unlock()
-	return err
+	return t.write(packet, protocol.ConnS2C, false)
 }
 
+// WriteServer queues the packet with a logical ID, see WriteClient
 func (t *MinecraftTunnel) WriteServer(packet pk.Packet) error {
-	t.ServerWrite.Lock()
-	err := t.Server.WritePacket(packet)
-	t.ServerWrite.Unlock()
-	return err
+	return t.write(packet, protocol.ConnC2S, false)
+}
+
+func (t *MinecraftTunnel) write(packet pk.Packet, direction int, wait bool) error {
+	logical, state, version := packet.ID, t.GetState(), t.GetVersion()
+	id, ok := version.ID(state, direction, logical)
+	if !ok {
+		return fmt.Errorf("%w: %s on %s", protocol.ErrUnsupportedPacket, protocol.FormatPacket(logical, direction), version)
+	}
+
+	packet.ID = id
+	return t.queue(direction).push(queuedWrite{packet: packet}, protocol.IsKeepAlive(state, logical, direction), wait)
+}
+
+// WriteRaw queues the packet to the side the direction leads to, the ID of the packet is written as it is.
+// It waits for room in the queue, so that a slow peer slows down the pipe forwarding packets to it
+func (t *MinecraftTunnel) WriteRaw(packet pk.Packet, direction int) error {
+	return t.queue(direction).push(queuedWrite{packet: packet}, false, true)
+}
+
+// Forward acts like WriteRaw for packets the pipe has decoded, keep-alives jump the queue
+// so that the peer does not time out while the queue is full
+func (t *MinecraftTunnel) Forward(packet pk.Packet, direction int, keepAlive bool) error {
+	return t.queue(direction).push(queuedWrite{packet: packet}, keepAlive, true)
+}
+
+func (t *MinecraftTunnel) queue(direction int) *writeQueue {
+	if direction == protocol.ConnC2S {
+		return t.serverQueue
+	}
+
+	return t.clientQueue
+}
+
+// Flush waits until the packets queued to both sides are sent
+func (t *MinecraftTunnel) Flush() error {
+	err := t.clientQueue.exec((*mcnet.Conn).Flush)
+	if err != nil {
+		return err
+	}
+
+	return t.serverQueue.exec((*mcnet.Conn).Flush)
+}
+
+// Exec runs the function with the connection the direction leads to once the packets queued before are written,
+// e.g. to enable encryption or compression from the next packet on
+func (t *MinecraftTunnel) Exec(direction int, exec func(conn *mcnet.Conn)) error {
+	return t.queue(direction).exec(func(conn *mcnet.Conn) error {
+		exec(conn)
+		return nil
+	})
+}
+
+// fail closes the tunnel because of an error writing to either side, only the first error is kept
+func (t *MinecraftTunnel) fail(err error) {
+	// Writes fail once the connections are closed, the tunnel has not failed because of them
+	if t.IsClosed() {
+		return
+	}
+
+	t.errLock.Lock()
+	first := t.err == nil
+	if first {
+		t.err = err
+	}
+	t.errLock.Unlock()
+
+	if first {
+		log.Println("closing tunnel of", t.PlayerHandler.GetPlayerName()+":", err)
+		t.Close()
+	}
+}
+
+// Err returns the error the tunnel has failed with, nil if it has not
+func (t *MinecraftTunnel) Err() error {
+	t.errLock.Lock()
+	defer t.errLock.Unlock()
+	return t.err
 }
 
 func (t *MinecraftTunnel) GetRemoteAddr() string {
@@ -97,25 +228,40 @@ func (t *MinecraftTunnel) GetRemoteAddr() string {
 	return host
 }
 
+// Close cancels the context of the tunnel and closes both connections, only the first call does anything.
+// The pair is unregistered afterwards, it closes the tunnel again
 func (t *MinecraftTunnel) Close() {
-	if t.Closed {
-		return
-	}
+	t.closeOnce.Do(func() {
+		t.cancel()
+		for _, module := range t.GetModuleHandler().GetModules() {
+			module.Close()
+		}
 
-	for _, module := range t.GetModuleHandler().GetModules() {
-		module.Close()
-	}
+		_ = t.Server.Close()
+		_ = t.Client.Close()
+	})
 
-	t.Closed = true
-	_ = t.Server.Close()
-	_ = t.Client.Close()
 	CurrentTunnelPool.UnregisterPair(t.PairID)
 }
 
+// IsClosed reports whether Close has been called
+func (t *MinecraftTunnel) IsClosed() bool {
+	return t.ctx.Err() != nil
+}
+
+// GetContext returns the context of the session, it is cancelled when the tunnel is closed
+func (t *MinecraftTunnel) GetContext() context.Context {
+	return t.ctx
+}
+
 func WrapConn(server, client *mcnet.Conn) *MinecraftTunnel {
+	ctx, cancel := context.WithCancel(context.Background())
 	tunnel := &MinecraftTunnel{
+		ctx:                 ctx,
+		cancel:              cancel,
 		Server:              server,
 		Client:              client,
+		Version:             protocol.DefaultVersion,
 		EnableEncryptionS2C: make(chan []byte),
 		EnableEncryptionC2S: make(chan []byte),
 	}
@@ -124,14 +270,23 @@ func WrapConn(server, client *mcnet.Conn) *MinecraftTunnel {
 	tunnel.TexteriaHandler = NewTexteriaHandler(tunnel)
 	tunnel.ModuleHandler = NewModuleHandler(tunnel)
 	tunnel.EntityHandler = NewEntityHandler(tunnel)
+	tunnel.WorldHandler = NewWorldHandler(tunnel)
 	tunnel.PlayerHandler = NewPlayerHandler(tunnel)
 	tunnel.ChatHandler = NewChatHandler(tunnel)
+	tunnel.HandlerRegistry = NewHandlerRegistry(tunnel)
+	tunnel.SetState(protocol.ConnStateHandshake)
+
+	settings := GetSettings()
+	tunnel.clientQueue = newWriteQueue(client, protocol.ConnS2C, settings, ctx.Done(), tunnel.fail)
+	tunnel.serverQueue = newWriteQueue(server, protocol.ConnC2S, settings, ctx.Done(), tunnel.fail)
+	go tunnel.clientQueue.run()
+	go tunnel.serverQueue.run()
 
 	return tunnel
 }
 
 func (t *MinecraftTunnel) Attack(target int) error {
-	return t.WriteServer(pk.Marshal(0x02, pk.VarInt(target), pk.VarInt(1)))
+	return t.WriteServer(pk.Marshal(protocol.ServerboundUseEntity, pk.VarInt(target), pk.VarInt(1)))
 }
 
 func (t *MinecraftTunnel) HasFeature(feature license.Feature) bool {
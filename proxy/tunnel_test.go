@@ -0,0 +1,177 @@
+package proxy
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tnze/go-mc/chat"
+	"github.com/destructiqn/kogtevran/generic"
+	"github.com/destructiqn/kogtevran/minecraft/blocks"
+	mcnet "github.com/destructiqn/kogtevran/minecraft/net"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/destructiqn/kogtevran/minecraft/protocol"
+	"github.com/destructiqn/kogtevran/modules"
+	"github.com/destructiqn/kogtevran/modules/aura"
+	"github.com/destructiqn/kogtevran/modules/flight"
+	"github.com/destructiqn/kogtevran/modules/nuker"
+	"github.com/destructiqn/kogtevran/modules/spammer"
+	"github.com/destructiqn/kogtevran/modules/unlimitedcps"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMinecraftTunnel_WriteVersion(t *testing.T) {
+	tunnel, toServer, toClient := newRecordingTunnel(t)
+	tunnel.SetVersion(protocol.V1_12_2)
+
+	assert.NoError(t, tunnel.WriteClient(pk.Marshal(protocol.ClientboundChatMessage, pk.String("{}"), pk.Byte(0))))
+	assert.Equal(t, int32(0x0F), (<-toClient).ID)
+
+	assert.NoError(t, tunnel.WriteServer(pk.Marshal(protocol.ServerboundHeldItemChange, pk.Short(1))))
+	assert.Equal(t, int32(0x1A), (<-toServer).ID)
+
+	err := tunnel.WriteClient((&protocol.JoinGame{}).Marshal())
+	assert.True(t, errors.Is(err, protocol.ErrUnsupportedPacket))
+
+	// Raw packets are written as they are
+	assert.NoError(t, tunnel.WriteRaw(pk.Marshal(0x4D), protocol.ConnS2C))
+	assert.Equal(t, int32(0x4D), (<-toClient).ID)
+}
+
+func TestMinecraftTunnel_DisconnectLogin(t *testing.T) {
+	tunnel, _, toClient := newRecordingTunnel(t)
+	tunnel.SetState(protocol.ConnStateLogin)
+	tunnel.SetVersion(protocol.V1_12_2)
+
+	tunnel.Disconnect(chat.Text("unsupported protocol version"))
+	assert.Equal(t, int32(protocol.ClientboundLoginDisconnect), (<-toClient).ID)
+	assert.True(t, tunnel.IsClosed())
+}
+
+func TestMinecraftTunnel_GetState(t *testing.T) {
+	tunnel, _, _ := newRecordingTunnel(t)
+	tunnel.SetState(protocol.ConnStateLogin)
+
+	// The login timeout reads the state while the pipe switches it
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tunnel.SetState(protocol.ConnStatePlay)
+	}()
+
+	state := tunnel.GetState()
+	assert.True(t, state == protocol.ConnStateLogin || state == protocol.ConnStatePlay)
+	<-done
+	assert.Equal(t, protocol.ConnStatePlay, tunnel.GetState())
+}
+
+func TestMinecraftTunnel_StateLimits(t *testing.T) {
+	server, _ := net.Pipe()
+	client, clientPeer := net.Pipe()
+	t.Cleanup(func() {
+		_ = server.Close()
+		_ = client.Close()
+	})
+
+	tunnel := WrapConn(mcnet.WrapConn(server), mcnet.WrapConn(client))
+	peer := mcnet.WrapConn(clientPeer)
+	handshake := pk.Marshal(protocol.ServerboundHandshake, pk.VarInt(47), pk.String(strings.Repeat("a", 4096)), pk.UnsignedShort(25565), pk.VarInt(2))
+	go func() {
+		_ = peer.WritePacket(handshake)
+		_ = peer.WritePacket(handshake)
+		_ = peer.Flush()
+	}()
+
+	// The stream cannot be read past a packet over the limits, so the one within them goes first
+	var packet pk.Packet
+	tunnel.SetState(protocol.ConnStatePlay)
+	assert.NoError(t, tunnel.Client.ReadPacket(&packet))
+	assert.Equal(t, pk.DefaultLimits, packet.Limits)
+
+	tunnel.SetState(protocol.ConnStateHandshake)
+	assert.True(t, errors.Is(tunnel.Client.ReadPacket(&packet), pk.ErrLimitExceeded))
+}
+
+// dialAuxiliary returns an auxiliary channel connected to a websocket server, which reads until it is closed
+func dialAuxiliary(t *testing.T) *AuxiliaryChannel {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := WebsocketUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+		var message WebsocketMessage
+		for conn.ReadJSON(&message) == nil {
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewAuxiliaryChannel(conn)
+}
+
+func TestMinecraftTunnel_Close(t *testing.T) {
+	// The auxiliary channel of the pair is closed along with the tunnel, so is its keep-alive loop
+	channel := dialAuxiliary(t)
+	before := runtime.NumGoroutine()
+	tunnel, toServer, _ := newRecordingTunnel(t)
+	pairID := TunnelPairID{Username: "Steve", RemoteAddr: "127.0.0.1"}
+	pair := &TunnelPair{Primary: tunnel, Auxiliary: channel}
+	tunnel.PairID, tunnel.TunnelPair = pairID, pair
+	channel.PairID, channel.TunnelPair = pairID, pair
+	CurrentTunnelPool.RegisterPair(pairID, pair)
+	go channel.HandleKeepAlive()
+
+	// Nuker starts its queue on the first tick
+	ticking := modules.SimpleTickingModule{Interval: time.Millisecond}
+	ticking.SetEnabled(true)
+	killAura := &aura.KillAura{GenericAura: aura.GenericAura{SimpleTickingModule: ticking}}
+	nukerModule := &nuker.Nuker{Radius: 1, Delay: 100, SimpleTickingModule: ticking}
+	flightModule, unlimitedCPS := &flight.Flight{Speed: 1}, &unlimitedcps.UnlimitedCPS{}
+	flightModule.SetEnabled(true)
+	unlimitedCPS.SetEnabled(true)
+	for _, module := range []generic.Module{killAura, nukerModule, &spammer.Spammer{SimpleTickingModule: ticking}, flightModule, unlimitedCPS} {
+		tunnel.ModuleHandler.RegisterModule(module)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// Flight and UnlimitedCPS update the client a while after these, the tunnel is closed before
+	handle(t, tunnel, &protocol.PlayerAbilities{}, protocol.ConnS2C)
+	handle(t, tunnel, &protocol.JoinGame{LevelType: "default"}, protocol.ConnS2C)
+
+	// The dirt next to the player takes Nuker far longer to break than the test runs
+	handle(t, tunnel, &protocol.BlockChange{BlockID: pk.VarInt(blocks.Dirt.ID) << 4}, protocol.ConnS2C)
+	digging := false
+	for timeout := time.After(time.Second); !digging; {
+		select {
+		case packet := <-toServer:
+			digging = packet.ID == protocol.ServerboundPlayerDigging
+		case <-timeout:
+			t.Fatal("nuker has not started digging")
+		}
+	}
+
+	// Stopping a ticker does not wait for it, however many times it is done
+	killAura.StopTicker()
+	killAura.StopTicker()
+
+	tunnel.Close()
+	tunnel.Close()
+	assert.True(t, tunnel.IsClosed())
+	assert.Error(t, tunnel.GetContext().Err())
+
+	assert.Eventually(t, func() bool {
+		return runtime.NumGoroutine() <= before
+	}, time.Second, 10*time.Millisecond, "goroutines of the session are left running")
+}
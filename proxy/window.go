@@ -0,0 +1,313 @@
+package proxy
+
+import (
+	"github.com/destructiqn/kogtevran/generic"
+	"github.com/destructiqn/kogtevran/minecraft"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+)
+
+const (
+	clickModePickup = 0
+	clickModeShift  = 1
+	clickModeHotbar = 2
+	clickModeDrop   = 4
+)
+
+var emptySlot = pk.Slot{BlockID: -1}
+
+// Windows moving shift clicked items between the container and the player inventory only
+var simpleContainers = map[string]bool{
+	"minecraft:chest":     true,
+	"minecraft:hopper":    true,
+	"minecraft:dispenser": true,
+	"minecraft:dropper":   true,
+}
+
+func (w *Window) GetID() int {
+	return int(w.id)
+}
+
+func (w *Window) GetLayout() minecraft.WindowLayout {
+	return w.layout
+}
+
+// FindItems returns non-empty slots of the region matching the filter in ascending order
+func (w *Window) FindItems(filter minecraft.ItemFilter, region minecraft.WindowRegion) []int {
+	w.state.Lock()
+	defer w.state.Unlock()
+
+	slots := make([]int, 0)
+	for _, slot := range w.layout.Slots(region) {
+		if item := w.item(slot); !item.IsEmpty() && filter(item) {
+			slots = append(slots, slot)
+		}
+	}
+
+	return slots
+}
+
+func (w *Window) FindItem(filter minecraft.ItemFilter, region minecraft.WindowRegion) (slot int, ok bool) {
+	slots := w.FindItems(filter, region)
+	if len(slots) == 0 {
+		return 0, false
+	}
+
+	return slots[0], true
+}
+
+// slotRange returns slots from first to last exclusive, reversed ones are filled starting from the end
+func slotRange(first, last int, reverse bool) []int {
+	slots := make([]int, 0, last-first)
+	for slot := first; slot < last; slot++ {
+		slots = append(slots, slot)
+	}
+
+	if reverse {
+		for i, j := 0, len(slots)-1; i < j; i, j = i+1, j-1 {
+			slots[i], slots[j] = slots[j], slots[i]
+		}
+	}
+
+	return slots
+}
+
+// shiftTargets returns the slots the shift clicked item goes to in the order the client fills them,
+// ok is false when they depend on crafting or smelting recipes. Must be called under the state lock
+func (w *Window) shiftTargets(slot int, item pk.Slot) (targets []int, ok bool) {
+	layout := w.layout
+	inventory := slotRange(layout.ContainerSize, layout.Size(), false)
+	region := layout.Region(slot)
+
+	switch {
+	case w.id == 0:
+		armorSlot := 5 + int(item.BlockID-298)%4
+		switch {
+		case slot == 0:
+			return nil, false
+		case region == minecraft.RegionContainer:
+			return inventory, true
+		case item.BlockID >= 298 && item.BlockID <= 317 && w.item(armorSlot).IsEmpty():
+			return []int{armorSlot}, true
+		case region == minecraft.RegionMain:
+			return layout.Slots(minecraft.RegionHotbar), true
+		default:
+			return layout.Slots(minecraft.RegionMain), true
+		}
+	case simpleContainers[w.wType]:
+		if region == minecraft.RegionContainer {
+			return slotRange(layout.ContainerSize, layout.Size(), true), true
+		}
+
+		return layout.Slots(minecraft.RegionContainer), true
+	case w.wType == "minecraft:furnace" && region == minecraft.RegionContainer:
+		// Smelted items are taken from the output starting from the hotbar
+		return slotRange(layout.ContainerSize, layout.Size(), slot == 2), true
+	}
+
+	return nil, false
+}
+
+// merge spreads the stack over the slots the way the client does, and returns the changed slots and the count left
+func (w *Window) merge(stack pk.Slot, slots []int) (changes map[int]pk.Slot, left int) {
+	changes, left = make(map[int]pk.Slot), int(stack.ItemCount)
+	maxStackSize := minecraft.MaxStackSize(stack.BlockID)
+
+	if maxStackSize > 1 {
+		for _, slot := range slots {
+			item := w.item(slot)
+			if left == 0 || !minecraft.CanStack(item, stack) || int(item.ItemCount) >= maxStackSize {
+				continue
+			}
+
+			moved := maxStackSize - int(item.ItemCount)
+			if moved > left {
+				moved = left
+			}
+
+			item.ItemCount += int8(moved)
+			changes[slot], left = item, left-moved
+		}
+	}
+
+	for _, slot := range slots {
+		if left > 0 && w.item(slot).IsEmpty() {
+			item := stack
+			item.ItemCount = int8(left)
+			changes[slot], left = item, 0
+		}
+	}
+
+	return changes, left
+}
+
+// ShiftClick moves the stack between the container and the player inventory the same way the client does.
+// If the outcome depends on recipes, the click makes the server send the window contents instead of being predicted
+func (w *Window) ShiftClick(slot int) (*generic.ClickResult, error) {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.GetItem(slot).IsEmpty() {
+		return nil, generic.ErrEmptySlot
+	}
+
+	transaction, changes, err := w.click(slot, clickModeShift, 0, func() prediction {
+		stack := w.item(slot)
+		targets, ok := w.shiftTargets(slot, stack)
+		if !ok {
+			return prediction{clickedItem: emptySlot, resync: true}
+		}
+
+		changes, left := w.merge(stack, targets)
+		if len(changes) == 0 {
+			// Server returns nothing if the stack has not moved
+			return prediction{clickedItem: emptySlot}
+		}
+
+		changes[slot] = emptySlot
+		if left > 0 {
+			remaining := stack
+			remaining.ItemCount = int8(left)
+			changes[slot] = remaining
+		}
+
+		return prediction{clickedItem: stack, changes: changes}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.updateClient(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction.result, nil
+}
+
+// SwapHotbar swaps contents of the slot and the hotbar slot with the index from 0 to 8, like pressing a number key does
+func (w *Window) SwapHotbar(slot, index int) (*generic.ClickResult, error) {
+	w.Lock()
+	defer w.Unlock()
+
+	if index < 0 || index >= minecraft.HotbarSize {
+		return nil, generic.ErrInvalidSlot
+	}
+
+	hotbarSlot := w.layout.HotbarSlot(index)
+	transaction, changes, err := w.click(slot, clickModeHotbar, byte(index), func() prediction {
+		return prediction{
+			clickedItem: emptySlot,
+			changes:     map[int]pk.Slot{slot: w.item(hotbarSlot), hotbarSlot: w.item(slot)},
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.updateClient(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction.result, nil
+}
+
+// Drop throws a single item or the whole stack out of the slot
+func (w *Window) Drop(slot int, stack bool) (*generic.ClickResult, error) {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.GetItem(slot).IsEmpty() {
+		return nil, generic.ErrEmptySlot
+	}
+
+	var button byte
+	if stack {
+		button = 1
+	}
+
+	transaction, changes, err := w.click(slot, clickModeDrop, button, func() prediction {
+		item := w.item(slot)
+		item.ItemCount--
+		if stack || item.ItemCount == 0 {
+			item = emptySlot
+		}
+
+		return prediction{clickedItem: emptySlot, changes: map[int]pk.Slot{slot: item}}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.updateClient(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction.result, nil
+}
+
+// Split moves the bigger half of the stack to the empty slot
+func (w *Window) Split(from, to int) (*generic.ClickResult, error) {
+	w.Lock()
+	defer w.Unlock()
+
+	source := w.GetItem(from)
+	switch {
+	case source.IsEmpty():
+		return nil, generic.ErrEmptySlot
+	case source.ItemCount < 2:
+		return nil, generic.ErrNotStackable
+	case !w.GetItem(to).IsEmpty():
+		return nil, generic.ErrSlotOccupied
+	}
+
+	// Right click picks up the bigger half
+	left, moved := source, source
+	left.ItemCount, moved.ItemCount = source.ItemCount/2, source.ItemCount-source.ItemCount/2
+
+	return w.sequence([]int{from, to}, 1, func() prediction {
+		return prediction{clickedItem: w.item(to), changes: map[int]pk.Slot{from: left, to: moved}}
+	})
+}
+
+// Merge moves as many items as fits from the stack to another stack of the same item
+func (w *Window) Merge(from, to int) (*generic.ClickResult, error) {
+	w.Lock()
+	defer w.Unlock()
+
+	source, destination := w.GetItem(from), w.GetItem(to)
+	if source.IsEmpty() {
+		return nil, generic.ErrEmptySlot
+	}
+
+	space := minecraft.MaxStackSize(source.BlockID) - int(destination.ItemCount)
+	if !minecraft.CanStack(source, destination) || space <= 0 {
+		return nil, generic.ErrNotStackable
+	}
+
+	moved := int(source.ItemCount)
+	if moved > space {
+		moved = space
+	}
+
+	left := source
+	left.ItemCount -= int8(moved)
+	destination.ItemCount += int8(moved)
+
+	// Whatever does not fit is put back
+	slots := []int{from, to}
+	if left.ItemCount == 0 {
+		left = emptySlot
+	} else {
+		slots = append(slots, from)
+	}
+
+	return w.sequence(slots, 0, func() prediction {
+		last := slots[len(slots)-1]
+		return prediction{clickedItem: w.item(last), changes: map[int]pk.Slot{from: left, to: destination}}
+	})
+}
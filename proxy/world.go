@@ -0,0 +1,205 @@
+package proxy
+
+import (
+	"log"
+	"sync"
+
+	"github.com/destructiqn/kogtevran/generic"
+	"github.com/destructiqn/kogtevran/minecraft"
+	"github.com/destructiqn/kogtevran/minecraft/biomes"
+	"github.com/destructiqn/kogtevran/minecraft/blocks"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/destructiqn/kogtevran/minecraft/protocol"
+)
+
+const DimensionOverworld = 0
+
+type WorldHandler struct {
+	tunnel    *MinecraftTunnel
+	dimension int
+	chunks    map[minecraft.ChunkPosition]*minecraft.Chunk
+	sync.RWMutex
+}
+
+func NewWorldHandler(tunnel *MinecraftTunnel) *WorldHandler {
+	return &WorldHandler{tunnel: tunnel, chunks: make(map[minecraft.ChunkPosition]*minecraft.Chunk)}
+}
+
+func chunkPosition(position pk.Position) minecraft.ChunkPosition {
+	return minecraft.ChunkPosition{X: position.X >> 4, Z: position.Z >> 4}
+}
+
+func (h *WorldHandler) GetDimension() int {
+	h.RLock()
+	defer h.RUnlock()
+	return h.dimension
+}
+
+func (h *WorldHandler) IsLoaded(position pk.Position) bool {
+	h.RLock()
+	_, ok := h.chunks[chunkPosition(position)]
+	h.RUnlock()
+	return ok
+}
+
+func (h *WorldHandler) GetBlockState(position pk.Position) (state uint16, ok bool) {
+	h.RLock()
+	defer h.RUnlock()
+
+	chunk, ok := h.chunks[chunkPosition(position)]
+	if !ok {
+		return 0, false
+	}
+
+	return chunk.GetBlockState(position.X&15, position.Y, position.Z&15), true
+}
+
+// GetBlock returns air for blocks in unloaded chunks, IsLoaded tells them apart from the real air
+func (h *WorldHandler) GetBlock(position pk.Position) blocks.Block {
+	state, _ := h.GetBlockState(position)
+	block, ok := blocks.ByID[blocks.ID(state>>4)]
+	if !ok {
+		return blocks.Block{ID: blocks.ID(state >> 4)}
+	}
+
+	return *block
+}
+
+// GetBiome returns the biome of the column, the second value is false for unloaded chunks
+func (h *WorldHandler) GetBiome(position pk.Position) (biomes.Biome, bool) {
+	h.RLock()
+	defer h.RUnlock()
+
+	chunk, ok := h.chunks[chunkPosition(position)]
+	if !ok {
+		return biomes.Biome{}, false
+	}
+
+	id := chunk.GetBiome(position.X&15, position.Z&15)
+	biome, ok := biomes.ByID[id]
+	if !ok {
+		return biomes.Biome{ID: id}, true
+	}
+
+	return *biome, true
+}
+
+func (h *WorldHandler) SetBlockState(position pk.Position, state uint16) {
+	h.Lock()
+	defer h.Unlock()
+
+	chunk, ok := h.chunks[chunkPosition(position)]
+	if !ok {
+		return
+	}
+
+	chunk.SetBlockState(position.X&15, position.Y, position.Z&15, state)
+}
+
+// LoadChunk reads the chunk column, merging it with the already loaded one unless the data is ground-up continuous
+func (h *WorldHandler) LoadChunk(x, z int, data []byte, bitMask uint16, skyLight, groundUp bool) (n int, err error) {
+	h.Lock()
+	defer h.Unlock()
+
+	position := minecraft.ChunkPosition{X: x, Z: z}
+	chunk, ok := h.chunks[position]
+	if !ok || groundUp {
+		chunk = &minecraft.Chunk{}
+	}
+
+	n, err = chunk.ReadSections(data, bitMask, skyLight, groundUp)
+	if err != nil {
+		return
+	}
+
+	h.chunks[position] = chunk
+	return
+}
+
+func (h *WorldHandler) UnloadChunk(x, z int) {
+	h.Lock()
+	delete(h.chunks, minecraft.ChunkPosition{X: x, Z: z})
+	h.Unlock()
+}
+
+// Reset unloads all chunks, the client does the same when it changes dimension
+func (h *WorldHandler) Reset(dimension int) {
+	h.Lock()
+	h.dimension = dimension
+	h.chunks = make(map[minecraft.ChunkPosition]*minecraft.Chunk)
+	h.Unlock()
+}
+
+func HandleChunkData(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	chunkData := packet.(*protocol.ChunkData)
+	world := tunnel.GetWorldHandler().(*WorldHandler)
+	x, z := int(chunkData.ChunkX), int(chunkData.ChunkZ)
+
+	// Empty ground-up continuous chunk is sent to unload the column
+	if chunkData.GroundUpContinuous && chunkData.PrimaryBitMask == 0 {
+		world.UnloadChunk(x, z)
+		return generic.PassPacket(), nil
+	}
+
+	// The packet is passed either way, the tracker only observes the terrain
+	skyLight := world.GetDimension() == DimensionOverworld
+	_, err = world.LoadChunk(x, z, chunkData.Data, uint16(chunkData.PrimaryBitMask), skyLight, bool(chunkData.GroundUpContinuous))
+	if err != nil {
+		log.Println("error loading chunk", x, z, "from chunk data:", err)
+	}
+
+	return generic.PassPacket(), nil
+}
+
+func HandleMapChunkBulk(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	mapChunkBulk := packet.(*protocol.MapChunkBulk)
+	world := tunnel.GetWorldHandler().(*WorldHandler)
+
+	data := []byte(mapChunkBulk.Data)
+	for _, meta := range mapChunkBulk.Meta {
+		n, err := world.LoadChunk(int(meta.ChunkX), int(meta.ChunkZ), data, uint16(meta.PrimaryBitMask), bool(mapChunkBulk.SkyLightSent), true)
+		if err != nil {
+			// The chunks which follow cannot be located, the packet is passed anyway
+			log.Println("error loading chunk", meta.ChunkX, meta.ChunkZ, "from map chunk bulk:", err)
+			break
+		}
+
+		data = data[n:]
+	}
+
+	return generic.PassPacket(), nil
+}
+
+func HandleMultiBlockChange(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	multiBlockChange := packet.(*protocol.MultiBlockChange)
+	world := tunnel.GetWorldHandler().(*WorldHandler)
+
+	baseX, baseZ := int(multiBlockChange.ChunkX)<<4, int(multiBlockChange.ChunkZ)<<4
+	for _, record := range multiBlockChange.Records {
+		world.SetBlockState(pk.Position{
+			X: baseX + int(record.HorizontalPosition>>4),
+			Y: int(record.Y),
+			Z: baseZ + int(record.HorizontalPosition&15),
+		}, uint16(record.BlockID))
+	}
+
+	return generic.PassPacket(), nil
+}
+
+func HandleBlockChange(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	blockChange := packet.(*protocol.BlockChange)
+	tunnel.GetWorldHandler().(*WorldHandler).SetBlockState(blockChange.Location, uint16(blockChange.BlockID))
+	return generic.PassPacket(), nil
+}
+
+func HandleExplosion(packet protocol.Packet, tunnel generic.Tunnel) (result *generic.HandlerResult, err error) {
+	explosion := packet.(*protocol.Explosion)
+	world := tunnel.GetWorldHandler().(*WorldHandler)
+
+	x, y, z := int(explosion.X), int(explosion.Y), int(explosion.Z)
+	for _, record := range explosion.Records {
+		world.SetBlockState(pk.Position{X: x + int(record.X), Y: y + int(record.Y), Z: z + int(record.Z)}, 0)
+	}
+
+	return generic.PassPacket(), nil
+}
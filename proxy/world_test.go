@@ -0,0 +1,187 @@
+package proxy
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/destructiqn/kogtevran/minecraft"
+	"github.com/destructiqn/kogtevran/minecraft/biomes"
+	"github.com/destructiqn/kogtevran/minecraft/blocks"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/destructiqn/kogtevran/minecraft/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func newWorldTunnel() *MinecraftTunnel {
+	tunnel := &MinecraftTunnel{State: protocol.ConnStatePlay}
+	tunnel.WorldHandler = NewWorldHandler(tunnel)
+	tunnel.PlayerHandler = NewPlayerHandler(tunnel)
+	tunnel.EntityHandler = NewEntityHandler(tunnel)
+	tunnel.InventoryHandler = NewInventoryHandler(tunnel)
+	tunnel.HandlerRegistry = NewHandlerRegistry(tunnel)
+
+	registry := tunnel.HandlerRegistry
+	registry.RegisterHandler(nil, protocol.ConnStatePlay, protocol.ConnS2C, protocol.ClientboundChunkData, &protocol.ChunkData{}, 0, HandleChunkData)
+	registry.RegisterHandler(nil, protocol.ConnStatePlay, protocol.ConnS2C, protocol.ClientboundMapChunkBulk, &protocol.MapChunkBulk{}, 0, HandleMapChunkBulk)
+	registry.RegisterHandler(nil, protocol.ConnStatePlay, protocol.ConnS2C, protocol.ClientboundMultiBlockChange, &protocol.MultiBlockChange{}, 0, HandleMultiBlockChange)
+	registry.RegisterHandler(nil, protocol.ConnStatePlay, protocol.ConnS2C, protocol.ClientboundBlockChange, &protocol.BlockChange{}, 0, HandleBlockChange)
+	registry.RegisterHandler(nil, protocol.ConnStatePlay, protocol.ConnS2C, protocol.ClientboundExplosion, &protocol.Explosion{}, 0, HandleExplosion)
+	registry.RegisterHandler(nil, protocol.ConnStatePlay, protocol.ConnS2C, protocol.ClientboundRespawn, &protocol.Respawn{}, 0, HandleRespawn)
+	return tunnel
+}
+
+// chunkData encodes a ground-up continuous chunk column with the given block states in 1.8 format
+func chunkData(bitMask uint16, skyLight bool, states map[[3]int]uint16) []byte {
+	data := make([]byte, minecraft.ChunkDataLen(bitMask, skyLight, true))
+
+	offset := 0
+	for section := 0; section < minecraft.ChunkSectionCount; section++ {
+		if bitMask&(1<<section) == 0 {
+			continue
+		}
+
+		for position, state := range states {
+			if position[1]>>4 != section {
+				continue
+			}
+
+			index := (position[1]&15)<<8 | position[2]<<4 | position[0]
+			binary.LittleEndian.PutUint16(data[offset+index*2:], state)
+		}
+
+		offset += 8192
+	}
+
+	return data
+}
+
+func handleS2C(t *testing.T, tunnel *MinecraftTunnel, packet protocol.Packet) {
+	result, err := tunnel.HandlerRegistry.Handle(packet.Marshal(), protocol.ConnS2C)
+	assert.NoError(t, err)
+	assert.True(t, result.ShouldPass)
+}
+
+func TestWorldHandler(t *testing.T) {
+	tunnel := newWorldTunnel()
+	world := tunnel.WorldHandler
+
+	handleS2C(t, tunnel, &protocol.ChunkData{
+		ChunkX:             -1,
+		ChunkZ:             2,
+		GroundUpContinuous: true,
+		PrimaryBitMask:     1<<4 | 1<<5,
+		Data: chunkData(1<<4|1<<5, true, map[[3]int]uint16{
+			{1, 65, 2}:  uint16(blocks.Stone.ID) << 4,
+			{15, 80, 0}: uint16(blocks.Planks.ID)<<4 | 2,
+		}),
+	})
+
+	assert.True(t, world.IsLoaded(pk.Position{X: -15, Y: 65, Z: 34}))
+	assert.Equal(t, blocks.Stone, world.GetBlock(pk.Position{X: -15, Y: 65, Z: 34}))
+	assert.Equal(t, blocks.Planks, world.GetBlock(pk.Position{X: -1, Y: 80, Z: 32}))
+	assert.Equal(t, blocks.Air, world.GetBlock(pk.Position{X: -15, Y: 10, Z: 34}))
+	assert.False(t, world.IsLoaded(pk.Position{X: 0, Y: 65, Z: 34}))
+
+	state, ok := world.GetBlockState(pk.Position{X: -1, Y: 80, Z: 32})
+	assert.True(t, ok)
+	assert.Equal(t, uint16(blocks.Planks.ID)<<4|2, state)
+
+	// Block changes
+	handleS2C(t, tunnel, &protocol.BlockChange{Location: pk.Position{X: -15, Y: 10, Z: 34}, BlockID: pk.VarInt(blocks.Dirt.ID) << 4})
+	assert.Equal(t, blocks.Dirt, world.GetBlock(pk.Position{X: -15, Y: 10, Z: 34}))
+
+	handleS2C(t, tunnel, &protocol.MultiBlockChange{ChunkX: -1, ChunkZ: 2, Records: []pk.BlockRecord{
+		{HorizontalPosition: 1<<4 | 2, Y: 65, BlockID: 0},
+		{HorizontalPosition: 3<<4 | 4, Y: 66, BlockID: pk.VarInt(blocks.Cobblestone.ID) << 4},
+	}})
+	assert.Equal(t, blocks.Air, world.GetBlock(pk.Position{X: -15, Y: 65, Z: 34}))
+	assert.Equal(t, blocks.Cobblestone, world.GetBlock(pk.Position{X: -13, Y: 66, Z: 36}))
+
+	handleS2C(t, tunnel, &protocol.Explosion{X: -12.5, Y: 66.2, Z: 36.7, Records: []pk.ExplosionRecord{{X: -1, Y: 0, Z: 0}}})
+	assert.Equal(t, blocks.Air, world.GetBlock(pk.Position{X: -13, Y: 66, Z: 36}))
+
+	// Partial update keeps the other sections
+	handleS2C(t, tunnel, &protocol.ChunkData{
+		ChunkX:         -1,
+		ChunkZ:         2,
+		PrimaryBitMask: 1 << 4,
+		Data:           chunkData(1<<4, true, nil)[:minecraft.ChunkDataLen(1<<4, true, false)],
+	})
+	assert.Equal(t, blocks.Planks, world.GetBlock(pk.Position{X: -1, Y: 80, Z: 32}))
+	assert.Equal(t, blocks.Air, world.GetBlock(pk.Position{X: -15, Y: 65, Z: 34}))
+
+	// Unload
+	handleS2C(t, tunnel, &protocol.ChunkData{ChunkX: -1, ChunkZ: 2, GroundUpContinuous: true})
+	assert.False(t, world.IsLoaded(pk.Position{X: -1, Y: 80, Z: 32}))
+	assert.Equal(t, blocks.Air, world.GetBlock(pk.Position{X: -1, Y: 80, Z: 32}))
+}
+
+func TestWorldHandler_Biomes(t *testing.T) {
+	tunnel := newWorldTunnel()
+	world := tunnel.WorldHandler
+
+	// Biomes of the column go after the sections, indexed by z << 4 | x
+	data := chunkData(1, true, nil)
+	data[len(data)-256+(2<<4|1)] = byte(biomes.Desert.ID)
+	handleS2C(t, tunnel, &protocol.ChunkData{ChunkX: -1, ChunkZ: 2, GroundUpContinuous: true, PrimaryBitMask: 1, Data: data})
+
+	biome, ok := world.GetBiome(pk.Position{X: -15, Y: 64, Z: 34})
+	assert.True(t, ok)
+	assert.Equal(t, biomes.Desert, biome)
+	biome, _ = world.GetBiome(pk.Position{X: -16, Y: 64, Z: 34})
+	assert.Equal(t, biomes.Ocean, biome)
+
+	// Partial updates do not carry biomes
+	handleS2C(t, tunnel, &protocol.ChunkData{ChunkX: -1, ChunkZ: 2, PrimaryBitMask: 1, Data: data[:minecraft.ChunkDataLen(1, true, false)]})
+	biome, _ = world.GetBiome(pk.Position{X: -15, Y: 64, Z: 34})
+	assert.Equal(t, biomes.Desert, biome)
+
+	_, ok = world.GetBiome(pk.Position{X: 0, Y: 64, Z: 34})
+	assert.False(t, ok)
+}
+
+func TestWorldHandler_MapChunkBulk(t *testing.T) {
+	tunnel := newWorldTunnel()
+	world := tunnel.WorldHandler
+	world.Reset(-1)
+
+	data := append(
+		chunkData(1, false, map[[3]int]uint16{{0, 0, 0}: uint16(blocks.Bedrock.ID) << 4}),
+		chunkData(1<<1|1<<15, false, map[[3]int]uint16{{5, 255, 5}: uint16(blocks.Glass.ID) << 4})...,
+	)
+
+	handleS2C(t, tunnel, &protocol.MapChunkBulk{
+		Meta: []pk.ChunkMeta{{ChunkX: 0, ChunkZ: 0, PrimaryBitMask: 1}, {ChunkX: 1, ChunkZ: 0, PrimaryBitMask: 1<<1 | 1<<15}},
+		Data: data,
+	})
+
+	assert.Equal(t, blocks.Bedrock, world.GetBlock(pk.Position{X: 0, Y: 0, Z: 0}))
+	assert.Equal(t, blocks.Glass, world.GetBlock(pk.Position{X: 21, Y: 255, Z: 5}))
+	assert.Equal(t, blocks.Air, world.GetBlock(pk.Position{X: 21, Y: 256, Z: 5}))
+
+	// Respawn in the same dimension keeps the chunks
+	handleS2C(t, tunnel, &protocol.Respawn{Dimension: -1})
+	assert.True(t, world.IsLoaded(pk.Position{X: 0, Y: 0, Z: 0}))
+
+	handleS2C(t, tunnel, &protocol.Respawn{Dimension: 0})
+	assert.Equal(t, 0, world.GetDimension())
+	assert.False(t, world.IsLoaded(pk.Position{X: 0, Y: 0, Z: 0}))
+}
+
+func TestWorldHandler_ChunkDataTooShort(t *testing.T) {
+	tunnel := newWorldTunnel()
+
+	// Malformed chunks are not loaded, the packets still reach the client
+	handleS2C(t, tunnel, &protocol.ChunkData{GroundUpContinuous: true, PrimaryBitMask: 1, Data: make([]byte, 100)})
+	assert.False(t, tunnel.WorldHandler.IsLoaded(pk.Position{}))
+
+	handleS2C(t, tunnel, &protocol.MapChunkBulk{
+		SkyLightSent: true,
+		Meta:         []pk.ChunkMeta{{ChunkX: 0, ChunkZ: 0, PrimaryBitMask: 1}},
+		Data:         make([]byte, 100),
+	})
+	assert.False(t, tunnel.WorldHandler.IsLoaded(pk.Position{}))
+
+	_, err := tunnel.WorldHandler.LoadChunk(0, 0, make([]byte, 100), 1, true, true)
+	assert.ErrorIs(t, err, minecraft.ErrChunkDataTooShort)
+}
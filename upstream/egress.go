@@ -0,0 +1,338 @@
+package upstream
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	stdnet "net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/destructiqn/kogtevran/metrics"
+	"h12.io/socks"
+)
+
+var ErrEgress = errors.New("egress failed")
+
+// EgressError is returned when the outbound proxy of a session fails, the node behind it may be up
+type EgressError struct {
+	Egress string
+	Err    error
+}
+
+func (e *EgressError) Error() string {
+	return fmt.Sprintf("through egress %s: %v", e.Egress, e.Err)
+}
+
+func (e *EgressError) Unwrap() error {
+	return e.Err
+}
+
+func (e *EgressError) Is(target error) bool {
+	return target == ErrEgress
+}
+
+// stickyExpiry is how long a session key keeps its egress after its last dial
+const stickyExpiry = time.Hour
+
+// Dialer connects to a node, directly or through an outbound proxy
+type Dialer func(network, address string) (stdnet.Conn, error)
+
+// Session is who is being connected, it is known once the client has started the login
+type Session struct {
+	Username string
+	// License is the ID of the license of the session, empty when it has none
+	License string
+}
+
+// DialerStrategy picks the dialer of a session, fallback is the one of its route
+type DialerStrategy interface {
+	Dialer(session Session, fallback Dialer) Dialer
+}
+
+// DirectStrategy connects every session with the dialer of its route
+type DirectStrategy struct{}
+
+func (DirectStrategy) Dialer(_ Session, fallback Dialer) Dialer {
+	return fallback
+}
+
+// Egress is an outbound proxy connections to the nodes go through
+type Egress struct {
+	Name string
+	Dial Dialer
+}
+
+// NewEgress returns the proxy at the address, the protocol is socks4, socks4a, socks5 or http.
+// Connecting through it fails after the timeout
+func NewEgress(name, address, protocol string, timeout time.Duration) (*Egress, error) {
+	switch protocol {
+	case "socks4", "socks4a", "socks5":
+		return &Egress{Name: name, Dial: socks.Dial(fmt.Sprintf("%s://%s?timeout=%s", protocol, address, timeout))}, nil
+	case "http":
+		return &Egress{Name: name, Dial: dialHTTP(address, timeout)}, nil
+	}
+
+	return nil, fmt.Errorf("unknown proxy protocol %q", protocol)
+}
+
+// dialHTTP connects through the HTTP proxy at proxyAddress with the CONNECT method
+func dialHTTP(proxyAddress string, timeout time.Duration) Dialer {
+	return func(network, address string) (stdnet.Conn, error) {
+		conn, err := stdnet.DialTimeout(network, proxyAddress, timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+
+		request := &http.Request{
+			Method: http.MethodConnect,
+			URL:    &url.URL{Opaque: address},
+			Host:   address,
+			Header: make(http.Header),
+		}
+
+		if err = request.Write(conn); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+
+		reader := bufio.NewReader(conn)
+		response, err := http.ReadResponse(reader, request)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		_ = response.Body.Close()
+
+		if response.StatusCode != http.StatusOK {
+			_ = conn.Close()
+			return nil, fmt.Errorf("proxy %s refused to connect to %s: %s", proxyAddress, address, response.Status)
+		}
+
+		if err = conn.SetDeadline(time.Time{}); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+}
+
+// bufferedConn reads what the proxy has sent along with its response first
+type bufferedConn struct {
+	stdnet.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// SessionKey is what sessions keep their egress by
+type SessionKey int
+
+const (
+	KeyUsername SessionKey = iota
+	// KeyLicense keys sessions by license ID, those without a license are keyed by username
+	KeyLicense
+)
+
+func ParseSessionKey(name string) (SessionKey, error) {
+	switch name {
+	case "username":
+		return KeyUsername, nil
+	case "license":
+		return KeyLicense, nil
+	}
+
+	return KeyUsername, fmt.Errorf("unknown session key %q", name)
+}
+
+// EgressPool spreads sessions over outbound proxies. A session sticks to its egress while it works,
+// an egress which fails to connect is skipped for the cooldown and its sessions rotate to the next one
+type EgressPool struct {
+	lock        sync.Mutex
+	egresses    []*Egress
+	key         SessionKey
+	assignments map[string][]string
+	cooldown    time.Duration
+
+	// sticky is the egress of a session key, down is until when an egress is skipped
+	sticky map[string]stickyEgress
+	down   map[string]time.Time
+	pruned time.Time
+	now    func() time.Time
+}
+
+type stickyEgress struct {
+	name string
+	used time.Time
+}
+
+func NewEgressPool() *EgressPool {
+	return &EgressPool{
+		sticky: make(map[string]stickyEgress),
+		down:   make(map[string]time.Time),
+		now:    time.Now,
+	}
+}
+
+// Update replaces the egresses, assignments limit session keys to the named egresses. Sessions keep
+// their egress when it stays
+func (p *EgressPool) Update(egresses []*Egress, key SessionKey, assignments map[string][]string, cooldown time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	names := make(map[string]bool, len(egresses))
+	for _, egress := range egresses {
+		names[egress.Name] = true
+	}
+
+	for session, sticky := range p.sticky {
+		if !names[sticky.name] {
+			delete(p.sticky, session)
+		}
+	}
+
+	for name := range p.down {
+		if !names[name] {
+			delete(p.down, name)
+		}
+	}
+
+	// Sessions keyed differently are assigned again
+	if key != p.key {
+		p.sticky = make(map[string]stickyEgress)
+	}
+
+	p.egresses, p.key, p.assignments, p.cooldown = egresses, key, assignments, cooldown
+}
+
+func (p *EgressPool) Dialer(session Session, fallback Dialer) Dialer {
+	p.lock.Lock()
+	key := session.Username
+	if p.key == KeyLicense && session.License != "" {
+		key = session.License
+	}
+	p.lock.Unlock()
+
+	return func(network, address string) (stdnet.Conn, error) {
+		egress := p.assign(key)
+		if egress == nil {
+			return fallback(network, address)
+		}
+
+		conn, err := egress.Dial(network, address)
+		if err != nil {
+			p.fail(egress)
+			return nil, &EgressError{Egress: egress.Name, Err: err}
+		}
+
+		return conn, nil
+	}
+}
+
+// Egress returns the egress the session key sticks to, if it has been assigned one
+func (p *EgressPool) Egress(key string) (string, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	sticky, ok := p.sticky[key]
+	return sticky.name, ok
+}
+
+// assign returns the egress of the session key. It is the one the key sticks to unless that one is down,
+// the next egress which is up is taken then. Keys new to the pool are spread by their hash
+func (p *EgressPool) assign(key string) *Egress {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := p.now()
+	p.prune(now)
+
+	candidates := p.egresses
+	if names, ok := p.assignments[key]; ok {
+		candidates = make([]*Egress, 0, len(names))
+		for _, egress := range p.egresses {
+			if contains(names, egress.Name) {
+				candidates = append(candidates, egress)
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	start := -1
+	for i, egress := range candidates {
+		if egress.Name == p.sticky[key].name {
+			start = i
+			break
+		}
+	}
+
+	if start < 0 {
+		hash := fnv.New32a()
+		_, _ = hash.Write([]byte(key))
+		start = int(hash.Sum32() % uint32(len(candidates)))
+	}
+
+	egress := candidates[start]
+	for i := range candidates {
+		candidate := candidates[(start+i)%len(candidates)]
+		if !now.Before(p.down[candidate.Name]) {
+			egress = candidate
+			break
+		}
+	}
+
+	// When every egress is down the one of the session is tried anyway, the cooldown may be behind
+	p.sticky[key] = stickyEgress{name: egress.Name, used: now}
+	return egress
+}
+
+// prune forgets the keys which have not dialed for stickyExpiry, it runs once in that time. Forgotten keys
+// are spread by their hash again, so most of them get the same egress
+func (p *EgressPool) prune(now time.Time) {
+	if now.Sub(p.pruned) < stickyExpiry {
+		return
+	}
+
+	for key, sticky := range p.sticky {
+		if now.Sub(sticky.used) >= stickyExpiry {
+			delete(p.sticky, key)
+		}
+	}
+
+	p.pruned = now
+}
+
+func (p *EgressPool) fail(egress *Egress) {
+	metrics.EgressDialFailures.WithLabelValues(egress.Name).Inc()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if !p.now().Before(p.down[egress.Name]) {
+		log.Println("egress", egress.Name, "is down, its sessions are rotated for", p.cooldown)
+	}
+	p.down[egress.Name] = p.now().Add(p.cooldown)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
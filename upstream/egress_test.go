@@ -0,0 +1,234 @@
+package upstream
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	stdnet "net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newEgresses returns egresses which fail while they are in down, dials through them are recorded
+func newEgresses(down map[string]bool, names ...string) ([]*Egress, *[]string) {
+	dialed := new([]string)
+	egresses := make([]*Egress, 0, len(names))
+	for _, name := range names {
+		name := name
+		egresses = append(egresses, &Egress{Name: name, Dial: func(network, address string) (stdnet.Conn, error) {
+			if down[name] {
+				return nil, errors.New("connection refused")
+			}
+
+			*dialed = append(*dialed, name)
+			server, client := stdnet.Pipe()
+			_ = server.Close()
+			return client, nil
+		}})
+	}
+
+	return egresses, dialed
+}
+
+func TestEgressPool_Sticky(t *testing.T) {
+	pool := NewEgressPool()
+	egresses, dialed := newEgresses(nil, "a", "b", "c")
+	pool.Update(egresses, KeyUsername, nil, time.Minute)
+
+	dial := pool.Dialer(Session{Username: "Steve"}, nil)
+	for i := 0; i < 3; i++ {
+		_, err := dial("tcp", "node:25565")
+		assert.NoError(t, err)
+	}
+
+	name, ok := pool.Egress("Steve")
+	assert.True(t, ok)
+	assert.Equal(t, []string{name, name, name}, *dialed)
+
+	// A later session of the player keeps the egress
+	_, err := pool.Dialer(Session{Username: "Steve"}, nil)("tcp", "node:25565")
+	assert.NoError(t, err)
+	assert.Equal(t, name, (*dialed)[3])
+
+	used := make(map[string]bool)
+	for _, username := range []string{"Alex", "Notch", "jeb_", "Dinnerbone", "Grumm", "Searge", "Marc", "Jens"} {
+		_, err = pool.Dialer(Session{Username: username}, nil)("tcp", "node:25565")
+		assert.NoError(t, err)
+		name, _ = pool.Egress(username)
+		used[name] = true
+	}
+	assert.True(t, len(used) > 1)
+}
+
+func TestEgressPool_StickyExpiry(t *testing.T) {
+	now := time.Now()
+	pool := NewEgressPool()
+	pool.now = func() time.Time { return now }
+	egresses, _ := newEgresses(nil, "a", "b")
+	pool.Update(egresses, KeyUsername, nil, time.Minute)
+
+	for _, username := range []string{"Steve", "Alex"} {
+		_, err := pool.Dialer(Session{Username: username}, nil)("tcp", "node:25565")
+		assert.NoError(t, err)
+	}
+
+	// Keys which keep dialing stay, the others are forgotten
+	now = now.Add(stickyExpiry / 2)
+	_, err := pool.Dialer(Session{Username: "Steve"}, nil)("tcp", "node:25565")
+	assert.NoError(t, err)
+
+	now = now.Add(stickyExpiry)
+	_, err = pool.Dialer(Session{Username: "Steve"}, nil)("tcp", "node:25565")
+	assert.NoError(t, err)
+
+	_, ok := pool.Egress("Steve")
+	assert.True(t, ok)
+	_, ok = pool.Egress("Alex")
+	assert.False(t, ok)
+	assert.Len(t, pool.sticky, 1)
+}
+
+func TestEgressPool_Rotation(t *testing.T) {
+	now := time.Now()
+	pool := NewEgressPool()
+	pool.now = func() time.Time { return now }
+
+	down := make(map[string]bool)
+	egresses, dialed := newEgresses(down, "a", "b", "c")
+	pool.Update(egresses, KeyUsername, nil, time.Minute)
+
+	dial := pool.Dialer(Session{Username: "Steve"}, nil)
+	_, err := dial("tcp", "node:25565")
+	assert.NoError(t, err)
+	first, _ := pool.Egress("Steve")
+
+	down[first] = true
+	_, err = dial("tcp", "node:25565")
+	assert.True(t, errors.Is(err, ErrEgress))
+	assert.Contains(t, err.Error(), first)
+
+	// The next dial of the session rotates to the next egress, which it sticks to afterwards
+	_, err = dial("tcp", "node:25565")
+	assert.NoError(t, err)
+	second, _ := pool.Egress("Steve")
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, []string{first, second}, *dialed)
+
+	down[first] = false
+	now = now.Add(2 * time.Minute)
+	_, err = dial("tcp", "node:25565")
+	assert.NoError(t, err)
+	assert.Equal(t, second, (*dialed)[2])
+
+	// The egress of the session is tried when every one of them is down
+	for _, egress := range egresses {
+		down[egress.Name] = true
+		pool.fail(egress)
+	}
+	_, err = dial("tcp", "node:25565")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), second)
+}
+
+func TestEgressPool_Assignments(t *testing.T) {
+	pool := NewEgressPool()
+	egresses, dialed := newEgresses(map[string]bool{"c": true}, "a", "b", "c")
+	pool.Update(egresses, KeyLicense, map[string][]string{"Notch": {"c"}, "license": {"b"}}, time.Minute)
+
+	// Sessions with the same license share the egress, those without one are keyed by username
+	for _, session := range []Session{{"Steve", "license"}, {"Alex", "license"}} {
+		_, err := pool.Dialer(session, nil)("tcp", "node:25565")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"b", "b"}, *dialed)
+
+	_, err := pool.Dialer(Session{Username: "Notch"}, nil)("tcp", "node:25565")
+	assert.Error(t, err)
+	name, _ := pool.Egress("Notch")
+	assert.Equal(t, "c", name)
+
+	// Removed egresses are not assigned anymore, the direct dialer applies without any
+	pool.Update(egresses[:2], KeyLicense, map[string][]string{"Notch": {"c"}}, time.Minute)
+	var fallback bool
+	_, err = pool.Dialer(Session{Username: "Notch"}, func(network, address string) (stdnet.Conn, error) {
+		fallback = true
+		return nil, errors.New("direct")
+	})("tcp", "node:25565")
+	assert.Error(t, err)
+	assert.True(t, fallback)
+	_, ok := pool.Egress("Notch")
+	assert.False(t, ok)
+}
+
+func TestDirectStrategy(t *testing.T) {
+	egresses, dialed := newEgresses(nil, "a")
+	dial := DirectStrategy{}.Dialer(Session{Username: "Steve"}, egresses[0].Dial)
+	_, err := dial("tcp", "node:25565")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, *dialed)
+}
+
+// listenHTTPProxy starts an HTTP proxy on loopback which accepts CONNECT to the address only, it echoes
+// what it is sent through the tunnel
+func listenHTTPProxy(t *testing.T, address string) string {
+	listener, err := stdnet.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("loopback is not available:", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			socket, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer socket.Close()
+				reader := bufio.NewReader(socket)
+				request, err := http.ReadRequest(reader)
+				if err != nil {
+					return
+				}
+
+				if request.Method != http.MethodConnect || request.Host != address {
+					_, _ = io.WriteString(socket, "HTTP/1.1 403 Forbidden\r\n\r\n")
+					return
+				}
+
+				_, _ = io.WriteString(socket, "HTTP/1.1 200 Connection established\r\n\r\nhello")
+				_, _ = io.Copy(socket, reader)
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestNewEgress_HTTP(t *testing.T) {
+	egress, err := NewEgress("http", listenHTTPProxy(t, "node:25565"), "http", time.Second)
+	assert.NoError(t, err)
+
+	conn, err := egress.Dial("tcp", "node:25565")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	// What the proxy sends along with its response is read first
+	_, err = io.WriteString(conn, "ping")
+	assert.NoError(t, err)
+	buffer := make([]byte, len("helloping"))
+	_, err = io.ReadFull(conn, buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, "helloping", string(buffer))
+
+	_, err = egress.Dial("tcp", "other:25565")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+
+	_, err = NewEgress("ftp", "127.0.0.1:21", "ftp", time.Second)
+	assert.Error(t, err)
+}
@@ -0,0 +1,103 @@
+package upstream
+
+import (
+	"errors"
+	"fmt"
+	stdnet "net"
+	"strconv"
+	"time"
+
+	"github.com/destructiqn/kogtevran/minecraft/net"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/destructiqn/kogtevran/minecraft/protocol"
+)
+
+var ErrUnexpectedPacket = errors.New("unexpected packet")
+
+// Ping runs the status ping with the server, the way the server list does. It returns the round trip
+// of the ping packet, the whole exchange has to be done within the timeout. The server is dialed with dial,
+// so that it is checked the way sessions reach it, nil dials it directly
+func Ping(address string, timeout time.Duration, dial Dialer) (time.Duration, error) {
+	host, rawPort, err := stdnet.SplitHostPort(address)
+	if err != nil {
+		return 0, err
+	}
+
+	port, err := strconv.Atoi(rawPort)
+	if err != nil {
+		return 0, err
+	}
+
+	if dial == nil {
+		dial = (&stdnet.Dialer{Timeout: timeout}).Dial
+	}
+
+	socket, err := dial("tcp", address)
+	if err != nil {
+		return 0, err
+	}
+	defer socket.Close()
+
+	if err = socket.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return 0, err
+	}
+
+	conn := net.WrapConn(socket)
+	conn.SetLimits(protocol.GetLimits(protocol.ConnStateStatus))
+
+	handshake := &protocol.Handshake{
+		ProtocolVersion: pk.VarInt(protocol.DefaultVersion.Protocol),
+		ServerAddress:   pk.String(host),
+		ServerPort:      pk.UnsignedShort(port),
+		NextState:       pk.VarInt(protocol.ConnStateStatus),
+	}
+
+	if err = writePackets(conn, handshake.Marshal(), (&protocol.StatusRequest{}).Marshal()); err != nil {
+		return 0, err
+	}
+
+	var response protocol.StatusResponse
+	if err = readPacket(conn, protocol.ClientboundStatusResponse, &response); err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	ping := &protocol.StatusPing{Payload: pk.Long(start.UnixNano())}
+	if err = writePackets(conn, ping.Marshal()); err != nil {
+		return 0, err
+	}
+
+	var pong protocol.StatusPong
+	if err = readPacket(conn, protocol.ClientboundStatusPong, &pong); err != nil {
+		return 0, err
+	}
+
+	if pong.Payload != ping.Payload {
+		return 0, fmt.Errorf("%w: pong with payload %d instead of %d", ErrUnexpectedPacket, pong.Payload, ping.Payload)
+	}
+
+	return time.Since(start), nil
+}
+
+func writePackets(conn *net.Conn, packets ...pk.Packet) error {
+	for _, packet := range packets {
+		if err := conn.WritePacket(packet); err != nil {
+			return err
+		}
+	}
+
+	return conn.Flush()
+}
+
+func readPacket(conn *net.Conn, id int32, packet protocol.Packet) error {
+	var raw pk.Packet
+	if err := conn.ReadPacket(&raw); err != nil {
+		return err
+	}
+
+	if raw.ID != id {
+		return fmt.Errorf("%w: %#x instead of %#x", ErrUnexpectedPacket, raw.ID, id)
+	}
+
+	return packet.Read(raw)
+}
@@ -0,0 +1,333 @@
+// Package upstream keeps the nodes of the servers the proxy connects players to, routed by hostname.
+// Nodes are health checked with the status ping, sessions are spread over the healthy ones.
+package upstream
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/destructiqn/kogtevran/metrics"
+	"github.com/destructiqn/kogtevran/minecraft/net"
+)
+
+var ErrNoUpstream = errors.New("no upstream node is available")
+
+// Strategy decides which of the healthy nodes a session is connected to
+type Strategy int
+
+const (
+	// StrategyWeighted picks a random node, nodes with a higher weight are picked proportionally more often
+	StrategyWeighted Strategy = iota
+	// StrategyLeastConnections picks the node with the fewest sessions relative to its weight
+	StrategyLeastConnections
+)
+
+func ParseStrategy(name string) (Strategy, error) {
+	switch name {
+	case "weighted":
+		return StrategyWeighted, nil
+	case "leastConnections":
+		return StrategyLeastConnections, nil
+	}
+
+	return StrategyWeighted, fmt.Errorf("unknown upstream strategy %q", name)
+}
+
+func (s Strategy) String() string {
+	if s == StrategyLeastConnections {
+		return "leastConnections"
+	}
+
+	return "weighted"
+}
+
+// Target describes a node of the pool, nodes with zero weight are not picked for new sessions
+type Target struct {
+	Address string
+	Weight  int
+}
+
+type Node struct {
+	Address string
+	// pool is the name of the pool the node is in, the metrics of the node are labeled with it
+	pool string
+
+	weight  int32
+	healthy int32
+	removed int32
+	active  int64
+}
+
+func (n *Node) Weight() int {
+	return int(atomic.LoadInt32(&n.weight))
+}
+
+func (n *Node) IsHealthy() bool {
+	return atomic.LoadInt32(&n.healthy) == 1
+}
+
+// Active returns the number of sessions connected through the node
+func (n *Node) Active() int {
+	return int(atomic.LoadInt64(&n.active))
+}
+
+// Release ends a session connected through the node by Pool.Dial
+func (n *Node) Release() {
+	active := atomic.AddInt64(&n.active, -1)
+	if atomic.LoadInt32(&n.removed) == 0 {
+		metrics.UpstreamConnections.WithLabelValues(n.pool, n.Address).Set(float64(active))
+	}
+}
+
+func (n *Node) setHealthy(healthy bool, reason error) {
+	value := int32(0)
+	if healthy {
+		value = 1
+	}
+
+	if atomic.SwapInt32(&n.healthy, value) != value {
+		if healthy {
+			log.Println("upstream", n.Address, "of", n.pool, "is up")
+		} else {
+			log.Println("upstream", n.Address, "of", n.pool, "is down:", reason)
+		}
+	}
+
+	if atomic.LoadInt32(&n.removed) == 0 {
+		metrics.UpstreamHealthy.WithLabelValues(n.pool, n.Address).Set(float64(value))
+	}
+}
+
+// Pool is a set of nodes, it can be updated while sessions are being connected
+type Pool struct {
+	name     string
+	lock     sync.RWMutex
+	nodes    []*Node
+	strategy Strategy
+	interval time.Duration
+	timeout  time.Duration
+	// dial is the dialer of the route, the health checks reach the nodes through it
+	dial Dialer
+
+	random   *rand.Rand
+	randLock sync.Mutex
+	done     chan struct{}
+	once     sync.Once
+}
+
+// NewPool returns an empty pool, the name tells it apart from the pools of other routes
+func NewPool(name string) *Pool {
+	return &Pool{
+		name:   name,
+		random: rand.New(rand.NewSource(time.Now().UnixNano())),
+		done:   make(chan struct{}),
+	}
+}
+
+// Update replaces the nodes of the pool. Nodes which stay keep their sessions and health,
+// new ones are healthy until they fail a check or a dial
+func (p *Pool) Update(targets []Target, strategy Strategy, interval, timeout time.Duration, dial Dialer) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	existing := make(map[string]*Node, len(p.nodes))
+	for _, node := range p.nodes {
+		existing[node.Address] = node
+	}
+
+	nodes := make([]*Node, 0, len(targets))
+	for _, target := range targets {
+		node, ok := existing[target.Address]
+		if ok {
+			delete(existing, target.Address)
+		} else {
+			node = &Node{Address: target.Address, pool: p.name, healthy: 1}
+			metrics.UpstreamHealthy.WithLabelValues(node.pool, node.Address).Set(1)
+			metrics.UpstreamConnections.WithLabelValues(node.pool, node.Address).Set(0)
+		}
+
+		atomic.StoreInt32(&node.weight, int32(target.Weight))
+		nodes = append(nodes, node)
+	}
+
+	// Sessions of removed nodes are left running, they are not counted anymore
+	for address, node := range existing {
+		atomic.StoreInt32(&node.removed, 1)
+		metrics.UpstreamHealthy.DeleteLabelValues(p.name, address)
+		metrics.UpstreamConnections.DeleteLabelValues(p.name, address)
+		metrics.UpstreamLatency.DeleteLabelValues(p.name, address)
+	}
+
+	p.nodes, p.strategy, p.interval, p.timeout, p.dial = nodes, strategy, interval, timeout, dial
+}
+
+func (p *Pool) Nodes() []*Node {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return append([]*Node(nil), p.nodes...)
+}
+
+// pick returns a node which has not been tried yet, healthy nodes first. When none of them is left,
+// the others are tried as well, the health checks may be behind
+func (p *Pool) pick(tried map[*Node]bool) *Node {
+	p.lock.RLock()
+	nodes, strategy := p.nodes, p.strategy
+	p.lock.RUnlock()
+
+	candidates := make([]*Node, 0, len(nodes))
+	for _, node := range nodes {
+		if !tried[node] && node.IsHealthy() && node.Weight() > 0 {
+			candidates = append(candidates, node)
+		}
+	}
+
+	if len(candidates) == 0 {
+		for _, node := range nodes {
+			if !tried[node] && node.Weight() > 0 {
+				candidates = append(candidates, node)
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	if strategy == StrategyLeastConnections {
+		best := candidates[0]
+		for _, node := range candidates[1:] {
+			// active / weight is compared without dividing
+			if node.Active()*best.Weight() < best.Active()*node.Weight() {
+				best = node
+			}
+		}
+
+		return best
+	}
+
+	total := 0
+	for _, node := range candidates {
+		total += node.Weight()
+	}
+
+	p.randLock.Lock()
+	n := p.random.Intn(total)
+	p.randLock.Unlock()
+
+	for _, node := range candidates {
+		n -= node.Weight()
+		if n < 0 {
+			return node
+		}
+	}
+
+	return candidates[len(candidates)-1]
+}
+
+// Dial connects to a node of the pool, a node which cannot be connected to is marked as down
+// and the next one is tried. Failures of the egress of the session leave the node as it is.
+// The node counts the session until it is released
+func (p *Pool) Dial(dial func(address string) (*net.Conn, error)) (*net.Conn, *Node, error) {
+	tried := make(map[*Node]bool)
+	var lastErr error
+	for {
+		node := p.pick(tried)
+		if node == nil {
+			break
+		}
+
+		tried[node] = true
+		conn, err := dial(node.Address)
+		if errors.Is(err, ErrEgress) {
+			// The egress pool rotates the session to another egress, the node is not to blame
+			lastErr = err
+			continue
+		}
+
+		if err != nil {
+			metrics.UpstreamDialFailures.WithLabelValues(node.pool, node.Address).Inc()
+			node.setHealthy(false, err)
+			lastErr = err
+			continue
+		}
+
+		active := atomic.AddInt64(&node.active, 1)
+		if atomic.LoadInt32(&node.removed) == 0 {
+			metrics.UpstreamConnections.WithLabelValues(node.pool, node.Address).Set(float64(active))
+		}
+
+		return conn, node, nil
+	}
+
+	if lastErr != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrNoUpstream, lastErr)
+	}
+
+	return nil, nil, ErrNoUpstream
+}
+
+// Check pings all of the nodes at once through the dialer of the route and waits for the results
+func (p *Pool) Check() {
+	p.lock.RLock()
+	nodes, timeout, dial := p.nodes, p.timeout, p.dial
+	p.lock.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(node *Node) {
+			defer wg.Done()
+
+			latency, err := Ping(node.Address, timeout, dial)
+			if err != nil {
+				metrics.UpstreamHealthChecks.WithLabelValues(node.pool, node.Address, "failure").Inc()
+				node.setHealthy(false, err)
+				return
+			}
+
+			metrics.UpstreamHealthChecks.WithLabelValues(node.pool, node.Address, "success").Inc()
+			if atomic.LoadInt32(&node.removed) == 0 {
+				metrics.UpstreamLatency.WithLabelValues(node.pool, node.Address).Set(latency.Seconds())
+			}
+			node.setHealthy(true, nil)
+		}(node)
+	}
+
+	wg.Wait()
+}
+
+// RunHealthChecks checks the nodes on the interval of the pool until it is closed
+func (p *Pool) RunHealthChecks() {
+	for {
+		p.Check()
+
+		p.lock.RLock()
+		interval := p.interval
+		p.lock.RUnlock()
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+		case <-p.done:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// Close stops the health checks and removes the nodes, sessions connected through them are left running
+func (p *Pool) Close() {
+	p.once.Do(func() {
+		close(p.done)
+
+		p.lock.RLock()
+		strategy, interval, timeout, dial := p.strategy, p.interval, p.timeout, p.dial
+		p.lock.RUnlock()
+		p.Update(nil, strategy, interval, timeout, dial)
+	})
+}
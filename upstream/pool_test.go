@@ -0,0 +1,208 @@
+package upstream
+
+import (
+	"errors"
+	stdnet "net"
+	"testing"
+	"time"
+
+	"github.com/destructiqn/kogtevran/minecraft/net"
+	pk "github.com/destructiqn/kogtevran/minecraft/net/packet"
+	"github.com/destructiqn/kogtevran/minecraft/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+// listenStatus starts a server answering status pings on loopback, answer decides whether it responds at all
+func listenStatus(t *testing.T, answer bool) string {
+	listener, err := stdnet.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("loopback is not available:", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			socket, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer socket.Close()
+				if answer {
+					serveStatus(net.WrapConn(socket))
+				} else {
+					_, _ = socket.Read(make([]byte, 1))
+				}
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func serveStatus(conn *net.Conn) {
+	var handshake protocol.Handshake
+	var packet pk.Packet
+	if conn.ReadPacket(&packet) != nil || handshake.Read(packet) != nil || handshake.NextState != pk.VarInt(protocol.ConnStateStatus) {
+		return
+	}
+
+	if conn.ReadPacket(&packet) != nil || packet.ID != protocol.ServerboundStatusRequest {
+		return
+	}
+
+	response := &protocol.StatusResponse{Response: `{"version":{"name":"1.8.9","protocol":47}}`}
+	if writePackets(conn, response.Marshal()) != nil {
+		return
+	}
+
+	var ping protocol.StatusPing
+	if conn.ReadPacket(&packet) != nil || ping.Read(packet) != nil {
+		return
+	}
+
+	_ = writePackets(conn, (&protocol.StatusPong{Payload: ping.Payload}).Marshal())
+}
+
+func TestPing(t *testing.T) {
+	latency, err := Ping(listenStatus(t, true), time.Second, nil)
+	assert.NoError(t, err)
+	assert.True(t, latency > 0)
+
+	_, err = Ping(listenStatus(t, false), 50*time.Millisecond, nil)
+	assert.Error(t, err)
+
+	// The node is reached through the dialer, as a proxy would do
+	proxied := listenStatus(t, true)
+	var dialed []string
+	_, err = Ping("node:25565", time.Second, func(network, address string) (stdnet.Conn, error) {
+		dialed = append(dialed, address)
+		return stdnet.Dial(network, proxied)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"node:25565"}, dialed)
+}
+
+// dialer returns a dial function failing for the addresses, successful dials are counted
+func dialer(failing ...string) (func(address string) (*net.Conn, error), map[string]int) {
+	dialed := make(map[string]int)
+	return func(address string) (*net.Conn, error) {
+		for _, f := range failing {
+			if f == address {
+				return nil, errors.New("connection refused")
+			}
+		}
+
+		dialed[address]++
+		return &net.Conn{}, nil
+	}, dialed
+}
+
+func TestPool_Weighted(t *testing.T) {
+	pool := NewPool("test")
+	pool.Update([]Target{{"a:25565", 3}, {"b:25565", 1}, {"c:25565", 0}}, StrategyWeighted, time.Minute, time.Second, nil)
+
+	dial, dialed := dialer()
+	for i := 0; i < 4000; i++ {
+		_, _, err := pool.Dial(dial)
+		assert.NoError(t, err)
+	}
+
+	assert.InDelta(t, 3000, dialed["a:25565"], 200)
+	assert.InDelta(t, 1000, dialed["b:25565"], 200)
+	assert.Zero(t, dialed["c:25565"])
+}
+
+func TestPool_LeastConnections(t *testing.T) {
+	pool := NewPool("test")
+	pool.Update([]Target{{"a:25565", 1}, {"b:25565", 2}}, StrategyLeastConnections, time.Minute, time.Second, nil)
+
+	dial, _ := dialer()
+	nodes := make([]*Node, 0)
+	for i := 0; i < 6; i++ {
+		_, node, err := pool.Dial(dial)
+		assert.NoError(t, err)
+		nodes = append(nodes, node)
+	}
+
+	a, b := pool.Nodes()[0], pool.Nodes()[1]
+	assert.Equal(t, 2, a.Active())
+	assert.Equal(t, 4, b.Active())
+
+	// The released session makes a the least loaded node
+	for _, node := range nodes {
+		if node == a {
+			node.Release()
+			break
+		}
+	}
+
+	_, node, err := pool.Dial(dial)
+	assert.NoError(t, err)
+	assert.Equal(t, a, node)
+}
+
+func TestPool_Failover(t *testing.T) {
+	pool := NewPool("test")
+	pool.Update([]Target{{"a:25565", 1}, {"b:25565", 1}}, StrategyLeastConnections, time.Minute, time.Second, nil)
+
+	dial, dialed := dialer("a:25565")
+	_, node, err := pool.Dial(dial)
+	assert.NoError(t, err)
+	assert.Equal(t, "b:25565", node.Address)
+	assert.False(t, pool.Nodes()[0].IsHealthy())
+
+	// The node which is down is not tried again while another one is up
+	for i := 0; i < 3; i++ {
+		_, node, err = pool.Dial(dial)
+		assert.NoError(t, err)
+		assert.Equal(t, "b:25565", node.Address)
+	}
+	assert.Equal(t, 4, dialed["b:25565"])
+
+	// Nodes which are down are still tried when there is nothing else
+	dial, dialed = dialer("b:25565")
+	_, node, err = pool.Dial(dial)
+	assert.NoError(t, err)
+	assert.Equal(t, "a:25565", node.Address)
+
+	dial, _ = dialer("a:25565", "b:25565")
+	_, _, err = pool.Dial(dial)
+	assert.True(t, errors.Is(err, ErrNoUpstream))
+}
+
+func TestPool_EgressFailure(t *testing.T) {
+	pool := NewPool("test")
+	pool.Update([]Target{{"a:25565", 1}}, StrategyWeighted, time.Minute, time.Second, nil)
+
+	// The egress of the session failing tells nothing about the node
+	_, _, err := pool.Dial(func(address string) (*net.Conn, error) {
+		return nil, &EgressError{Egress: "socks", Err: errors.New("connection refused")}
+	})
+	assert.True(t, errors.Is(err, ErrNoUpstream))
+	assert.Contains(t, err.Error(), "through egress socks")
+	assert.True(t, pool.Nodes()[0].IsHealthy())
+}
+
+func TestPool_Check(t *testing.T) {
+	up, stuck := listenStatus(t, true), listenStatus(t, false)
+
+	pool := NewPool("test")
+	pool.Update([]Target{{up, 1}, {stuck, 1}}, StrategyWeighted, time.Minute, 100*time.Millisecond, nil)
+	pool.Check()
+
+	nodes := pool.Nodes()
+	assert.True(t, nodes[0].IsHealthy())
+	assert.False(t, nodes[1].IsHealthy())
+
+	// Nodes which stay in the pool keep their state
+	pool.Update([]Target{{stuck, 2}}, StrategyWeighted, time.Minute, 100*time.Millisecond, nil)
+	assert.Equal(t, nodes[1], pool.Nodes()[0])
+	assert.False(t, pool.Nodes()[0].IsHealthy())
+	assert.Equal(t, 2, pool.Nodes()[0].Weight())
+
+	go pool.RunHealthChecks()
+	pool.Close()
+	pool.Close()
+}
@@ -0,0 +1,153 @@
+package upstream
+
+import (
+	"errors"
+	stdnet "net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/destructiqn/kogtevran/minecraft/net"
+)
+
+var ErrNoRoute = errors.New("no route for the hostname")
+
+// Route sends the players connecting with one of its hostnames to its pool. A route without hostnames
+// is the default one, it is taken when no other route has the hostname
+type Route struct {
+	Name      string
+	Hostnames []string
+
+	Targets  []Target
+	Strategy Strategy
+	Interval time.Duration
+	Timeout  time.Duration
+	// Dial connects to the nodes and runs their health checks, the dialer strategy may pick another dialer
+	// for the sessions
+	Dial Dialer
+	// Modules are the module options of the players on the route
+	Modules map[string]map[string]string
+
+	pool *Pool
+}
+
+func (r *Route) Pool() *Pool {
+	return r.pool
+}
+
+// Connect dials a node of the route with its dialer, see Pool.Dial
+func (r *Route) Connect() (*net.Conn, *Node, error) {
+	return r.ConnectWith(r.Dial)
+}
+
+// ConnectWith dials a node of the route with the dialer of the session
+func (r *Route) ConnectWith(dial Dialer) (*net.Conn, *Node, error) {
+	if dial == nil {
+		dial = stdnet.Dial
+	}
+
+	return r.pool.Dial(func(address string) (*net.Conn, error) {
+		return net.DialMC(address, dial)
+	})
+}
+
+// Router picks the route of a session by the hostname in its handshake
+type Router struct {
+	lock      sync.RWMutex
+	exact     map[string]*Route
+	wildcards map[string]*Route
+	fallback  *Route
+	pools     map[string]*Pool
+}
+
+func NewRouter() *Router {
+	return &Router{pools: make(map[string]*Pool)}
+}
+
+// Update replaces the routes. Pools are kept by route name, so nodes of a route which stays keep their
+// sessions and health. Health checks are started for new routes and stopped for removed ones
+func (r *Router) Update(routes []*Route) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	exact, wildcards := make(map[string]*Route), make(map[string]*Route)
+	pools := make(map[string]*Pool, len(routes))
+	var fallback *Route
+	var started []*Pool
+	for _, route := range routes {
+		pool, ok := r.pools[route.Name]
+		if ok {
+			delete(r.pools, route.Name)
+		} else {
+			pool = NewPool(route.Name)
+			started = append(started, pool)
+		}
+
+		pool.Update(route.Targets, route.Strategy, route.Interval, route.Timeout, route.Dial)
+		route.pool, pools[route.Name] = pool, pool
+
+		if len(route.Hostnames) == 0 {
+			fallback = route
+		}
+
+		for _, hostname := range route.Hostnames {
+			if strings.HasPrefix(hostname, "*.") {
+				wildcards[hostname[1:]] = route
+			} else {
+				exact[hostname] = route
+			}
+		}
+	}
+
+	for _, pool := range r.pools {
+		pool.Close()
+	}
+
+	for _, pool := range started {
+		go pool.RunHealthChecks()
+	}
+
+	r.exact, r.wildcards, r.fallback, r.pools = exact, wildcards, fallback, pools
+}
+
+// Route returns the route of the server address of a handshake. Exact hostnames are matched first,
+// then the wildcard with the longest suffix, sessions matching neither take the default route
+func (r *Router) Route(serverAddress string) (*Route, error) {
+	hostname := Hostname(serverAddress)
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if route, ok := r.exact[hostname]; ok {
+		return route, nil
+	}
+
+	// ".survival.proxy.local" is tried before ".proxy.local"
+	for suffix := hostname; ; suffix = suffix[1:] {
+		i := strings.IndexByte(suffix, '.')
+		if i < 0 {
+			break
+		}
+
+		suffix = suffix[i:]
+		if route, ok := r.wildcards[suffix]; ok {
+			return route, nil
+		}
+	}
+
+	if r.fallback == nil {
+		return nil, ErrNoRoute
+	}
+
+	return r.fallback, nil
+}
+
+// Hostname returns the hostname the client typed from the server address of its handshake. Forge and
+// BungeeCord append data after a null byte, a trailing dot is left by clients resolving SRV records
+func Hostname(serverAddress string) string {
+	if i := strings.IndexByte(serverAddress, 0); i >= 0 {
+		serverAddress = serverAddress[:i]
+	}
+
+	return strings.ToLower(strings.TrimSuffix(serverAddress, "."))
+}
@@ -0,0 +1,83 @@
+package upstream
+
+import (
+	"errors"
+	stdnet "net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRoute(name string, hostnames ...string) *Route {
+	return &Route{
+		Name:      name,
+		Hostnames: hostnames,
+		Targets:   []Target{{name + ":25565", 1}},
+		Interval:  time.Minute,
+		Timeout:   time.Second,
+	}
+}
+
+func TestHostname(t *testing.T) {
+	assert.Equal(t, "mini.proxy.local", Hostname("Mini.Proxy.Local"))
+	assert.Equal(t, "mini.proxy.local", Hostname("mini.proxy.local."))
+	assert.Equal(t, "mini.proxy.local", Hostname("mini.proxy.local\x00FML\x00"))
+}
+
+func TestRouter_Route(t *testing.T) {
+	router := NewRouter()
+	defaultRoute := newRoute("default")
+	mini, survival, local := newRoute("mini", "mini.proxy.local"), newRoute("survival", "*.survival.proxy.local"), newRoute("local", "*.proxy.local")
+	router.Update([]*Route{defaultRoute, mini, survival, local})
+
+	for serverAddress, expected := range map[string]*Route{
+		"mini.proxy.local":          mini,
+		"MINI.proxy.local.":         mini,
+		"eu.survival.proxy.local":   survival,
+		"a.eu.survival.proxy.local": survival,
+		"survival.proxy.local":      local,
+		"skyblock.proxy.local":      local,
+		"proxy.local":               defaultRoute,
+		"127.0.0.1":                 defaultRoute,
+		"":                          defaultRoute,
+	} {
+		route, err := router.Route(serverAddress)
+		assert.NoError(t, err)
+		assert.Equal(t, expected.Name, route.Name, serverAddress)
+	}
+
+	// Routes which stay keep their pool, removed routes are not picked anymore
+	pool := mini.Pool()
+	mini = newRoute("mini", "mini.proxy.local")
+	router.Update([]*Route{mini})
+	assert.Equal(t, pool, mini.Pool())
+
+	_, err := router.Route("skyblock.proxy.local")
+	assert.True(t, errors.Is(err, ErrNoRoute))
+	assert.Empty(t, defaultRoute.Pool().Nodes())
+}
+
+func TestRoute_Connect(t *testing.T) {
+	route := newRoute("mini", "mini.proxy.local")
+	var dialed []string
+	route.Dial = func(network, address string) (stdnet.Conn, error) {
+		dialed = append(dialed, address)
+		server, client := stdnet.Pipe()
+		_ = server.Close()
+		return client, nil
+	}
+
+	router := NewRouter()
+	router.Update([]*Route{route})
+
+	conn, node, err := route.Connect()
+	assert.NoError(t, err)
+	assert.NotNil(t, conn)
+	assert.Equal(t, "mini:25565", node.Address)
+	assert.Equal(t, []string{"mini:25565"}, dialed)
+	assert.Equal(t, 1, node.Active())
+	_ = conn.Close()
+
+	router.Update(nil)
+}